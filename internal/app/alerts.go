@@ -0,0 +1,208 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"nasbot/internal/format"
+	"nasbot/internal/notify"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ═══════════════════════════════════════════════════════════════════
+//  ALERT FAN-OUT
+// ═══════════════════════════════════════════════════════════════════
+
+const alertDeliveryTimeout = 15 * time.Second
+
+// alertMessage builds a Markdown alert with no recipient; sendAlert fills in
+// the chat ID for each subscriber.
+func alertMessage(text string) tgbotapi.MessageConfig {
+	m := tgbotapi.NewMessage(0, text)
+	m.ParseMode = "Markdown"
+	return m
+}
+
+// alertRoutes returns the notification channels enabled in c, Telegram first.
+func alertRoutes(bot BotAPI, c *Config, recipients func(topic string) []int64) []notify.Route {
+	n := c.Notifiers
+	var routes []notify.Route
+	if !n.Telegram.Disabled {
+		routes = append(routes, notify.Route{
+			Notifier: &notify.Telegram{Bot: bot, Recipients: recipients},
+			Topics:   n.Telegram.Topics,
+		})
+	}
+	if n.Webhook.Enabled && n.Webhook.URL != "" {
+		routes = append(routes, notify.Route{
+			Notifier: &notify.Webhook{URL: n.Webhook.URL, Headers: n.Webhook.Headers},
+			Topics:   n.Webhook.Topics,
+		})
+	}
+	if n.Ntfy.Enabled && n.Ntfy.Topic != "" {
+		routes = append(routes, notify.Route{
+			Notifier: &notify.Ntfy{Server: n.Ntfy.Server, Topic: n.Ntfy.Topic, Token: n.Ntfy.Token},
+			Topics:   n.Ntfy.Topics,
+		})
+	}
+	if n.Gotify.Enabled && n.Gotify.URL != "" {
+		routes = append(routes, notify.Route{
+			Notifier: &notify.Gotify{URL: n.Gotify.URL, Token: n.Gotify.Token},
+			Topics:   n.Gotify.Topics,
+		})
+	}
+	if n.SMTP.Enabled && n.SMTP.Host != "" && len(n.SMTP.To) > 0 {
+		routes = append(routes, notify.Route{
+			Notifier: &notify.SMTP{
+				Host:     n.SMTP.Host,
+				Port:     n.SMTP.Port,
+				Username: n.SMTP.Username,
+				Password: n.SMTP.Password,
+				From:     n.SMTP.From,
+				To:       n.SMTP.To,
+			},
+			Topics: n.SMTP.Topics,
+		})
+	}
+	return routes
+}
+
+// sendAlert delivers m on every channel configured for topic. Telegram is
+// sent inline so callers keep their ordering; external channels run in the
+// background so a slow SMTP or webhook server cannot stall the monitors.
+func sendAlert(bot BotAPI, c *Config, topic string, m tgbotapi.MessageConfig) {
+	dispatchAlert(bot, c, topic, m, c.AlertRecipients, nil)
+}
+
+// sendEscalation re-sends an unacknowledged critical alert to the usual
+// recipients plus the escalation users, and to the escalation channels even
+// when their topic filter would skip it.
+func sendEscalation(bot BotAPI, c *Config, m tgbotapi.MessageConfig) {
+	esc := c.Alerts.Escalation
+	recipients := func(topic string) []int64 {
+		ids := c.AlertRecipients(topic)
+		for _, id := range esc.Users {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+	dispatchAlert(bot, c, AlertTopicCritical, m, recipients, esc.Channels)
+}
+
+func dispatchAlert(bot BotAPI, c *Config, topic string, m tgbotapi.MessageConfig, recipients func(string) []int64, forced []string) {
+	msg := notify.Message{Topic: topic, Text: m.Text, Markdown: m.ParseMode != ""}
+	if kb, ok := m.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup); ok {
+		msg.Keyboard = &kb
+	}
+
+	for _, r := range alertRoutes(bot, c, recipients) {
+		n := r.Notifier
+		if !r.Accepts(topic) && !slices.Contains(forced, n.Name()) {
+			continue
+		}
+		if _, ok := n.(*notify.Telegram); ok {
+			deliverAlert(n, msg)
+			continue
+		}
+		goSafe("notify-"+n.Name(), func() { deliverAlert(n, msg) })
+	}
+}
+
+// criticalAlertMessage builds a critical alert carrying the acknowledge,
+// snooze and mute buttons for ids; withAI adds the "Analyze with AI" row.
+func criticalAlertMessage(ctx *AppContext, text string, withAI bool, ids ...string) tgbotapi.MessageConfig {
+	m := alertMessage(text)
+	m.ReplyMarkup = alertKeyboard(ctx, ctx.Monitor.Alerts.Ref(ids...), withAI, false)
+	return m
+}
+
+// alertKeyboard renders the alert action buttons for ref. Muted alerts only
+// offer to unmute.
+func alertKeyboard(ctx *AppContext, ref string, withAI, muted bool) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if muted {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("alert_btn_unmute"), "alert_unmute_"+ref),
+		))
+	} else {
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("alert_btn_ack"), "alert_ack_"+ref),
+				tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("alert_btn_mute"), "alert_mute_"+ref),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("alert_btn_snooze"), "alert_snooze_"+ref),
+				tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("alert_btn_tomorrow"), "alert_tomorrow_"+ref),
+			),
+		)
+	}
+	if withAI {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🤖 "+ctx.Tr("analyze_with_ai"), "ai_analyze_critical"),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// notifyCritical sends a critical alert for ev, as an escalation when the
+// manager escalated it.
+func notifyCritical(ctx *AppContext, bot BotAPI, ev AlertEvent, text string, withAI bool) {
+	if ev.Kind == AlertEscalated {
+		sendEscalation(bot, ctx.Config, criticalAlertMessage(ctx, fmt.Sprintf(ctx.Tr("alert_escalated"), text), withAI, ev.ID))
+		return
+	}
+	sendAlert(bot, ctx.Config, AlertTopicCritical, criticalAlertMessage(ctx, text, withAI, ev.ID))
+}
+
+// alertRuleFor resolves the rule for an alert ID. RAID and network alerts
+// default to their watchdog settings unless alerts.rules overrides them.
+func alertRuleFor(c *Config, id string) AlertRule {
+	rc, ok := c.Alerts.Rule(id)
+	if !ok {
+		rc = c.Alerts.Defaults
+		switch {
+		case id == "raid":
+			rc.CooldownMinutes = c.RaidWatchdog.CooldownMins
+			rc.NotifyResolved = c.RaidWatchdog.RecoveryNotify
+		case id == "net" || strings.HasPrefix(id, "net:"):
+			rc.CooldownMinutes = c.NetworkWatchdog.CooldownMins
+			rc.NotifyResolved = c.NetworkWatchdog.RecoveryNotify
+		}
+	}
+	cooldown := rc.CooldownMinutes
+	if cooldown <= 0 {
+		cooldown = c.Intervals.CriticalAlertCooldownMins
+	}
+	return AlertRule{
+		Sustain:        time.Duration(rc.SustainMinutes) * time.Minute,
+		Hysteresis:     rc.Hysteresis,
+		Cooldown:       time.Duration(cooldown) * time.Minute,
+		NotifyResolved: rc.NotifyResolved,
+		EscalateAfter:  time.Duration(c.Alerts.Escalation.AfterMinutes) * time.Minute,
+	}
+}
+
+// observeAlert feeds obs into the shared alert manager using its configured rule.
+func observeAlert(ctx *AppContext, obs AlertObservation) AlertEvent {
+	return ctx.Monitor.Alerts.Observe(time.Now(), obs, alertRuleFor(ctx.Config, obs.ID))
+}
+
+// resolvedText renders the generic recovery notice for label.
+func resolvedText(ctx *AppContext, label string, ev AlertEvent) string {
+	return fmt.Sprintf(ctx.Tr("alert_resolved"), label, format.FormatDuration(time.Since(ev.Since)))
+}
+
+func deliverAlert(n notify.Notifier, msg notify.Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), alertDeliveryTimeout)
+	defer cancel()
+	if err := n.Notify(ctx, msg); err != nil {
+		slog.Error("Alert delivery failed", "channel", n.Name(), "topic", msg.Topic, "err", err)
+	}
+}
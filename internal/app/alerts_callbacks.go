@@ -0,0 +1,98 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleAlertCallback applies the buttons attached to critical alerts.
+// Data is "alert_<action>_<ref>", where ref identifies the alert IDs the
+// message was sent for.
+func handleAlertCallback(ctx *AppContext, bot BotAPI, chatID int64, msgID int, query *tgbotapi.CallbackQuery, data string) bool {
+	action, ref, ok := strings.Cut(strings.TrimPrefix(data, "alert_"), "_")
+	if !ok {
+		return false
+	}
+	alerts := ctx.Monitor.Alerts
+	ids := alerts.Lookup(ref)
+	if len(ids) == 0 {
+		safeSend(bot, tgbotapi.NewMessage(chatID, ctx.Tr("alert_expired")))
+		return true
+	}
+	list := "`" + strings.Join(ids, "`, `") + "`"
+
+	var text string
+	muted := false
+	switch action {
+	case "ack":
+		for _, id := range ids {
+			alerts.Ack(id)
+		}
+		text = fmt.Sprintf(ctx.Tr("alert_acked"), list)
+	case "snooze", "tomorrow":
+		until := time.Now().Add(time.Hour)
+		if action == "tomorrow" {
+			until = alertSnoozeTomorrow(ctx, time.Now())
+		}
+		for _, id := range ids {
+			alerts.Snooze(id, until)
+		}
+		text = fmt.Sprintf(ctx.Tr("alert_snoozed"), list, until.In(ctx.State.TimeLocation).Format("Mon 15:04"))
+	case "mute", "unmute":
+		muted = action == "mute"
+		for _, id := range ids {
+			alerts.SetMuted(id, muted)
+		}
+		text = fmt.Sprintf(ctx.Tr("alert_"+action+"d"), list)
+	default:
+		return false
+	}
+	saveState(ctx)
+
+	who := ""
+	if query != nil && query.From != nil {
+		who = query.From.UserName
+		if who == "" {
+			who = query.From.FirstName
+		}
+	}
+	ctx.State.AddEvent("info", fmt.Sprintf("Alert %s by %s: %s", action, who, strings.Join(ids, ", ")))
+
+	// The alert buttons are rebuilt for the new state; the actions the
+	// alerts offered, such as truncating a log, are kept as they were.
+	withAI := false
+	var actions []tgbotapi.InlineKeyboardButton
+	if query != nil && query.Message != nil && query.Message.ReplyMarkup != nil {
+		for _, row := range query.Message.ReplyMarkup.InlineKeyboard {
+			for _, b := range row {
+				switch {
+				case b.CallbackData != nil && *b.CallbackData == "ai_analyze_critical":
+					withAI = true
+				case b.CallbackData == nil || !strings.HasPrefix(*b.CallbackData, "alert_"):
+					actions = append(actions, b)
+				}
+			}
+		}
+	}
+	kb := withActionRows(alertKeyboard(ctx, ref, withAI, muted), actions)
+	safeSend(bot, tgbotapi.NewEditMessageReplyMarkup(chatID, msgID, kb))
+	sendMarkdown(bot, chatID, text)
+	return true
+}
+
+// alertSnoozeTomorrow returns tomorrow morning: the end of quiet hours when
+// they are enabled, 08:00 otherwise.
+func alertSnoozeTomorrow(ctx *AppContext, now time.Time) time.Time {
+	wake := TimePoint{Hour: 8}
+	ctx.Settings.Mu.RLock()
+	if ctx.Settings.QuietHours.Enabled {
+		wake = ctx.Settings.QuietHours.End
+	}
+	ctx.Settings.Mu.RUnlock()
+
+	local := now.In(ctx.State.TimeLocation)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, wake.Hour, wake.Minute, 0, 0, local.Location())
+}
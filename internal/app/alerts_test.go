@@ -0,0 +1,278 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestSendAlertFansOutToSubscribers(t *testing.T) {
+	c := &Config{
+		AllowedUserID: 1,
+		Users: []UserConfig{
+			{ID: 2, Role: RoleViewer, Subscriptions: []string{AlertTopicCritical}},
+			{ID: 3, Role: RoleOperator, Subscriptions: []string{AlertTopicReport}},
+		},
+	}
+	bot := &fakeBot{}
+
+	sendAlert(bot, c, AlertTopicCritical, alertMessage("disk on fire"))
+
+	if len(bot.sent) != 2 {
+		t.Fatalf("expected 2 alerts, got %d", len(bot.sent))
+	}
+	for i, want := range []int64{1, 2} {
+		m, ok := bot.sent[i].(tgbotapi.MessageConfig)
+		if !ok {
+			t.Fatalf("unexpected message type %T", bot.sent[i])
+		}
+		if m.ChatID != want || m.ParseMode != "Markdown" {
+			t.Fatalf("alert %d sent to %d (%q), want %d", i, m.ChatID, m.ParseMode, want)
+		}
+	}
+}
+
+func TestHandleCallbackViewerDenied(t *testing.T) {
+	prev := app
+	app = newTestAppContext()
+	app.Config.Users = []UserConfig{{ID: 5, Role: RoleViewer}}
+	t.Cleanup(func() { app = prev })
+
+	bot := &fakeBot{}
+	query := &tgbotapi.CallbackQuery{
+		ID:      "1",
+		Data:    "set_lang_it",
+		From:    &tgbotapi.User{ID: 5},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 5}, MessageID: 10},
+	}
+
+	handleCallback(bot, query)
+	if app.Settings.GetLanguage() != "en" {
+		t.Fatalf("viewer should not be able to change settings")
+	}
+	if len(bot.sent) == 0 {
+		t.Fatalf("expected a permission denied reply")
+	}
+}
+
+func TestSendAlertRoutesExternalChannelsByTopic(t *testing.T) {
+	hits := make(chan string, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits <- r.URL.Path
+	}))
+	defer srv.Close()
+
+	c := &Config{
+		AllowedUserID: 1,
+		Notifiers: NotifiersConfig{
+			Telegram: TelegramNotifierConfig{Topics: []string{AlertTopicCritical}},
+			Webhook:  WebhookNotifierConfig{Enabled: true, URL: srv.URL + "/hook", Topics: []string{AlertTopicWarning}},
+		},
+	}
+	bot := &fakeBot{}
+
+	sendAlert(bot, c, AlertTopicWarning, alertMessage("⚠️ *Disk warning*"))
+
+	select {
+	case path := <-hits:
+		if path != "/hook" {
+			t.Fatalf("webhook hit %q", path)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("webhook was not called")
+	}
+	if len(bot.sent) != 0 {
+		t.Fatalf("telegram is limited to critical alerts, got %d messages", len(bot.sent))
+	}
+}
+
+func TestProcessMonitorAlertsMergesCriticalAndResolved(t *testing.T) {
+	ctx := newTestAppContext()
+	ctx.Config.Alerts = AlertsConfig{Defaults: AlertRuleConfig{CooldownMinutes: 30, NotifyResolved: true}}
+	bot := &fakeBot{}
+
+	firing := []MonitorAlert{
+		{ID: "cpu", Label: "CPU", Level: AlertLevelCritical, Message: "🧠 CPU critical: `97.0%`", Value: 97, Threshold: 95},
+		{ID: "ram", Label: "RAM", Level: AlertLevelCritical, Message: "💾 RAM critical: `96.0%`", Value: 96, Threshold: 95},
+		{ID: "swap", Label: "Swap", Level: AlertLevelWarning, Message: "Swap high: 60.0%", Value: 60, Threshold: 50},
+	}
+	processMonitorAlerts(ctx, bot, firing)
+	if len(bot.sent) != 1 {
+		t.Fatalf("expected one merged critical alert, got %d messages", len(bot.sent))
+	}
+	m := bot.sent[0].(tgbotapi.MessageConfig)
+	if !strings.Contains(m.Text, "CPU critical") || !strings.Contains(m.Text, "RAM critical") || strings.Contains(m.Text, "Swap") {
+		t.Fatalf("unexpected critical alert text: %q", m.Text)
+	}
+
+	// Same readings on the next tick are deduplicated.
+	processMonitorAlerts(ctx, bot, firing)
+	if len(bot.sent) != 1 {
+		t.Fatalf("duplicate critical alert sent")
+	}
+
+	processMonitorAlerts(ctx, bot, []MonitorAlert{
+		{ID: "cpu", Label: "CPU", Value: 20, Threshold: 90},
+		{ID: "ram", Label: "RAM", Level: AlertLevelCritical, Message: "💾 RAM critical: `96.0%`", Value: 96, Threshold: 95},
+	})
+	if len(bot.sent) != 2 {
+		t.Fatalf("expected a resolved notice, got %d messages", len(bot.sent))
+	}
+	if m := bot.sent[1].(tgbotapi.MessageConfig); !strings.Contains(m.Text, "Resolved") || !strings.Contains(m.Text, "CPU") {
+		t.Fatalf("unexpected resolved text: %q", m.Text)
+	}
+}
+
+func TestProcessMonitorAlertsHeldInQuietHours(t *testing.T) {
+	ctx := newTestAppContext()
+	ctx.Config.Alerts = AlertsConfig{Defaults: AlertRuleConfig{CooldownMinutes: 30}}
+	bot := &fakeBot{}
+	now := time.Now().In(ctx.State.TimeLocation)
+	ctx.Settings.QuietHours = QuietSettings{Enabled: true,
+		Start: TimePoint{Hour: now.Add(-time.Hour).Hour()},
+		End:   TimePoint{Hour: now.Add(2 * time.Hour).Hour()},
+	}
+	firing := []MonitorAlert{{ID: "cpu", Label: "CPU", Level: AlertLevelCritical, Message: "🧠 CPU critical: `97.0%`", Value: 97, Threshold: 95}}
+
+	processMonitorAlerts(ctx, bot, firing)
+	processMonitorAlerts(ctx, bot, firing)
+	if len(bot.sent) != 0 {
+		t.Fatalf("nothing should be sent in quiet hours, got %d messages", len(bot.sent))
+	}
+
+	ctx.Settings.QuietHours.Enabled = false
+	processMonitorAlerts(ctx, bot, firing)
+	if len(bot.sent) != 1 || !strings.Contains(bot.sent[0].(tgbotapi.MessageConfig).Text, "CPU critical") {
+		t.Fatalf("the held alert should be sent after quiet hours, got %d messages", len(bot.sent))
+	}
+	if events := ctx.State.GetEvents(); len(events) != 1 {
+		t.Fatalf("the fire should be recorded once, got %+v", events)
+	}
+}
+
+func TestAlertRuleFor(t *testing.T) {
+	c := &Config{
+		Intervals: IntervalsConfig{CriticalAlertCooldownMins: 45},
+		Alerts: AlertsConfig{
+			Defaults: AlertRuleConfig{Hysteresis: 2},
+			Rules:    map[string]AlertRuleConfig{"container:": {CooldownMinutes: 10, NotifyResolved: true}},
+		},
+		RaidWatchdog: RaidWatchdogConfig{CooldownMins: 20, RecoveryNotify: true},
+	}
+
+	if r := alertRuleFor(c, "cpu"); r.Cooldown != 45*time.Minute || r.Hysteresis != 2 {
+		t.Fatalf("cpu should use defaults with the legacy cooldown, got %+v", r)
+	}
+	if r := alertRuleFor(c, "container:db"); r.Cooldown != 10*time.Minute || !r.NotifyResolved {
+		t.Fatalf("container rule not applied, got %+v", r)
+	}
+	if r := alertRuleFor(c, "raid"); r.Cooldown != 20*time.Minute || !r.NotifyResolved {
+		t.Fatalf("raid should follow the watchdog settings, got %+v", r)
+	}
+}
+
+func TestHandleAlertCallbackAckAndMute(t *testing.T) {
+	t.Setenv("NASBOT_STATE_FILE", t.TempDir()+"/state.json")
+	ctx := newTestAppContext()
+	bot := &fakeBot{}
+
+	m := criticalAlertMessage(ctx, "🚨 *Critical*", true, "cpu", "ram")
+	kb := m.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	ack := *kb.InlineKeyboard[0][0].CallbackData
+	mute := *kb.InlineKeyboard[0][1].CallbackData
+	query := &tgbotapi.CallbackQuery{
+		From:    &tgbotapi.User{ID: 1, UserName: "owner"},
+		Message: &tgbotapi.Message{MessageID: 7, Chat: &tgbotapi.Chat{ID: 1}, ReplyMarkup: &kb},
+	}
+
+	if !handleAlertCallback(ctx, bot, 1, 7, query, ack) {
+		t.Fatalf("ack callback not handled")
+	}
+	if !ctx.Monitor.Alerts.Silence("cpu").Acked || !ctx.Monitor.Alerts.Silence("ram").Acked {
+		t.Fatalf("both alerts of the message should be acknowledged")
+	}
+
+	handleAlertCallback(ctx, bot, 1, 7, query, mute)
+	if !ctx.Monitor.Alerts.Silence("cpu").Muted {
+		t.Fatalf("cpu should be muted")
+	}
+	var edited tgbotapi.EditMessageReplyMarkupConfig
+	for _, c := range bot.sent {
+		if e, ok := c.(tgbotapi.EditMessageReplyMarkupConfig); ok {
+			edited = e
+		}
+	}
+	rows := edited.ReplyMarkup.InlineKeyboard
+	if len(rows) != 2 || !strings.HasPrefix(*rows[0][0].CallbackData, "alert_unmute_") || *rows[1][0].CallbackData != "ai_analyze_critical" {
+		t.Fatalf("muted keyboard should offer unmute and keep the AI button, got %+v", rows)
+	}
+
+	handleAlertCallback(ctx, bot, 1, 7, query, "alert_ack_deadbeef00")
+	last := bot.sent[len(bot.sent)-1].(tgbotapi.MessageConfig)
+	if last.Text != ctx.Tr("alert_expired") {
+		t.Fatalf("unknown ref should report an expired alert, got %q", last.Text)
+	}
+}
+
+func TestHandleAlertCallbackKeepsActions(t *testing.T) {
+	t.Setenv("NASBOT_STATE_FILE", t.TempDir()+"/state.json")
+	ctx := newTestAppContext()
+	bot := &fakeBot{}
+
+	m := criticalAlertMessage(ctx, "🚨 *Critical*", true, "container_log:web")
+	kb := withActionRows(m.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup), []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData("✂️ web", "dlog_ask_web"),
+	})
+	ack := *kb.InlineKeyboard[0][0].CallbackData
+	query := &tgbotapi.CallbackQuery{
+		From:    &tgbotapi.User{ID: 1, UserName: "owner"},
+		Message: &tgbotapi.Message{MessageID: 7, Chat: &tgbotapi.Chat{ID: 1}, ReplyMarkup: &kb},
+	}
+
+	handleAlertCallback(ctx, bot, 1, 7, query, ack)
+	var edited tgbotapi.EditMessageReplyMarkupConfig
+	for _, c := range bot.sent {
+		if e, ok := c.(tgbotapi.EditMessageReplyMarkupConfig); ok {
+			edited = e
+		}
+	}
+	data := keyboardData(edited.ReplyMarkup)
+	if len(data) != len(keyboardData(&kb)) || data[len(data)-1] != "dlog_ask_web" || data[len(data)-2] != "ai_analyze_critical" {
+		t.Fatalf("alert actions lost after ack: %v", data)
+	}
+}
+
+func TestSendEscalationAddsUsersAndChannels(t *testing.T) {
+	hits := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits <- r.URL.Path
+	}))
+	defer srv.Close()
+
+	c := &Config{
+		AllowedUserID: 1,
+		Notifiers: NotifiersConfig{
+			Webhook: WebhookNotifierConfig{Enabled: true, URL: srv.URL + "/esc", Topics: []string{AlertTopicInfo}},
+		},
+		Alerts: AlertsConfig{Escalation: AlertEscalationConfig{Users: []int64{1, 9}, Channels: []string{"webhook"}}},
+	}
+	bot := &fakeBot{}
+
+	sendEscalation(bot, c, alertMessage("still down"))
+
+	if len(bot.sent) != 2 || bot.sent[1].(tgbotapi.MessageConfig).ChatID != 9 {
+		t.Fatalf("escalation should reach the owner and user 9 once each, got %+v", bot.sent)
+	}
+	select {
+	case path := <-hits:
+		if path != "/esc" {
+			t.Fatalf("unexpected webhook path %q", path)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("escalation channel was not notified despite its topic filter")
+	}
+}
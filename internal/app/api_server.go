@@ -0,0 +1,380 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"nasbot/internal/metrics"
+)
+
+// ═══════════════════════════════════════════════════════════════════
+//  HTTP API — Prometheus /metrics and JSON status
+// ═══════════════════════════════════════════════════════════════════
+
+const defaultAPIListen = ":9184"
+
+type apiVolume struct {
+	Mount       string  `json:"mount"`
+	UsedPercent float64 `json:"used_percent"`
+	FreeBytes   uint64  `json:"free_bytes"`
+}
+
+type apiSmart struct {
+	Device       string           `json:"device"`
+	Model        string           `json:"model,omitempty"`
+	TemperatureC int              `json:"temperature_c"`
+	Health       string           `json:"health"`
+	PowerOnHours *int64           `json:"power_on_hours,omitempty"`
+	PercentUsed  *int             `json:"percent_used,omitempty"`
+	Counters     map[string]int64 `json:"counters,omitempty"`
+}
+
+type apiContainer struct {
+	Name    string `json:"name"`
+	Image   string `json:"image"`
+	Status  string `json:"status"`
+	Running bool   `json:"running"`
+	Health  string `json:"health,omitempty"`
+}
+
+type apiNetworkWatchdog struct {
+	FailCount          int       `json:"fail_count"`
+	ConsecutiveDegrade int       `json:"consecutive_degraded"`
+	Down               bool      `json:"down"`
+	DownSince          time.Time `json:"down_since,omitempty"`
+	LastCheck          time.Time `json:"last_check,omitempty"`
+}
+
+type apiKernelWatchdog struct {
+	ConsecutiveErrors int       `json:"consecutive_errors"`
+	LastError         string    `json:"last_error,omitempty"`
+	LastCheck         time.Time `json:"last_check,omitempty"`
+}
+
+type apiWatchdogs struct {
+	Network          apiNetworkWatchdog `json:"network"`
+	Kernel           apiKernelWatchdog  `json:"kernel"`
+	DockerFailing    bool               `json:"docker_failing"`
+	HealthInDowntime bool               `json:"healthchecks_in_downtime"`
+}
+
+type apiHealthchecks struct {
+	TotalPings      int       `json:"total_pings"`
+	SuccessfulPings int       `json:"successful_pings"`
+	FailedPings     int       `json:"failed_pings"`
+	LastPingTime    time.Time `json:"last_ping_time,omitempty"`
+	LastPingSuccess bool      `json:"last_ping_success"`
+}
+
+// apiSnapshot is the state exposed by both /metrics and /api/v1/status.
+type apiSnapshot struct {
+	Time           time.Time         `json:"time"`
+	Ready          bool              `json:"ready"`
+	BotUptimeSecs  float64           `json:"bot_uptime_seconds"`
+	HostUptimeSecs uint64            `json:"host_uptime_seconds"`
+	CPUPercent     float64           `json:"cpu_percent"`
+	CPUTempC       float64           `json:"cpu_temp_c"`
+	RAMPercent     float64           `json:"ram_percent"`
+	RAMFreeMB      uint64            `json:"ram_free_mb"`
+	RAMTotalMB     uint64            `json:"ram_total_mb"`
+	SwapPercent    float64           `json:"swap_percent"`
+	Load1m         float64           `json:"load_1m"`
+	Load5m         float64           `json:"load_5m"`
+	Load15m        float64           `json:"load_15m"`
+	DiskReadMBs    float64           `json:"disk_read_mbs"`
+	DiskWriteMBs   float64           `json:"disk_write_mbs"`
+	DiskUtil       float64           `json:"disk_util_percent"`
+	NetRxMbps      float64           `json:"net_rx_mbps"`
+	NetTxMbps      float64           `json:"net_tx_mbps"`
+	NetRxTotalMB   float64           `json:"net_rx_total_mb"`
+	NetTxTotalMB   float64           `json:"net_tx_total_mb"`
+	Volumes        []apiVolume       `json:"volumes"`
+	Smart          []apiSmart        `json:"smart"`
+	Containers     []apiContainer    `json:"containers"`
+	Healthchecks   apiHealthchecks   `json:"healthchecks"`
+	Watchdogs      apiWatchdogs      `json:"watchdogs"`
+	Alerts         map[string]string `json:"alerts"`
+}
+
+func buildAPISnapshot(ctx *AppContext) apiSnapshot {
+	s, ready := ctx.Stats.Get()
+	snap := apiSnapshot{
+		Time:           time.Now(),
+		Ready:          ready,
+		HostUptimeSecs: s.Uptime,
+		CPUPercent:     s.CPU,
+		CPUTempC:       readCPUTemp(),
+		RAMPercent:     s.RAM,
+		RAMFreeMB:      s.RAMFreeMB,
+		RAMTotalMB:     s.RAMTotalMB,
+		SwapPercent:    s.Swap,
+		Load1m:         s.Load1m,
+		Load5m:         s.Load5m,
+		Load15m:        s.Load15m,
+		DiskReadMBs:    s.ReadMBs,
+		DiskWriteMBs:   s.WriteMBs,
+		DiskUtil:       s.DiskUtil,
+		NetRxMbps:      s.NetRxMbps,
+		NetTxMbps:      s.NetTxMbps,
+		NetRxTotalMB:   s.NetRxTotalMB,
+		NetTxTotalMB:   s.NetTxTotalMB,
+		Volumes:        []apiVolume{},
+		Smart:          []apiSmart{},
+		Containers:     []apiContainer{},
+		Alerts:         ctx.Monitor.Alerts.Active(),
+	}
+	if ctx.Bot != nil {
+		snap.BotUptimeSecs = time.Since(ctx.Bot.StartTime).Seconds()
+	}
+
+	if ctx.Config.Paths.SSD != "" {
+		snap.Volumes = append(snap.Volumes, apiVolume{Mount: ctx.Config.Paths.SSD, UsedPercent: s.VolSSD.Used, FreeBytes: s.VolSSD.Free})
+	}
+	mounts := make([]string, 0, len(s.SecondaryVols))
+	for m := range s.SecondaryVols {
+		mounts = append(mounts, m)
+	}
+	sort.Strings(mounts)
+	for _, m := range mounts {
+		v := s.SecondaryVols[m]
+		snap.Volumes = append(snap.Volumes, apiVolume{Mount: m, UsedPercent: v.Used, FreeBytes: v.Free})
+	}
+
+	for _, c := range getCachedContainerList(ctx) {
+		snap.Containers = append(snap.Containers, apiContainer{Name: c.Name, Image: c.Image, Status: c.Status, Running: c.Running, Health: c.Health})
+	}
+
+	ctx.Monitor.Mu.Lock()
+	for dev, res := range ctx.Monitor.SmartCache {
+		d := apiSmart{Device: dev, Model: res.Model, TemperatureC: res.Temp, Health: res.Health, Counters: res.Counters}
+		if res.PowerOnHours >= 0 {
+			d.PowerOnHours = &res.PowerOnHours
+		}
+		if res.PercentUsed >= 0 {
+			d.PercentUsed = &res.PercentUsed
+		}
+		snap.Smart = append(snap.Smart, d)
+	}
+	hc := ctx.Monitor.Healthchecks
+	snap.Healthchecks = apiHealthchecks{
+		TotalPings:      hc.TotalPings,
+		SuccessfulPings: hc.SuccessfulPings,
+		FailedPings:     hc.FailedPings,
+		LastPingTime:    hc.LastPingTime,
+		LastPingSuccess: hc.LastPingSuccess,
+	}
+	snap.Watchdogs = apiWatchdogs{
+		Network: apiNetworkWatchdog{
+			FailCount:          ctx.Monitor.NetFailCount,
+			ConsecutiveDegrade: ctx.Monitor.NetConsecutiveDegraded,
+			Down:               !ctx.Monitor.NetDownSince.IsZero(),
+			DownSince:          ctx.Monitor.NetDownSince,
+			LastCheck:          ctx.Monitor.NetLastCheckTime,
+		},
+		Kernel: apiKernelWatchdog{
+			ConsecutiveErrors: ctx.Monitor.KwConsecutiveCheckErrors,
+			LastError:         ctx.Monitor.KwLastCheckError,
+			LastCheck:         ctx.Monitor.KwLastCheckTime,
+		},
+		HealthInDowntime: ctx.Monitor.HealthInDowntime,
+	}
+	ctx.Monitor.Mu.Unlock()
+	sort.Slice(snap.Smart, func(i, j int) bool { return snap.Smart[i].Device < snap.Smart[j].Device })
+
+	ctx.State.Mu.Lock()
+	snap.Watchdogs.DockerFailing = !ctx.State.DockerFailure.IsZero()
+	ctx.State.Mu.Unlock()
+
+	return snap
+}
+
+func renderMetrics(snap apiSnapshot) string {
+	var w metrics.Writer
+	const mb = 1024 * 1024
+
+	w.Bool("nasbot_stats_ready", "Whether the stats collector has produced a sample.", snap.Ready)
+	w.Gauge("nasbot_bot_uptime_seconds", "Seconds since the bot started.", snap.BotUptimeSecs)
+	w.Gauge("nasbot_host_uptime_seconds", "Host uptime in seconds.", float64(snap.HostUptimeSecs))
+	w.Gauge("nasbot_cpu_usage_percent", "CPU usage in percent.", snap.CPUPercent)
+	if snap.CPUTempC > 0 {
+		w.Gauge("nasbot_cpu_temperature_celsius", "CPU temperature.", snap.CPUTempC)
+	}
+	w.Gauge("nasbot_memory_usage_percent", "RAM usage in percent.", snap.RAMPercent)
+	w.Gauge("nasbot_memory_free_bytes", "Available RAM in bytes.", float64(snap.RAMFreeMB)*mb)
+	w.Gauge("nasbot_memory_total_bytes", "Total RAM in bytes.", float64(snap.RAMTotalMB)*mb)
+	w.Gauge("nasbot_swap_usage_percent", "Swap usage in percent.", snap.SwapPercent)
+	w.Gauge("nasbot_load", "System load average.", snap.Load1m, metrics.L("period", "1m"))
+	w.Gauge("nasbot_load", "System load average.", snap.Load5m, metrics.L("period", "5m"))
+	w.Gauge("nasbot_load", "System load average.", snap.Load15m, metrics.L("period", "15m"))
+	w.Gauge("nasbot_disk_read_bytes_per_second", "Aggregate disk read throughput.", snap.DiskReadMBs*mb)
+	w.Gauge("nasbot_disk_write_bytes_per_second", "Aggregate disk write throughput.", snap.DiskWriteMBs*mb)
+	w.Gauge("nasbot_disk_util_percent", "Highest disk utilization in percent.", snap.DiskUtil)
+	w.Gauge("nasbot_network_receive_bits_per_second", "Network receive rate.", snap.NetRxMbps*1e6)
+	w.Gauge("nasbot_network_transmit_bits_per_second", "Network transmit rate.", snap.NetTxMbps*1e6)
+	w.Counter("nasbot_network_receive_bytes_total", "Bytes received since boot.", snap.NetRxTotalMB*mb)
+	w.Counter("nasbot_network_transmit_bytes_total", "Bytes sent since boot.", snap.NetTxTotalMB*mb)
+
+	for _, v := range snap.Volumes {
+		w.Gauge("nasbot_volume_used_percent", "Used space per volume in percent.", v.UsedPercent, metrics.L("mount", v.Mount))
+	}
+	for _, v := range snap.Volumes {
+		w.Gauge("nasbot_volume_free_bytes", "Free space per volume in bytes.", float64(v.FreeBytes), metrics.L("mount", v.Mount))
+	}
+
+	for _, d := range snap.Smart {
+		if d.TemperatureC > 0 {
+			w.Gauge("nasbot_smart_temperature_celsius", "Disk temperature reported by SMART.", float64(d.TemperatureC), metrics.L("device", d.Device))
+		}
+	}
+	for _, d := range snap.Smart {
+		w.Bool("nasbot_smart_healthy", "1 unless SMART reports the disk as failing.", !strings.Contains(strings.ToUpper(d.Health), "FAIL"), metrics.L("device", d.Device))
+	}
+	for _, d := range snap.Smart {
+		if d.PowerOnHours != nil {
+			w.Gauge("nasbot_smart_power_on_hours", "Disk power-on hours reported by SMART.", float64(*d.PowerOnHours), metrics.L("device", d.Device))
+		}
+	}
+	for _, d := range snap.Smart {
+		if d.PercentUsed != nil {
+			w.Gauge("nasbot_smart_percent_used", "Share of the rated SSD life used, in percent.", float64(*d.PercentUsed), metrics.L("device", d.Device))
+		}
+	}
+	for _, d := range snap.Smart {
+		for _, name := range sortedKeys(d.Counters) {
+			w.Gauge("nasbot_smart_errors", "SMART error counters: reallocated, pending and uncorrectable sectors, CRC and media errors.", float64(d.Counters[name]), metrics.L("device", d.Device), metrics.L("counter", name))
+		}
+	}
+
+	running := 0
+	for _, c := range snap.Containers {
+		w.Bool("nasbot_container_running", "Whether the container is running.", c.Running, metrics.L("name", c.Name), metrics.L("image", c.Image))
+		if c.Health != "" {
+			w.Bool("nasbot_container_healthy", "Whether the container's healthcheck passes.", c.Health == "healthy", metrics.L("name", c.Name))
+		}
+		if c.Running {
+			running++
+		}
+	}
+	w.Gauge("nasbot_containers", "Number of containers by state.", float64(running), metrics.L("state", "running"))
+	w.Gauge("nasbot_containers", "Number of containers by state.", float64(len(snap.Containers)-running), metrics.L("state", "stopped"))
+
+	hc := snap.Healthchecks
+	w.Counter("nasbot_healthchecks_pings_total", "Healthchecks.io pings by result.", float64(hc.SuccessfulPings), metrics.L("result", "success"))
+	w.Counter("nasbot_healthchecks_pings_total", "Healthchecks.io pings by result.", float64(hc.FailedPings), metrics.L("result", "failure"))
+	if !hc.LastPingTime.IsZero() {
+		w.Gauge("nasbot_healthchecks_last_ping_timestamp_seconds", "Unix time of the last healthchecks.io ping.", float64(hc.LastPingTime.Unix()))
+	}
+
+	wd := snap.Watchdogs
+	w.Bool("nasbot_network_down", "Whether the network watchdog considers the network down.", wd.Network.Down)
+	w.Gauge("nasbot_network_watchdog_failures", "Consecutive failed network checks.", float64(wd.Network.FailCount))
+	w.Gauge("nasbot_kernel_watchdog_check_errors", "Consecutive kernel watchdog check errors.", float64(wd.Kernel.ConsecutiveErrors))
+	w.Bool("nasbot_docker_failing", "Whether the Docker daemon is currently unresponsive.", wd.DockerFailing)
+	w.Bool("nasbot_healthchecks_in_downtime", "Whether a healthchecks.io downtime is open.", wd.HealthInDowntime)
+
+	ids := make([]string, 0, len(snap.Alerts))
+	for id := range snap.Alerts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		w.Gauge("nasbot_alert_firing", "Alerts currently firing.", 1, metrics.L("id", id), metrics.L("level", snap.Alerts[id]))
+	}
+	return w.String()
+}
+
+// newAPIHandler wires the HTTP routes for ctx.
+func newAPIHandler(ctx *AppContext) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		if ctx.Config.API.MetricsRequireToken && !apiAuthorized(ctx, w, r) {
+			return
+		}
+		w.Header().Set("Content-Type", metrics.ContentType)
+		_, _ = w.Write([]byte(renderMetrics(buildAPISnapshot(ctx))))
+	})
+	mux.HandleFunc("GET /api/v1/status", func(w http.ResponseWriter, r *http.Request) {
+		if !apiAuthorized(ctx, w, r) {
+			return
+		}
+		writeJSON(w, buildAPISnapshot(ctx))
+	})
+	mux.HandleFunc("GET /api/v1/containers", func(w http.ResponseWriter, r *http.Request) {
+		if !apiAuthorized(ctx, w, r) {
+			return
+		}
+		writeJSON(w, buildAPISnapshot(ctx).Containers)
+	})
+	mux.HandleFunc("GET /api/v1/alerts", func(w http.ResponseWriter, r *http.Request) {
+		if !apiAuthorized(ctx, w, r) {
+			return
+		}
+		writeJSON(w, ctx.Monitor.Alerts.Active())
+	})
+	return mux
+}
+
+// apiAuthorized checks the bearer token (or X-API-Token header) and writes
+// the error response when it does not match.
+func apiAuthorized(ctx *AppContext, w http.ResponseWriter, r *http.Request) bool {
+	want := ctx.Config.API.Token
+	if want == "" {
+		http.Error(w, "api token not configured", http.StatusForbidden)
+		return false
+	}
+	got := r.Header.Get("X-API-Token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		got = bearer
+	}
+	if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="nasbot"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		slog.Warn("API response encode failed", "err", err)
+	}
+}
+
+// startAPIServer serves the HTTP API until runCtx is cancelled.
+func startAPIServer(ctx *AppContext, runCtx context.Context) {
+	addr := ctx.Config.API.Listen
+	if addr == "" {
+		addr = defaultAPIListen
+	}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           newAPIHandler(ctx),
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+
+	go func() {
+		<-runCtx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("HTTP API listening", "addr", addr, "json_api", ctx.Config.API.Token != "")
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("HTTP API stopped", "err", err)
+	}
+}
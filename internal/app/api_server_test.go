@@ -0,0 +1,84 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIMetricsPublicByDefault(t *testing.T) {
+	ctx := newTestAppContext()
+	ctx.Config.Paths.SSD = "/Volume1"
+	ctx.Monitor.SmartCache = map[string]SmartResult{"sda": {Temp: 38, Health: "PASSED", PowerOnHours: 36012, PercentUsed: -1, Counters: map[string]int64{"reallocated_sectors": 8}}}
+	ctx.Monitor.Alerts.Observe(time.Now(), AlertObservation{ID: "disk:/mnt/data", Level: AlertLevelWarning}, AlertRule{})
+
+	rec := httptest.NewRecorder()
+	newAPIHandler(ctx).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"nasbot_cpu_usage_percent 12\n",
+		`nasbot_volume_used_percent{mount="/Volume1"} 10`,
+		`nasbot_volume_used_percent{mount="/mnt/data"} 20`,
+		`nasbot_smart_temperature_celsius{device="sda"} 38`,
+		`nasbot_smart_healthy{device="sda"} 1`,
+		`nasbot_smart_power_on_hours{device="sda"} 36012`,
+		`nasbot_smart_errors{counter="reallocated_sectors",device="sda"} 8`,
+		`nasbot_container_running{image="",name="x"} 1`,
+		`nasbot_alert_firing{id="disk:/mnt/data",level="warning"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
+
+func TestAPIAuth(t *testing.T) {
+	ctx := newTestAppContext()
+	h := newAPIHandler(ctx)
+	get := func(path string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if len(header) == 2 {
+			req.Header.Set(header[0], header[1])
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get("/api/v1/status"); rec.Code != http.StatusForbidden {
+		t.Fatalf("JSON API without a configured token should be disabled, got %d", rec.Code)
+	}
+
+	ctx.Config.API.Token = "s3cret"
+	if rec := get("/api/v1/status", "Authorization", "Bearer wrong"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong token accepted: %d", rec.Code)
+	}
+	rec := get("/api/v1/status", "Authorization", "Bearer s3cret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var snap apiSnapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &snap); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if snap.CPUPercent != 12 || len(snap.Containers) != 1 || snap.Containers[0].Name != "x" {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+	if rec := get("/api/v1/containers", "X-API-Token", "s3cret"); rec.Code != http.StatusOK {
+		t.Fatalf("X-API-Token header rejected: %d", rec.Code)
+	}
+
+	ctx.Config.API.MetricsRequireToken = true
+	if rec := get("/metrics"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("/metrics should require the token, got %d", rec.Code)
+	}
+	if rec := get("/healthz"); rec.Code != http.StatusOK {
+		t.Fatalf("/healthz should stay public, got %d", rec.Code)
+	}
+}
@@ -240,6 +240,7 @@ func getMainKeyboard(ctx *AppContext) tgbotapi.InlineKeyboardMarkup {
 			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("btn_top"), "show_top"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("btn_graphs"), "graph_cpu_"+defaultGraphRange),
 			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("btn_power"), "show_power"),
 		),
 	)
@@ -32,6 +32,11 @@ type AskCmd = pcommands.AskCmd
 type HelpCmd = pcommands.HelpCmd
 type QuickCmd = pcommands.QuickCmd
 type DiskPredCmd = pcommands.DiskPredCmd
+type GraphCmd = pcommands.GraphCmd
+type SmartCmd = pcommands.SmartCmd
+type RaidCmd = pcommands.RaidCmd
+type DockerUpdatesCmd = pcommands.DockerUpdatesCmd
+type DockerDiskCmd = pcommands.DockerDiskCmd
 type HealthCmd = pcommands.HealthCmd
 type UpdateCmd = pcommands.UpdateCmd
 type ChangelogCmd = pcommands.ChangelogCmd
@@ -46,6 +51,8 @@ type BackupCmd = pcommands.BackupCmd
 type ProcessesCmd = pcommands.ProcessesCmd
 type AdBlockCmd = pcommands.AdBlockCmd
 
+func messageSenderID(msg *tgbotapi.Message) int64 { return pcommands.MessageSenderID(msg) }
+
 func getStatusText(ctx *AppContext) string  { return pcommands.GetStatusText(ctx) }
 func getTempText(ctx *AppContext) string    { return pcommands.GetTempText(ctx) }
 func getTopProcText(ctx *AppContext) string { return pcommands.GetTopProcText(ctx) }
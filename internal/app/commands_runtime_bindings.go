@@ -26,9 +26,15 @@ func init() {
 		RunCommandOutput:             runCommandOutput,
 		RunCommandStdout:             runCommandStdout,
 		RunCommand:                   runCommand,
+		ContainerLogs:                containerLogs,
 		EditMessage:                  editMessage,
 		SafeSend:                     safeSend,
 		HandleHealthCommand:          handleHealthCommand,
+		HandleGraphCommand:           handleGraphCommand,
+		HandleSmartCommand:           handleSmartCommand,
+		HandleRaidCommand:            handleRaidCommand,
+		HandleImageUpdatesCommand:    handleImageUpdatesCommand,
+		HandleDockerDiskCommand:      handleDockerDiskCommand,
 		ApplyLatestRelease:           applyLatestRelease,
 		CheckForUpdate: func(ctx *pcommands.AppContext) (pcommands.ReleaseInfo, bool, error) {
 			rel, has, err := checkForUpdate(ctx)
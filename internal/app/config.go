@@ -6,9 +6,13 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"nasbot/internal/docker"
 )
 
 // Global computed values (Shared by main bot and watchdog)
@@ -110,6 +114,25 @@ func getConfigJSONSafe() (string, error) {
 	safeCfg := cfg
 	safeCfg.BotToken = "REDACTED"
 	safeCfg.GeminiAPIKey = "REDACTED"
+	if safeCfg.Notifiers.Ntfy.Token != "" {
+		safeCfg.Notifiers.Ntfy.Token = "REDACTED"
+	}
+	if safeCfg.Notifiers.Gotify.Token != "" {
+		safeCfg.Notifiers.Gotify.Token = "REDACTED"
+	}
+	if safeCfg.Notifiers.SMTP.Password != "" {
+		safeCfg.Notifiers.SMTP.Password = "REDACTED"
+	}
+	if safeCfg.API.Token != "" {
+		safeCfg.API.Token = "REDACTED"
+	}
+	if len(safeCfg.Notifiers.Webhook.Headers) > 0 {
+		headers := make(map[string]string, len(safeCfg.Notifiers.Webhook.Headers))
+		for k := range safeCfg.Notifiers.Webhook.Headers {
+			headers[k] = "REDACTED"
+		}
+		safeCfg.Notifiers.Webhook.Headers = headers
+	}
 
 	b, err := json.MarshalIndent(safeCfg, "", "  ")
 	if err != nil {
@@ -151,6 +174,30 @@ func sanitizeConfig(c *Config) []string {
 		}
 	}
 
+	// Users & permissions
+	sanitizeUsers(c, add)
+
+	// Notification channels
+	sanitizeNotifiers(c, trimField, clampIntField, add)
+
+	// Alert rules
+	sanitizeAlertRules(c, clampIntField, clampFloatField, add)
+
+	// HTTP API
+	trimField("api.listen", &c.API.Listen)
+	if c.API.Listen == "" {
+		c.API.Listen = defaultAPIListen
+		add("api.listen", c.API.Listen)
+	}
+
+	// Metric history
+	clampIntField("history.sample_seconds", &c.History.SampleSeconds, 10, 3600)
+	clampIntField("history.raw_retention_hours", &c.History.RawRetentionHours, 1, 168)
+	clampIntField("history.five_min_retention_days", &c.History.FiveMinRetentionDays, 1, 90)
+	clampIntField("history.hourly_retention_days", &c.History.HourlyRetentionDays, 1, 730)
+	clampIntField("history.daily_retention_days", &c.History.DailyRetentionDays, 1, 3650)
+	clampIntField("history.flush_minutes", &c.History.FlushMinutes, 1, 60)
+
 	trimField("timezone", &c.Timezone)
 	trimField("paths.ssd", &c.Paths.SSD)
 	if c.Paths.SSD == "" {
@@ -207,6 +254,24 @@ func sanitizeConfig(c *Config) []string {
 		c.Notifications.SMART.Devices = normalizeStringList(c.Notifications.SMART.Devices)
 		add("notifications.smart.devices", "normalized")
 	}
+	clampIntField("notifications.smart.wear_warning_percent", &c.Notifications.SMART.WearWarningPercent, 1, 100)
+	sanitizeTestSchedule := func(field string, sch *SmartTestSchedule, inherit bool) {
+		if inherit && strings.TrimSpace(sch.Day) == "" {
+			sch.Day = ""
+		} else if day, changed := normalizeTestDay(sch.Day); changed {
+			sch.Day = day
+			add(field+".day", day)
+		}
+		clampIntField(field+".hour", &sch.Hour, 0, 23)
+	}
+	selfTests := &c.Notifications.SMART.SelfTests
+	sanitizeTestSchedule("notifications.smart.self_tests.short", &selfTests.Short, false)
+	sanitizeTestSchedule("notifications.smart.self_tests.long", &selfTests.Long, false)
+	for dev, sch := range selfTests.Devices {
+		sanitizeTestSchedule("notifications.smart.self_tests.devices."+dev+".short", &sch.Short, true)
+		sanitizeTestSchedule("notifications.smart.self_tests.devices."+dev+".long", &sch.Long, true)
+		selfTests.Devices[dev] = sch
+	}
 
 	// Temperature
 	clampFloatField("temperature.warning_threshold", &c.Temperature.WarningThreshold, 0, 120)
@@ -226,6 +291,19 @@ func sanitizeConfig(c *Config) []string {
 	clampIntField("stress_tracking.duration_threshold_minutes", &c.StressTracking.DurationThresholdMinutes, 1, 1440)
 
 	// Docker
+	rt, ok := docker.RuntimeByName(c.Docker.Runtime)
+	if !ok {
+		rt = docker.Docker
+	}
+	if rt.Name != c.Docker.Runtime {
+		c.Docker.Runtime = rt.Name
+		add("docker.runtime", rt.Name)
+	}
+	trimField("docker.socket", &c.Docker.Socket)
+	if c.Docker.Socket == "" {
+		c.Docker.Socket = rt.Socket
+		add("docker.socket", c.Docker.Socket)
+	}
 	clampIntField("docker.watchdog.timeout_minutes", &c.Docker.Watchdog.TimeoutMinutes, 1, 120)
 	clampIntField("docker.weekly_prune.hour", &c.Docker.WeeklyPrune.Hour, 0, 23)
 	if day, changed := normalizeDay(c.Docker.WeeklyPrune.Day); changed {
@@ -234,6 +312,44 @@ func sanitizeConfig(c *Config) []string {
 	}
 	clampFloatField("docker.auto_restart_on_ram_critical.ram_threshold", &c.Docker.AutoRestartOnRAMCritical.RAMThreshold, 0, 100)
 	clampIntField("docker.auto_restart_on_ram_critical.max_restarts_per_hour", &c.Docker.AutoRestartOnRAMCritical.MaxRestartsPerHour, 0, 100)
+	clampIntField("docker.restart_loop.max_restarts", &c.Docker.RestartLoop.MaxRestarts, 1, 100)
+	clampIntField("docker.restart_loop.window_minutes", &c.Docker.RestartLoop.WindowMinutes, 1, 1440)
+	clampIntField("docker.updates.check_interval_hours", &c.Docker.Updates.CheckIntervalHours, 1, 168)
+	clampIntField("docker.updates.rollback_grace_seconds", &c.Docker.Updates.RollbackGraceSeconds, 10, 3600)
+	for name, lim := range c.Docker.ContainerLimits {
+		prefix := "docker.container_limits." + name
+		clampFloatField(prefix+".cpu_percent", &lim.CPUPercent, 0, 100000)
+		clampFloatField(prefix+".mem_percent", &lim.MemPercent, 0, 100)
+		clampFloatField(prefix+".mem_mb", &lim.MemMB, 0, 1<<24)
+		clampIntField(prefix+".max_restarts_per_hour", &lim.MaxRestartsPerHour, 0, 1000)
+		c.Docker.ContainerLimits[name] = lim
+	}
+	clampFloatField("docker.logs.max_size_mb", &c.Docker.Logs.MaxSizeMB, 0, 1<<24)
+	clampFloatField("docker.logs.max_growth_mb_per_hour", &c.Docker.Logs.MaxGrowthMBPerHour, 0, 1<<24)
+	clampIntField("docker.dependencies.health_timeout_seconds", &c.Docker.Dependencies.HealthTimeoutSeconds, 5, 1800)
+	for name, deps := range c.Docker.Dependencies.DependsOn {
+		if i := slices.Index(deps, name); i >= 0 {
+			c.Docker.Dependencies.DependsOn[name] = slices.Delete(deps, i, i+1)
+			add("docker.dependencies.depends_on."+name, "self-dependency removed")
+		}
+	}
+	for name, actions := range c.Docker.ExecActions {
+		kept := actions[:0]
+		for i, a := range actions {
+			prefix := fmt.Sprintf("docker.exec_actions.%s[%d]", name, i)
+			trimField(prefix+".name", &a.Name)
+			if a.Name == "" || len(a.Command) == 0 || a.Command[0] == "" {
+				add(prefix, "dropped (name and command required)")
+				continue
+			}
+			if a.TimeoutSeconds == 0 {
+				a.TimeoutSeconds = 30
+			}
+			clampIntField(prefix+".timeout_seconds", &a.TimeoutSeconds, 1, 600)
+			kept = append(kept, a)
+		}
+		c.Docker.ExecActions[name] = kept
+	}
 
 	// Intervals
 	clampIntField("intervals.stats_seconds", &c.Intervals.StatsSeconds, 1, 3600)
@@ -308,10 +424,157 @@ func sanitizeConfig(c *Config) []string {
 	// Raid watchdog
 	clampIntField("raid_watchdog.check_interval_seconds", &c.RaidWatchdog.CheckIntervalSecs, 30, 7200)
 	clampIntField("raid_watchdog.cooldown_minutes", &c.RaidWatchdog.CooldownMins, 1, 1440)
+	clampIntField("raid_watchdog.progress_step_percent", &c.RaidWatchdog.ProgressStepPercent, 0, 100)
 
 	return changes
 }
 
+// sanitizeUsers drops user entries without an ID or with an unknown role and
+// normalizes roles, subscriptions and permission overrides.
+func sanitizeUsers(c *Config, add func(string, any)) {
+	validUsers := make([]UserConfig, 0, len(c.Users))
+	for i, u := range c.Users {
+		role := strings.ToLower(strings.TrimSpace(u.Role))
+		if u.ID == 0 || RoleRank(role) == 0 {
+			add(fmt.Sprintf("users[%d]", i), "removed")
+			continue
+		}
+		if role != u.Role {
+			u.Role = role
+			add(fmt.Sprintf("users[%d].role", i), role)
+		}
+		u.Name = strings.TrimSpace(u.Name)
+		if len(u.Subscriptions) > 0 {
+			subs := make([]string, 0, len(u.Subscriptions))
+			for _, s := range u.Subscriptions {
+				subs = append(subs, strings.ToLower(s))
+			}
+			u.Subscriptions = normalizeStringList(subs)
+		}
+		validUsers = append(validUsers, u)
+	}
+	c.Users = validUsers
+
+	sanitizeRoleMap := func(field string, m map[string]string) {
+		for key, role := range m {
+			normalized := strings.ToLower(strings.TrimSpace(role))
+			if RoleRank(normalized) == 0 {
+				delete(m, key)
+				add(field+"."+key, "removed")
+				continue
+			}
+			if normalized != role {
+				m[key] = normalized
+				add(field+"."+key, normalized)
+			}
+		}
+	}
+	sanitizeRoleMap("permissions.commands", c.Permissions.Commands)
+	sanitizeRoleMap("permissions.callbacks", c.Permissions.Callbacks)
+}
+
+// sanitizeNotifiers trims endpoints, normalizes topic lists and disables
+// channels that are missing the fields they need to deliver anything.
+func sanitizeNotifiers(c *Config, trimField func(string, *string), clampIntField func(string, *int, int, int), add func(string, any)) {
+	n := &c.Notifiers
+	normalizeTopics := func(topics []string) []string {
+		lowered := make([]string, 0, len(topics))
+		for _, t := range topics {
+			lowered = append(lowered, strings.ToLower(t))
+		}
+		return normalizeStringList(lowered)
+	}
+	disable := func(field string, enabled *bool) {
+		if *enabled {
+			*enabled = false
+			add(field, false)
+		}
+	}
+
+	n.Telegram.Topics = normalizeTopics(n.Telegram.Topics)
+
+	n.Webhook.Topics = normalizeTopics(n.Webhook.Topics)
+	trimField("notifiers.webhook.url", &n.Webhook.URL)
+	if n.Webhook.URL == "" {
+		disable("notifiers.webhook.enabled", &n.Webhook.Enabled)
+	}
+
+	n.Ntfy.Topics = normalizeTopics(n.Ntfy.Topics)
+	trimField("notifiers.ntfy.server", &n.Ntfy.Server)
+	trimField("notifiers.ntfy.topic", &n.Ntfy.Topic)
+	if n.Ntfy.Topic == "" {
+		disable("notifiers.ntfy.enabled", &n.Ntfy.Enabled)
+	}
+
+	n.Gotify.Topics = normalizeTopics(n.Gotify.Topics)
+	trimField("notifiers.gotify.url", &n.Gotify.URL)
+	if n.Gotify.URL == "" || n.Gotify.Token == "" {
+		disable("notifiers.gotify.enabled", &n.Gotify.Enabled)
+	}
+
+	n.SMTP.Topics = normalizeTopics(n.SMTP.Topics)
+	trimField("notifiers.smtp.host", &n.SMTP.Host)
+	trimField("notifiers.smtp.from", &n.SMTP.From)
+	if n.SMTP.Port == 0 {
+		n.SMTP.Port = 587
+		add("notifiers.smtp.port", n.SMTP.Port)
+	}
+	clampIntField("notifiers.smtp.port", &n.SMTP.Port, 1, 65535)
+	n.SMTP.To = normalizeStringList(n.SMTP.To)
+	if n.SMTP.Host == "" || n.SMTP.From == "" || len(n.SMTP.To) == 0 {
+		disable("notifiers.smtp.enabled", &n.SMTP.Enabled)
+	}
+}
+
+// sanitizeAlertRules clamps the default and per-ID alert rules, drops rules
+// with an empty key and normalizes the escalation targets.
+func sanitizeAlertRules(c *Config, clampIntField func(string, *int, int, int), clampFloatField func(string, *float64, float64, float64), add func(string, any)) {
+	clampRule := func(prefix string, r *AlertRuleConfig) {
+		clampIntField(prefix+".sustain_minutes", &r.SustainMinutes, 0, 1440)
+		clampFloatField(prefix+".hysteresis", &r.Hysteresis, 0, 100)
+		clampIntField(prefix+".cooldown_minutes", &r.CooldownMinutes, 0, 1440)
+	}
+
+	clampRule("alerts.defaults", &c.Alerts.Defaults)
+
+	esc := &c.Alerts.Escalation
+	clampIntField("alerts.escalation.after_minutes", &esc.AfterMinutes, 0, 1440)
+	channels := make([]string, 0, len(esc.Channels))
+	for _, ch := range esc.Channels {
+		channels = append(channels, strings.ToLower(ch))
+	}
+	esc.Channels = normalizeStringList(channels)
+	users := make([]int64, 0, len(esc.Users))
+	for _, id := range esc.Users {
+		if id > 0 && !slices.Contains(users, id) {
+			users = append(users, id)
+		}
+	}
+	if len(users) != len(esc.Users) {
+		add("alerts.escalation.users", users)
+	}
+	esc.Users = users
+
+	if c.Alerts.Rules == nil {
+		c.Alerts.Rules = map[string]AlertRuleConfig{}
+		return
+	}
+	for id, r := range c.Alerts.Rules {
+		key := strings.TrimSpace(id)
+		if key == "" {
+			delete(c.Alerts.Rules, id)
+			add("alerts.rules", "removed empty rule id")
+			continue
+		}
+		clampRule("alerts.rules."+key, &r)
+		if key != id {
+			delete(c.Alerts.Rules, id)
+			add("alerts.rules."+key, "trimmed rule id")
+		}
+		c.Alerts.Rules[key] = r
+	}
+}
+
 func sanitizeResourceConfig(rc *ResourceConfig, prefix string, clampFloatField func(string, *float64, float64, float64), add func(string, any)) {
 	clampFloatField(prefix+".warning_threshold", &rc.WarningThreshold, 0, 100)
 	clampFloatField(prefix+".critical_threshold", &rc.CriticalThreshold, 0, 100)
@@ -354,6 +617,22 @@ func normalizeDay(day string) (string, bool) {
 	}
 }
 
+// normalizeTestDay validates a self-test schedule day; anything unknown
+// turns the schedule off rather than testing at a surprising time.
+func normalizeTestDay(day string) (string, bool) {
+	d := strings.ToLower(strings.TrimSpace(day))
+	if d == "daily" || d == "never" {
+		return d, d != day
+	}
+	if n, err := strconv.Atoi(d); err == nil && n >= 1 && n <= 28 {
+		return strconv.Itoa(n), strconv.Itoa(n) != day
+	}
+	if weekday, _ := normalizeDay(d); weekday == d {
+		return d, d != day
+	}
+	return "never", true
+}
+
 func normalizeStringList(items []string) []string {
 	if len(items) == 0 {
 		return items
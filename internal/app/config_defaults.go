@@ -1,9 +1,40 @@
 package app
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"nasbot/internal/docker"
+)
 
 func defaultConfigTemplate() Config {
 	return Config{
+		Users:       []UserConfig{},
+		Permissions: PermissionsConfig{Commands: map[string]string{}, Callbacks: map[string]string{}},
+		Notifiers: NotifiersConfig{
+			Telegram: TelegramNotifierConfig{Topics: []string{}},
+			Webhook:  WebhookNotifierConfig{Topics: []string{}, Headers: map[string]string{}},
+			Ntfy:     NtfyNotifierConfig{Topics: []string{}, Server: "https://ntfy.sh"},
+			Gotify:   GotifyNotifierConfig{Topics: []string{}},
+			SMTP:     SMTPNotifierConfig{Topics: []string{}, Port: 587, To: []string{}},
+		},
+		Alerts: AlertsConfig{
+			Defaults: AlertRuleConfig{Hysteresis: 2, NotifyResolved: true},
+			Rules: map[string]AlertRuleConfig{
+				"temp:":      {CooldownMinutes: 30, Hysteresis: 3, NotifyResolved: true},
+				"container:": {CooldownMinutes: 10, NotifyResolved: true},
+			},
+			Escalation: AlertEscalationConfig{Users: []int64{}, Channels: []string{}},
+		},
+		API: APIConfig{Listen: defaultAPIListen},
+		History: HistoryConfig{
+			Enabled:              true,
+			SampleSeconds:        60,
+			RawRetentionHours:    24,
+			FiveMinRetentionDays: 7,
+			HourlyRetentionDays:  90,
+			DailyRetentionDays:   730,
+			FlushMinutes:         5,
+		},
 		Paths:    PathsConfig{SSD: defaultPathSSD},
 		Timezone: "Europe/Rome",
 		Reports: ReportsConfig{
@@ -19,15 +50,31 @@ func defaultConfigTemplate() Config {
 			DiskSSD:        ResourceConfig{Enabled: true, WarningThreshold: 90, CriticalThreshold: 95},
 			SecondaryDisks: map[string]ResourceConfig{},
 			DiskIO:         DiskIOConfig{Enabled: true, WarningThreshold: 95},
-			SMART:          SmartConfig{Enabled: true},
+			SMART: SmartConfig{
+				Enabled:            true,
+				WearWarningPercent: 80,
+				SelfTests: SmartSelfTestConfig{
+					Short:   SmartTestSchedule{Day: "sunday", Hour: 3},
+					Long:    SmartTestSchedule{Day: "1", Hour: 2},
+					Devices: map[string]SmartDeviceSchedule{},
+				},
+			},
 		},
 		Temperature:        TemperatureConfig{Enabled: true, WarningThreshold: 70, CriticalThreshold: 85},
 		CriticalContainers: []string{},
 		StressTracking:     StressTrackingConfig{Enabled: true, DurationThresholdMinutes: 2},
 		Docker: DockerConfig{
+			Runtime:                  docker.Docker.Name,
 			Watchdog:                 DockerWatchdogConfig{Enabled: true, TimeoutMinutes: 2, AutoRestartService: true},
 			WeeklyPrune:              DockerPruneConfig{Enabled: true, Day: "sunday", Hour: 4},
 			AutoRestartOnRAMCritical: DockerAutoRestartConfig{Enabled: true, MaxRestartsPerHour: 3, RAMThreshold: 98},
+			RestartLoop:              DockerRestartLoopConfig{Enabled: true, MaxRestarts: 3, WindowMinutes: 10},
+			AutoRestartUnhealthy:     true,
+			Updates:                  DockerUpdatesConfig{Enabled: true, CheckIntervalHours: 12, RollbackGraceSeconds: 120, InsecureRegistries: []string{}},
+			ContainerLimits:          map[string]ContainerLimitConfig{},
+			Logs:                     DockerLogsConfig{Enabled: true, MaxSizeMB: 1024, MaxGrowthMBPerHour: 256},
+			Dependencies:             DockerDependencyConfig{DependsOn: map[string][]string{}, HealthTimeoutSeconds: 120, StartOnBoot: true},
+			ExecActions:              map[string][]ExecActionConfig{},
 		},
 		Intervals:      IntervalsConfig{StatsSeconds: 5, MonitorSeconds: 30, CriticalAlertCooldownMins: 30},
 		Cache:          CacheConfig{DockerTTLSeconds: 10},
@@ -46,7 +93,7 @@ func defaultConfigTemplate() Config {
 			ForceRebootOnDown:    true,
 			ForceRebootAfterMins: 3,
 		},
-		RaidWatchdog: RaidWatchdogConfig{Enabled: true, CheckIntervalSecs: 300, CooldownMins: 30, RecoveryNotify: true},
+		RaidWatchdog: RaidWatchdogConfig{Enabled: true, CheckIntervalSecs: 300, CooldownMins: 30, RecoveryNotify: true, ProgressStepPercent: 25},
 		Backup:       BackupConfig{TargetUserID: 0},
 		Update:       UpdateConfig{AutoApply: true, CheckIntervalHours: 1},
 	}
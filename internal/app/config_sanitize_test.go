@@ -178,3 +178,107 @@ func TestNormalizeStringList(t *testing.T) {
 		t.Fatalf("unexpected normalized list: %#v", result)
 	}
 }
+
+func TestSanitizeConfig_Users(t *testing.T) {
+	cfg := Config{
+		AllowedUserID: 1,
+		Users: []UserConfig{
+			{ID: 2, Role: " Operator ", Subscriptions: []string{"Critical", " ", "critical"}},
+			{ID: 0, Role: "viewer"},
+			{ID: 3, Role: "root"},
+		},
+		Permissions: PermissionsConfig{
+			Commands:  map[string]string{"status": "ADMIN", "docker": "nobody"},
+			Callbacks: map[string]string{},
+		},
+	}
+
+	sanitizeConfig(&cfg)
+
+	if len(cfg.Users) != 1 || cfg.Users[0].ID != 2 {
+		t.Fatalf("expected only user 2 to survive, got %+v", cfg.Users)
+	}
+	if cfg.Users[0].Role != "operator" {
+		t.Fatalf("role not normalized: %q", cfg.Users[0].Role)
+	}
+	if len(cfg.Users[0].Subscriptions) != 1 || cfg.Users[0].Subscriptions[0] != "critical" {
+		t.Fatalf("subscriptions not normalized: %v", cfg.Users[0].Subscriptions)
+	}
+	if cfg.Permissions.Commands["status"] != "admin" {
+		t.Fatalf("permission role not normalized: %q", cfg.Permissions.Commands["status"])
+	}
+	if _, ok := cfg.Permissions.Commands["docker"]; ok {
+		t.Fatalf("invalid permission role should be removed")
+	}
+}
+
+func TestSanitizeConfig_Notifiers(t *testing.T) {
+	cfg := Config{
+		Notifiers: NotifiersConfig{
+			Webhook: WebhookNotifierConfig{Enabled: true, URL: "  "},
+			Ntfy:    NtfyNotifierConfig{Enabled: true, Topic: " nas ", Topics: []string{"Critical", "critical"}},
+			SMTP:    SMTPNotifierConfig{Enabled: true, Host: "mail.local", From: "nas@local"},
+		},
+	}
+
+	sanitizeConfig(&cfg)
+
+	if cfg.Notifiers.Webhook.Enabled {
+		t.Fatalf("webhook without URL should be disabled")
+	}
+	if !cfg.Notifiers.Ntfy.Enabled || cfg.Notifiers.Ntfy.Topic != "nas" {
+		t.Fatalf("ntfy should stay enabled with trimmed topic, got %+v", cfg.Notifiers.Ntfy)
+	}
+	if len(cfg.Notifiers.Ntfy.Topics) != 1 || cfg.Notifiers.Ntfy.Topics[0] != "critical" {
+		t.Fatalf("ntfy topics not normalized: %v", cfg.Notifiers.Ntfy.Topics)
+	}
+	if cfg.Notifiers.SMTP.Enabled || cfg.Notifiers.SMTP.Port != 587 {
+		t.Fatalf("smtp without recipients should be disabled with default port, got %+v", cfg.Notifiers.SMTP)
+	}
+}
+
+func TestSanitizeConfig_AlertRules(t *testing.T) {
+	cfg := Config{
+		Alerts: AlertsConfig{
+			Defaults: AlertRuleConfig{SustainMinutes: -1, Hysteresis: 250},
+			Rules: map[string]AlertRuleConfig{
+				" cpu ": {CooldownMinutes: 5000},
+				"":      {SustainMinutes: 1},
+			},
+			Escalation: AlertEscalationConfig{AfterMinutes: -5, Users: []int64{0, 7, 7}, Channels: []string{"NTFY", "ntfy "}},
+		},
+	}
+
+	sanitizeConfig(&cfg)
+
+	if cfg.Alerts.Defaults.SustainMinutes != 0 || cfg.Alerts.Defaults.Hysteresis != 100 {
+		t.Fatalf("defaults not clamped: %+v", cfg.Alerts.Defaults)
+	}
+	if len(cfg.Alerts.Rules) != 1 || cfg.Alerts.Rules["cpu"].CooldownMinutes != 1440 {
+		t.Fatalf("rules not normalized: %+v", cfg.Alerts.Rules)
+	}
+	esc := cfg.Alerts.Escalation
+	if esc.AfterMinutes != 0 || len(esc.Users) != 1 || esc.Users[0] != 7 || len(esc.Channels) != 1 || esc.Channels[0] != "ntfy" {
+		t.Fatalf("escalation not normalized: %+v", esc)
+	}
+}
+
+func TestSanitizeConfig_SmartTestSchedules(t *testing.T) {
+	cfg := defaultConfigTemplate()
+	cfg.Notifications.SMART.SelfTests = SmartSelfTestConfig{
+		Short: SmartTestSchedule{Day: " Sunday ", Hour: 3},
+		Long:  SmartTestSchedule{Day: "31", Hour: 30},
+		Devices: map[string]SmartDeviceSchedule{
+			"sdb": {Short: SmartTestSchedule{Day: "Daily", Hour: 1}},
+		},
+	}
+	sanitizeConfig(&cfg)
+
+	st := cfg.Notifications.SMART.SelfTests
+	if st.Short.Day != "sunday" || st.Long.Day != "never" || st.Long.Hour != 23 {
+		t.Fatalf("schedules not normalized: %+v %+v", st.Short, st.Long)
+	}
+	if sdb := st.Devices["sdb"]; sdb.Short.Day != "daily" || sdb.Long.Day != "" {
+		t.Fatalf("device override not normalized: %+v", sdb)
+	}
+}
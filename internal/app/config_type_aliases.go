@@ -12,11 +12,20 @@ type ResourceConfig = pmodel.ResourceConfig
 type TemperatureConfig = pmodel.TemperatureConfig
 type DiskIOConfig = pmodel.DiskIOConfig
 type SmartConfig = pmodel.SmartConfig
+type SmartSelfTestConfig = pmodel.SmartSelfTestConfig
+type SmartDeviceSchedule = pmodel.SmartDeviceSchedule
+type SmartTestSchedule = pmodel.SmartTestSchedule
 type StressTrackingConfig = pmodel.StressTrackingConfig
 type DockerConfig = pmodel.DockerConfig
 type DockerWatchdogConfig = pmodel.DockerWatchdogConfig
 type DockerPruneConfig = pmodel.DockerPruneConfig
 type DockerAutoRestartConfig = pmodel.DockerAutoRestartConfig
+type DockerRestartLoopConfig = pmodel.DockerRestartLoopConfig
+type DockerUpdatesConfig = pmodel.DockerUpdatesConfig
+type DockerLogsConfig = pmodel.DockerLogsConfig
+type DockerDependencyConfig = pmodel.DockerDependencyConfig
+type ExecActionConfig = pmodel.ExecActionConfig
+type ContainerLimitConfig = pmodel.ContainerLimitConfig
 type IntervalsConfig = pmodel.IntervalsConfig
 type CacheConfig = pmodel.CacheConfig
 type FSWatchdogConfig = pmodel.FSWatchdogConfig
@@ -26,3 +35,16 @@ type NetworkWatchdogConfig = pmodel.NetworkWatchdogConfig
 type RaidWatchdogConfig = pmodel.RaidWatchdogConfig
 type UpdateConfig = pmodel.UpdateConfig
 type BackupConfig = pmodel.BackupConfig
+type UserConfig = pmodel.UserConfig
+type PermissionsConfig = pmodel.PermissionsConfig
+type NotifiersConfig = pmodel.NotifiersConfig
+type TelegramNotifierConfig = pmodel.TelegramNotifierConfig
+type WebhookNotifierConfig = pmodel.WebhookNotifierConfig
+type NtfyNotifierConfig = pmodel.NtfyNotifierConfig
+type GotifyNotifierConfig = pmodel.GotifyNotifierConfig
+type SMTPNotifierConfig = pmodel.SMTPNotifierConfig
+type AlertsConfig = pmodel.AlertsConfig
+type AlertRuleConfig = pmodel.AlertRuleConfig
+type AlertEscalationConfig = pmodel.AlertEscalationConfig
+type APIConfig = pmodel.APIConfig
+type HistoryConfig = pmodel.HistoryConfig
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// getDiskSpinSummary lists, for each disk that slept during the report
+// period, the share of time in standby and how many times it spun up.
+func getDiskSpinSummary(ctx *AppContext) string {
+	ctx.Monitor.Mu.Lock()
+	defer ctx.Monitor.Mu.Unlock()
+
+	devs := make([]string, 0, len(ctx.Monitor.DiskPower))
+	for dev, p := range ctx.Monitor.DiskPower {
+		if p.Tracked > 0 && (p.StandbyTime > 0 || p.SpinUps > 0) {
+			devs = append(devs, dev)
+		}
+	}
+	sort.Strings(devs)
+
+	var lines []string
+	for _, dev := range devs {
+		p := ctx.Monitor.DiskPower[dev]
+		share := float64(p.StandbyTime) / float64(p.Tracked) * 100
+		lines = append(lines, fmt.Sprintf(ctx.Tr("report_disk_spin"), dev, share, p.SpinUps))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func resetDiskSpinStats(ctx *AppContext) {
+	ctx.Monitor.Mu.Lock()
+	defer ctx.Monitor.Mu.Unlock()
+
+	for _, p := range ctx.Monitor.DiskPower {
+		p.ResetPeriod()
+	}
+}
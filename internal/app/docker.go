@@ -2,24 +2,18 @@ package app
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 	"time"
 
+	"nasbot/internal/docker"
+	"nasbot/internal/format"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-// dockerContainerRaw maps the raw JSON output from docker CLI
-type dockerContainerRaw struct {
-	Names  string `json:"Names"`
-	Status string `json:"Status"`
-	State  string `json:"State"` // running, exited, etc.
-	Image  string `json:"Image"`
-	ID     string `json:"ID"`
-}
-
 // getContainerList gets list of all Docker containers
 func getContainerList() []ContainerInfo {
 	list, _ := getContainerListWithError()
@@ -27,49 +21,22 @@ func getContainerList() []ContainerInfo {
 }
 
 // getContainerListWithError gets list of all Docker containers and returns error
-// Uses JSON formatting for robust parsing
 func getContainerListWithError() ([]ContainerInfo, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	// Use --format json for clear object parsing.
-	// We use {{json .}} to get a JSON object per line.
-	out, err := runCommandOutput(ctx, "docker", "ps", "-a", "--format", "{{json .}}")
+	list, err := dockerClient.List(ctx)
 	if err != nil {
-		slog.Error("Docker error", "err", err, "output", string(out))
+		slog.Error("Docker error", "err", err)
 		return nil, err
 	}
-
-	return parseDockerJSON(string(out))
+	return containerInfos(list), nil
 }
 
 // parseDockerJSON parses the raw output from docker ps --format "{{json .}}"
 // Extracted for testability
 func parseDockerJSON(output string) ([]ContainerInfo, error) {
-	var containers []ContainerInfo
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		var raw dockerContainerRaw
-		if err := json.Unmarshal([]byte(line), &raw); err != nil {
-			slog.Warn("Failed to unmarshal docker line", "line", line, "err", err)
-			continue
-		}
-
-		containers = append(containers, ContainerInfo{
-			Name:    raw.Names,
-			Status:  raw.Status,
-			Image:   raw.Image,
-			ID:      raw.ID,
-			Running: strings.ToLower(raw.State) == "running",
-		})
-	}
-	return containers, nil
+	return containerInfos(docker.ParseListJSON(output)), nil
 }
 
 // sendDockerMenu sends the Docker container menu
@@ -83,101 +50,35 @@ func sendDockerMenu(ctx *AppContext, bot BotAPI, chatID int64) {
 	safeSend(bot, msg)
 }
 
-// getDockerMenuText generates the Docker menu text and keyboard
-func getDockerMenuText(ctx *AppContext) (string, *tgbotapi.InlineKeyboardMarkup) {
-	containers := getCachedContainerList(ctx)
-	if len(containers) == 0 {
-		mainKb := getMainKeyboard(ctx)
-		return ctx.Tr("docker_no_containers"), &mainKb
-	}
-
-	var b strings.Builder
-	b.WriteString(ctx.Tr("docker_title"))
-
-	running, stopped := 0, 0
-	for _, c := range containers {
-		icon := "⏸"
-		statusText := "stopped"
-		if c.Running {
-			icon = "▶️"
-			statusText = parseUptime(c.Status)
-			running++
-		} else {
-			stopped++
-		}
-		b.WriteString(fmt.Sprintf("%s *%s* — %s\n", icon, c.Name, statusText))
-	}
-
-	b.WriteString(fmt.Sprintf(ctx.Tr("docker_running"), running, stopped))
-
-	var rows [][]tgbotapi.InlineKeyboardButton
-	for i := 0; i < len(containers); i += 2 {
-		var row []tgbotapi.InlineKeyboardButton
-		for j := 0; j < 2 && i+j < len(containers); j++ {
-			c := containers[i+j]
-			icon := "⏸"
-			if c.Running {
-				icon = "▶"
-			}
-			row = append(row, tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("%s %s", icon, truncate(c.Name, 10)),
-				"container_select_"+c.Name,
-			))
-		}
-		rows = append(rows, row)
-	}
-
-	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("🔄 "+ctx.Tr("docker_menu_restart_all"), "docker_restart_all"),
-		tgbotapi.NewInlineKeyboardButtonData("🐳 "+ctx.Tr("docker_menu_restart_service"), "docker_restart_service"),
-	))
-	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("docker_menu_refresh"), "show_docker"),
-		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("docker_menu_home"), "back_main"),
-	))
-
-	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
-	return b.String(), &kb
-}
-
 // getDockerStatsText returns container resource usage stats
 func getDockerStatsText(ctx *AppContext) string {
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	out, err := runCommandStdout(timeoutCtx, "docker", "stats", "--no-stream", "--format", "{{.Name}}|{{.CPUPerc}}|{{.MemUsage}}|{{.MemPerc}}")
+	stats, err := dockerClient.Stats(timeoutCtx)
 	if err != nil {
 		if timeoutCtx.Err() == context.DeadlineExceeded {
 			return "*timeout*"
 		}
 		return "*stats n/a*"
 	}
-
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	if len(lines) == 0 || (len(lines) == 1 && lines[0] == "") {
+	if len(stats) == 0 {
 		return "_No containers running_"
 	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
 
 	var b strings.Builder
 	b.WriteString("📊 *" + ctx.Tr("docker_stats_title") + "*\n```\n")
 	b.WriteString(fmt.Sprintf("%-12s %5s %5s %s\n", "NAME", "CPU", "MEM%", "MEM"))
 	b.WriteString("─────────────────────────────\n")
 
-	for _, line := range lines {
-		parts := strings.Split(line, "|")
-		if len(parts) < 4 {
-			continue
+	for _, st := range stats {
+		mark := ""
+		if lim, ok := containerLimit(ctx.Config.Docker.ContainerLimits, st.Name); ok && overLimit(lim, st) {
+			mark = " ⚠"
 		}
-		name := truncate(parts[0], 12)
-		cpuP := strings.TrimSpace(parts[1])
-		memUsage := strings.TrimSpace(parts[2])
-		memP := strings.TrimSpace(parts[3])
-
-		memShort := strings.Split(memUsage, " ")[0]
-		memShort = strings.Replace(memShort, "MiB", "M", 1)
-		memShort = strings.Replace(memShort, "GiB", "G", 1)
-
-		b.WriteString(fmt.Sprintf("%-12s %5s %5s %s\n", name, cpuP, memP, memShort))
+		b.WriteString(fmt.Sprintf("%-12s %5s %5s %s%s\n", truncate(st.Name, 12),
+			fmt.Sprintf("%.1f%%", st.CPUPercent), fmt.Sprintf("%.1f%%", st.MemPercent), format.FormatRAM(st.MemUsage>>20), mark))
 	}
 	b.WriteString("```")
 
@@ -189,21 +90,17 @@ func getContainerStats(containerName string) string {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	out, err := runCommandStdout(ctx, "docker", "stats", "--no-stream", "--format", "{{.CPUPerc}}|{{.MemUsage}}|{{.MemPerc}}|{{.NetIO}}", containerName)
-	if err != nil {
-		return ""
-	}
-
-	parts := strings.Split(strings.TrimSpace(string(out)), "|")
-	if len(parts) < 4 {
+	stats, err := dockerClient.Stats(ctx, containerName)
+	if err != nil || len(stats) == 0 {
 		return ""
 	}
+	st := stats[0]
 
-	return fmt.Sprintf("   CPU: `%s` │ RAM: `%s` (`%s`)\n   Net: `%s`",
-		strings.TrimSpace(parts[0]),
-		strings.TrimSpace(parts[1]),
-		strings.TrimSpace(parts[2]),
-		strings.TrimSpace(parts[3]))
+	return fmt.Sprintf("   CPU: `%.2f%%` │ RAM: `%s / %s` (`%.2f%%`)\n   Net: `%s / %s`",
+		st.CPUPercent,
+		docker.FormatSize(st.MemUsage), docker.FormatSize(st.MemLimit),
+		st.MemPercent,
+		docker.FormatSize(st.NetRx), docker.FormatSize(st.NetTx))
 }
 
 // handleContainerCallback handles container-related callbacks
@@ -265,6 +162,9 @@ func showContainerActions(ctx *AppContext, bot BotAPI, chatID int64, msgID int,
 
 	b.WriteString(fmt.Sprintf("%s *%s*\n\n", icon, container.Name))
 	b.WriteString(fmt.Sprintf(ctx.Tr("docker_status"), statusText))
+	if container.Running && container.Health != "" {
+		b.WriteString(containerHealthText(ctx, containerName, container.Health))
+	}
 	b.WriteString(fmt.Sprintf(ctx.Tr("docker_image"), truncate(container.Image, 20)))
 	cID := container.ID
 	if len(cID) > 12 {
@@ -289,12 +189,22 @@ func showContainerActions(ctx *AppContext, bot BotAPI, chatID int64, msgID int,
 			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("kill"), "container_kill_"+containerName),
 			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("logs"), "container_logs_"+containerName),
 		))
+		rows = append(rows, execActionButtons(ctx, containerName)...)
 	} else {
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("start"), "container_start_"+containerName),
 		))
 	}
+	ctx.Docker.Mu.RLock()
+	outdated := ctx.Docker.Updates[containerName].Outdated
+	ctx.Docker.Mu.RUnlock()
+	if outdated {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("docker_update_image"), "dupdate_ask_"+containerName),
+		))
+	}
 	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("inspect"), "dinspect_0_"+containerName),
 		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("back"), "show_docker"),
 	))
 
@@ -302,6 +212,50 @@ func showContainerActions(ctx *AppContext, bot BotAPI, chatID int64, msgID int,
 	editMessage(bot, chatID, msgID, b.String(), &kb)
 }
 
+// containerMenuLine renders one container of the /docker list.
+func containerMenuLine(c ContainerInfo) string {
+	icon := "⏸"
+	statusText := "stopped"
+	if c.Running {
+		icon = "▶️"
+		statusText = parseUptime(c.Status) + healthBadge(c.Health)
+	}
+	return fmt.Sprintf("%s *%s* — %s\n", icon, c.Name, statusText)
+}
+
+// healthBadge is appended to a running container's uptime in the menu.
+func healthBadge(health string) string {
+	switch health {
+	case "unhealthy":
+		return " · 🩺 unhealthy"
+	case "starting":
+		return " · ⏳ starting"
+	}
+	return ""
+}
+
+// containerHealthText renders the healthcheck state with the failing streak
+// and the output of the last probe from docker inspect.
+func containerHealthText(ctx *AppContext, name, health string) string {
+	icon := map[string]string{"healthy": "💚", "unhealthy": "🩺", "starting": "⏳"}[health]
+	text := fmt.Sprintf(ctx.Tr("docker_health"), icon+" "+health)
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	d, err := dockerClient.Inspect(timeoutCtx, name)
+	cancel()
+	if err != nil || d.State.Health == nil {
+		return text
+	}
+	if d.State.Health.FailingStreak > 0 {
+		text += fmt.Sprintf(ctx.Tr("docker_health_streak"), d.State.Health.FailingStreak)
+	}
+	if out := d.State.Health.LastOutput(); out != "" && health != "healthy" {
+		out = strings.ReplaceAll(strings.Join(strings.Fields(out), " "), "`", "'")
+		text += fmt.Sprintf(ctx.Tr("docker_health_probe"), truncate(out, 120))
+	}
+	return text
+}
+
 // confirmContainerAction asks for confirmation before container action
 func confirmContainerAction(ctx *AppContext, bot BotAPI, chatID int64, msgID int, containerName, action string) {
 	if action == "logs" {
@@ -337,26 +291,22 @@ func executeContainerAction(ctx *AppContext, bot BotAPI, chatID int64, msgID int
 
 	editMessage(bot, chatID, msgID, fmt.Sprintf("... `%s` %s", containerName, action), nil)
 
-	var output []byte
 	var err error
 	switch action {
 	case "start":
-		output, err = runCommandOutput(timeoutCtx, "docker", "start", containerName)
+		err = dockerClient.Start(timeoutCtx, containerName)
 	case "stop":
-		output, err = runCommandOutput(timeoutCtx, "docker", "stop", containerName)
+		err = dockerClient.Stop(timeoutCtx, containerName)
 	case "restart":
-		output, err = runCommandOutput(timeoutCtx, "docker", "restart", containerName)
+		err = dockerClient.Restart(timeoutCtx, containerName)
 	case "kill":
-		output, err = runCommandOutput(timeoutCtx, "docker", "kill", containerName)
+		err = dockerClient.Kill(timeoutCtx, containerName)
 	default:
 		return
 	}
 	var resultText string
 	if err != nil {
-		errMsg := strings.TrimSpace(string(output))
-		if errMsg == "" {
-			errMsg = err.Error()
-		}
+		errMsg := err.Error()
 		resultText = fmt.Sprintf(ctx.Tr("docker_action_err"), action, containerName, errMsg)
 		ctx.State.AddEvent("warning", fmt.Sprintf("Error %s container %s: %s", action, containerName, errMsg))
 	} else {
@@ -384,7 +334,7 @@ func showContainerLogs(ctx *AppContext, bot BotAPI, chatID int64, msgID int, con
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	out, err := runCommandOutput(timeoutCtx, "docker", "logs", "--tail", "30", containerName)
+	out, err := dockerClient.Logs(timeoutCtx, containerName, 30)
 
 	var text string
 	if err != nil {
@@ -437,7 +387,7 @@ func showContainerAIAnalysis(ctx *AppContext, bot BotAPI, chatID int64, msgID in
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	out, err := runCommandOutput(timeoutCtx, "docker", "logs", "--tail", "100", containerName)
+	out, err := dockerClient.Logs(timeoutCtx, containerName, 100)
 	if err != nil {
 		errText := fmt.Sprintf("❌ %s: %v", ctx.Tr("docker_logs_err_short"), err)
 		kb := tgbotapi.NewInlineKeyboardMarkup(
@@ -511,7 +461,7 @@ func showContainerAIAnalysis(ctx *AppContext, bot BotAPI, chatID int64, msgID in
 }
 
 // getContainerInfoText gets container info text
-func getContainerInfoText(c ContainerInfo) (string, *tgbotapi.InlineKeyboardMarkup) {
+func getContainerInfoText(ctx *AppContext, c ContainerInfo) (string, *tgbotapi.InlineKeyboardMarkup) {
 	var b strings.Builder
 	icon := "⏸"
 	if c.Running {
@@ -528,7 +478,10 @@ func getContainerInfoText(c ContainerInfo) (string, *tgbotapi.InlineKeyboardMark
 		}
 	}
 
-	return b.String(), nil
+	kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("inspect"), "dinspect_0_"+c.Name),
+	))
+	return b.String(), &kb
 }
 
 // handleContainerCommand handles the /container command
@@ -543,12 +496,22 @@ func handleContainerCommand(ctx *AppContext, bot BotAPI, chatID int64, args stri
 		if strings.EqualFold(c.Name, args) {
 			msg := tgbotapi.NewMessage(chatID, "")
 			msg.ParseMode = "Markdown"
-			text, _ := getContainerInfoText(c)
+			text, kb := getContainerInfoText(ctx, c)
 			msg.Text = text
+			msg.ReplyMarkup = kb
 			safeSend(bot, msg)
 			return
 		}
 	}
+	// Checked after the names, so a container called "find" stays reachable.
+	if query, ok := strings.CutPrefix(args, "find"); ok && (query == "" || query[0] == ' ') {
+		if query = strings.TrimSpace(strings.ReplaceAll(query, "`", "")); query == "" {
+			sendMarkdown(bot, chatID, ctx.Tr("docker_find_usage"))
+			return
+		}
+		handleContainerFind(ctx, bot, chatID, query)
+		return
+	}
 	safeSend(bot, tgbotapi.NewMessage(chatID, fmt.Sprintf("❓ Container `%s` not found.", args)))
 }
 
@@ -581,13 +544,8 @@ func handleKillCommand(ctx *AppContext, bot BotAPI, chatID int64, args string) {
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	output, err := runCommandOutput(timeoutCtx, "docker", "kill", args)
-
-	if err != nil {
-		errMsg := strings.TrimSpace(string(output))
-		if errMsg == "" {
-			errMsg = err.Error()
-		}
+	if err := dockerClient.Kill(timeoutCtx, found.Name); err != nil {
+		errMsg := err.Error()
 		sendMarkdown(bot, chatID, fmt.Sprintf("❌ Failed to kill `%s`:\n`%s`", args, errMsg))
 		ctx.State.AddEvent("warning", fmt.Sprintf("Kill failed: %s - %s", args, errMsg))
 	} else {
@@ -666,12 +624,38 @@ func executeRestartAllContainers(ctx *AppContext, bot BotAPI, chatID int64, msgI
 		return
 	}
 
-	editMessage(bot, chatID, msgID, fmt.Sprintf("🔄 %s (%d)...", ctx.Tr("docker_restart_all_running"), len(running)), nil)
+	title := fmt.Sprintf("🔄 %s (%d)...", ctx.Tr("docker_restart_all_running"), len(running))
+	editMessage(bot, chatID, msgID, title, nil)
+
+	// Containers with declared dependencies go down dependents first and
+	// come back up in dependency order, each waiting for the ones it needs
+	// to be healthy. The others get a plain restart, which never leaves
+	// them stopped, and the bot's own container is restarted last.
+	selfID := selfContainerID()
+	inPlan := dependencyNames(ctx.Config.Docker.Dependencies.DependsOn)
+	var ordered, plain []string
+	self := ""
+	for _, c := range containers {
+		switch {
+		case !c.Running:
+		case isSelfContainer(c, selfID):
+			self = c.Name
+		case inPlan[c.Name]:
+			ordered = append(ordered, c.Name)
+		default:
+			plain = append(plain, c.Name)
+		}
+	}
 
 	var succeeded, failed []string
-	for _, name := range running {
+	if len(ordered) > 0 {
+		s := newOrderedStart(ctx, ordered, stepEditor(bot, chatID, msgID, title))
+		s.restart(context.Background())
+		succeeded, failed = s.results()
+	}
+	for _, name := range plain {
 		timeoutCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		err := runCommand(timeoutCtx, "docker", "restart", name)
+		err := dockerClient.Restart(timeoutCtx, name)
 		cancel()
 
 		if err != nil {
@@ -681,6 +665,9 @@ func executeRestartAllContainers(ctx *AppContext, bot BotAPI, chatID int64, msgI
 			succeeded = append(succeeded, name)
 		}
 	}
+	if self != "" {
+		succeeded = append(succeeded, self)
+	}
 
 	var b strings.Builder
 	b.WriteString(fmt.Sprintf("🔄 *%s*\n\n", ctx.Tr("docker_restart_all_result")))
@@ -707,6 +694,15 @@ func executeRestartAllContainers(ctx *AppContext, bot BotAPI, chatID int64, msgI
 		),
 	)
 	editMessage(bot, chatID, msgID, b.String(), &kb)
+
+	// The result is sent first: restarting its own container stops the bot.
+	if self != "" {
+		timeoutCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := dockerClient.Restart(timeoutCtx, self); err != nil {
+			slog.Error("Failed to restart container", "container", self, "err", err)
+		}
+	}
 }
 
 // executeDockerServiceRestart restarts the Docker service
@@ -716,13 +712,7 @@ func executeDockerServiceRestart(ctx *AppContext, bot BotAPI, chatID int64, msgI
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
-	var output []byte
-	var err error
-	if commandExists("systemctl") {
-		output, err = runCommandOutput(timeoutCtx, "systemctl", "restart", "docker")
-	} else {
-		output, err = runCommandOutput(timeoutCtx, "service", "docker", "restart")
-	}
+	output, err := restartContainerService(timeoutCtx, ctx.Config.Docker.Socket)
 	var resultText string
 	if err != nil {
 		errMsg := strings.TrimSpace(string(output))
@@ -736,11 +726,19 @@ func executeDockerServiceRestart(ctx *AppContext, bot BotAPI, chatID int64, msgI
 		ctx.State.AddEvent("action", "Docker service restarted (manual)")
 	}
 
-	kb := tgbotapi.NewInlineKeyboardMarkup(
+	kb := dockerHomeKeyboard(ctx)
+	editMessage(bot, chatID, msgID, resultText, &kb)
+	if err == nil {
+		goSafe("docker-ordered-start", func() { ensureStartOrder(ctx, bot, context.Background(), "recovery", chatID, msgID) })
+	}
+}
+
+// dockerHomeKeyboard leads back to the container list and the main menu.
+func dockerHomeKeyboard(ctx *AppContext) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("🐳 "+ctx.Tr("docker_menu_home_containers"), "show_docker"),
 			tgbotapi.NewInlineKeyboardButtonData("🏠 "+ctx.Tr("docker_menu_home"), "back_main"),
 		),
 	)
-	editMessage(bot, chatID, msgID, resultText, &kb)
 }
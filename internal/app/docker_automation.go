@@ -5,16 +5,14 @@ import (
 	"fmt"
 	"log/slog"
 	"sort"
-	"strconv"
-	"strings"
 	"time"
 
 	"nasbot/internal/format"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-// checkContainerStates monitors for container state changes (down/up)
+// checkContainerStates monitors for container state changes (down/up) and
+// healthcheck transitions. While the event stream is connected it only keeps
+// LastStates and Health in sync.
 func checkContainerStates(ctx *AppContext, bot BotAPI) {
 	containers := getCachedContainerList(ctx)
 	if containers == nil {
@@ -24,28 +22,36 @@ func checkContainerStates(ctx *AppContext, bot BotAPI) {
 	ctx.Docker.Mu.Lock()
 	defer ctx.Docker.Mu.Unlock()
 
-	if ctx.Docker.LastStates == nil {
-		ctx.Docker.LastStates = make(map[string]bool)
-	}
-	if ctx.Docker.ContainerDowntime == nil {
-		ctx.Docker.ContainerDowntime = make(map[string]time.Time)
-	}
+	initDockerMaps(ctx.Docker)
 
 	currentStates := make(map[string]bool)
 	for _, c := range containers {
 		currentStates[c.Name] = c.Running
 	}
+	if ctx.Docker.EventsActive {
+		ctx.Docker.LastStates = currentStates
+		for _, c := range containers {
+			ctx.Docker.Health[c.Name] = c.Health
+		}
+		return
+	}
+
+	for _, c := range containers {
+		prev := ctx.Docker.Health[c.Name]
+		ctx.Docker.Health[c.Name] = c.Health
+		if _, tracked := ctx.Docker.LastStates[c.Name]; tracked && c.Running {
+			notifyHealthChange(ctx, bot, c.Name, prev, c.Health)
+		}
+	}
 
 	for name, wasRunning := range ctx.Docker.LastStates {
 		isRunning, exists := currentStates[name]
 		if exists && wasRunning && !isRunning {
 			ctx.Docker.ContainerDowntime[name] = time.Now()
 
-			if !ctx.IsQuietHours() {
-				msg := fmt.Sprintf("🔴 *Container DOWN*\n\n📦 `%s`\n\n_The container has stopped unexpectedly._", name)
-				m := tgbotapi.NewMessage(ctx.Config.AllowedUserID, msg)
-				m.ParseMode = "Markdown"
-				safeSend(bot, m)
+			if !ctx.IsQuietHours() && !ctx.Docker.RestartLoop[name] {
+				m := alertMessage(containerDownText(name, "", false))
+				sendAlert(bot, ctx.Config, AlertTopicWarning, m)
 			}
 			ctx.State.AddEvent("warning", fmt.Sprintf("🔴 Container stopped: %s", name))
 		}
@@ -54,21 +60,18 @@ func checkContainerStates(ctx *AppContext, bot BotAPI) {
 	for name, isRunning := range currentStates {
 		wasRunning, wasTracked := ctx.Docker.LastStates[name]
 		if wasTracked && !wasRunning && isRunning {
-			var downtimeMsg string
+			var downtime time.Duration
 			if downStart, hasDowntime := ctx.Docker.ContainerDowntime[name]; hasDowntime {
-				duration := time.Since(downStart)
-				downtimeMsg = fmt.Sprintf("\n⏱ Downtime: `%s`", format.FormatDuration(duration))
+				downtime = time.Since(downStart)
 				delete(ctx.Docker.ContainerDowntime, name)
-				ctx.State.AddEvent("info", fmt.Sprintf("🟢 Container recovered: %s (down for %s)", name, format.FormatDuration(duration)))
+				ctx.State.AddEvent("info", fmt.Sprintf("🟢 Container recovered: %s (down for %s)", name, format.FormatDuration(downtime)))
 			} else {
 				ctx.State.AddEvent("info", fmt.Sprintf("🟢 Container started: %s", name))
 			}
 
-			if !ctx.IsQuietHours() {
-				msg := fmt.Sprintf("🟢 *Container UP*\n\n📦 `%s`\n\n_The container is now running._%s", name, downtimeMsg)
-				m := tgbotapi.NewMessage(ctx.Config.AllowedUserID, msg)
-				m.ParseMode = "Markdown"
-				safeSend(bot, m)
+			if !ctx.IsQuietHours() && !ctx.Docker.RestartLoop[name] {
+				m := alertMessage(containerUpText(name, downtime))
+				sendAlert(bot, ctx.Config, AlertTopicInfo, m)
 			}
 		}
 	}
@@ -84,9 +87,8 @@ func handleCriticalRAM(ctx *AppContext, bot BotAPI, s Stats) {
 	}
 
 	// Single batch call instead of N sequential calls (one per container).
-	// This reduces O(N × 2s) to O(1 × 5s) for the docker stats query.
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	out, err := runCommandStdout(timeoutCtx, "docker", "stats", "--no-stream", "--format", "{{.Name}}|{{.MemPerc}}")
+	stats, err := dockerClient.Stats(timeoutCtx)
 	cancel()
 
 	if err != nil {
@@ -95,15 +97,9 @@ func handleCriticalRAM(ctx *AppContext, bot BotAPI, s Stats) {
 	}
 
 	var heavyContainers []containerMem
-	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
-		parts := strings.SplitN(line, "|", 2)
-		if len(parts) < 2 {
-			continue
-		}
-		name := strings.TrimSpace(parts[0])
-		memStr := strings.TrimSuffix(strings.TrimSpace(parts[1]), "%")
-		if memPct, parseErr := strconv.ParseFloat(memStr, 64); parseErr == nil && memPct > 20 {
-			heavyContainers = append(heavyContainers, containerMem{name, memPct})
+	for _, st := range stats {
+		if st.MemPercent > 20 {
+			heavyContainers = append(heavyContainers, containerMem{st.Name, st.MemPercent})
 		}
 	}
 
@@ -119,7 +115,7 @@ func handleCriticalRAM(ctx *AppContext, bot BotAPI, s Stats) {
 				slog.Warn("RAM critical, auto-restart", "ram", s.RAM, "container", target.name, "mem_pct", target.memPct)
 
 				timeoutCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-				err := runCommand(timeoutCtx, "docker", "restart", target.name)
+				err := dockerClient.Restart(timeoutCtx, target.name)
 				cancel()
 
 				recordAutoRestart(ctx, target.name)
@@ -134,9 +130,8 @@ func handleCriticalRAM(ctx *AppContext, bot BotAPI, s Stats) {
 				}
 
 				if !ctx.IsQuietHours() {
-					msg := tgbotapi.NewMessage(ctx.Config.AllowedUserID, msgText)
-					msg.ParseMode = "Markdown"
-					safeSend(bot, msg)
+					msg := alertMessage(msgText)
+					sendAlert(bot, ctx.Config, AlertTopicWarning, msg)
 				}
 				restarted = true
 				break // Only restart one container per tick
@@ -149,6 +144,37 @@ func handleCriticalRAM(ctx *AppContext, bot BotAPI, s Stats) {
 	}
 }
 
+// restartUnhealthyContainer restarts a critical container whose healthcheck
+// fails, within the same hourly budget as the RAM-critical restarts.
+func restartUnhealthyContainer(ctx *AppContext, bot BotAPI, name string) {
+	if !canAutoRestart(ctx, name) {
+		slog.Warn("Critical container unhealthy, auto-restart throttled", "container", name)
+		return
+	}
+	slog.Warn("Critical container unhealthy, auto-restart", "container", name)
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	err := dockerClient.Restart(timeoutCtx, name)
+	cancel()
+
+	recordAutoRestart(ctx, name)
+	ctx.Docker.Mu.Lock()
+	ctx.Docker.Cache.LastUpdate = time.Time{}
+	ctx.Docker.Mu.Unlock()
+
+	var msgText string
+	if err != nil {
+		msgText = fmt.Sprintf("❌ *Auto-restart failed*\n\nContainer `%s` is unhealthy\nError: %v", name, err)
+		ctx.State.AddEvent("critical", fmt.Sprintf("Auto-restart failed: %s (%v)", name, err))
+	} else {
+		msgText = fmt.Sprintf("🔄 *Auto-restart done*\n\nRestarted unhealthy container `%s`\n\n_Watching..._", name)
+		ctx.State.AddEvent("action", fmt.Sprintf("Auto-restart: %s (unhealthy)", name))
+	}
+	if !ctx.IsQuietHours() {
+		sendAlert(bot, ctx.Config, AlertTopicWarning, alertMessage(msgText))
+	}
+}
+
 // canAutoRestart checks if container can be auto-restarted
 func canAutoRestart(ctx *AppContext, containerName string) bool {
 	ctx.Docker.Mu.Lock()
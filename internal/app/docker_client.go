@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"nasbot/internal/docker"
+)
+
+// containerRuntime is the configured engine, for what the client does not
+// cover: compose and restarting the service.
+var containerRuntime = docker.Docker
+
+// dockerClient is the active Docker backend: the Engine API socket with the
+// CLI as fallback. Swapped in tests.
+var dockerClient docker.Client = docker.New(docker.Docker, "")
+
+func setDockerClient(c docker.Client) (restore func()) {
+	prev := dockerClient
+	dockerClient = c
+	return func() { dockerClient = prev }
+}
+
+// configureDockerClient points the client at the configured runtime and
+// socket.
+func configureDockerClient(cfg *Config) {
+	rt, ok := docker.RuntimeByName(cfg.Docker.Runtime)
+	if !ok {
+		rt = docker.Docker
+	}
+	containerRuntime = rt
+	dockerClient = docker.New(rt, cfg.Docker.Socket)
+}
+
+// restartContainerService restarts the engine through systemd, as the
+// socket's user for rootless podman, or with the SysV script for Docker on
+// hosts without systemd.
+func restartContainerService(ctx context.Context, socket string) ([]byte, error) {
+	if commandExists("systemctl") {
+		args := []string{"restart"}
+		if docker.Rootless(socket) {
+			args = []string{"--user", "restart"}
+		}
+		return runCommandOutput(ctx, "systemctl", append(args, containerRuntime.Services...)...)
+	}
+	if containerRuntime.Name != docker.Docker.Name {
+		return nil, errors.New("restarting " + containerRuntime.Name + " needs systemctl")
+	}
+	return runCommandOutput(ctx, "service", "docker", "restart")
+}
+
+func containerLogs(ctx context.Context, name string, tail int) ([]byte, error) {
+	return dockerClient.Logs(ctx, name, tail)
+}
+
+// containerInfos converts the client's list to the cached ContainerInfo form.
+func containerInfos(list []docker.Container) []ContainerInfo {
+	var out []ContainerInfo
+	for _, c := range list {
+		out = append(out, ContainerInfo{
+			Name:    c.Name,
+			Status:  c.Status,
+			Image:   c.Image,
+			ID:      c.ID,
+			Running: c.Running(),
+			Health:  c.HealthStatus(),
+			Labels:  c.Labels,
+		})
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"nasbot/internal/docker"
+)
+
+func TestConfigureRuntime(t *testing.T) {
+	prevRuntime, prevClient := containerRuntime, dockerClient
+	defer func() { containerRuntime, dockerClient = prevRuntime, prevClient }()
+
+	cfg := &Config{}
+	cfg.Docker.Runtime = "podman"
+	if changes := sanitizeConfig(cfg); cfg.Docker.Socket != "/run/podman/podman.sock" {
+		t.Fatalf("podman socket not defaulted: %q (%v)", cfg.Docker.Socket, changes)
+	}
+	configureDockerClient(cfg)
+	if containerRuntime.Name != "podman" {
+		t.Fatalf("runtime = %+v", containerRuntime)
+	}
+
+	cfg = &Config{}
+	cfg.Docker.Runtime = "containerd"
+	sanitizeConfig(cfg)
+	if cfg.Docker.Runtime != "docker" || cfg.Docker.Socket != docker.DefaultSocket {
+		t.Fatalf("unknown runtime not reset: %+v", cfg.Docker)
+	}
+}
+
+func TestRestartContainerService(t *testing.T) {
+	prev := containerRuntime
+	defer func() { containerRuntime = prev }()
+	cases := []struct {
+		rt     docker.Runtime
+		socket string
+		exists bool
+		want   string
+	}{
+		{docker.Docker, docker.DefaultSocket, true, "[systemctl restart docker]"},
+		{docker.Docker, docker.DefaultSocket, false, "[service docker restart]"},
+		{docker.Podman, "/run/podman/podman.sock", true, "[systemctl restart podman.socket podman.service]"},
+		{docker.Podman, "/run/user/1000/podman/podman.sock", true, "[systemctl --user restart podman.socket podman.service]"},
+		{docker.Podman, "/run/podman/podman.sock", false, "[]"},
+	}
+	for _, c := range cases {
+		runner := &recordingRunner{mockRunner: mockRunner{exists: c.exists}}
+		restore := setCommandRunner(runner)
+		containerRuntime = c.rt
+		_, err := restartContainerService(context.Background(), c.socket)
+		restore()
+		if got := fmt.Sprint(runner.calls); got != c.want || (c.want == "[]") != (err != nil) {
+			t.Errorf("%s on %s: calls = %s, err = %v", c.rt.Name, c.socket, got, err)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package app
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"nasbot/internal/docker"
+	"nasbot/internal/format"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	ddiskListImages  = 8
+	ddiskListVolumes = 6
+	ddiskListLogs    = 5
+	// Volume names are up to 64 characters; callback data is limited to 64
+	// bytes, so buttons carry longer names as a hash resolved on delete.
+	ddiskVolumeKeyMax = 40
+)
+
+// Cleanup categories of the /ddisk view.
+const (
+	ddiskDangling = "dangling"
+	ddiskUnused   = "unused"
+	ddiskVolumes  = "volumes"
+	ddiskBuild    = "build"
+)
+
+func sizeText(b uint64) string { return format.FormatRAM(b >> 20) }
+
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
+
+// imageLabel names an image by its first tag, or by short ID when dangling.
+func imageLabel(img docker.ImageUsage) string {
+	if img.Dangling() {
+		return "<none> " + shortImageID(img.ID)
+	}
+	return img.Tags[0]
+}
+
+// volumeKey identifies a volume in callback data: the name itself, or for
+// long names "~" and a hash of it. Volume names cannot contain "~", so the
+// two never collide.
+func volumeKey(name string) string {
+	if len(name) <= ddiskVolumeKeyMax {
+		return name
+	}
+	sum := sha1.Sum([]byte(name))
+	return "~" + hex.EncodeToString(sum[:8])
+}
+
+// handleDockerDiskCommand sends the /ddisk view.
+func handleDockerDiskCommand(ctx *AppContext, bot BotAPI, chatID int64) {
+	text, kb := getDockerDiskText(ctx)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	if kb != nil {
+		msg.ReplyMarkup = kb
+	}
+	safeSend(bot, msg)
+}
+
+// getDockerDiskText renders the disk usage breakdown with delete buttons
+// for unused images, orphan volumes and whole categories.
+func getDockerDiskText(ctx *AppContext) (string, *tgbotapi.InlineKeyboardMarkup) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	du, err := dockerClient.DiskUsage(timeoutCtx)
+	if err != nil {
+		kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("back"), "show_docker"),
+		))
+		return fmt.Sprintf(ctx.Tr("ddisk_err"), err), &kb
+	}
+	logs := containerLogSizes(timeoutCtx, getCachedContainerList(ctx))
+
+	var b strings.Builder
+	var rows [][]tgbotapi.InlineKeyboardButton
+	b.WriteString(ctx.Tr("ddisk_title"))
+
+	// Images
+	images := append([]docker.ImageUsage(nil), du.Images...)
+	sort.Slice(images, func(i, j int) bool { return images[i].Size > images[j].Size })
+	var unused, dangling int
+	var unusedSize, danglingSize uint64
+	for _, img := range images {
+		switch {
+		case img.Dangling():
+			dangling++
+			danglingSize += img.Size
+		case img.Unused():
+			unused++
+			unusedSize += img.Size
+		}
+	}
+	b.WriteString(fmt.Sprintf(ctx.Tr("ddisk_images"), len(images), sizeText(du.ImagesSize())))
+	if unused > 0 {
+		b.WriteString(fmt.Sprintf(ctx.Tr("ddisk_unused"), unused, sizeText(unusedSize)))
+	}
+	if dangling > 0 {
+		b.WriteString(fmt.Sprintf(ctx.Tr("ddisk_dangling"), dangling, sizeText(danglingSize)))
+	}
+	b.WriteString("\n")
+	var imageButtons []tgbotapi.InlineKeyboardButton
+	for _, img := range images[:min(ddiskListImages, len(images))] {
+		flag := ""
+		if img.Dangling() {
+			flag = " · _dangling_"
+		} else if img.Unused() {
+			flag = " · _unused_"
+		}
+		b.WriteString(fmt.Sprintf("• `%s` %s%s\n", truncate(imageLabel(img), 32), sizeText(img.Size), flag))
+		if flag != "" {
+			imageButtons = append(imageButtons, tgbotapi.NewInlineKeyboardButtonData(
+				"🗑 "+truncate(imageLabel(img), 18), "ddisk_ask_img_"+shortImageID(img.ID)))
+		}
+	}
+
+	// Build cache
+	var cacheSize, cacheIdle uint64
+	for _, bc := range du.BuildCache {
+		cacheSize += bc.Size
+		if !bc.InUse {
+			cacheIdle += bc.Size
+		}
+	}
+	b.WriteString(fmt.Sprintf(ctx.Tr("ddisk_build"), sizeText(cacheSize), sizeText(cacheIdle)))
+
+	// Volumes
+	volumes := append([]docker.VolumeUsage(nil), du.Volumes...)
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Size > volumes[j].Size })
+	var volSize, orphanSize uint64
+	orphans := 0
+	for _, v := range volumes {
+		volSize += v.Size
+		if v.Orphan() {
+			orphans++
+			orphanSize += v.Size
+		}
+	}
+	b.WriteString(fmt.Sprintf(ctx.Tr("ddisk_volumes"), len(volumes), sizeText(volSize)))
+	if orphans > 0 {
+		b.WriteString(fmt.Sprintf(ctx.Tr("ddisk_orphans"), orphans, sizeText(orphanSize)))
+	}
+	b.WriteString("\n")
+	var volumeButtons []tgbotapi.InlineKeyboardButton
+	for _, v := range volumes[:min(ddiskListVolumes, len(volumes))] {
+		flag := ""
+		if v.Orphan() {
+			flag = " · _orphan_"
+			volumeButtons = append(volumeButtons, tgbotapi.NewInlineKeyboardButtonData(
+				"🗑 📁 "+truncate(v.Name, 16), "ddisk_ask_vol_"+volumeKey(v.Name)))
+		}
+		b.WriteString(fmt.Sprintf("• `%s` %s%s\n", truncate(v.Name, 32), sizeText(v.Size), flag))
+	}
+
+	// Container logs
+	var logSize uint64
+	for _, l := range logs {
+		logSize += l.Size
+	}
+	b.WriteString(fmt.Sprintf(ctx.Tr("ddisk_logs"), sizeText(logSize)))
+	var logButtons []tgbotapi.InlineKeyboardButton
+	for _, l := range logs[:min(ddiskListLogs, len(logs))] {
+		b.WriteString(fmt.Sprintf("• `%s` %s\n", l.Name, sizeText(l.Size)))
+		if l.Size >= 1<<20 {
+			logButtons = append(logButtons, tgbotapi.NewInlineKeyboardButtonData("✂️ "+truncate(l.Name, 18), "dlog_ask_"+l.Name))
+		}
+	}
+
+	for _, buttons := range [][]tgbotapi.InlineKeyboardButton{imageButtons, volumeButtons, logButtons} {
+		for i := 0; i < len(buttons); i += 2 {
+			rows = append(rows, buttons[i:min(i+2, len(buttons))])
+		}
+	}
+	var cats []tgbotapi.InlineKeyboardButton
+	if dangling > 0 {
+		cats = append(cats, tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("ddisk_btn_dangling"), "ddisk_ask_cat_"+ddiskDangling))
+	}
+	if unused > 0 {
+		cats = append(cats, tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("ddisk_btn_unused"), "ddisk_ask_cat_"+ddiskUnused))
+	}
+	if cacheSize > 0 {
+		cats = append(cats, tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("ddisk_btn_build"), "ddisk_ask_cat_"+ddiskBuild))
+	}
+	if orphans > 0 {
+		cats = append(cats, tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("ddisk_btn_volumes"), "ddisk_ask_cat_"+ddiskVolumes))
+	}
+	for i := 0; i < len(cats); i += 2 {
+		rows = append(rows, cats[i:min(i+2, len(cats))])
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("docker_menu_refresh"), "ddisk_refresh"),
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("back"), "show_docker"),
+	))
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return b.String(), &kb
+}
+
+// handleDockerDiskCallback handles ddisk_refresh, ddisk_ask_<kind>_<arg>
+// and ddisk_do_<kind>_<arg>, kind being img, vol or cat.
+func handleDockerDiskCallback(ctx *AppContext, bot BotAPI, chatID int64, msgID int, data string) {
+	if data == "ddisk_refresh" {
+		text, kb := getDockerDiskText(ctx)
+		editMessage(bot, chatID, msgID, text, kb)
+		return
+	}
+	parts := strings.SplitN(data, "_", 4)
+	if len(parts) != 4 {
+		return
+	}
+	step, kind, arg := parts[1], parts[2], parts[3]
+	switch step {
+	case "ask":
+		confirmDockerDiskDelete(ctx, bot, chatID, msgID, kind, arg)
+	case "do":
+		executeDockerDiskDelete(ctx, bot, chatID, msgID, kind, arg)
+	}
+}
+
+// confirmDockerDiskDelete asks before deleting an item or a category.
+func confirmDockerDiskDelete(ctx *AppContext, bot BotAPI, chatID int64, msgID int, kind, arg string) {
+	var text string
+	switch kind {
+	case "img":
+		text = fmt.Sprintf(ctx.Tr("ddisk_confirm_img"), arg)
+	case "vol":
+		text = fmt.Sprintf(ctx.Tr("ddisk_confirm_vol"), arg)
+	case "cat":
+		text = ctx.Tr("ddisk_confirm_" + arg)
+	default:
+		return
+	}
+	kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("yes"), "ddisk_do_"+kind+"_"+arg),
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("no"), "ddisk_refresh"),
+	))
+	editMessage(bot, chatID, msgID, text, &kb)
+}
+
+// executeDockerDiskDelete deletes an image, a volume or a category and
+// reports the space reclaimed as an action event.
+func executeDockerDiskDelete(ctx *AppContext, bot BotAPI, chatID int64, msgID int, kind, arg string) {
+	editMessage(bot, chatID, msgID, ctx.Tr("ddisk_deleting"), nil)
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	label, reclaimed, err := deleteDockerDiskItem(timeoutCtx, kind, arg)
+	var text string
+	if err != nil {
+		slog.Error("Docker cleanup failed", "kind", kind, "item", arg, "err", err)
+		text = fmt.Sprintf(ctx.Tr("ddisk_delete_err"), label, err)
+	} else {
+		text = fmt.Sprintf(ctx.Tr("ddisk_delete_ok"), label, sizeText(reclaimed))
+		ctx.State.AddEvent("action", fmt.Sprintf("Docker cleanup: %s (%s reclaimed)", label, sizeText(reclaimed)))
+	}
+	kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("💽 "+ctx.Tr("ddisk_back"), "ddisk_refresh"),
+	))
+	editMessage(bot, chatID, msgID, text, &kb)
+}
+
+// deleteDockerDiskItem performs the deletion. Single items are looked up
+// again, so the size is known and a stale button cannot hit another item.
+func deleteDockerDiskItem(ctx context.Context, kind, arg string) (label string, reclaimed uint64, err error) {
+	switch kind {
+	case "cat":
+		switch arg {
+		case ddiskDangling:
+			reclaimed, err = dockerClient.PruneImages(ctx, false)
+			return "dangling images", reclaimed, err
+		case ddiskUnused:
+			reclaimed, err = dockerClient.PruneImages(ctx, true)
+			return "unused images", reclaimed, err
+		case ddiskBuild:
+			reclaimed, err = dockerClient.PruneBuildCache(ctx)
+			return "build cache", reclaimed, err
+		case ddiskVolumes:
+			reclaimed, err = dockerClient.PruneVolumes(ctx)
+			return "orphan volumes", reclaimed, err
+		}
+		return arg, 0, fmt.Errorf("unknown category %q", arg)
+	}
+
+	du, err := dockerClient.DiskUsage(ctx)
+	if err != nil {
+		return arg, 0, err
+	}
+	switch kind {
+	case "img":
+		for _, img := range du.Images {
+			if shortImageID(img.ID) != arg {
+				continue
+			}
+			label = "image " + imageLabel(img)
+			if !img.Dangling() && !img.Unused() {
+				return label, 0, fmt.Errorf("image is in use")
+			}
+			return label, img.Size - min(img.SharedSize, img.Size), dockerClient.RemoveImage(ctx, img.ID)
+		}
+		return "image " + arg, 0, fmt.Errorf("image not found")
+	case "vol":
+		for _, v := range du.Volumes {
+			if volumeKey(v.Name) != arg {
+				continue
+			}
+			label = "volume " + v.Name
+			if !v.Orphan() {
+				return label, 0, fmt.Errorf("volume is in use")
+			}
+			return label, v.Size, dockerClient.RemoveVolume(ctx, v.Name)
+		}
+		return "volume " + arg, 0, fmt.Errorf("volume not found")
+	}
+	return arg, 0, fmt.Errorf("unknown item kind %q", kind)
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nasbot/internal/docker"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func diskFixture(t *testing.T) (*fakeDocker, *AppContext) {
+	logPath := filepath.Join(t.TempDir(), "web-json.log")
+	if err := os.WriteFile(logPath, make([]byte, 3<<20), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	web := containerDetails("web", "nginx:latest", "sha256:aaa", true, "")
+	web.LogPath = logPath
+	fake := &fakeDocker{
+		details: map[string]*docker.Details{"web": web},
+		disk: &docker.DiskUsage{
+			Images: []docker.ImageUsage{
+				{ID: "sha256:aaa111111111ffff", Tags: []string{"nginx:latest"}, Size: 200 << 20, Containers: 1},
+				{ID: "sha256:bbb222222222ffff", Tags: []string{"redis:6"}, Size: 100 << 20},
+				{ID: "sha256:ccc333333333ffff", Size: 50 << 20},
+			},
+			Volumes: []docker.VolumeUsage{
+				{Name: "web-data", Size: 10 << 20, RefCount: 1},
+				{Name: "old-db", Size: 500 << 20},
+			},
+			BuildCache: []docker.BuildCacheUsage{{ID: "c1", Size: 30 << 20}},
+		},
+	}
+	ctx := newTestAppContext()
+	ctx.Docker.Cache.Containers = []ContainerInfo{{Name: "web", Running: true}}
+	return fake, ctx
+}
+
+func keyboardData(kb *tgbotapi.InlineKeyboardMarkup) []string {
+	var out []string
+	for _, row := range kb.InlineKeyboard {
+		for _, b := range row {
+			if b.CallbackData != nil {
+				out = append(out, *b.CallbackData)
+			}
+		}
+	}
+	return out
+}
+
+func TestDockerDiskView(t *testing.T) {
+	fake, ctx := diskFixture(t)
+	defer setDockerClient(fake)()
+
+	text, kb := getDockerDiskText(ctx)
+	for _, want := range []string{"`redis:6` 100M · _unused_", "`<none> ccc333333333` 50M · _dangling_", "`old-db` 500M · _orphan_", "`web` 3M", "1 unused (100M)"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("missing %q in:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "`nginx:latest` 200M ·") || strings.Contains(text, "`web-data` 10M ·") {
+		t.Fatalf("items in use must not be flagged:\n%s", text)
+	}
+	data := strings.Join(keyboardData(kb), " ")
+	for _, want := range []string{"ddisk_ask_img_bbb222222222", "ddisk_ask_img_ccc333333333", "ddisk_ask_vol_old-db", "ddisk_ask_cat_dangling", "ddisk_ask_cat_build", "ddisk_ask_cat_volumes"} {
+		if !strings.Contains(data, want) {
+			t.Fatalf("missing button %q in %s", want, data)
+		}
+	}
+	if strings.Contains(data, "aaa111111111") || strings.Contains(data, "web-data") {
+		t.Fatalf("items in use must have no delete button: %s", data)
+	}
+	for _, d := range keyboardData(kb) {
+		if len(d) > 64 {
+			t.Fatalf("callback data too long: %q", d)
+		}
+	}
+}
+
+func TestDockerDiskDelete(t *testing.T) {
+	fake, ctx := diskFixture(t)
+	defer setDockerClient(fake)()
+	bot := &fakeBot{}
+
+	handleDockerDiskCallback(ctx, bot, 1, 1, "ddisk_ask_vol_old-db")
+	if len(fake.actions) != 0 {
+		t.Fatalf("asking must not delete: %v", fake.actions)
+	}
+	handleDockerDiskCallback(ctx, bot, 1, 1, "ddisk_do_vol_old-db")
+	handleDockerDiskCallback(ctx, bot, 1, 1, "ddisk_do_img_bbb222222222")
+	handleDockerDiskCallback(ctx, bot, 1, 1, "ddisk_do_img_aaa111111111")
+	handleDockerDiskCallback(ctx, bot, 1, 1, "ddisk_do_cat_unused")
+
+	want := []string{"rmv old-db", "rmi sha256:bbb222222222ffff", "prune images all=true"}
+	if fmt.Sprint(fake.actions) != fmt.Sprint(want) {
+		t.Fatalf("actions = %v, want %v", fake.actions, want)
+	}
+	var events []string
+	for _, e := range ctx.State.GetEvents() {
+		if e.Type == "action" {
+			events = append(events, e.Message)
+		}
+	}
+	wantEvents := []string{
+		"Docker cleanup: volume old-db (500M reclaimed)",
+		"Docker cleanup: image redis:6 (100M reclaimed)",
+		"Docker cleanup: unused images (100M reclaimed)",
+	}
+	if fmt.Sprint(events) != fmt.Sprint(wantEvents) {
+		t.Fatalf("events = %q, want %q", events, wantEvents)
+	}
+	var inUse bool
+	for _, c := range bot.sent {
+		if e, ok := c.(tgbotapi.EditMessageTextConfig); ok && strings.Contains(e.Text, "image is in use") {
+			inUse = true
+		}
+	}
+	if !inUse {
+		t.Fatalf("deleting an image in use should be refused")
+	}
+}
+
+func TestDockerDiskLongVolumeNames(t *testing.T) {
+	fake, ctx := diskFixture(t)
+	defer setDockerClient(fake)()
+	prefix := "nextcloud-stack-production-2024_" + strings.Repeat("x", 10)
+	fake.disk.Volumes = []docker.VolumeUsage{
+		{Name: prefix + "_data", Size: 10 << 20, RefCount: 1},
+		{Name: prefix + "_cache", Size: 5 << 20},
+	}
+
+	_, kb := getDockerDiskText(ctx)
+	var keys []string
+	for _, data := range keyboardData(kb) {
+		if key, ok := strings.CutPrefix(data, "ddisk_ask_vol_"); ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) != 1 || len("ddisk_do_vol_"+keys[0]) > 64 || keys[0] != volumeKey(prefix+"_cache") {
+		t.Fatalf("unexpected volume keys: %v", keys)
+	}
+	if volumeKey(prefix+"_data") == keys[0] {
+		t.Fatalf("volumes with a shared prefix got the same key")
+	}
+
+	handleDockerDiskCallback(ctx, &fakeBot{}, 1, 1, "ddisk_do_vol_"+keys[0])
+	if want := "[rmv " + prefix + "_cache]"; fmt.Sprint(fake.actions) != want {
+		t.Fatalf("actions = %v, want %v", fake.actions, want)
+	}
+}
@@ -0,0 +1,260 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"nasbot/internal/docker"
+	"nasbot/internal/format"
+)
+
+const (
+	dockerEventsMinBackoff = 5 * time.Second
+	dockerEventsMaxBackoff = time.Minute
+	// A die within this window after a stop/kill request is not a crash.
+	dockerStopGrace = 30 * time.Second
+)
+
+// dockerEventWatcher follows the Docker event stream so crashes between two
+// polls of checkContainerStates are still noticed. It reconnects with
+// backoff and gives up only when the backend has no event stream (CLI).
+func dockerEventWatcher(ctx *AppContext, bot BotAPI, runCtx context.Context) {
+	backoff := dockerEventsMinBackoff
+	for runCtx.Err() == nil {
+		started := time.Now()
+		events, errs := dockerClient.Events(runCtx)
+		for ev := range events {
+			setDockerEventsActive(ctx, true)
+			handleDockerEvent(ctx, bot, ev)
+		}
+		err := <-errs
+		setDockerEventsActive(ctx, false)
+
+		if runCtx.Err() != nil {
+			return
+		}
+		if errors.Is(err, docker.ErrNotSupported) {
+			slog.Info("Docker events not available, relying on polling")
+			return
+		}
+		if time.Since(started) > dockerEventsMaxBackoff {
+			backoff = dockerEventsMinBackoff
+		}
+		slog.Warn("Docker event stream interrupted", "err", err, "retry_in", backoff)
+
+		select {
+		case <-runCtx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, dockerEventsMaxBackoff)
+	}
+}
+
+func setDockerEventsActive(ctx *AppContext, active bool) {
+	ctx.Docker.Mu.Lock()
+	ctx.Docker.EventsActive = active
+	ctx.Docker.Mu.Unlock()
+}
+
+// handleDockerEvent applies a container event to the tracked state and sends
+// the matching notification.
+func handleDockerEvent(ctx *AppContext, bot BotAPI, ev docker.Event) {
+	name := ev.Name
+	if ev.Type != "container" || name == "" {
+		return
+	}
+	action := ev.Action
+	if status, ok := strings.CutPrefix(action, "health_status"); ok {
+		handleHealthEvent(ctx, bot, name, strings.TrimSpace(strings.TrimPrefix(status, ":")))
+		return
+	}
+
+	switch action {
+	case "oom":
+		ctx.Docker.Mu.Lock()
+		initDockerMaps(ctx.Docker)
+		ctx.Docker.OOMKilled[name] = true
+		ctx.Docker.Mu.Unlock()
+		ctx.State.AddEvent("warning", fmt.Sprintf("💥 OOM kill in container: %s", name))
+	case "stop", "kill":
+		ctx.Docker.Mu.Lock()
+		initDockerMaps(ctx.Docker)
+		ctx.Docker.StopRequested[name] = ev.Time
+		ctx.Docker.Mu.Unlock()
+	case "die":
+		handleDieEvent(ctx, bot, name, ev)
+	case "start":
+		handleStartEvent(ctx, bot, name)
+	case "restart":
+		ctx.State.AddEvent("info", fmt.Sprintf("🔄 Container restarted: %s", name))
+	case "destroy":
+		ctx.Docker.Mu.Lock()
+		delete(ctx.Docker.LastStates, name)
+		delete(ctx.Docker.ContainerDowntime, name)
+		delete(ctx.Docker.Health, name)
+		delete(ctx.Docker.OOMKilled, name)
+		delete(ctx.Docker.StopRequested, name)
+		delete(ctx.Docker.Crashes, name)
+		delete(ctx.Docker.RestartCounts, name)
+		delete(ctx.Docker.RestartLoop, name)
+		ctx.Docker.Cache.LastUpdate = time.Time{}
+		ctx.Docker.Mu.Unlock()
+		ctx.State.AddEvent("info", fmt.Sprintf("🗑 Container removed: %s", name))
+	}
+}
+
+func handleDieEvent(ctx *AppContext, bot BotAPI, name string, ev docker.Event) {
+	exitCode := ev.Attributes["exitCode"]
+
+	ctx.Docker.Mu.Lock()
+	initDockerMaps(ctx.Docker)
+	oom := ctx.Docker.OOMKilled[name]
+	delete(ctx.Docker.OOMKilled, name)
+	requested, ok := ctx.Docker.StopRequested[name]
+	expected := ok && ev.Time.Sub(requested) < dockerStopGrace
+	delete(ctx.Docker.StopRequested, name)
+	ctx.Docker.LastStates[name] = false
+	if !expected {
+		ctx.Docker.ContainerDowntime[name] = ev.Time
+	}
+	ctx.Docker.Cache.LastUpdate = time.Time{}
+	ctx.Docker.Mu.Unlock()
+
+	if expected && !oom {
+		ctx.State.AddEvent("info", fmt.Sprintf("⏹ Container stopped: %s", name))
+		return
+	}
+	ctx.State.AddEvent("warning", "🔴 Container stopped: "+name+containerExitDetail(exitCode, oom))
+	if recordContainerCrashes(ctx, bot, name, exitCode, 1) {
+		return
+	}
+	if !ctx.IsQuietHours() {
+		sendAlert(bot, ctx.Config, AlertTopicWarning, alertMessage(containerDownText(name, exitCode, oom)))
+	}
+}
+
+func handleStartEvent(ctx *AppContext, bot BotAPI, name string) {
+	ctx.Docker.Mu.Lock()
+	initDockerMaps(ctx.Docker)
+	downStart, wasDown := ctx.Docker.ContainerDowntime[name]
+	delete(ctx.Docker.ContainerDowntime, name)
+	ctx.Docker.LastStates[name] = true
+	looping := ctx.Docker.RestartLoop[name]
+	ctx.Docker.Cache.LastUpdate = time.Time{}
+	ctx.Docker.Mu.Unlock()
+
+	if !wasDown || looping {
+		ctx.State.AddEvent("info", fmt.Sprintf("🟢 Container started: %s", name))
+		return
+	}
+	downtime := time.Since(downStart)
+	ctx.State.AddEvent("info", fmt.Sprintf("🟢 Container recovered: %s (down for %s)", name, format.FormatDuration(downtime)))
+	if !ctx.IsQuietHours() {
+		sendAlert(bot, ctx.Config, AlertTopicInfo, alertMessage(containerUpText(name, downtime)))
+	}
+}
+
+// handleHealthEvent records a health_status event and notifies on changes.
+func handleHealthEvent(ctx *AppContext, bot BotAPI, name, status string) {
+	ctx.Docker.Mu.Lock()
+	initDockerMaps(ctx.Docker)
+	prev := ctx.Docker.Health[name]
+	ctx.Docker.Health[name] = status
+	ctx.Docker.Mu.Unlock()
+
+	notifyHealthChange(ctx, bot, name, prev, status)
+}
+
+// notifyHealthChange notifies when a container turns unhealthy and when it
+// recovers from it; "starting" is only recorded.
+func notifyHealthChange(ctx *AppContext, bot BotAPI, name, prev, status string) {
+	switch {
+	case status == "unhealthy" && prev != "unhealthy":
+		ctx.State.AddEvent("warning", fmt.Sprintf("🩺 Container unhealthy: %s", name))
+		if !ctx.IsQuietHours() {
+			msg := fmt.Sprintf("🩺 *Container UNHEALTHY*\n\n📦 `%s`\n\n_Its healthcheck is failing._", name)
+			sendAlert(bot, ctx.Config, AlertTopicWarning, alertMessage(msg))
+		}
+	case status == "healthy" && prev == "unhealthy":
+		ctx.State.AddEvent("info", fmt.Sprintf("💚 Container healthy again: %s", name))
+		if !ctx.IsQuietHours() {
+			msg := fmt.Sprintf("💚 *Container HEALTHY*\n\n📦 `%s`\n\n_Its healthcheck passes again._", name)
+			sendAlert(bot, ctx.Config, AlertTopicInfo, alertMessage(msg))
+		}
+	}
+}
+
+// initDockerMaps allocates the tracking maps. Callers hold d.Mu.
+func initDockerMaps(d *DockerManager) {
+	if d.LastStates == nil {
+		d.LastStates = make(map[string]bool)
+	}
+	if d.ContainerDowntime == nil {
+		d.ContainerDowntime = make(map[string]time.Time)
+	}
+	if d.Health == nil {
+		d.Health = make(map[string]string)
+	}
+	if d.OOMKilled == nil {
+		d.OOMKilled = make(map[string]bool)
+	}
+	if d.StopRequested == nil {
+		d.StopRequested = make(map[string]time.Time)
+	}
+	if d.Crashes == nil {
+		d.Crashes = make(map[string][]time.Time)
+	}
+	if d.RestartCounts == nil {
+		d.RestartCounts = make(map[string][]RestartSample)
+	}
+	if d.RestartLoop == nil {
+		d.RestartLoop = make(map[string]bool)
+	}
+	if d.Updates == nil {
+		d.Updates = make(map[string]ImageUpdate)
+	}
+	if d.Updating == nil {
+		d.Updating = make(map[string]bool)
+	}
+}
+
+// containerExitDetail formats " (exit 137, OOM)" for event log entries.
+func containerExitDetail(exitCode string, oom bool) string {
+	var parts []string
+	if exitCode != "" {
+		parts = append(parts, "exit "+exitCode)
+	}
+	if oom {
+		parts = append(parts, "OOM")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+func containerDownText(name, exitCode string, oom bool) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🔴 *Container DOWN*\n\n📦 `%s`\n", name))
+	if exitCode != "" {
+		b.WriteString(fmt.Sprintf("🔢 Exit code: `%s`\n", exitCode))
+	}
+	if oom {
+		b.WriteString("💥 Killed by the OOM killer\n")
+	}
+	b.WriteString("\n_The container has stopped unexpectedly._")
+	return b.String()
+}
+
+func containerUpText(name string, downtime time.Duration) string {
+	var downtimeMsg string
+	if downtime > 0 {
+		downtimeMsg = fmt.Sprintf("\n⏱ Downtime: `%s`", format.FormatDuration(downtime))
+	}
+	return fmt.Sprintf("🟢 *Container UP*\n\n📦 `%s`\n\n_The container is now running._%s", name, downtimeMsg)
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"nasbot/internal/docker"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func containerEvent(action, name string, attrs map[string]string) docker.Event {
+	return docker.Event{Type: "container", Action: action, Name: name, Attributes: attrs, Time: time.Now()}
+}
+
+func sentTexts(bot *fakeBot) []string {
+	var out []string
+	for _, c := range bot.sent {
+		if m, ok := c.(tgbotapi.MessageConfig); ok {
+			out = append(out, m.Text)
+		}
+	}
+	return out
+}
+
+func TestDockerEventCrashAndRecovery(t *testing.T) {
+	ctx := newTestAppContext()
+	bot := &fakeBot{}
+
+	handleDockerEvent(ctx, bot, containerEvent("oom", "db", nil))
+	handleDockerEvent(ctx, bot, containerEvent("die", "db", map[string]string{"exitCode": "137"}))
+	handleDockerEvent(ctx, bot, containerEvent("start", "db", nil))
+
+	texts := sentTexts(bot)
+	if len(texts) != 2 {
+		t.Fatalf("expected down and up alerts, got %q", texts)
+	}
+	if !strings.Contains(texts[0], "DOWN") || !strings.Contains(texts[0], "`137`") || !strings.Contains(texts[0], "OOM") {
+		t.Fatalf("unexpected down alert: %q", texts[0])
+	}
+	if !strings.Contains(texts[1], "UP") || !strings.Contains(texts[1], "Downtime") {
+		t.Fatalf("unexpected up alert: %q", texts[1])
+	}
+	if !ctx.Docker.LastStates["db"] || len(ctx.Docker.OOMKilled) != 0 {
+		t.Fatalf("state not updated: %+v", ctx.Docker)
+	}
+
+	var found bool
+	for _, e := range ctx.State.GetEvents() {
+		if strings.Contains(e.Message, "db (exit 137, OOM)") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("report event missing exit details: %+v", ctx.State.GetEvents())
+	}
+}
+
+func TestDockerEventRequestedStopIsQuiet(t *testing.T) {
+	ctx := newTestAppContext()
+	bot := &fakeBot{}
+
+	handleDockerEvent(ctx, bot, containerEvent("kill", "web", map[string]string{"signal": "15"}))
+	handleDockerEvent(ctx, bot, containerEvent("die", "web", map[string]string{"exitCode": "0"}))
+	handleDockerEvent(ctx, bot, containerEvent("stop", "web", nil))
+	handleDockerEvent(ctx, bot, containerEvent("start", "web", nil))
+	handleDockerEvent(ctx, bot, containerEvent("restart", "web", nil))
+
+	if texts := sentTexts(bot); len(texts) != 0 {
+		t.Fatalf("a requested restart must not alert, got %q", texts)
+	}
+}
+
+func TestDockerEventHealthTransitions(t *testing.T) {
+	ctx := newTestAppContext()
+	bot := &fakeBot{}
+
+	for _, status := range []string{"starting", "healthy", "unhealthy", "unhealthy", "healthy", "healthy"} {
+		handleDockerEvent(ctx, bot, containerEvent("health_status: "+status, "app", nil))
+	}
+	texts := sentTexts(bot)
+	if len(texts) != 2 || !strings.Contains(texts[0], "UNHEALTHY") || !strings.Contains(texts[1], "HEALTHY") {
+		t.Fatalf("expected one unhealthy and one recovery alert, got %q", texts)
+	}
+}
+
+func TestPollerDefersToEventStream(t *testing.T) {
+	ctx := newTestAppContext()
+	bot := &fakeBot{}
+	ctx.Docker.LastStates = map[string]bool{"x": false}
+	ctx.Docker.EventsActive = true
+
+	checkContainerStates(ctx, bot)
+	if len(bot.sent) != 0 || !ctx.Docker.LastStates["x"] {
+		t.Fatalf("poller should only sync state while events are active: sent=%d states=%v", len(bot.sent), ctx.Docker.LastStates)
+	}
+
+	ctx.Docker.LastStates = map[string]bool{"x": false}
+	ctx.Docker.EventsActive = false
+	checkContainerStates(ctx, bot)
+	if texts := sentTexts(bot); len(texts) != 1 || !strings.Contains(texts[0], "UP") {
+		t.Fatalf("poller should notify without events, got %q", texts)
+	}
+}
+
+func TestDockerEventWatcherStopsWithoutStream(t *testing.T) {
+	fake := &fakeDocker{
+		events: []docker.Event{containerEvent("die", "x", map[string]string{"exitCode": "1"})},
+		err:    docker.ErrNotSupported,
+	}
+	defer setDockerClient(fake)()
+	ctx := newTestAppContext()
+	bot := &fakeBot{}
+
+	done := make(chan struct{})
+	go func() {
+		dockerEventWatcher(ctx, bot, context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("watcher did not return on an unsupported backend")
+	}
+	if ctx.Docker.EventsActive || ctx.Docker.LastStates["x"] {
+		t.Fatalf("unexpected state after watcher exit: %+v", ctx.Docker)
+	}
+	if len(sentTexts(bot)) != 1 {
+		t.Fatalf("queued event not handled")
+	}
+}
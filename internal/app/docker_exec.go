@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nasbot/internal/format"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// execOutputLimit is how much of the output is shown, from the end.
+const execOutputLimit = 3000
+
+// execRunning holds the "container/index" actions in progress, so a
+// double tap does not run a command twice.
+var execRunning sync.Map
+
+// execActionButtons returns a button per exec action of the container.
+func execActionButtons(ctx *AppContext, name string) [][]tgbotapi.InlineKeyboardButton {
+	actions := ctx.Config.Docker.ExecActions[name]
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(actions); i += 2 {
+		var row []tgbotapi.InlineKeyboardButton
+		for j := i; j < min(i+2, len(actions)); j++ {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+				"⚙️ "+truncate(actions[j].Name, 24), fmt.Sprintf("dexec_ask_%d_%s", j, name)))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// handleExecActionCallback handles dexec_ask_<index>_<container> and
+// dexec_run_<index>_<container>.
+func handleExecActionCallback(ctx *AppContext, bot BotAPI, chatID int64, msgID int, data string) {
+	parts := strings.SplitN(data, "_", 4)
+	if len(parts) != 4 {
+		return
+	}
+	step, name := parts[1], parts[3]
+	idx, err := strconv.Atoi(parts[2])
+	actions := ctx.Config.Docker.ExecActions[name]
+	if err != nil || idx < 0 || idx >= len(actions) {
+		editMessage(bot, chatID, msgID, ctx.Tr("docker_exec_unknown"), nil)
+		return
+	}
+	action := actions[idx]
+
+	switch step {
+	case "ask":
+		text := fmt.Sprintf(ctx.Tr("docker_exec_confirm"), action.Name, name, strings.Join(action.Command, " "))
+		if action.User != "" {
+			text += fmt.Sprintf(ctx.Tr("docker_exec_user"), action.User)
+		}
+		kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("yes"), fmt.Sprintf("dexec_run_%d_%s", idx, name)),
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("no"), "container_select_"+name),
+		))
+		editMessage(bot, chatID, msgID, text, &kb)
+	case "run":
+		runExecAction(ctx, bot, chatID, msgID, name, idx, action)
+	}
+}
+
+// runExecAction runs the command and shows the tail of its output.
+func runExecAction(ctx *AppContext, bot BotAPI, chatID int64, msgID int, name string, idx int, action ExecActionConfig) {
+	back := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("back"), "container_select_"+name),
+	))
+	key := fmt.Sprintf("%s/%d", name, idx)
+	if _, busy := execRunning.LoadOrStore(key, true); busy {
+		editMessage(bot, chatID, msgID, fmt.Sprintf(ctx.Tr("docker_exec_busy"), action.Name), &back)
+		return
+	}
+	defer execRunning.Delete(key)
+
+	editMessage(bot, chatID, msgID, fmt.Sprintf(ctx.Tr("docker_exec_running"), action.Name, name), nil)
+	timeout := time.Duration(action.TimeoutSeconds) * time.Second
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	res, err := dockerClient.Exec(timeoutCtx, name, action.Command, action.User)
+	elapsed := time.Since(start).Round(100 * time.Millisecond)
+
+	var text string
+	switch {
+	case errors.Is(timeoutCtx.Err(), context.DeadlineExceeded):
+		slog.Warn("Exec action timed out", "container", name, "action", action.Name, "timeout", timeout)
+		text = fmt.Sprintf(ctx.Tr("docker_exec_timeout"), action.Name, timeout)
+		ctx.State.AddEvent("warning", fmt.Sprintf("Exec %q on %s timed out after %s", action.Name, name, timeout))
+	case err != nil:
+		slog.Error("Exec action failed", "container", name, "action", action.Name, "err", err)
+		text = fmt.Sprintf(ctx.Tr("docker_exec_err"), action.Name, err)
+	default:
+		icon := "✅"
+		if res.ExitCode != 0 {
+			icon = "⚠️"
+		}
+		text = fmt.Sprintf(ctx.Tr("docker_exec_done"), icon, action.Name, res.ExitCode, elapsed)
+		text += execOutputBlock(ctx, res.Output, res.Truncated)
+		ctx.State.AddEvent("action", fmt.Sprintf("Exec %q on %s: exit %d", action.Name, name, res.ExitCode))
+	}
+	editMessage(bot, chatID, msgID, text, &back)
+}
+
+// execOutputBlock renders the end of the output as a code block.
+func execOutputBlock(ctx *AppContext, output []byte, truncated bool) string {
+	// Telegram rejects invalid UTF-8, even as plain text.
+	out := strings.TrimSpace(strings.ToValidUTF8(string(output), "�"))
+	if out == "" {
+		return ctx.Tr("docker_exec_no_output")
+	}
+	if len(out) > execOutputLimit {
+		out = "…" + format.Tail(out, execOutputLimit)
+		truncated = true
+	}
+	// A fence inside the output would end the block early.
+	out = strings.ReplaceAll(out, "```", "'''")
+	text := "\n```\n" + out + "\n```"
+	if truncated {
+		text += ctx.Tr("docker_exec_truncated")
+	}
+	return text
+}
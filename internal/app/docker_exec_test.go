@@ -0,0 +1,94 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"nasbot/internal/docker"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestExecActions(t *testing.T) {
+	fake := &fakeDocker{exec: docker.ExecResult{Output: []byte("Maintenance mode disabled\n```\n"), ExitCode: 0}}
+	defer setDockerClient(fake)()
+	ctx := newTestAppContext()
+	ctx.Docker.Cache.Containers = []ContainerInfo{{Name: "nextcloud", Running: true}, {Name: "db", Running: true}}
+	ctx.Config.Docker.ExecActions = map[string][]ExecActionConfig{
+		"nextcloud": {
+			{Name: "Scan files", Command: []string{"php", "occ", "files:scan", "--all"}, User: "www-data", TimeoutSeconds: 30},
+			{Name: "Maintenance off", Command: []string{"php", "occ", "maintenance:mode", "--off"}, User: "www-data", TimeoutSeconds: 30},
+		},
+	}
+	bot := &fakeBot{}
+
+	showContainerActions(ctx, bot, 1, 1, "nextcloud")
+	kb := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig).ReplyMarkup
+	data := strings.Join(keyboardData(kb), " ")
+	if !strings.Contains(data, "dexec_ask_0_nextcloud dexec_ask_1_nextcloud") {
+		t.Fatalf("missing exec buttons: %s", data)
+	}
+	showContainerActions(ctx, bot, 1, 1, "db")
+	if data := keyboardData(bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig).ReplyMarkup); strings.Contains(strings.Join(data, " "), "dexec_") {
+		t.Fatalf("db has no exec actions: %v", data)
+	}
+
+	handleExecActionCallback(ctx, bot, 1, 1, "dexec_ask_1_nextcloud")
+	confirm := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig)
+	if !strings.Contains(confirm.Text, "`php occ maintenance:mode --off`") || !strings.Contains(confirm.Text, "`www-data`") {
+		t.Fatalf("unexpected confirmation: %q", confirm.Text)
+	}
+	if len(fake.actions) != 0 {
+		t.Fatalf("ran before confirmation: %v", fake.actions)
+	}
+
+	handleExecActionCallback(ctx, bot, 1, 1, "dexec_run_1_nextcloud")
+	want := `exec nextcloud ["php" "occ" "maintenance:mode" "--off"] user=www-data`
+	if len(fake.actions) != 1 || fake.actions[0] != want {
+		t.Fatalf("unexpected exec: %v", fake.actions)
+	}
+	result := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig).Text
+	if !strings.Contains(result, "exited with code 0") || !strings.Contains(result, "Maintenance mode disabled\n'''") {
+		t.Fatalf("unexpected result: %q", result)
+	}
+	if strings.Count(result, "```") != 2 {
+		t.Fatalf("output fence not escaped: %q", result)
+	}
+	var found bool
+	for _, e := range ctx.State.GetEvents() {
+		found = found || (e.Type == "action" && e.Message == `Exec "Maintenance off" on nextcloud: exit 0`)
+	}
+	if !found {
+		t.Fatalf("exec not recorded: %+v", ctx.State.GetEvents())
+	}
+
+	// Indexes come from the message, so a stale button must not run
+	// whatever the config now has in that position.
+	handleExecActionCallback(ctx, bot, 1, 1, "dexec_run_5_nextcloud")
+	handleExecActionCallback(ctx, bot, 1, 1, "dexec_run_0_db")
+	if len(fake.actions) != 1 {
+		t.Fatalf("unknown action ran: %v", fake.actions)
+	}
+	if text := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig).Text; !strings.Contains(text, "no longer configured") {
+		t.Fatalf("unexpected reply: %q", text)
+	}
+}
+
+func TestExecOutputBlock(t *testing.T) {
+	ctx := newTestAppContext()
+	if got := execOutputBlock(ctx, []byte(" \n"), false); !strings.Contains(got, "No output") {
+		t.Fatalf("empty output: %q", got)
+	}
+	long := strings.Repeat("x", execOutputLimit) + "END"
+	got := execOutputBlock(ctx, []byte(long), false)
+	if !strings.Contains(got, "xEND\n```") || strings.Contains(got, strings.Repeat("x", execOutputLimit)) || !strings.Contains(got, "truncated") {
+		t.Fatalf("output not cut from the start: %q", got[len(got)-60:])
+	}
+	// Cutting into a multi-byte character, or invalid bytes from the
+	// command, must still render valid UTF-8.
+	got = execOutputBlock(ctx, []byte(strings.Repeat("è", execOutputLimit)+"\xff"), false)
+	if !utf8.ValidString(got) {
+		t.Fatalf("invalid UTF-8 in the output block")
+	}
+}
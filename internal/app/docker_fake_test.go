@@ -0,0 +1,237 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"nasbot/internal/docker"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeDocker is an in-memory docker.Client recording the actions it receives.
+type fakeDocker struct {
+	containers []docker.Container
+	stats      []docker.Stats
+	details    map[string]*docker.Details
+	logs       map[string]string
+	events     []docker.Event
+	images     map[string]*docker.Image
+	disk       *docker.DiskUsage
+	exec       docker.ExecResult
+	err        error
+	actions    []string
+	// onPull and onRecreate let tests change what the daemon reports.
+	onPull     func(ref string) error
+	onRecreate func(name, image string) error
+}
+
+func (f *fakeDocker) Ping(ctx context.Context) error { return f.err }
+
+func (f *fakeDocker) List(ctx context.Context) ([]docker.Container, error) {
+	return f.containers, f.err
+}
+
+func (f *fakeDocker) Inspect(ctx context.Context, name string) (*docker.Details, error) {
+	if d, ok := f.details[name]; ok {
+		return d, nil
+	}
+	return nil, errors.New("no such container: " + name)
+}
+
+func (f *fakeDocker) Stats(ctx context.Context, names ...string) ([]docker.Stats, error) {
+	if len(names) == 0 {
+		return f.stats, f.err
+	}
+	var out []docker.Stats
+	for _, s := range f.stats {
+		for _, n := range names {
+			if s.Name == n {
+				out = append(out, s)
+			}
+		}
+	}
+	return out, f.err
+}
+
+func (f *fakeDocker) Logs(ctx context.Context, name string, tail int) ([]byte, error) {
+	return []byte(f.logs[name]), f.err
+}
+
+func (f *fakeDocker) action(verb, name string) error {
+	f.actions = append(f.actions, verb+" "+name)
+	return f.err
+}
+
+func (f *fakeDocker) Start(ctx context.Context, name string) error {
+	if d, ok := f.details[name]; ok && f.err == nil {
+		d.State.Running = true
+	}
+	return f.action("start", name)
+}
+
+func (f *fakeDocker) Stop(ctx context.Context, name string) error {
+	if d, ok := f.details[name]; ok && f.err == nil {
+		d.State.Running = false
+	}
+	return f.action("stop", name)
+}
+
+func (f *fakeDocker) Restart(ctx context.Context, name string) error {
+	return f.action("restart", name)
+}
+
+func (f *fakeDocker) Kill(ctx context.Context, name string) error {
+	return f.action("kill", name)
+}
+
+func (f *fakeDocker) Prune(ctx context.Context) (docker.PruneReport, error) {
+	f.actions = append(f.actions, "prune")
+	return docker.PruneReport{SpaceReclaimed: 1 << 30}, f.err
+}
+
+func (f *fakeDocker) Events(ctx context.Context) (<-chan docker.Event, <-chan error) {
+	events := make(chan docker.Event, len(f.events))
+	errs := make(chan error, 1)
+	for _, e := range f.events {
+		events <- e
+	}
+	close(events)
+	if f.err != nil {
+		errs <- f.err
+	}
+	close(errs)
+	return events, errs
+}
+
+func (f *fakeDocker) ImageInspect(ctx context.Context, ref string) (*docker.Image, error) {
+	if img, ok := f.images[ref]; ok {
+		return img, nil
+	}
+	return nil, errors.New("no such image: " + ref)
+}
+
+func (f *fakeDocker) Pull(ctx context.Context, ref string) error {
+	f.actions = append(f.actions, "pull "+ref)
+	if f.onPull != nil {
+		return f.onPull(ref)
+	}
+	return f.err
+}
+
+func (f *fakeDocker) Tag(ctx context.Context, source, target string) error {
+	f.actions = append(f.actions, "tag "+source+" "+target)
+	if img, ok := f.images[source]; ok && f.err == nil {
+		f.images[target] = img
+	}
+	return f.err
+}
+
+func (f *fakeDocker) Recreate(ctx context.Context, name, image string) error {
+	f.actions = append(f.actions, "recreate "+name+" "+image)
+	if f.onRecreate != nil {
+		return f.onRecreate(name, image)
+	}
+	return f.err
+}
+
+func (f *fakeDocker) DiskUsage(ctx context.Context) (*docker.DiskUsage, error) {
+	if f.disk == nil {
+		return &docker.DiskUsage{}, f.err
+	}
+	return f.disk, f.err
+}
+
+func (f *fakeDocker) RemoveImage(ctx context.Context, id string) error {
+	return f.action("rmi", id)
+}
+
+func (f *fakeDocker) RemoveVolume(ctx context.Context, name string) error {
+	return f.action("rmv", name)
+}
+
+func (f *fakeDocker) PruneImages(ctx context.Context, all bool) (uint64, error) {
+	f.actions = append(f.actions, fmt.Sprintf("prune images all=%v", all))
+	return 100 << 20, f.err
+}
+
+func (f *fakeDocker) PruneVolumes(ctx context.Context) (uint64, error) {
+	f.actions = append(f.actions, "prune volumes")
+	return 200 << 20, f.err
+}
+
+func (f *fakeDocker) PruneBuildCache(ctx context.Context) (uint64, error) {
+	f.actions = append(f.actions, "prune build")
+	return 300 << 20, f.err
+}
+
+func (f *fakeDocker) Exec(ctx context.Context, name string, cmd []string, user string) (docker.ExecResult, error) {
+	f.actions = append(f.actions, fmt.Sprintf("exec %s %q user=%s", name, cmd, user))
+	return f.exec, f.err
+}
+
+func TestContainerListFromClient(t *testing.T) {
+	defer setDockerClient(&fakeDocker{containers: []docker.Container{
+		{ID: "abc", Name: "web", Image: "nginx", State: "running", Status: "Up 1 hour"},
+		{ID: "def", Name: "db", State: "exited"},
+	}})()
+
+	list, err := getContainerListWithError()
+	if err != nil || len(list) != 2 || !list[0].Running || list[1].Running || list[0].Image != "nginx" {
+		t.Fatalf("getContainerListWithError = %+v, %v", list, err)
+	}
+}
+
+func TestExecuteContainerActionUsesClient(t *testing.T) {
+	fake := &fakeDocker{}
+	defer setDockerClient(fake)()
+	ctx := newTestAppContext()
+	bot := &fakeBot{}
+
+	executeContainerAction(ctx, bot, 1, 10, "web", "restart")
+	if len(fake.actions) != 1 || fake.actions[0] != "restart web" {
+		t.Fatalf("actions = %v", fake.actions)
+	}
+	last := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig)
+	if !strings.Contains(last.Text, "web") || strings.Contains(last.Text, "❌") {
+		t.Fatalf("unexpected result: %q", last.Text)
+	}
+
+	fake.err = errors.New("No such container: web")
+	executeContainerAction(ctx, bot, 1, 10, "web", "stop")
+	last = bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig)
+	if !strings.Contains(last.Text, "No such container") {
+		t.Fatalf("error not reported: %q", last.Text)
+	}
+}
+
+func TestDockerStatsText(t *testing.T) {
+	defer setDockerClient(&fakeDocker{stats: []docker.Stats{
+		{Name: "web", CPUPercent: 1.5, MemUsage: 512 << 20, MemPercent: 12.5},
+	}})()
+
+	text := getDockerStatsText(newTestAppContext())
+	if !strings.Contains(text, "web") || !strings.Contains(text, "1.5%") || !strings.Contains(text, "512M") {
+		t.Fatalf("unexpected stats text: %q", text)
+	}
+}
+
+func TestHandleCriticalRAMRestartsHeaviest(t *testing.T) {
+	fake := &fakeDocker{stats: []docker.Stats{
+		{Name: "small", MemPercent: 25},
+		{Name: "big", MemPercent: 60},
+		{Name: "tiny", MemPercent: 5},
+	}}
+	defer setDockerClient(fake)()
+	ctx := newTestAppContext()
+	ctx.Config.Docker.AutoRestartOnRAMCritical = DockerAutoRestartConfig{Enabled: true, MaxRestartsPerHour: 3, RAMThreshold: 90}
+	t.Setenv("NASBOT_STATE_FILE", t.TempDir()+"/state.json")
+
+	handleCriticalRAM(ctx, nil, Stats{RAM: 95})
+	if len(fake.actions) != 1 || fake.actions[0] != "restart big" {
+		t.Fatalf("actions = %v", fake.actions)
+	}
+}
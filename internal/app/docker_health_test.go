@@ -0,0 +1,91 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"nasbot/internal/docker"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestContainerInfosHealth(t *testing.T) {
+	infos := containerInfos([]docker.Container{
+		{Name: "web", State: "running", Status: "Up 2 hours (unhealthy)"},
+		{Name: "db", State: "running", Status: "Up 2 hours"},
+	})
+	if infos[0].Health != "unhealthy" || infos[1].Health != "" {
+		t.Fatalf("unexpected health: %+v", infos)
+	}
+}
+
+func TestPollerHealthTransitions(t *testing.T) {
+	ctx := newTestAppContext()
+	bot := &fakeBot{}
+	ctx.Docker.LastStates = map[string]bool{"x": true}
+
+	for _, health := range []string{"healthy", "unhealthy", "unhealthy", "healthy"} {
+		ctx.Docker.Cache.Containers = []ContainerInfo{{Name: "x", Running: true, Health: health}}
+		checkContainerStates(ctx, bot)
+	}
+	texts := sentTexts(bot)
+	if len(texts) != 2 || !strings.Contains(texts[0], "UNHEALTHY") || !strings.Contains(texts[1], "HEALTHY") {
+		t.Fatalf("expected one unhealthy and one recovery alert, got %q", texts)
+	}
+}
+
+func TestDockerMenuShowsHealth(t *testing.T) {
+	ctx := newTestAppContext()
+	ctx.Docker.Cache.Containers = []ContainerInfo{{Name: "web", Running: true, Status: "Up 2 hours (unhealthy)", Health: "unhealthy"}}
+
+	text, kb := getDockerMenuText(ctx)
+	if !strings.Contains(text, "unhealthy") || !strings.HasPrefix(kb.InlineKeyboard[0][0].Text, "🩺") {
+		t.Fatalf("menu does not flag the unhealthy container: %q", text)
+	}
+}
+
+func TestContainerDetailsShowProbe(t *testing.T) {
+	d := &docker.Details{}
+	d.State.Health = &docker.Health{Status: "unhealthy", FailingStreak: 4, Log: []docker.HealthProbe{
+		{ExitCode: 1, Output: "curl: (7) Failed to connect\n"},
+	}}
+	defer setDockerClient(&fakeDocker{details: map[string]*docker.Details{"web": d}})()
+	ctx := newTestAppContext()
+	ctx.Docker.Cache.Containers = []ContainerInfo{{Name: "web", Running: true, Status: "Up 2 hours (unhealthy)", Health: "unhealthy"}}
+	bot := &fakeBot{}
+
+	showContainerActions(ctx, bot, 1, 10, "web")
+	text := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig).Text
+	if !strings.Contains(text, "unhealthy") || !strings.Contains(text, "`4`") || !strings.Contains(text, "Failed to connect") {
+		t.Fatalf("detail view lacks health details: %q", text)
+	}
+}
+
+func TestUnhealthyCriticalContainerRestarted(t *testing.T) {
+	fake := &fakeDocker{}
+	defer setDockerClient(fake)()
+	t.Setenv("NASBOT_STATE_FILE", t.TempDir()+"/state.json")
+	ctx := newTestAppContext()
+	ctx.Config.CriticalContainers = []string{"db"}
+	ctx.Config.Docker.AutoRestartUnhealthy = true
+	ctx.Config.Docker.AutoRestartOnRAMCritical.MaxRestartsPerHour = 2
+	bot := &fakeBot{}
+
+	for range 3 {
+		ctx.Docker.Cache = DockerCache{Containers: []ContainerInfo{{Name: "db", Running: true, Health: "unhealthy"}}, LastUpdate: time.Now()}
+		checkCriticalContainers(ctx, bot)
+	}
+	if len(fake.actions) != 2 || fake.actions[0] != "restart db" {
+		t.Fatalf("expected two rate-limited restarts, got %v", fake.actions)
+	}
+	var alerted bool
+	for _, text := range sentTexts(bot) {
+		if strings.Contains(text, "Critical Container") && strings.Contains(text, "unhealthy") {
+			alerted = true
+		}
+	}
+	if !alerted {
+		t.Fatalf("no critical alert for the unhealthy container: %q", sentTexts(bot))
+	}
+}
@@ -0,0 +1,239 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nasbot/internal/docker"
+	"nasbot/internal/format"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// inspectPageSize keeps a page well below Telegram's 4096 characters.
+const inspectPageSize = 3000
+
+// secretKeyMarkers are the parts of an environment variable or label name
+// whose value is never shown.
+var secretKeyMarkers = []string{"PASSWORD", "PASSWD", "TOKEN", "KEY", "SECRET"}
+
+const redacted = "••••••"
+
+// redactValue hides value when key looks like a secret, and the password
+// of a URL such as postgres://user:pass@db/app in any other value.
+func redactValue(key, value string) string {
+	upper := strings.ToUpper(key)
+	for _, m := range secretKeyMarkers {
+		if strings.Contains(upper, m) {
+			return redacted
+		}
+	}
+	if strings.Contains(value, "://") {
+		if u, err := url.Parse(value); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				u.User = url.UserPassword(u.User.Username(), redacted)
+				return u.String()
+			}
+		}
+	}
+	return value
+}
+
+// inspectCode wraps s, shortened to fit a page, in backticks.
+func inspectCode(s string) string {
+	return "`" + strings.ReplaceAll(truncate(s, 300), "`", "'") + "`"
+}
+
+// inspectSections renders the details as titled blocks of lines.
+func inspectSections(ctx *AppContext, d *docker.Details) [][]string {
+	restart := d.HostConfig.RestartPolicy.Name
+	if restart == "" {
+		restart = "no"
+	}
+	if restart == "on-failure" && d.HostConfig.RestartPolicy.MaximumRetryCount > 0 {
+		restart += fmt.Sprintf(":%d", d.HostConfig.RestartPolicy.MaximumRetryCount)
+	}
+	general := []string{
+		fmt.Sprintf(ctx.Tr("inspect_image"), inspectCode(d.Config.Image)),
+		fmt.Sprintf(ctx.Tr("inspect_created"), d.Created.Local().Format("2006-01-02 15:04")),
+		fmt.Sprintf(ctx.Tr("inspect_restart"), inspectCode(restart), d.RestartCount),
+	}
+	sections := [][]string{general}
+
+	var ports []string
+	for _, port := range sortedKeys(d.NetworkSettings.Ports) {
+		bindings := d.NetworkSettings.Ports[port]
+		if len(bindings) == 0 {
+			ports = append(ports, fmt.Sprintf(ctx.Tr("inspect_port_internal"), inspectCode(port)))
+			continue
+		}
+		for _, b := range bindings {
+			host := b.HostPort
+			if b.HostIP != "" && b.HostIP != "0.0.0.0" && b.HostIP != "::" {
+				host = b.HostIP + ":" + host
+			}
+			ports = append(ports, fmt.Sprintf("%s → %s", inspectCode(host), inspectCode(port)))
+		}
+	}
+	sections = append(sections, inspectSection(ctx, "inspect_ports", ports))
+
+	var mounts []string
+	for _, m := range d.Mounts {
+		source := m.Source
+		if m.Type == "volume" && m.Name != "" {
+			source = m.Name
+		}
+		mode := "rw"
+		if !m.RW {
+			mode = "ro"
+		}
+		mounts = append(mounts, fmt.Sprintf("%s → %s (%s, %s)", inspectCode(source), inspectCode(m.Destination), m.Type, mode))
+	}
+	sections = append(sections, inspectSection(ctx, "inspect_mounts", mounts))
+
+	var networks []string
+	if len(d.NetworkSettings.Networks) == 0 && d.HostConfig.NetworkMode != "" {
+		networks = append(networks, inspectCode(d.HostConfig.NetworkMode))
+	}
+	for _, name := range sortedKeys(d.NetworkSettings.Networks) {
+		line := inspectCode(name)
+		if ip := d.NetworkSettings.Networks[name].IPAddress; ip != "" {
+			line += " " + ip
+		}
+		networks = append(networks, line)
+	}
+	sections = append(sections, inspectSection(ctx, "inspect_networks", networks))
+
+	var limits []string
+	if d.HostConfig.Memory > 0 {
+		limits = append(limits, fmt.Sprintf(ctx.Tr("inspect_memory"), format.FormatRAM(uint64(d.HostConfig.Memory>>20))))
+	}
+	if d.HostConfig.MemoryReservation > 0 {
+		limits = append(limits, fmt.Sprintf(ctx.Tr("inspect_memory_reservation"), format.FormatRAM(uint64(d.HostConfig.MemoryReservation>>20))))
+	}
+	if d.HostConfig.NanoCpus > 0 {
+		limits = append(limits, fmt.Sprintf(ctx.Tr("inspect_cpus"), strconv.FormatFloat(float64(d.HostConfig.NanoCpus)/1e9, 'f', -1, 64)))
+	}
+	if d.HostConfig.CpusetCpus != "" {
+		limits = append(limits, fmt.Sprintf(ctx.Tr("inspect_cpuset"), inspectCode(d.HostConfig.CpusetCpus)))
+	}
+	if d.HostConfig.CPUShares > 0 {
+		limits = append(limits, fmt.Sprintf(ctx.Tr("inspect_cpu_shares"), d.HostConfig.CPUShares))
+	}
+	if d.HostConfig.PidsLimit != nil && *d.HostConfig.PidsLimit > 0 {
+		limits = append(limits, fmt.Sprintf(ctx.Tr("inspect_pids"), *d.HostConfig.PidsLimit))
+	}
+	if len(limits) == 0 {
+		limits = append(limits, ctx.Tr("inspect_unlimited"))
+	}
+	sections = append(sections, inspectSection(ctx, "inspect_limits", limits))
+
+	var labels []string
+	for _, k := range sortedKeys(d.Config.Labels) {
+		labels = append(labels, inspectCode(k+"="+redactValue(k, d.Config.Labels[k])))
+	}
+	sections = append(sections, inspectSection(ctx, "inspect_labels", labels))
+
+	var env []string
+	for _, kv := range d.Config.Env {
+		k, v, _ := strings.Cut(kv, "=")
+		env = append(env, inspectCode(k+"="+redactValue(k, v)))
+	}
+	return append(sections, inspectSection(ctx, "inspect_env", env))
+}
+
+// inspectSection puts a title above lines, or "none" below it.
+func inspectSection(ctx *AppContext, titleKey string, lines []string) []string {
+	if len(lines) == 0 {
+		lines = []string{ctx.Tr("inspect_none")}
+	}
+	return append([]string{ctx.Tr(titleKey)}, lines...)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// inspectPages packs sections into pages of at most size characters,
+// starting a new page rather than splitting a section that fits on one.
+func inspectPages(sections [][]string, size int) []string {
+	var pages []string
+	var page strings.Builder
+	flush := func() {
+		if page.Len() > 0 {
+			pages = append(pages, strings.TrimRight(page.String(), "\n"))
+			page.Reset()
+		}
+	}
+	for _, sec := range sections {
+		block := strings.Join(sec, "\n") + "\n\n"
+		if page.Len()+len(block) > size && len(block) <= size {
+			flush()
+		}
+		for i, line := range sec {
+			line += "\n"
+			if i == len(sec)-1 {
+				line += "\n"
+			}
+			if page.Len()+len(line) > size {
+				flush()
+			}
+			page.WriteString(line)
+		}
+	}
+	flush()
+	return pages
+}
+
+// handleContainerInspectCallback handles dinspect_<page>_<container>.
+func handleContainerInspectCallback(ctx *AppContext, bot BotAPI, chatID int64, msgID int, data string) {
+	pageStr, name, ok := strings.Cut(strings.TrimPrefix(data, "dinspect_"), "_")
+	page, err := strconv.Atoi(pageStr)
+	if !ok || err != nil {
+		return
+	}
+	back := tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("back"), "container_select_"+name)
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	d, err := dockerClient.Inspect(timeoutCtx, name)
+	cancel()
+	if err != nil {
+		slog.Error("Container inspect failed", "container", name, "err", err)
+		kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(back))
+		editMessage(bot, chatID, msgID, fmt.Sprintf(ctx.Tr("inspect_err"), name, err), &kb)
+		return
+	}
+
+	pages := inspectPages(inspectSections(ctx, d), inspectPageSize)
+	page = max(0, min(page, len(pages)-1))
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("◀️", fmt.Sprintf("dinspect_%d_%s", page-1, name)))
+	}
+	if page < len(pages)-1 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("▶️", fmt.Sprintf("dinspect_%d_%s", page+1, name)))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(back))
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	text := fmt.Sprintf(ctx.Tr("inspect_title"), name)
+	if len(pages) > 1 {
+		text += fmt.Sprintf(" (%d/%d)", page+1, len(pages))
+	}
+	editMessage(bot, chatID, msgID, text+"\n\n"+pages[page], &kb)
+}
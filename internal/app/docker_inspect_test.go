@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"nasbot/internal/docker"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestRedactValue(t *testing.T) {
+	cases := []struct{ key, value, want string }{
+		{"POSTGRES_PASSWORD", "hunter2", redacted},
+		{"api_token", "abc", redacted},
+		{"SECRET_KEY_BASE", "abc", redacted},
+		{"traefik.http.middlewares.auth.basicauth.users", "admin:$apr1$x", "admin:$apr1$x"},
+		{"DATABASE_URL", "postgres://app:hunter2@db:5432/app", "postgres://app:%E2%80%A2%E2%80%A2%E2%80%A2%E2%80%A2%E2%80%A2%E2%80%A2@db:5432/app"},
+		{"UPSTREAM", "http://proxy:8080", "http://proxy:8080"},
+		{"TZ", "Europe/Rome", "Europe/Rome"},
+	}
+	for _, c := range cases {
+		if got := redactValue(c.key, c.value); got != c.want {
+			t.Errorf("redactValue(%q, %q) = %q, want %q", c.key, c.value, got, c.want)
+		}
+	}
+}
+
+func TestContainerInspectView(t *testing.T) {
+	d := containerDetails("nextcloud", "nextcloud:29", "sha256:nc", true, "")
+	d.HostConfig.RestartPolicy.Name = "unless-stopped"
+	d.HostConfig.Memory = 2 << 30
+	d.HostConfig.NanoCpus = 1.5e9
+	d.Mounts = []docker.Mount{{Type: "volume", Name: "nc_data", Source: "/var/lib/docker/volumes/nc_data/_data", Destination: "/var/www/html", RW: true}}
+	d.NetworkSettings.Ports = map[string][]docker.PortBinding{"80/tcp": {{HostIP: "127.0.0.1", HostPort: "8080"}}, "9000/tcp": nil}
+	d.NetworkSettings.Networks = map[string]docker.Endpoint{"cloud": {IPAddress: "172.20.0.3"}}
+	d.Config.Labels = map[string]string{"com.docker.compose.project": "cloud"}
+	d.Config.Env = []string{"MYSQL_PASSWORD=hunter2", "NEXTCLOUD_TRUSTED_DOMAINS=cloud.example.com"}
+	for i := 0; i < 80; i++ {
+		d.Config.Env = append(d.Config.Env, fmt.Sprintf("PLUGIN_OPTION_%03d=some-long-enough-value-%d", i, i))
+	}
+	defer setDockerClient(&fakeDocker{details: map[string]*docker.Details{"nextcloud": d}})()
+	ctx := newTestAppContext()
+	bot := &fakeBot{}
+
+	handleContainerInspectCallback(ctx, bot, 1, 1, "dinspect_0_nextcloud")
+	first := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig)
+	for _, want := range []string{"(1/2)", "`127.0.0.1:8080` → `80/tcp`", "`9000/tcp` (not published)",
+		"`nc_data` → `/var/www/html` (volume, rw)", "`cloud` 172.20.0.3", "Memory: 2.0G", "CPUs: 1.5",
+		"`unless-stopped` · 0 restarts", "`MYSQL_PASSWORD=" + redacted + "`", "`NEXTCLOUD_TRUSTED_DOMAINS=cloud.example.com`"} {
+		if !strings.Contains(first.Text, want) {
+			t.Errorf("page 1 lacks %q", want)
+		}
+	}
+	if strings.Contains(first.Text, "hunter2") || len(first.Text) > 4096 {
+		t.Fatalf("secret shown or page too long (%d)", len(first.Text))
+	}
+	if data := keyboardData(first.ReplyMarkup); fmt.Sprint(data) != "[dinspect_1_nextcloud container_select_nextcloud]" {
+		t.Fatalf("unexpected buttons: %v", data)
+	}
+
+	handleContainerInspectCallback(ctx, bot, 1, 1, "dinspect_1_nextcloud")
+	second := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig)
+	if !strings.Contains(second.Text, "(2/2)") || !strings.Contains(second.Text, "PLUGIN_OPTION_079") {
+		t.Fatalf("unexpected last page: %q", second.Text)
+	}
+	if data := keyboardData(second.ReplyMarkup); fmt.Sprint(data) != "[dinspect_0_nextcloud container_select_nextcloud]" {
+		t.Fatalf("unexpected buttons: %v", data)
+	}
+
+	handleContainerInspectCallback(ctx, bot, 1, 1, "dinspect_0_gone")
+	if text := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig).Text; !strings.Contains(text, "Could not inspect `gone`") {
+		t.Fatalf("unexpected error view: %q", text)
+	}
+}
+
+func TestInspectPagesKeepSections(t *testing.T) {
+	sections := [][]string{
+		{"*A*", strings.Repeat("a", 40)},
+		{"*B*", strings.Repeat("b", 40), strings.Repeat("b", 40)},
+	}
+	pages := inspectPages(sections, 100)
+	if len(pages) != 2 || !strings.HasPrefix(pages[1], "*B*") {
+		t.Fatalf("section split across pages: %q", pages)
+	}
+	for _, p := range inspectPages([][]string{{"*Env*", strings.Repeat("x", 60), strings.Repeat("y", 60)}}, 100) {
+		if len(p) > 100 {
+			t.Fatalf("page over the limit: %q", p)
+		}
+	}
+}
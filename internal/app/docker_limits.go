@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"nasbot/internal/docker"
+	"nasbot/internal/format"
+)
+
+// ContainerLimitsMonitor checks containers against docker.container_limits.
+// Its alerts are notified at warning level too, so a leaking container is
+// reported before the host runs out of memory.
+type ContainerLimitsMonitor struct{}
+
+func (m *ContainerLimitsMonitor) Check(ctx *AppContext, s *Stats) []MonitorAlert {
+	limits := ctx.Config.Docker.ContainerLimits
+	if len(limits) == 0 {
+		return nil
+	}
+
+	var running []string
+	var checked []ContainerInfo
+	for _, c := range getCachedContainerList(ctx) {
+		if _, ok := containerLimit(limits, c.Name); !ok {
+			continue
+		}
+		checked = append(checked, c)
+		if c.Running {
+			running = append(running, c.Name)
+		}
+	}
+	if len(checked) == 0 {
+		return nil
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	usage := make(map[string]docker.Stats)
+	if len(running) > 0 {
+		stats, err := dockerClient.Stats(timeoutCtx, running...)
+		if err != nil {
+			slog.Warn("Container limits: stats failed", "err", err)
+		}
+		for _, st := range stats {
+			usage[st.Name] = st
+		}
+	}
+
+	var alerts []MonitorAlert
+	for _, c := range checked {
+		lim, _ := containerLimit(limits, c.Name)
+		st, ok := usage[c.Name]
+		if ok || !c.Running {
+			alerts = append(alerts, containerUsageAlerts(c.Name, lim, st)...)
+		}
+		if lim.MaxRestartsPerHour > 0 {
+			if n, ok := restartsLastHour(timeoutCtx, ctx, c.Name); ok {
+				alerts = append(alerts, limitAlert("container_restarts:"+c.Name, c.Name+" restarts", float64(n), float64(lim.MaxRestartsPerHour),
+					fmt.Sprintf("🔁 `%s` restarted `%d` times in the last hour (limit %d)", c.Name, n, lim.MaxRestartsPerHour)))
+			}
+		}
+	}
+	return alerts
+}
+
+// containerLimit returns the limits for name, falling back to "*".
+func containerLimit(limits map[string]ContainerLimitConfig, name string) (ContainerLimitConfig, bool) {
+	if lim, ok := limits[name]; ok {
+		return lim, true
+	}
+	lim, ok := limits["*"]
+	return lim, ok
+}
+
+// containerUsageAlerts compares a stats sample with the CPU and memory
+// limits; a stopped container is observed with zero usage so its alerts
+// resolve.
+func containerUsageAlerts(name string, lim ContainerLimitConfig, st docker.Stats) []MonitorAlert {
+	var alerts []MonitorAlert
+	if lim.CPUPercent > 0 {
+		alerts = append(alerts, limitAlert("container_cpu:"+name, name+" CPU", st.CPUPercent, lim.CPUPercent,
+			fmt.Sprintf("🧠 `%s` CPU `%.0f%%` (limit %.0f%%)", name, st.CPUPercent, lim.CPUPercent)))
+	}
+	if lim.MemPercent > 0 {
+		alerts = append(alerts, limitAlert("container_mem:"+name, name+" memory", st.MemPercent, lim.MemPercent,
+			fmt.Sprintf("💾 `%s` memory `%.1f%%` of %s (limit %.0f%%)", name, st.MemPercent, format.FormatRAM(st.MemLimit>>20), lim.MemPercent)))
+	}
+	if lim.MemMB > 0 {
+		mb := float64(st.MemUsage >> 20)
+		alerts = append(alerts, limitAlert("container_mem_mb:"+name, name+" memory", mb, lim.MemMB,
+			fmt.Sprintf("💾 `%s` memory `%s` (limit %s)", name, format.FormatRAM(st.MemUsage>>20), format.FormatRAM(uint64(lim.MemMB)))))
+	}
+	return alerts
+}
+
+// overLimit reports whether a stats sample exceeds a CPU or memory limit.
+func overLimit(lim ContainerLimitConfig, st docker.Stats) bool {
+	return (lim.CPUPercent > 0 && st.CPUPercent >= lim.CPUPercent) ||
+		(lim.MemPercent > 0 && st.MemPercent >= lim.MemPercent) ||
+		(lim.MemMB > 0 && float64(st.MemUsage>>20) >= lim.MemMB)
+}
+
+// limitAlert is a warning-level reading against a single limit.
+func limitAlert(id, label string, value, limit float64, message string) MonitorAlert {
+	a := MonitorAlert{ID: id, Label: label, Value: value, NotifyWarning: true}
+	a.Level, a.Threshold = thresholdLevel(value, limit, 0)
+	// Set at every level: a reading held above OK by hysteresis still
+	// sends reminders.
+	a.Message = message
+	return a
+}
+
+// restartsLastHour records the container's RestartCount and returns how
+// much it grew over the last hour. The first reading only sets a baseline.
+func restartsLastHour(opCtx context.Context, ctx *AppContext, name string) (int, bool) {
+	d, err := dockerClient.Inspect(opCtx, name)
+	if err != nil {
+		return 0, false
+	}
+	ctx.Docker.Mu.Lock()
+	samples := recordRestartCount(ctx.Docker, name, d.RestartCount, time.Now())
+	ctx.Docker.Mu.Unlock()
+	return d.RestartCount - samples[0].Count, true
+}
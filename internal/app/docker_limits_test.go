@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"nasbot/internal/docker"
+)
+
+func TestContainerLimitsMonitor(t *testing.T) {
+	fake := &fakeDocker{
+		stats: []docker.Stats{
+			{Name: "web", CPUPercent: 150, MemUsage: 200 << 20, MemPercent: 10},
+			{Name: "db", CPUPercent: 5, MemUsage: 600 << 20, MemPercent: 60, MemLimit: 1 << 30},
+		},
+		details: map[string]*docker.Details{"web": {Name: "web", RestartCount: 1}},
+	}
+	defer setDockerClient(fake)()
+	ctx := newTestAppContext()
+	ctx.Docker.Cache.Containers = []ContainerInfo{{Name: "web", Running: true}, {Name: "db", Running: true}}
+	ctx.Config.Docker.ContainerLimits = map[string]ContainerLimitConfig{
+		"*":   {MemPercent: 50},
+		"web": {CPUPercent: 100, MemMB: 512, MaxRestartsPerHour: 2},
+	}
+	ctx.Config.Alerts.Defaults.NotifyResolved = true
+	bot := &fakeBot{}
+	monitor := &ContainerLimitsMonitor{}
+
+	processMonitorAlerts(ctx, bot, monitor.Check(ctx, nil))
+	texts := sentTexts(bot)
+	if len(texts) != 1 || !strings.Contains(texts[0], "`web` CPU `150%`") || !strings.Contains(texts[0], "`db` memory `60.0%`") {
+		t.Fatalf("unexpected alerts: %q", texts)
+	}
+	if strings.Contains(texts[0], "`web` memory") {
+		t.Fatalf("web has its own limits and is under mem_mb: %q", texts[0])
+	}
+
+	// Restarts are counted from the first reading on.
+	fake.details["web"].RestartCount = 4
+	fake.stats[0].CPUPercent = 20
+	processMonitorAlerts(ctx, bot, monitor.Check(ctx, nil))
+	texts = sentTexts(bot)
+	if !strings.Contains(texts[1], "restarted `3` times") {
+		t.Fatalf("restart limit not reported: %q", texts)
+	}
+	if !strings.Contains(strings.Join(texts[1:], "\n"), "web CPU") {
+		t.Fatalf("CPU alert not resolved: %q", texts)
+	}
+
+	// Hysteresis can hold an alert below its limit; its reminders need text.
+	if a := limitAlert("x", "x", 1, 2, "msg"); a.Message != "msg" {
+		t.Fatalf("message dropped below the limit: %+v", a)
+	}
+}
+
+func TestRestartsLastHourWindow(t *testing.T) {
+	d := &docker.Details{Name: "app", RestartCount: 10}
+	defer setDockerClient(&fakeDocker{details: map[string]*docker.Details{"app": d}})()
+	ctx := newTestAppContext()
+	ctx.Docker.RestartCounts = map[string][]RestartSample{"app": {
+		{At: time.Now().Add(-3 * time.Hour), Count: 2},
+		{At: time.Now().Add(-90 * time.Minute), Count: 7},
+		{At: time.Now().Add(-10 * time.Minute), Count: 9},
+	}}
+
+	if n, ok := restartsLastHour(context.Background(), ctx, "app"); !ok || n != 3 {
+		t.Fatalf("restartsLastHour = %d, %v", n, ok)
+	}
+	if len(ctx.Docker.RestartCounts["app"]) != 3 {
+		t.Fatalf("old samples not pruned: %+v", ctx.Docker.RestartCounts["app"])
+	}
+
+	// A recreated container starts counting again.
+	d.RestartCount = 0
+	if n, _ := restartsLastHour(context.Background(), ctx, "app"); n != 0 {
+		t.Fatalf("count after recreate = %d", n)
+	}
+}
@@ -0,0 +1,227 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"nasbot/internal/format"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	logGrowthWindow  = time.Hour
+	logGrowthMinSpan = 10 * time.Minute
+	// A container whose log could not be found is looked up again after
+	// this long, not on every cycle.
+	logPathRetry = 30 * time.Minute
+)
+
+// containerLog is the json-file log of one container.
+type containerLog struct {
+	Name string
+	Path string
+	Size uint64
+}
+
+// containerLogSizes returns the log file of every container, largest
+// first. Containers whose log driver keeps no file are left out.
+func containerLogSizes(ctx context.Context, containers []ContainerInfo) []containerLog {
+	var logs []containerLog
+	for _, c := range containers {
+		path, err := containerLogPath(ctx, c.Name)
+		if err != nil {
+			continue
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		logs = append(logs, containerLog{Name: c.Name, Path: path, Size: uint64(fi.Size())})
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].Size > logs[j].Size })
+	return logs
+}
+
+func containerLogPath(ctx context.Context, name string) (string, error) {
+	d, err := dockerClient.Inspect(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if d.LogPath == "" {
+		return "", fmt.Errorf("%s has no log file (log driver %q)", name, d.HostConfig.LogConfig.Type)
+	}
+	return d.LogPath, nil
+}
+
+type logSample struct {
+	At   time.Time
+	Size uint64
+}
+
+// ContainerLogsMonitor alerts when a container log outgrows
+// docker.logs.max_size_mb or grows faster than max_growth_mb_per_hour.
+// Log paths are looked up once per container, so a cycle only stats files.
+type ContainerLogsMonitor struct {
+	paths   map[string]string
+	samples map[string][]logSample
+	missing map[string]time.Time // failed lookups, retried after logPathRetry
+}
+
+func (m *ContainerLogsMonitor) Check(ctx *AppContext, s *Stats) []MonitorAlert {
+	cfg := ctx.Config.Docker.Logs
+	if !cfg.Enabled || (cfg.MaxSizeMB <= 0 && cfg.MaxGrowthMBPerHour <= 0) {
+		return nil
+	}
+	if m.paths == nil {
+		m.paths = make(map[string]string)
+		m.samples = make(map[string][]logSample)
+		m.missing = make(map[string]time.Time)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	now := time.Now()
+	seen := make(map[string]bool)
+	var alerts []MonitorAlert
+	for _, c := range getCachedContainerList(ctx) {
+		seen[c.Name] = true
+		size, ok := m.logSize(timeoutCtx, c.Name, now)
+		if !ok {
+			continue
+		}
+		actions := []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData("✂️ "+truncate(c.Name, 20), "dlog_ask_"+c.Name),
+		}
+		if cfg.MaxSizeMB > 0 {
+			a := limitAlert("container_log:"+c.Name, c.Name+" log", float64(size>>20), cfg.MaxSizeMB,
+				fmt.Sprintf("📜 `%s` log is `%s` (limit %s)", c.Name, sizeText(size), format.FormatRAM(uint64(cfg.MaxSizeMB))))
+			a.Actions = actions
+			alerts = append(alerts, a)
+		}
+		rate := m.growth(c.Name, now, size)
+		if cfg.MaxGrowthMBPerHour > 0 {
+			a := limitAlert("container_log_rate:"+c.Name, c.Name+" log growth", rate, cfg.MaxGrowthMBPerHour,
+				fmt.Sprintf("📜 `%s` log grows `%.0f MB/h` (limit %.0f MB/h)", c.Name, rate, cfg.MaxGrowthMBPerHour))
+			a.Actions = actions
+			alerts = append(alerts, a)
+		}
+	}
+	for name := range m.paths {
+		if !seen[name] {
+			delete(m.paths, name)
+			delete(m.samples, name)
+		}
+	}
+	for name := range m.missing {
+		if !seen[name] {
+			delete(m.missing, name)
+		}
+	}
+	return alerts
+}
+
+// logSize stats the container's log, looking its path up again when the
+// file is gone, e.g. after the container was recreated. A container
+// without a readable log, such as one using the journald driver, is only
+// inspected again after logPathRetry.
+func (m *ContainerLogsMonitor) logSize(ctx context.Context, name string, now time.Time) (uint64, bool) {
+	if path, ok := m.paths[name]; ok {
+		if fi, err := os.Stat(path); err == nil {
+			return uint64(fi.Size()), true
+		}
+		delete(m.paths, name)
+	}
+	if failed, ok := m.missing[name]; ok && now.Sub(failed) < logPathRetry {
+		return 0, false
+	}
+	path, err := containerLogPath(ctx, name)
+	if err != nil {
+		m.missing[name] = now
+		return 0, false
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		m.missing[name] = now
+		return 0, false
+	}
+	delete(m.missing, name)
+	m.paths[name] = path
+	return uint64(fi.Size()), true
+}
+
+// growth records a size reading and returns the growth in MB per hour
+// over the last logGrowthWindow, zero until logGrowthMinSpan is covered. A
+// smaller file means it was rotated or truncated, which starts over.
+func (m *ContainerLogsMonitor) growth(name string, now time.Time, size uint64) float64 {
+	samples := m.samples[name]
+	if n := len(samples); n > 0 && size < samples[n-1].Size {
+		samples = nil
+	}
+	samples = append(samples, logSample{At: now, Size: size})
+	cut := 0
+	for cut+1 < len(samples) && now.Sub(samples[cut+1].At) >= logGrowthWindow {
+		cut++
+	}
+	samples = samples[cut:]
+	m.samples[name] = samples
+
+	span := now.Sub(samples[0].At)
+	if span < logGrowthMinSpan {
+		return 0
+	}
+	mb := float64(size-samples[0].Size) / (1 << 20)
+	return mb / span.Hours()
+}
+
+// handleContainerLogCallback handles dlog_ask_<name> and dlog_do_<name>,
+// the confirmed truncation of a container log.
+func handleContainerLogCallback(ctx *AppContext, bot BotAPI, chatID int64, msgID int, data string) {
+	kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("💽 "+ctx.Tr("ddisk_back"), "ddisk_refresh"),
+	))
+	if name, ok := strings.CutPrefix(data, "dlog_ask_"); ok {
+		confirm := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("yes"), "dlog_do_"+name),
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("no"), "ddisk_refresh"),
+		))
+		editMessage(bot, chatID, msgID, fmt.Sprintf(ctx.Tr("dlog_confirm"), name), &confirm)
+		return
+	}
+	name, ok := strings.CutPrefix(data, "dlog_do_")
+	if !ok {
+		return
+	}
+	freed, err := truncateContainerLog(name)
+	if err != nil {
+		slog.Error("Log truncation failed", "container", name, "err", err)
+		editMessage(bot, chatID, msgID, fmt.Sprintf(ctx.Tr("dlog_err"), name, err), &kb)
+		return
+	}
+	ctx.State.AddEvent("action", fmt.Sprintf("Truncated log of %s (%s freed)", name, sizeText(freed)))
+	editMessage(bot, chatID, msgID, fmt.Sprintf(ctx.Tr("dlog_ok"), name, sizeText(freed)), &kb)
+}
+
+// truncateContainerLog empties the container's json-file log in place.
+// The daemon appends to the file, so it keeps logging into it.
+func truncateContainerLog(name string) (uint64, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	path, err := containerLogPath(timeoutCtx, name)
+	if err != nil {
+		return 0, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Truncate(path, 0); err != nil {
+		return 0, err
+	}
+	return uint64(fi.Size()), nil
+}
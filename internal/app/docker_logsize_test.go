@@ -0,0 +1,114 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nasbot/internal/docker"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestContainerLogsMonitor(t *testing.T) {
+	dir := t.TempDir()
+	fake := &fakeDocker{details: map[string]*docker.Details{}}
+	for name, size := range map[string]int{"web": 3 << 20, "db": 1 << 20} {
+		d := containerDetails(name, name, "sha256:"+name, true, "")
+		d.LogPath = filepath.Join(dir, name+"-json.log")
+		if err := os.WriteFile(d.LogPath, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		fake.details[name] = d
+	}
+	defer setDockerClient(fake)()
+	ctx := newTestAppContext()
+	ctx.Docker.Cache.Containers = []ContainerInfo{{Name: "web", Running: true}, {Name: "db", Running: true}}
+	ctx.Config.Docker.Logs = DockerLogsConfig{Enabled: true, MaxSizeMB: 2, MaxGrowthMBPerHour: 100}
+	ctx.Config.Alerts.Defaults.NotifyResolved = true
+	bot := &fakeBot{}
+	monitor := &ContainerLogsMonitor{}
+
+	processMonitorAlerts(ctx, bot, monitor.Check(ctx, nil))
+	texts := sentTexts(bot)
+	if len(texts) != 1 || !strings.Contains(texts[0], "`web` log is `3M`") || strings.Contains(texts[0], "`db`") {
+		t.Fatalf("unexpected alerts: %q", texts)
+	}
+	kb := bot.sent[0].(tgbotapi.MessageConfig).ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	if data := keyboardData(&kb); len(data) != 1 || data[0] != "dlog_ask_web" {
+		t.Fatalf("missing truncate button: %v", data)
+	}
+
+	handleContainerLogCallback(ctx, bot, 1, 1, "dlog_do_web")
+	if fi, err := os.Stat(fake.details["web"].LogPath); err != nil || fi.Size() != 0 {
+		t.Fatalf("log not truncated: %v", err)
+	}
+	var found bool
+	for _, e := range ctx.State.GetEvents() {
+		found = found || (e.Type == "action" && e.Message == "Truncated log of web (3M freed)")
+	}
+	if !found {
+		t.Fatalf("truncation not recorded: %+v", ctx.State.GetEvents())
+	}
+
+	processMonitorAlerts(ctx, bot, monitor.Check(ctx, nil))
+	if texts = sentTexts(bot); !strings.Contains(texts[len(texts)-1], "web log") {
+		t.Fatalf("size alert not resolved: %q", texts)
+	}
+}
+
+func TestContainerLogGrowth(t *testing.T) {
+	now := time.Now()
+	m := &ContainerLogsMonitor{samples: map[string][]logSample{"app": {
+		{At: now.Add(-2 * time.Hour), Size: 0},
+		{At: now.Add(-70 * time.Minute), Size: 100 << 20},
+		{At: now.Add(-30 * time.Minute), Size: 200 << 20},
+	}}}
+
+	// Measured from the newest reading older than the window.
+	if rate := m.growth("app", now, 450<<20); rate < 299 || rate > 301 {
+		t.Fatalf("growth = %.1f MB/h, want ~300", rate)
+	}
+	if len(m.samples["app"]) != 3 {
+		t.Fatalf("old samples not pruned: %+v", m.samples["app"])
+	}
+	// A rotated log starts over and reports nothing until enough time passed.
+	if rate := m.growth("app", now.Add(time.Minute), 1<<20); rate != 0 {
+		t.Fatalf("growth after rotation = %.1f", rate)
+	}
+}
+
+func TestContainerLogsMissingPathRetried(t *testing.T) {
+	d := containerDetails("app", "app", "sha256:app", true, "")
+	fake := &fakeDocker{details: map[string]*docker.Details{"app": d}}
+	defer setDockerClient(fake)()
+	ctx := newTestAppContext()
+	ctx.Docker.Cache.Containers = []ContainerInfo{{Name: "app", Running: true}}
+	ctx.Config.Docker.Logs = DockerLogsConfig{Enabled: true, MaxSizeMB: 1}
+	monitor := &ContainerLogsMonitor{}
+
+	monitor.Check(ctx, nil)
+	d.LogPath = filepath.Join(t.TempDir(), "app-json.log")
+	if err := os.WriteFile(d.LogPath, make([]byte, 2<<20), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if alerts := monitor.Check(ctx, nil); len(alerts) != 0 {
+		t.Fatalf("the failed lookup should wait for the retry: %+v", alerts)
+	}
+
+	monitor.missing["app"] = time.Now().Add(-logPathRetry)
+	if alerts := monitor.Check(ctx, nil); len(alerts) != 1 || alerts[0].Level == AlertLevelOK {
+		t.Fatalf("log not picked up after the retry: %+v", alerts)
+	}
+}
+
+func TestWithActionRowsDeduplicates(t *testing.T) {
+	ask := tgbotapi.NewInlineKeyboardButtonData("✂️ web", "dlog_ask_web")
+	other := tgbotapi.NewInlineKeyboardButtonData("✂️ db", "dlog_ask_db")
+	kb := withActionRows(tgbotapi.NewInlineKeyboardMarkup(), []tgbotapi.InlineKeyboardButton{ask, other, ask})
+	if data := keyboardData(&kb); len(data) != 2 || data[0] != "dlog_ask_web" || data[1] != "dlog_ask_db" {
+		t.Fatalf("unexpected buttons: %v", data)
+	}
+}
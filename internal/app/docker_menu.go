@@ -0,0 +1,305 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"nasbot/internal/compose"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// dockerMenuPageSize is how many containers, or stacks, a menu page holds.
+const dockerMenuPageSize = 16
+
+// Filters of the container menu. A stack filter is "s:" + the stack name.
+const (
+	menuFilterAll       = "all"
+	menuFilterRunning   = "run"
+	menuFilterStopped   = "stop"
+	menuFilterUnhealthy = "sick"
+	menuFilterStacks    = "stacks" // the stack picker
+	menuFilterStack     = "s:"
+)
+
+// getDockerMenuText generates the first page of the Docker menu.
+func getDockerMenuText(ctx *AppContext) (string, *tgbotapi.InlineKeyboardMarkup) {
+	return dockerMenuPage(ctx, 0, menuFilterAll)
+}
+
+// handleDockerMenuCallback handles dmenu_<page>_<filter>.
+func handleDockerMenuCallback(ctx *AppContext, bot BotAPI, chatID int64, msgID int, data string) {
+	pageStr, filter, ok := strings.Cut(strings.TrimPrefix(data, "dmenu_"), "_")
+	page, err := strconv.Atoi(pageStr)
+	if !ok || err != nil {
+		return
+	}
+	text, kb := dockerMenuPage(ctx, page, filter)
+	editMessage(bot, chatID, msgID, text, kb)
+}
+
+// menuOrder lists containers stack by stack, then the standalone ones, so
+// that a page never interleaves stacks.
+func menuOrder(containers []ContainerInfo) ([]ContainerInfo, []compose.Stack) {
+	stacks, standalone := compose.Group(containers)
+	ordered := make([]ContainerInfo, 0, len(containers))
+	for _, st := range stacks {
+		ordered = append(ordered, st.Containers...)
+	}
+	return append(ordered, standalone...), stacks
+}
+
+// matchesMenuFilter reports whether c is shown under filter.
+func matchesMenuFilter(c ContainerInfo, filter string) bool {
+	switch filter {
+	case menuFilterRunning:
+		return c.Running
+	case menuFilterStopped:
+		return !c.Running
+	case menuFilterUnhealthy:
+		return c.Running && c.Health == "unhealthy"
+	}
+	if stack, ok := strings.CutPrefix(filter, menuFilterStack); ok {
+		return c.Labels[compose.LabelProject] == stack
+	}
+	return true
+}
+
+// menuPages clamps page to the pages needed for n items.
+func menuPages(n, page int) (int, int) {
+	pages := max(1, (n+dockerMenuPageSize-1)/dockerMenuPageSize)
+	return max(0, min(page, pages-1)), pages
+}
+
+// dockerMenuPage renders one page of the containers matching filter, or of
+// the stack picker.
+func dockerMenuPage(ctx *AppContext, page int, filter string) (string, *tgbotapi.InlineKeyboardMarkup) {
+	containers := getCachedContainerList(ctx)
+	if len(containers) == 0 {
+		mainKb := getMainKeyboard(ctx)
+		return ctx.Tr("docker_no_containers"), &mainKb
+	}
+	ordered, stacks := menuOrder(containers)
+	if filter == menuFilterStacks {
+		return stackPickerPage(ctx, stacks, page)
+	}
+
+	var shown []ContainerInfo
+	for _, c := range ordered {
+		if matchesMenuFilter(c, filter) {
+			shown = append(shown, c)
+		}
+	}
+	page, pages := menuPages(len(shown), page)
+	shown = shown[page*dockerMenuPageSize : min((page+1)*dockerMenuPageSize, len(shown))]
+
+	var b strings.Builder
+	b.WriteString(ctx.Tr("docker_title"))
+	if len(shown) == 0 {
+		b.WriteString(ctx.Tr("docker_filter_empty"))
+	}
+	byName := make(map[string]compose.Stack, len(stacks))
+	for _, st := range stacks {
+		byName[st.Name] = st
+	}
+	project := ""
+	for i, c := range shown {
+		// Headers use the whole stack, which may continue on other pages.
+		if p := c.Labels[compose.LabelProject]; len(stacks) > 0 && (i == 0 || p != project) {
+			if st, ok := byName[p]; ok {
+				b.WriteString(fmt.Sprintf("\n📚 *%s* %s %d/%d\n", st.Name, st.Icon(), st.Running(), len(st.Containers)))
+			} else {
+				b.WriteString(ctx.Tr("docker_standalone"))
+			}
+			project = p
+		}
+		b.WriteString(containerMenuLine(c))
+	}
+
+	running := 0
+	for _, c := range containers {
+		if c.Running {
+			running++
+		}
+	}
+	b.WriteString(fmt.Sprintf(ctx.Tr("docker_running"), running, len(containers)-running))
+	if filter != menuFilterAll || pages > 1 {
+		b.WriteString(fmt.Sprintf(ctx.Tr("docker_filter_page"), menuFilterLabel(ctx, filter), page+1, pages))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(shown); i += 2 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, c := range shown[i:min(i+2, len(shown))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%s %s", menuIcon(c), truncate(c.Name, 10)),
+				"container_select_"+c.Name,
+			))
+		}
+		rows = append(rows, row)
+	}
+	if nav := menuNavRow(page, pages, filter); nav != nil {
+		rows = append(rows, nav)
+	}
+	if stack, ok := strings.CutPrefix(filter, menuFilterStack); ok {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(ctx.Tr("docker_stack_actions"), truncate(stack, 16)), "stack_select_"+stack),
+		))
+	}
+	rows = append(rows, menuFilterRow(filter, len(stacks) > 0))
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔄 "+ctx.Tr("docker_menu_restart_all"), "docker_restart_all"),
+		tgbotapi.NewInlineKeyboardButtonData("🐳 "+ctx.Tr("docker_menu_restart_service"), "docker_restart_service"),
+	))
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("docker_menu_refresh"), fmt.Sprintf("dmenu_%d_%s", page, filter)),
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("dupdates_open"), "dupdate_list"),
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("ddisk_open"), "ddisk_refresh"),
+	))
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("docker_menu_home"), "back_main"),
+	))
+
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return b.String(), &kb
+}
+
+// stackPickerPage lists compose stacks as buttons that filter the menu.
+func stackPickerPage(ctx *AppContext, stacks []compose.Stack, page int) (string, *tgbotapi.InlineKeyboardMarkup) {
+	page, pages := menuPages(len(stacks), page)
+	stacks = stacks[page*dockerMenuPageSize : min((page+1)*dockerMenuPageSize, len(stacks))]
+
+	var b strings.Builder
+	b.WriteString(ctx.Tr("docker_stacks_title"))
+	if len(stacks) == 0 {
+		b.WriteString(ctx.Tr("docker_no_stacks"))
+	}
+	for _, st := range stacks {
+		b.WriteString(fmt.Sprintf("%s *%s* — %d/%d\n", st.Icon(), st.Name, st.Running(), len(st.Containers)))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(stacks); i += 2 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, st := range stacks[i:min(i+2, len(stacks))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("📚 "+truncate(st.Name, 12), "dmenu_0_"+menuFilterStack+st.Name))
+		}
+		rows = append(rows, row)
+	}
+	if nav := menuNavRow(page, pages, menuFilterStacks); nav != nil {
+		rows = append(rows, nav)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("back"), "show_docker"),
+	))
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return b.String(), &kb
+}
+
+// menuIcon is the state shown on a container's button.
+func menuIcon(c ContainerInfo) string {
+	switch {
+	case c.Running && c.Health == "unhealthy":
+		return "🩺"
+	case c.Running:
+		return "▶"
+	}
+	return "⏸"
+}
+
+// menuNavRow returns the previous/next buttons, or nil on a single page.
+func menuNavRow(page, pages int, filter string) []tgbotapi.InlineKeyboardButton {
+	if pages <= 1 {
+		return nil
+	}
+	prev, next := max(page-1, 0), min(page+1, pages-1)
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("◀️", fmt.Sprintf("dmenu_%d_%s", prev, filter)),
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d/%d", page+1, pages), fmt.Sprintf("dmenu_%d_%s", page, filter)),
+		tgbotapi.NewInlineKeyboardButtonData("▶️", fmt.Sprintf("dmenu_%d_%s", next, filter)),
+	)
+}
+
+// menuFilterRow holds the filter toggles; the active one is marked.
+func menuFilterRow(active string, withStacks bool) []tgbotapi.InlineKeyboardButton {
+	filters := []struct{ label, filter string }{
+		{"🐳", menuFilterAll},
+		{"▶", menuFilterRunning},
+		{"⏸", menuFilterStopped},
+		{"🩺", menuFilterUnhealthy},
+	}
+	if withStacks {
+		filters = append(filters, struct{ label, filter string }{"📚", menuFilterStacks})
+	}
+	var row []tgbotapi.InlineKeyboardButton
+	for _, f := range filters {
+		label := f.label
+		if f.filter == active || (f.filter == menuFilterStacks && strings.HasPrefix(active, menuFilterStack)) {
+			label = "·" + label + "·"
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, "dmenu_0_"+f.filter))
+	}
+	return row
+}
+
+// menuFilterLabel names filter in the page footer.
+func menuFilterLabel(ctx *AppContext, filter string) string {
+	if stack, ok := strings.CutPrefix(filter, menuFilterStack); ok {
+		return "📚 " + stack
+	}
+	switch filter {
+	case menuFilterRunning, menuFilterStopped, menuFilterUnhealthy:
+		return ctx.Tr("docker_filter_" + filter)
+	}
+	return ctx.Tr("docker_filter_all")
+}
+
+// findContainers returns the containers whose name, image or compose
+// project contains query, ignoring case.
+func findContainers(containers []ContainerInfo, query string) []ContainerInfo {
+	query = strings.ToLower(query)
+	var found []ContainerInfo
+	ordered, _ := menuOrder(containers)
+	for _, c := range ordered {
+		if strings.Contains(strings.ToLower(c.Name), query) ||
+			strings.Contains(strings.ToLower(c.Image), query) ||
+			strings.Contains(strings.ToLower(c.Labels[compose.LabelProject]), query) {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// handleContainerFind answers /container find <text> with the matches as
+// buttons, at most one menu page of them.
+func handleContainerFind(ctx *AppContext, bot BotAPI, chatID int64, query string) {
+	found := findContainers(getCachedContainerList(ctx), query)
+	if len(found) == 0 {
+		sendMarkdown(bot, chatID, fmt.Sprintf(ctx.Tr("docker_find_none"), query))
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(ctx.Tr("docker_find_title"), len(found), query))
+	shown := found[:min(len(found), dockerMenuPageSize)]
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, c := range shown {
+		b.WriteString(containerMenuLine(c))
+		if i%2 == 0 {
+			rows = append(rows, nil)
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%s %s", menuIcon(c), truncate(c.Name, 10)), "container_select_"+c.Name))
+	}
+	if more := len(found) - len(shown); more > 0 {
+		b.WriteString(fmt.Sprintf(ctx.Tr("docker_find_more"), more))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ParseMode = "Markdown"
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	msg.ReplyMarkup = kb
+	safeSend(bot, msg)
+}
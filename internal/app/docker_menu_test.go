@@ -0,0 +1,121 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func menuFixture(n int) *AppContext {
+	ctx := newTestAppContext()
+	ctx.Docker.Cache.Containers = nil
+	for i := 0; i < n; i++ {
+		c := ContainerInfo{Name: fmt.Sprintf("svc%02d", i), Running: i%3 != 0, Status: "Up 2 hours", Image: "busybox"}
+		if i == 4 {
+			c.Health = "unhealthy"
+		}
+		ctx.Docker.Cache.Containers = append(ctx.Docker.Cache.Containers, c)
+	}
+	ctx.Docker.Cache.Containers = append(ctx.Docker.Cache.Containers,
+		stackContainer("jellyfin", "media", true), stackContainer("sonarr", "media", false))
+	return ctx
+}
+
+// menuContainers returns the containers with a button on the page.
+func menuContainers(kb *tgbotapi.InlineKeyboardMarkup) []string {
+	var names []string
+	for _, d := range keyboardData(kb) {
+		if name, ok := strings.CutPrefix(d, "container_select_"); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func TestDockerMenuPages(t *testing.T) {
+	ctx := menuFixture(40)
+
+	text, kb := getDockerMenuText(ctx)
+	names := menuContainers(kb)
+	// Stacks come first, so the page starts with media.
+	if len(names) != dockerMenuPageSize || names[0] != "jellyfin" || names[2] != "svc00" {
+		t.Fatalf("unexpected first page: %v", names)
+	}
+	if !strings.Contains(text, "page 1/3") || !strings.Contains(text, "_27 running, 15 stopped_") {
+		t.Fatalf("unexpected footer: %q", text)
+	}
+	if data := keyboardData(kb); !slices.Contains(data, "dmenu_1_all") || !slices.Contains(data, "dmenu_0_run") {
+		t.Fatalf("missing navigation or filters: %v", data)
+	}
+
+	bot := &fakeBot{}
+	handleDockerMenuCallback(ctx, bot, 1, 1, "dmenu_2_all")
+	last := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig)
+	if names := menuContainers(last.ReplyMarkup); len(names) != 42-2*dockerMenuPageSize || names[len(names)-1] != "svc39" {
+		t.Fatalf("unexpected last page: %v", names)
+	}
+	// A stale page number lands on the last page.
+	if _, kb := dockerMenuPage(ctx, 9, menuFilterAll); !slices.Equal(menuContainers(kb), menuContainers(last.ReplyMarkup)) {
+		t.Fatalf("page not clamped")
+	}
+}
+
+func TestDockerMenuFilters(t *testing.T) {
+	ctx := menuFixture(10)
+
+	_, kb := dockerMenuPage(ctx, 0, menuFilterStopped)
+	if got := fmt.Sprint(menuContainers(kb)); got != "[sonarr svc00 svc03 svc06 svc09]" {
+		t.Fatalf("stopped filter = %s", got)
+	}
+	text, kb := dockerMenuPage(ctx, 0, menuFilterUnhealthy)
+	if got := fmt.Sprint(menuContainers(kb)); got != "[svc04]" || !strings.Contains(text, "Unhealthy · page 1/1") {
+		t.Fatalf("unhealthy filter = %s, %q", got, text)
+	}
+	if !slices.Contains(keyboardData(kb), "dmenu_0_sick") {
+		t.Fatalf("filter row missing")
+	}
+	_, kb = dockerMenuPage(ctx, 0, "s:media")
+	if got := fmt.Sprint(menuContainers(kb)); got != "[jellyfin sonarr]" {
+		t.Fatalf("stack filter = %s", got)
+	}
+
+	ctx.Docker.Cache.Containers = ctx.Docker.Cache.Containers[:3]
+	text, _ = dockerMenuPage(ctx, 0, menuFilterUnhealthy)
+	if !strings.Contains(text, "No containers match") {
+		t.Fatalf("empty filter not explained: %q", text)
+	}
+}
+
+func TestContainerFind(t *testing.T) {
+	ctx := menuFixture(30)
+	ctx.Docker.Cache.Containers = append(ctx.Docker.Cache.Containers, ContainerInfo{Name: "find", Running: true, Image: "alpine"})
+	bot := &fakeBot{}
+
+	handleContainerCommand(ctx, bot, 1, "find MEDIA")
+	msg := bot.sent[len(bot.sent)-1].(tgbotapi.MessageConfig)
+	kb := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	if got := fmt.Sprint(menuContainers(&kb)); got != "[jellyfin sonarr]" || !strings.Contains(msg.Text, "2 containers match") {
+		t.Fatalf("find media = %s, %q", got, msg.Text)
+	}
+
+	handleContainerCommand(ctx, bot, 1, "find svc")
+	msg = bot.sent[len(bot.sent)-1].(tgbotapi.MessageConfig)
+	kb = msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	if len(menuContainers(&kb)) != dockerMenuPageSize || !strings.Contains(msg.Text, "14 more") {
+		t.Fatalf("find svc not capped: %q", msg.Text)
+	}
+
+	handleContainerCommand(ctx, bot, 1, "find nothing-like-this")
+	if text := bot.sent[len(bot.sent)-1].(tgbotapi.MessageConfig).Text; !strings.Contains(text, "No container matches") {
+		t.Fatalf("unexpected reply: %q", text)
+	}
+
+	// An exact name still wins over the subcommand.
+	handleContainerCommand(ctx, bot, 1, "find")
+	if text := bot.sent[len(bot.sent)-1].(tgbotapi.MessageConfig).Text; !strings.Contains(text, "*find*") {
+		t.Fatalf("container named find not shown: %q", text)
+	}
+}
@@ -0,0 +1,401 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// selfMountinfo is read to find the bot's own container; tests point it
+// at a fixture.
+var selfMountinfo = "/proc/self/mountinfo"
+
+// containerDirRe matches the per-container directory Docker bind-mounts
+// /etc/hostname, /etc/hosts and /etc/resolv.conf from.
+var containerDirRe = regexp.MustCompile(`/containers/([0-9a-f]{64})/`)
+
+// startReadyPoll is how often a starting container is inspected.
+var startReadyPoll = 2 * time.Second
+
+// ensureStartRunning keeps the boot check and recoveries from overlapping.
+var ensureStartRunning atomic.Bool
+
+// startOrder sorts names so that every container comes after the ones it
+// depends on; dependencies outside names are ignored. Containers caught in
+// a cycle are appended in name order and returned as an error.
+func startOrder(deps map[string][]string, names []string) ([]string, error) {
+	in := make(map[string]bool, len(names))
+	for _, n := range names {
+		in[n] = true
+	}
+	pending := make(map[string]int, len(names))
+	dependents := make(map[string][]string)
+	for _, n := range names {
+		for _, d := range deps[n] {
+			if in[d] && d != n {
+				pending[n]++
+				dependents[d] = append(dependents[d], n)
+			}
+		}
+	}
+
+	var ready, order []string
+	for _, n := range names {
+		if pending[n] == 0 {
+			ready = append(ready, n)
+		}
+	}
+	sort.Strings(ready)
+	for len(ready) > 0 {
+		n := ready[0]
+		ready = ready[1:]
+		order = append(order, n)
+		for _, d := range dependents[n] {
+			if pending[d]--; pending[d] == 0 {
+				ready = append(ready, d)
+				sort.Strings(ready)
+			}
+		}
+	}
+	if len(order) == len(names) {
+		return order, nil
+	}
+	var cycle []string
+	for _, n := range names {
+		if pending[n] > 0 {
+			cycle = append(cycle, n)
+		}
+	}
+	sort.Strings(cycle)
+	return append(order, cycle...), fmt.Errorf("dependency cycle between %s", strings.Join(cycle, ", "))
+}
+
+// selfContainerID returns the ID of the container the bot runs in, or ""
+// outside Docker. The hostname cannot tell it with host networking, but
+// the files Docker mounts into every container name its directory.
+func selfContainerID() string {
+	data, err := os.ReadFile(selfMountinfo)
+	if err != nil {
+		return ""
+	}
+	if m := containerDirRe.FindSubmatch(data); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// isSelfContainer reports whether c is the bot's own container; listed IDs
+// may be the short form.
+func isSelfContainer(c ContainerInfo, selfID string) bool {
+	return selfID != "" && c.ID != "" && strings.HasPrefix(selfID, c.ID)
+}
+
+// dependencyNames returns every container that declares dependencies or
+// is one.
+func dependencyNames(deps map[string][]string) map[string]bool {
+	names := make(map[string]bool)
+	for n, ds := range deps {
+		names[n] = true
+		for _, d := range ds {
+			names[d] = true
+		}
+	}
+	return names
+}
+
+// dependencyClosure returns roots and everything they depend on.
+func dependencyClosure(deps map[string][]string, roots []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	var visit func(string)
+	visit = func(n string) {
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+		out = append(out, n)
+		for _, d := range deps[n] {
+			visit(d)
+		}
+	}
+	for _, r := range roots {
+		visit(r)
+	}
+	return out
+}
+
+// watchContainer inspects name every poll until timeout. It fails as soon
+// as the container stops or its healthcheck fails, and returns once the
+// healthcheck passes. A container without a healthcheck is ready at once,
+// or with watchExit only after running for the whole timeout. A
+// healthcheck still starting at the deadline counts as a failure.
+func watchContainer(ctx context.Context, name string, timeout, poll time.Duration, watchExit bool) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		d, err := dockerClient.Inspect(ctx, name)
+		if err != nil {
+			return err
+		}
+		health := ""
+		if d.State.Health != nil && d.State.Health.Status != "none" {
+			health = d.State.Health.Status
+		}
+		switch {
+		case !d.State.Running || d.State.Restarting:
+			return fmt.Errorf("container exited with code %d", d.State.ExitCode)
+		case health == "unhealthy":
+			if out := d.State.Health.LastOutput(); out != "" {
+				return fmt.Errorf("healthcheck failing: %s", truncate(out, 200))
+			}
+			return errors.New("healthcheck failing")
+		case health == "healthy", health == "" && !watchExit:
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			if health != "" {
+				return fmt.Errorf("healthcheck did not pass within %s", timeout)
+			}
+			return nil
+		}
+		if !sleepWithContext(ctx, poll) {
+			return ctx.Err()
+		}
+	}
+}
+
+// Step states of an ordered start.
+const (
+	stepStopping = "stopping"
+	stepStopped  = "stopped"
+	stepStarting = "starting"
+	stepReady    = "ready"
+	stepFailed   = "failed"
+)
+
+type startStep struct {
+	Name  string
+	State string
+	Err   error
+}
+
+// orderedStart starts containers in dependency order, reporting every
+// change of state through progress.
+type orderedStart struct {
+	steps    []startStep
+	timeout  time.Duration
+	progress func(steps []startStep)
+}
+
+func newOrderedStart(ctx *AppContext, names []string, progress func([]startStep)) *orderedStart {
+	deps := ctx.Config.Docker.Dependencies.DependsOn
+	order, err := startOrder(deps, names)
+	if err != nil {
+		slog.Warn("Container start order", "err", err)
+	}
+	s := &orderedStart{
+		timeout:  time.Duration(ctx.Config.Docker.Dependencies.HealthTimeoutSeconds) * time.Second,
+		progress: progress,
+	}
+	if s.timeout <= 0 {
+		s.timeout = 2 * time.Minute
+	}
+	for _, n := range order {
+		s.steps = append(s.steps, startStep{Name: n})
+	}
+	return s
+}
+
+func (s *orderedStart) set(i int, state string, err error) {
+	s.steps[i].State = state
+	s.steps[i].Err = err
+	if s.progress != nil {
+		s.progress(slices.Clone(s.steps))
+	}
+}
+
+// restart stops the containers in reverse order, then starts them.
+func (s *orderedStart) restart(ctx context.Context) {
+	for i := len(s.steps) - 1; i >= 0; i-- {
+		s.set(i, stepStopping, nil)
+		opCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		err := dockerClient.Stop(opCtx, s.steps[i].Name)
+		cancel()
+		if err != nil {
+			slog.Error("Failed to stop container", "container", s.steps[i].Name, "err", err)
+		}
+		s.set(i, stepStopped, nil)
+	}
+	s.start(ctx, false)
+}
+
+// start starts every container that is not running, in order, waiting for
+// each to be ready before moving on. A failed container does not hold its
+// dependents back: most retry their connections, and stopped they surely
+// do not work. With onlyStopped, running containers are only waited for.
+func (s *orderedStart) start(ctx context.Context, onlyStopped bool) {
+	for i, step := range s.steps {
+		s.set(i, stepStarting, nil)
+		running := false
+		if onlyStopped {
+			if d, err := dockerClient.Inspect(ctx, step.Name); err == nil {
+				running = d.State.Running
+			}
+		}
+		if !running {
+			opCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			err := dockerClient.Start(opCtx, step.Name)
+			cancel()
+			if err != nil {
+				slog.Error("Failed to start container", "container", step.Name, "err", err)
+				s.set(i, stepFailed, err)
+				continue
+			}
+		}
+		if err := watchContainer(ctx, step.Name, s.timeout, startReadyPoll, false); err != nil {
+			slog.Warn("Container not ready", "container", step.Name, "err", err)
+			s.set(i, stepFailed, err)
+			continue
+		}
+		s.set(i, stepReady, nil)
+	}
+}
+
+// results splits the steps into ready and failed containers.
+func (s *orderedStart) results() (ready, failed []string) {
+	for _, st := range s.steps {
+		if st.State == stepReady {
+			ready = append(ready, st.Name)
+		} else {
+			failed = append(failed, st.Name)
+		}
+	}
+	return ready, failed
+}
+
+// renderStartSteps shows one line per container under title.
+func renderStartSteps(title string, steps []startStep) string {
+	var b strings.Builder
+	b.WriteString(title + "\n\n")
+	for _, st := range steps {
+		icon := "▫️"
+		switch st.State {
+		case stepStopping, stepStarting:
+			icon = "⏳"
+		case stepStopped:
+			icon = "⏹"
+		case stepReady:
+			icon = "✅"
+		case stepFailed:
+			icon = "❌"
+		}
+		b.WriteString(fmt.Sprintf("%s `%s`", icon, st.Name))
+		if st.Err != nil {
+			b.WriteString(fmt.Sprintf(" — %s", truncate(st.Err.Error(), 80)))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// stepEditor returns a progress func that edits the steps into a message,
+// at most once a second to stay within Telegram's edit limits.
+func stepEditor(bot BotAPI, chatID int64, msgID int, title string) func([]startStep) {
+	var last time.Time
+	return func(steps []startStep) {
+		if msgID == 0 || time.Since(last) < time.Second {
+			return
+		}
+		last = time.Now()
+		editMessage(bot, chatID, msgID, renderStartSteps(title, steps), nil)
+	}
+}
+
+// startDependencies is what the boot check and the watchdog recovery
+// bring up: critical containers, every container with declared
+// dependencies, and those dependencies.
+func startDependencies(c *Config) []string {
+	roots := slices.Clone(c.CriticalContainers)
+	for name := range c.Docker.Dependencies.DependsOn {
+		roots = append(roots, name)
+	}
+	sort.Strings(roots)
+	return dependencyClosure(c.Docker.Dependencies.DependsOn, roots)
+}
+
+// ensureStartOrder starts, in dependency order, the containers of
+// startDependencies that are not running. When started from a chat, the
+// progress is edited into msgID there; boot and watchdog runs, with a zero
+// chatID, send the outcome to the alert recipients.
+func ensureStartOrder(ctx *AppContext, bot BotAPI, runCtx context.Context, reason string, chatID int64, msgID int) {
+	names := startDependencies(ctx.Config)
+	if len(names) == 0 || !ensureStartRunning.CompareAndSwap(false, true) {
+		return
+	}
+	defer ensureStartRunning.Store(false)
+	if !waitDockerReady(runCtx, 3*time.Minute) {
+		slog.Warn("Ordered start skipped: Docker not reachable", "reason", reason)
+		return
+	}
+
+	// Running containers stay in the plan so dependents wait for them.
+	var present []string
+	stopped := 0
+	for _, n := range names {
+		opCtx, cancel := context.WithTimeout(runCtx, 10*time.Second)
+		d, err := dockerClient.Inspect(opCtx, n)
+		cancel()
+		if err != nil {
+			slog.Warn("Ordered start: container not found", "container", n, "err", err)
+			continue
+		}
+		present = append(present, n)
+		if !d.State.Running {
+			stopped++
+		}
+	}
+	if stopped == 0 {
+		return
+	}
+
+	title := ctx.Tr("order_title_" + reason)
+	s := newOrderedStart(ctx, present, stepEditor(bot, chatID, msgID, title))
+	s.start(runCtx, true)
+
+	ready, failed := s.results()
+	ctx.State.AddEvent("action", fmt.Sprintf("Ordered container start (%s): %d ready, %d failed", reason, len(ready), len(failed)))
+	text := renderStartSteps(title, s.steps)
+	switch {
+	case msgID != 0:
+		kb := dockerHomeKeyboard(ctx)
+		editMessage(bot, chatID, msgID, text, &kb)
+	case ctx.IsQuietHours():
+	case len(failed) > 0:
+		sendAlert(bot, ctx.Config, AlertTopicWarning, alertMessage(text))
+	default:
+		sendAlert(bot, ctx.Config, AlertTopicInfo, alertMessage(text))
+	}
+}
+
+// waitDockerReady waits for the daemon to answer, e.g. after a restart.
+func waitDockerReady(ctx context.Context, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := dockerClient.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			return true
+		}
+		if !time.Now().Before(deadline) || !sleepWithContext(ctx, startReadyPoll) {
+			return false
+		}
+	}
+}
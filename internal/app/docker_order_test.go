@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"nasbot/internal/docker"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestStartOrder(t *testing.T) {
+	deps := map[string][]string{
+		"proxy": {"app"},
+		"app":   {"db", "cache", "elsewhere"},
+		"db":    {"db"},
+	}
+	order, err := startOrder(deps, []string{"proxy", "web", "app", "db", "cache"})
+	if err != nil || fmt.Sprint(order) != "[cache db app proxy web]" {
+		t.Fatalf("order = %v, %v", order, err)
+	}
+
+	deps["db"] = []string{"proxy"}
+	order, err = startOrder(deps, []string{"proxy", "web", "app", "db", "cache"})
+	if err == nil || !strings.Contains(err.Error(), "app, db, proxy") || fmt.Sprint(order) != "[cache web app db proxy]" {
+		t.Fatalf("cycle: order = %v, %v", order, err)
+	}
+
+	deps = map[string][]string{"app": {"db"}, "db": {"volume-helper"}}
+	if got := dependencyClosure(deps, []string{"app", "web"}); fmt.Sprint(got) != "[app db volume-helper web]" {
+		t.Fatalf("closure = %v", got)
+	}
+}
+
+func orderFixture() (*fakeDocker, *AppContext) {
+	fake := &fakeDocker{details: map[string]*docker.Details{
+		"db":    containerDetails("db", "postgres", "sha256:db", true, "healthy"),
+		"app":   containerDetails("app", "app", "sha256:app", true, ""),
+		"proxy": containerDetails("proxy", "nginx", "sha256:proxy", true, ""),
+	}}
+	ctx := newTestAppContext()
+	ctx.Config.Docker.Dependencies = DockerDependencyConfig{
+		DependsOn:            map[string][]string{"app": {"db"}, "proxy": {"app"}},
+		HealthTimeoutSeconds: 1,
+	}
+	ctx.Docker.Cache.Containers = []ContainerInfo{{Name: "proxy", Running: true}, {Name: "app", Running: true}, {Name: "db", Running: true}}
+	return fake, ctx
+}
+
+func TestRestartAllInDependencyOrder(t *testing.T) {
+	fake, ctx := orderFixture()
+	defer setDockerClient(fake)()
+	bot := &fakeBot{}
+
+	executeRestartAllContainers(ctx, bot, 1, 1)
+	want := []string{"stop proxy", "stop app", "stop db", "start db", "start app", "start proxy"}
+	if fmt.Sprint(fake.actions) != fmt.Sprint(want) {
+		t.Fatalf("actions = %v, want %v", fake.actions, want)
+	}
+	last := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig)
+	if !strings.Contains(last.Text, "*3*") {
+		t.Fatalf("unexpected summary: %s", last.Text)
+	}
+}
+
+func TestRestartAllKeepsBotRunning(t *testing.T) {
+	fake, ctx := orderFixture()
+	defer setDockerClient(fake)()
+	selfID := strings.Repeat("ab12", 16)
+	mountinfo := t.TempDir() + "/mountinfo"
+	line := "612 598 0:52 /docker/containers/" + selfID + "/hostname /etc/hostname rw,relatime - ext4 /dev/sda1 rw\n"
+	if err := os.WriteFile(mountinfo, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer func(p string) { selfMountinfo = p }(selfMountinfo)
+	selfMountinfo = mountinfo
+	ctx.Docker.Cache.Containers = append([]ContainerInfo{
+		{Name: "nasbot", ID: selfID[:12], Running: true},
+		{Name: "web", ID: "0123456789ab", Running: true},
+	}, ctx.Docker.Cache.Containers...)
+	bot := &fakeBot{}
+
+	executeRestartAllContainers(ctx, bot, 1, 1)
+	// The bot and containers outside the dependencies are never stopped;
+	// the bot goes last, after the result is sent.
+	want := []string{"stop proxy", "stop app", "stop db", "start db", "start app", "start proxy", "restart web", "restart nasbot"}
+	if fmt.Sprint(fake.actions) != fmt.Sprint(want) {
+		t.Fatalf("actions = %v, want %v", fake.actions, want)
+	}
+	last := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig)
+	if !strings.Contains(last.Text, "*5*") || !strings.Contains(last.Text, "`nasbot`") {
+		t.Fatalf("unexpected summary: %s", last.Text)
+	}
+}
+
+func TestEnsureStartOrder(t *testing.T) {
+	defer func(d time.Duration) { startReadyPoll = d }(startReadyPoll)
+	startReadyPoll = 10 * time.Millisecond
+	fake, ctx := orderFixture()
+	defer setDockerClient(fake)()
+	bot := &fakeBot{}
+
+	// Everything running: nothing to do and nothing sent.
+	ensureStartOrder(ctx, bot, context.Background(), "boot", 0, 0)
+	if len(fake.actions) != 0 || len(bot.sent) != 0 {
+		t.Fatalf("unexpected work: %v, %d messages", fake.actions, len(bot.sent))
+	}
+
+	// The database never becomes healthy; its dependents are started anyway.
+	fake.details["db"].State.Running = false
+	fake.details["db"].State.Health.Status = "starting"
+	fake.details["proxy"].State.Running = false
+	ensureStartOrder(ctx, bot, context.Background(), "boot", 0, 0)
+	if want := []string{"start db", "start proxy"}; fmt.Sprint(fake.actions) != fmt.Sprint(want) {
+		t.Fatalf("actions = %v, want %v", fake.actions, want)
+	}
+	// A boot run reports the outcome to the alert recipients.
+	texts := sentTexts(bot)
+	if len(texts) != 1 || !strings.Contains(texts[0], "boot") {
+		t.Fatalf("expected one outcome alert, got %q", texts)
+	}
+	if !strings.Contains(texts[0], "❌ `db` — healthcheck did not pass within 1s") || !strings.Contains(texts[0], "✅ `app`") || !strings.Contains(texts[0], "✅ `proxy`") {
+		t.Fatalf("unexpected outcome: %s", texts[0])
+	}
+
+	// Started from a chat, the progress is edited into the invoking message.
+	fake.details["db"].State.Health.Status = "healthy"
+	fake.details["db"].State.Running = false
+	bot.sent = nil
+	ensureStartOrder(ctx, bot, context.Background(), "recovery", 42, 7)
+	last, ok := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig)
+	if len(sentTexts(bot)) != 0 || !ok || last.ChatID != 42 || last.MessageID != 7 || !strings.Contains(last.Text, "✅ `db`") {
+		t.Fatalf("progress not edited into the chat: %+v", bot.sent)
+	}
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"nasbot/internal/format"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// Without the event stream, restart counts are read with docker inspect
+	// at most this often.
+	restartLoopPollInterval = time.Minute
+	restartLoopLogLines     = 10
+	// RestartCount readings are kept this long.
+	restartCountWindow = time.Hour
+)
+
+// recordContainerCrashes counts n unexpected exits of name and sends the
+// restart-loop alert once they exceed the configured limit. It reports
+// whether the container is in a loop, so callers can skip the per-exit
+// DOWN/UP notices.
+func recordContainerCrashes(ctx *AppContext, bot BotAPI, name, exitCode string, n int) bool {
+	cfg := ctx.Config.Docker.RestartLoop
+	if !cfg.Enabled || n <= 0 {
+		return false
+	}
+	window := time.Duration(cfg.WindowMinutes) * time.Minute
+	now := time.Now()
+
+	ctx.Docker.Mu.Lock()
+	initDockerMaps(ctx.Docker)
+	crashes := pruneCrashes(ctx.Docker.Crashes[name], now.Add(-window))
+	for range n {
+		crashes = append(crashes, now)
+	}
+	ctx.Docker.Crashes[name] = crashes
+	alerted := ctx.Docker.RestartLoop[name]
+	looping := alerted || len(crashes) > cfg.MaxRestarts
+	if looping {
+		ctx.Docker.RestartLoop[name] = true
+	}
+	ctx.Docker.Mu.Unlock()
+
+	if looping && !alerted {
+		ctx.State.AddEvent("critical", fmt.Sprintf("🔁 Container restart loop: %s (%d exits in %d min)", name, len(crashes), cfg.WindowMinutes))
+		if !ctx.IsQuietHours() {
+			sendAlert(bot, ctx.Config, AlertTopicWarning, restartLoopMessage(ctx, name, exitCode, len(crashes)))
+		}
+	}
+	return looping
+}
+
+// checkRestartLoops expires old exits, closes loops that have been quiet for
+// a whole window and, while the event stream is down, polls RestartCount.
+func checkRestartLoops(ctx *AppContext, bot BotAPI) {
+	cfg := ctx.Config.Docker.RestartLoop
+	if !cfg.Enabled {
+		return
+	}
+	now := time.Now()
+	cutoff := now.Add(-time.Duration(cfg.WindowMinutes) * time.Minute)
+
+	ctx.Docker.Mu.Lock()
+	initDockerMaps(ctx.Docker)
+	var recovered []string
+	for name, crashes := range ctx.Docker.Crashes {
+		if crashes = pruneCrashes(crashes, cutoff); len(crashes) > 0 {
+			ctx.Docker.Crashes[name] = crashes
+			continue
+		}
+		delete(ctx.Docker.Crashes, name)
+		if ctx.Docker.RestartLoop[name] {
+			delete(ctx.Docker.RestartLoop, name)
+			recovered = append(recovered, name)
+		}
+	}
+	lastPoll := ctx.Docker.RestartCheckTime
+	poll := !ctx.Docker.EventsActive && now.Sub(lastPoll) >= restartLoopPollInterval
+	if poll {
+		ctx.Docker.RestartCheckTime = now
+	}
+	ctx.Docker.Mu.Unlock()
+
+	for _, name := range recovered {
+		ctx.State.AddEvent("info", fmt.Sprintf("✅ Restart loop over: %s", name))
+		if !ctx.IsQuietHours() {
+			msg := fmt.Sprintf("✅ *Restart loop over*\n\n📦 `%s`\n\n_No unexpected exits in the last %d minutes._", name, cfg.WindowMinutes)
+			sendAlert(bot, ctx.Config, AlertTopicInfo, alertMessage(msg))
+		}
+	}
+	if poll {
+		pollRestartCounts(ctx, bot, lastPoll, now)
+	}
+}
+
+// pollRestartCounts compares each container's RestartCount with its value
+// at the previous poll, lastPoll; a container restarted by its policy
+// between two polls looks running at both.
+func pollRestartCounts(ctx *AppContext, bot BotAPI, lastPoll, now time.Time) {
+	containers := getCachedContainerList(ctx)
+	for _, c := range containers {
+		timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		d, err := dockerClient.Inspect(timeoutCtx, c.Name)
+		cancel()
+		if err != nil {
+			slog.Debug("restart loop: inspect failed", "container", c.Name, "err", err)
+			continue
+		}
+
+		ctx.Docker.Mu.Lock()
+		samples := recordRestartCount(ctx.Docker, c.Name, d.RestartCount, now)
+		ctx.Docker.Mu.Unlock()
+
+		// The newest reading up to the previous poll; there is none on the
+		// first poll or after a recreate.
+		i := len(samples) - 1
+		for i >= 0 && samples[i].At.After(lastPoll) {
+			i--
+		}
+		if i < 0 || d.RestartCount <= samples[i].Count {
+			continue
+		}
+		var exitCode string
+		if d.State.ExitCode != 0 {
+			exitCode = strconv.Itoa(d.State.ExitCode)
+		}
+		recordContainerCrashes(ctx, bot, c.Name, exitCode, d.RestartCount-samples[i].Count)
+	}
+}
+
+// recordRestartCount adds a RestartCount reading to the container's
+// history and returns it. Readings older than restartCountWindow are
+// dropped, except the newest of them, which is the baseline for the
+// window. The caller holds d.Mu.
+func recordRestartCount(d *DockerManager, name string, count int, now time.Time) []RestartSample {
+	initDockerMaps(d)
+	samples := d.RestartCounts[name]
+	// A lower count means the container was recreated.
+	if n := len(samples); n > 0 && count < samples[n-1].Count {
+		samples = nil
+	}
+	samples = append(samples, RestartSample{At: now, Count: count})
+	cut := 0
+	for cut+1 < len(samples) && now.Sub(samples[cut+1].At) >= restartCountWindow {
+		cut++
+	}
+	samples = samples[cut:]
+	d.RestartCounts[name] = samples
+	return samples
+}
+
+func pruneCrashes(crashes []time.Time, cutoff time.Time) []time.Time {
+	kept := crashes[:0]
+	for _, t := range crashes {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// restartLoopMessage builds the alert with the last exit code, the tail of
+// the container log and buttons to stop it or open its logs.
+func restartLoopMessage(ctx *AppContext, name, exitCode string, exits int) tgbotapi.MessageConfig {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🔁 *Container restart loop*\n\n📦 `%s`\n", name))
+	b.WriteString(fmt.Sprintf("💥 %d unexpected exits in %d min\n", exits, ctx.Config.Docker.RestartLoop.WindowMinutes))
+	if exitCode != "" {
+		b.WriteString(fmt.Sprintf("🔢 Last exit code: `%s`\n", exitCode))
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	out, err := dockerClient.Logs(timeoutCtx, name, restartLoopLogLines)
+	cancel()
+	if logs := strings.TrimSpace(strings.ToValidUTF8(string(out), "�")); err == nil && logs != "" {
+		logs = format.Tail(logs, 1500)
+		// A fence inside the log would end the block early.
+		logs = strings.ReplaceAll(logs, "```", "'''")
+		b.WriteString(fmt.Sprintf("\n📜 Last log lines:\n```\n%s\n```", logs))
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("stop"), "container_stop_"+name),
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("logs"), "container_logs_"+name),
+		),
+	}
+	if ctx.Config.GeminiAPIKey != "" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🤖 "+ctx.Tr("docker_ai_analyze"), "container_ailog_"+name),
+		))
+	}
+
+	m := alertMessage(b.String())
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return m
+}
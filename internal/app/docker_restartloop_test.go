@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"nasbot/internal/docker"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestRestartLoopFromEvents(t *testing.T) {
+	defer setDockerClient(&fakeDocker{logs: map[string]string{"app": "panic: config missing\n"}})()
+	ctx := newTestAppContext()
+	ctx.Config.Docker.RestartLoop = DockerRestartLoopConfig{Enabled: true, MaxRestarts: 2, WindowMinutes: 10}
+	bot := &fakeBot{}
+
+	for range 4 {
+		handleDockerEvent(ctx, bot, containerEvent("die", "app", map[string]string{"exitCode": "2"}))
+		handleDockerEvent(ctx, bot, containerEvent("start", "app", nil))
+	}
+
+	var loops []tgbotapi.MessageConfig
+	for _, c := range bot.sent {
+		if m, ok := c.(tgbotapi.MessageConfig); ok && strings.Contains(m.Text, "restart loop") {
+			loops = append(loops, m)
+		}
+	}
+	if len(loops) != 1 {
+		t.Fatalf("expected a single loop alert, got %q", sentTexts(bot))
+	}
+	text := loops[0].Text
+	if !strings.Contains(text, "`2`") || !strings.Contains(text, "panic: config missing") {
+		t.Fatalf("loop alert lacks exit code or logs: %q", text)
+	}
+	kb, ok := loops[0].ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	if !ok || *kb.InlineKeyboard[0][0].CallbackData != "container_stop_app" || *kb.InlineKeyboard[0][1].CallbackData != "container_logs_app" {
+		t.Fatalf("unexpected keyboard: %+v", loops[0].ReplyMarkup)
+	}
+	// Two DOWN/UP pairs before the limit, then only the loop alert.
+	if n := len(sentTexts(bot)); n != 5 {
+		t.Fatalf("expected per-exit alerts to stop once looping, got %q", sentTexts(bot))
+	}
+
+	// Once the window has passed without exits the loop is closed.
+	ctx.Docker.Crashes["app"] = []time.Time{time.Now().Add(-time.Hour)}
+	checkRestartLoops(ctx, bot)
+	texts := sentTexts(bot)
+	if !strings.Contains(texts[len(texts)-1], "Restart loop over") || ctx.Docker.RestartLoop["app"] {
+		t.Fatalf("loop not resolved: %q", texts)
+	}
+}
+
+func TestRestartLoopFromRestartCount(t *testing.T) {
+	details := &docker.Details{Name: "x", RestartCount: 5}
+	fake := &fakeDocker{details: map[string]*docker.Details{"x": details}}
+	defer setDockerClient(fake)()
+	ctx := newTestAppContext()
+	ctx.Config.Docker.RestartLoop = DockerRestartLoopConfig{Enabled: true, MaxRestarts: 3, WindowMinutes: 10}
+	bot := &fakeBot{}
+
+	checkRestartLoops(ctx, bot)
+	if len(bot.sent) != 0 || len(ctx.Docker.RestartCounts["x"]) != 1 {
+		t.Fatalf("first poll should only record the baseline: %+v", ctx.Docker.RestartCounts)
+	}
+
+	// A reading taken by docker.container_limits between two polls does
+	// not hide the restarts from the poller.
+	details.RestartCount = 7
+	restartsLastHour(context.Background(), ctx, "x")
+	details.RestartCount = 9
+	details.State.ExitCode = 139
+	pollRestartCounts(ctx, bot, ctx.Docker.RestartCheckTime, time.Now())
+	texts := sentTexts(bot)
+	if len(texts) != 1 || !strings.Contains(texts[0], "4 unexpected exits") || !strings.Contains(texts[0], "`139`") {
+		t.Fatalf("unexpected alerts: %q", texts)
+	}
+
+	// The poller stays idle while the event stream is connected.
+	details.RestartCount = 20
+	ctx.Docker.RestartCheckTime = time.Time{}
+	ctx.Docker.EventsActive = true
+	checkRestartLoops(ctx, bot)
+	if samples := ctx.Docker.RestartCounts["x"]; samples[len(samples)-1].Count != 9 {
+		t.Fatalf("inspect should be skipped while events are active")
+	}
+}
+
+func TestRestartLoopMessageLogs(t *testing.T) {
+	logs := strings.Repeat("è", 1000) + "\n```\npanic: boom\n"
+	defer setDockerClient(&fakeDocker{logs: map[string]string{"app": logs}})()
+	ctx := newTestAppContext()
+
+	text := restartLoopMessage(ctx, "app", "1", 4).Text
+	if !utf8.ValidString(text) || strings.Count(text, "```") != 2 || !strings.Contains(text, "'''\npanic: boom") {
+		t.Fatalf("log block not safe for Telegram:\n%s", text)
+	}
+}
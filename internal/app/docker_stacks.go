@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"nasbot/internal/compose"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleStackCallback handles stack_select_<name>, stack_<action>_<name>
+// and stack_confirm_<name>_<action>.
+func handleStackCallback(ctx *AppContext, bot BotAPI, chatID int64, msgID int, data string) {
+	parts := strings.Split(data, "_")
+	if len(parts) < 3 {
+		return
+	}
+
+	switch action := parts[1]; action {
+	case "select":
+		showStackActions(ctx, bot, chatID, msgID, strings.Join(parts[2:], "_"))
+	case "up", "down", "restart", "pull":
+		confirmStackAction(ctx, bot, chatID, msgID, strings.Join(parts[2:], "_"), action)
+	case "confirm":
+		if len(parts) < 4 {
+			return
+		}
+		executeStackAction(ctx, bot, chatID, msgID, strings.Join(parts[2:len(parts)-1], "_"), parts[len(parts)-1])
+	}
+}
+
+// showStackActions shows a compose project with its containers and the
+// stack-level actions.
+func showStackActions(ctx *AppContext, bot BotAPI, chatID int64, msgID int, name string) {
+	st, ok := compose.Find(getCachedContainerList(ctx), name)
+	if !ok {
+		kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("back"), "show_docker"),
+		))
+		editMessage(bot, chatID, msgID, ctx.Tr("stack_not_found"), &kb)
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(ctx.Tr("stack_title"), st.Name))
+	b.WriteString(fmt.Sprintf(ctx.Tr("stack_state"), st.Icon(), st.State(), st.Running(), len(st.Containers)))
+	if st.WorkingDir != "" {
+		b.WriteString(fmt.Sprintf(ctx.Tr("stack_dir"), st.WorkingDir))
+	}
+	b.WriteString("\n")
+	for _, c := range st.Containers {
+		b.WriteString(containerMenuLine(c))
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("stack_up"), "stack_up_"+st.Name),
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("stack_down"), "stack_down_"+st.Name),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("stack_restart"), "stack_restart_"+st.Name),
+			tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("stack_pull"), "stack_pull_"+st.Name),
+		),
+	}
+	for i := 0; i < len(st.Containers); i += 2 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, c := range st.Containers[i:min(i+2, len(st.Containers))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("📦 "+truncate(c.Name, 12), "container_select_"+c.Name))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("back"), "show_docker"),
+	))
+
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	editMessage(bot, chatID, msgID, b.String(), &kb)
+}
+
+// confirmStackAction asks for confirmation before a stack action.
+func confirmStackAction(ctx *AppContext, bot BotAPI, chatID int64, msgID int, name, action string) {
+	text := fmt.Sprintf(ctx.Tr("stack_confirm"), action, name)
+	if action == "down" {
+		text += ctx.Tr("stack_down_warn")
+	}
+	kb := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ "+ctx.Tr("yes"), fmt.Sprintf("stack_confirm_%s_%s", name, action)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ "+ctx.Tr("no"), "stack_select_"+name),
+		),
+	)
+	editMessage(bot, chatID, msgID, text, &kb)
+}
+
+// executeStackAction runs `docker compose <action>` for the project from the
+// working directory recorded in its labels.
+func executeStackAction(ctx *AppContext, bot BotAPI, chatID int64, msgID int, name, action string) {
+	st, ok := compose.Find(getCachedContainerList(ctx), name)
+	if !ok {
+		editMessage(bot, chatID, msgID, ctx.Tr("stack_not_found"), nil)
+		return
+	}
+	args, err := compose.Args(st, action)
+	if err != nil {
+		editMessage(bot, chatID, msgID, fmt.Sprintf(ctx.Tr("stack_err"), name, action, err), nil)
+		return
+	}
+
+	editMessage(bot, chatID, msgID, fmt.Sprintf(ctx.Tr("stack_running"), action, name), nil)
+
+	timeout := 3 * time.Minute
+	if action == "up" || action == "pull" {
+		timeout = 15 * time.Minute
+	}
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	out, err := runCommandOutput(timeoutCtx, containerRuntime.Binary, args...)
+	cancel()
+
+	ctx.Docker.Mu.Lock()
+	ctx.Docker.Cache.LastUpdate = time.Time{}
+	ctx.Docker.Mu.Unlock()
+
+	var text string
+	if err != nil {
+		slog.Error("docker compose failed", "stack", name, "action", action, "err", err)
+		text = fmt.Sprintf(ctx.Tr("stack_err"), name, action, err)
+		ctx.State.AddEvent("warning", fmt.Sprintf("Stack %s %s failed: %v", action, name, err))
+	} else {
+		text = fmt.Sprintf(ctx.Tr("stack_ok"), name, action)
+		ctx.State.AddEvent("action", fmt.Sprintf("Stack %s: %s", action, name))
+	}
+	if output := strings.TrimSpace(string(out)); output != "" {
+		if len(output) > 1500 {
+			output = output[len(output)-1500:]
+		}
+		text += fmt.Sprintf("\n```\n%s\n```", output)
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	if action != "down" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📚 "+name, "stack_select_"+name),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🐳 "+ctx.Tr("docker_menu_home_containers"), "show_docker"),
+	))
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	editMessage(bot, chatID, msgID, text, &kb)
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"nasbot/internal/compose"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func stackContainer(name, project string, running bool) ContainerInfo {
+	return ContainerInfo{Name: name, Running: running, Status: "Up 2 hours", Labels: map[string]string{
+		compose.LabelProject:     project,
+		compose.LabelWorkingDir:  "/srv/" + project,
+		compose.LabelConfigFiles: "/srv/" + project + "/compose.yml",
+	}}
+}
+
+func TestDockerMenuGroupsStacks(t *testing.T) {
+	ctx := newTestAppContext()
+	ctx.Docker.Cache.Containers = []ContainerInfo{
+		stackContainer("jellyfin", "media", true),
+		stackContainer("sonarr", "media", false),
+		{Name: "portainer", Running: true, Status: "Up 1 hour"},
+	}
+
+	text, kb := getDockerMenuText(ctx)
+	if !strings.Contains(text, "📚 *media* ⚠️ 1/2") || !strings.Contains(text, "Standalone") {
+		t.Fatalf("menu not grouped by stack: %q", text)
+	}
+	if !slices.Contains(keyboardData(kb), "dmenu_0_stacks") {
+		t.Fatalf("no stack filter in the menu")
+	}
+	_, kb = dockerMenuPage(ctx, 0, menuFilterStacks)
+	if !slices.Contains(keyboardData(kb), "dmenu_0_s:media") {
+		t.Fatalf("media missing from the stack picker: %v", keyboardData(kb))
+	}
+	text, kb = dockerMenuPage(ctx, 0, "s:media")
+	if strings.Contains(text, "portainer") || !slices.Contains(keyboardData(kb), "stack_select_media") {
+		t.Fatalf("no stack button in the filtered menu: %q", text)
+	}
+}
+
+func TestStackActionRunsCompose(t *testing.T) {
+	runner := &recordingRunner{mockRunner: mockRunner{exists: true, out: []byte("Container media-jellyfin-1  Started")}}
+	defer setCommandRunner(runner)()
+	ctx := newTestAppContext()
+	ctx.Docker.Cache.Containers = []ContainerInfo{stackContainer("jellyfin_1", "my_media", true)}
+	bot := &fakeBot{}
+
+	handleStackCallback(ctx, bot, 1, 10, "stack_restart_my_media")
+	confirm := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig)
+	if *confirm.ReplyMarkup.InlineKeyboard[0][0].CallbackData != "stack_confirm_my_media_restart" {
+		t.Fatalf("unexpected confirmation: %+v", confirm.ReplyMarkup)
+	}
+
+	handleStackCallback(ctx, bot, 1, 10, "stack_confirm_my_media_restart")
+	want := "docker compose -p my_media --project-directory /srv/my_media -f /srv/my_media/compose.yml restart"
+	if len(runner.calls) != 1 || runner.calls[0] != want {
+		t.Fatalf("calls = %q", runner.calls)
+	}
+	last := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig)
+	if !strings.Contains(last.Text, "✅") || !strings.Contains(last.Text, "Started") {
+		t.Fatalf("unexpected result: %q", last.Text)
+	}
+	if !ctx.Docker.Cache.LastUpdate.Equal(time.Time{}) {
+		t.Fatalf("container cache not invalidated")
+	}
+}
@@ -0,0 +1,390 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"nasbot/internal/docker"
+	"nasbot/internal/format"
+	"nasbot/internal/registry"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	startupImageCheck  = 10 * time.Minute
+	defaultImageCheck  = 12 * time.Hour
+	imageUpdateTimeout = 20 * time.Minute
+	rollbackTagPrefix  = "nasbot-rollback-"
+	imageCheckTimeout  = 30 * time.Second
+)
+
+// updateHealthPoll is how often a recreated container is inspected during
+// the rollback grace period.
+var updateHealthPoll = 5 * time.Second
+
+// imageRegistry resolves image tags to registry digests. Swapped in tests.
+var imageRegistry registry.Client = registry.New(nil)
+
+func setImageRegistry(c registry.Client) (restore func()) {
+	prev := imageRegistry
+	imageRegistry = c
+	return func() { imageRegistry = prev }
+}
+
+// configureImageRegistry applies the insecure registries from cfg.
+func configureImageRegistry(cfg *Config) {
+	imageRegistry = registry.New(cfg.Docker.Updates.InsecureRegistries)
+}
+
+// imageUpdateChecker compares container images with their registries
+// every check_interval_hours.
+func imageUpdateChecker(ctx *AppContext, bot BotAPI, runCtx context.Context) {
+	if !sleepWithContext(runCtx, startupImageCheck) {
+		return
+	}
+	for {
+		if ctx.Config.Docker.Updates.Enabled {
+			checkImageUpdates(ctx, bot, runCtx)
+		}
+		interval := defaultImageCheck
+		if h := ctx.Config.Docker.Updates.CheckIntervalHours; h > 0 {
+			interval = time.Duration(h) * time.Hour
+		}
+		if !sleepWithContext(runCtx, interval) {
+			return
+		}
+	}
+}
+
+// checkImageUpdates refreshes ctx.Docker.Updates and notifies about
+// containers that became outdated since the previous check.
+func checkImageUpdates(ctx *AppContext, bot BotAPI, runCtx context.Context) {
+	remote := make(map[string]remoteDigest)
+	results := make(map[string]ImageUpdate)
+	for _, c := range getCachedContainerList(ctx) {
+		if runCtx.Err() != nil {
+			return
+		}
+		results[c.Name] = imageUpdateFor(runCtx, c.Name, remote)
+	}
+
+	ctx.Docker.Mu.Lock()
+	initDockerMaps(ctx.Docker)
+	var fresh []string
+	for name, u := range results {
+		prev := ctx.Docker.Updates[name]
+		if u.Outdated && (!prev.Outdated || prev.Remote != u.Remote) {
+			fresh = append(fresh, name)
+		}
+	}
+	ctx.Docker.Updates = results
+	ctx.Docker.UpdatesChecked = time.Now()
+	ctx.Docker.Mu.Unlock()
+
+	if len(fresh) == 0 {
+		return
+	}
+	sort.Strings(fresh)
+	ctx.State.AddEvent("info", "🆕 Image updates available: "+strings.Join(fresh, ", "))
+	if ctx.IsQuietHours() {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(ctx.Tr("dupdates_alert"))
+	for _, name := range fresh {
+		b.WriteString(fmt.Sprintf("⬆️ `%s` — `%s`\n", name, results[name].Image))
+	}
+	m := alertMessage(b.String())
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("dupdates_open"), "dupdate_list"),
+	))
+	sendAlert(bot, ctx.Config, AlertTopicInfo, m)
+}
+
+type remoteDigest struct {
+	digest string
+	err    error
+}
+
+// imageUpdateFor compares the image the container runs with the digest its
+// registry serves for the same reference. remote caches lookups per
+// reference across containers.
+func imageUpdateFor(runCtx context.Context, name string, remote map[string]remoteDigest) ImageUpdate {
+	u := ImageUpdate{Checked: time.Now()}
+	opCtx, cancel := context.WithTimeout(runCtx, imageCheckTimeout)
+	defer cancel()
+
+	d, err := dockerClient.Inspect(opCtx, name)
+	if err != nil {
+		u.Err = err.Error()
+		return u
+	}
+	u.Image = d.Config.Image
+	if _, err := registry.ParseReference(u.Image); errors.Is(err, registry.ErrPinned) {
+		return u
+	}
+	img, err := dockerClient.ImageInspect(opCtx, d.Image)
+	if err != nil {
+		u.Err = err.Error()
+		return u
+	}
+	local := repoDigests(img)
+	if len(local) == 0 {
+		u.Err = "image has no registry digest (built locally?)"
+		return u
+	}
+	u.Local = local[0]
+
+	r, ok := remote[u.Image]
+	if !ok {
+		r.digest, r.err = imageRegistry.Digest(opCtx, u.Image)
+		remote[u.Image] = r
+	}
+	if r.err != nil {
+		u.Err = r.err.Error()
+		return u
+	}
+	u.Remote = r.digest
+	u.Outdated = true
+	for _, l := range local {
+		if l == r.digest {
+			u.Outdated = false
+		}
+	}
+	return u
+}
+
+// repoDigests returns the "sha256:..." part of the image's RepoDigests.
+func repoDigests(img *docker.Image) []string {
+	var out []string
+	for _, rd := range img.RepoDigests {
+		if _, digest, ok := strings.Cut(rd, "@"); ok {
+			out = append(out, digest)
+		}
+	}
+	return out
+}
+
+// handleImageUpdateCallback handles dupdate_list, dupdate_check,
+// dupdate_ask_<name> and dupdate_go_<name>.
+func handleImageUpdateCallback(ctx *AppContext, bot BotAPI, chatID int64, msgID int, data string) {
+	switch {
+	case data == "dupdate_list":
+		showImageUpdates(ctx, bot, chatID, msgID)
+	case data == "dupdate_check":
+		editMessage(bot, chatID, msgID, ctx.Tr("dupdates_checking"), nil)
+		checkImageUpdates(ctx, bot, context.Background())
+		showImageUpdates(ctx, bot, chatID, msgID)
+	case strings.HasPrefix(data, "dupdate_ask_"):
+		name := strings.TrimPrefix(data, "dupdate_ask_")
+		kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ "+ctx.Tr("yes"), "dupdate_go_"+name),
+			tgbotapi.NewInlineKeyboardButtonData("❌ "+ctx.Tr("no"), "dupdate_list"),
+		))
+		editMessage(bot, chatID, msgID, fmt.Sprintf(ctx.Tr("dupdates_confirm"), name, ctx.Config.Docker.Updates.RollbackGraceSeconds), &kb)
+	case strings.HasPrefix(data, "dupdate_go_"):
+		runImageUpdate(ctx, bot, chatID, msgID, strings.TrimPrefix(data, "dupdate_go_"))
+	}
+}
+
+// handleImageUpdatesCommand sends the /dupdates view.
+func handleImageUpdatesCommand(ctx *AppContext, bot BotAPI, chatID int64) {
+	showImageUpdates(ctx, bot, chatID, 0)
+}
+
+// showImageUpdates lists the outdated containers from the last check,
+// editing msgID or sending a new message when it is 0.
+func showImageUpdates(ctx *AppContext, bot BotAPI, chatID int64, msgID int) {
+	ctx.Docker.Mu.RLock()
+	checked := ctx.Docker.UpdatesChecked
+	var outdated []string
+	images := make(map[string]string)
+	failed := 0
+	for name, u := range ctx.Docker.Updates {
+		if u.Outdated {
+			outdated = append(outdated, name)
+			images[name] = u.Image
+		} else if u.Err != "" {
+			failed++
+		}
+	}
+	ctx.Docker.Mu.RUnlock()
+	sort.Strings(outdated)
+
+	var b strings.Builder
+	b.WriteString(ctx.Tr("dupdates_title"))
+	switch {
+	case checked.IsZero():
+		b.WriteString(ctx.Tr("dupdates_never"))
+	case len(outdated) == 0:
+		b.WriteString(fmt.Sprintf(ctx.Tr("dupdates_checked"), format.FormatDuration(time.Since(checked))))
+		b.WriteString(ctx.Tr("dupdates_none"))
+	default:
+		b.WriteString(fmt.Sprintf(ctx.Tr("dupdates_checked"), format.FormatDuration(time.Since(checked))))
+		for _, name := range outdated {
+			b.WriteString(fmt.Sprintf("⬆️ `%s` — `%s`\n", name, images[name]))
+		}
+	}
+	if failed > 0 {
+		b.WriteString(fmt.Sprintf(ctx.Tr("dupdates_failed"), failed))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, name := range outdated {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬆️ "+truncate(name, 20), "dupdate_ask_"+name),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("dupdates_check"), "dupdate_check"),
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("back"), "show_docker"),
+	))
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	if msgID != 0 {
+		editMessage(bot, chatID, msgID, b.String(), &kb)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = kb
+	safeSend(bot, msg)
+}
+
+// runImageUpdate updates one container from the chat, reporting progress
+// in msgID.
+func runImageUpdate(ctx *AppContext, bot BotAPI, chatID int64, msgID int, name string) {
+	ctx.Docker.Mu.Lock()
+	initDockerMaps(ctx.Docker)
+	busy := ctx.Docker.Updating[name]
+	ctx.Docker.Updating[name] = true
+	ctx.Docker.Mu.Unlock()
+	if busy {
+		editMessage(bot, chatID, msgID, fmt.Sprintf(ctx.Tr("dupdates_busy"), name), nil)
+		return
+	}
+	defer func() {
+		ctx.Docker.Mu.Lock()
+		delete(ctx.Docker.Updating, name)
+		ctx.Docker.Cache.LastUpdate = time.Time{}
+		ctx.Docker.Mu.Unlock()
+	}()
+
+	editMessage(bot, chatID, msgID, fmt.Sprintf(ctx.Tr("dupdates_running"), name), nil)
+	outcome, err := updateContainer(ctx, name)
+
+	var text string
+	switch outcome {
+	case updateCurrent:
+		text = fmt.Sprintf(ctx.Tr("dupdates_current"), name)
+	case updateDone:
+		text = fmt.Sprintf(ctx.Tr("dupdates_ok"), name)
+		ctx.State.AddEvent("action", "Container updated: "+name)
+	case updateRolledBack:
+		text = fmt.Sprintf(ctx.Tr("dupdates_rolled_back"), name, err)
+		ctx.State.AddEvent("warning", fmt.Sprintf("Update of %s rolled back: %v", name, err))
+	default:
+		text = fmt.Sprintf(ctx.Tr("dupdates_err"), name, err)
+		ctx.State.AddEvent("warning", fmt.Sprintf("Update of %s failed: %v", name, err))
+	}
+	if err != nil {
+		slog.Warn("Container update failed", "container", name, "err", err)
+	}
+	if outcome == updateDone || outcome == updateCurrent {
+		ctx.Docker.Mu.Lock()
+		if u, ok := ctx.Docker.Updates[name]; ok {
+			u.Outdated = false
+			ctx.Docker.Updates[name] = u
+		}
+		ctx.Docker.Mu.Unlock()
+	}
+
+	kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(ctx.Tr("dupdates_open"), "dupdate_list"),
+		tgbotapi.NewInlineKeyboardButtonData("📦 "+truncate(name, 16), "container_select_"+name),
+	))
+	editMessage(bot, chatID, msgID, text, &kb)
+}
+
+type updateOutcome int
+
+const (
+	updateFailed updateOutcome = iota
+	updateCurrent
+	updateDone
+	updateRolledBack
+)
+
+// updateContainer pulls the container's image and recreates it when the
+// pull brought a new one. The previous image is then tagged
+// "<repo>:nasbot-rollback-<name>"; if the new container stops or fails its
+// healthcheck within the grace period, the tag is moved back and the
+// container recreated from it. The rollback tag is dropped once the
+// outcome is settled, so the old image does not stay pinned.
+func updateContainer(ctx *AppContext, name string) (updateOutcome, error) {
+	opCtx, cancel := context.WithTimeout(context.Background(), imageUpdateTimeout)
+	defer cancel()
+
+	d, err := dockerClient.Inspect(opCtx, name)
+	if err != nil {
+		return updateFailed, err
+	}
+	ref := d.Config.Image
+	if _, err := registry.ParseReference(ref); err != nil {
+		return updateFailed, err
+	}
+	if err := dockerClient.Pull(opCtx, ref); err != nil {
+		return updateFailed, fmt.Errorf("pull: %w", err)
+	}
+	img, err := dockerClient.ImageInspect(opCtx, ref)
+	if err != nil {
+		return updateFailed, err
+	}
+	if img.ID == d.Image {
+		return updateCurrent, nil
+	}
+	repo, _ := docker.SplitRef(ref)
+	rollback := repo + ":" + rollbackTagPrefix + name
+	if err := dockerClient.Tag(opCtx, d.Image, rollback); err != nil {
+		return updateFailed, fmt.Errorf("tag previous image: %w", err)
+	}
+	defer removeRollbackTag(rollback)
+	// Recreate restores the old container by itself when it fails.
+	if err := dockerClient.Recreate(opCtx, name, ref); err != nil {
+		return updateFailed, fmt.Errorf("recreate: %w", err)
+	}
+	if !d.State.Running {
+		return updateDone, nil
+	}
+
+	grace := time.Duration(ctx.Config.Docker.Updates.RollbackGraceSeconds) * time.Second
+	// A container without a healthcheck has to keep running for the whole
+	// grace period.
+	failure := watchContainer(opCtx, name, grace, updateHealthPoll, true)
+	if failure == nil {
+		return updateDone, nil
+	}
+	if err := dockerClient.Tag(opCtx, rollback, ref); err != nil {
+		return updateFailed, fmt.Errorf("%v; rollback: %w", failure, err)
+	}
+	if err := dockerClient.Recreate(opCtx, name, ref); err != nil {
+		return updateFailed, fmt.Errorf("%v; rollback: %w", failure, err)
+	}
+	return updateRolledBack, failure
+}
+
+// removeRollbackTag drops the tag updateContainer put on the previous
+// image. An image left without tags or containers is deleted with it.
+func removeRollbackTag(tag string) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := dockerClient.RemoveImage(timeoutCtx, tag); err != nil {
+		slog.Warn("Could not remove rollback tag", "tag", tag, "err", err)
+	}
+}
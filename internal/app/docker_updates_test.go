@@ -0,0 +1,158 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"nasbot/internal/docker"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeRegistry serves digests by image reference.
+type fakeRegistry map[string]string
+
+func (r fakeRegistry) Digest(ctx context.Context, ref string) (string, error) {
+	if d, ok := r[ref]; ok {
+		return d, nil
+	}
+	return "", errors.New("manifest unknown")
+}
+
+func containerDetails(name, ref, imageID string, running bool, health string) *docker.Details {
+	d := &docker.Details{Name: name, Image: imageID}
+	d.Config.Image = ref
+	d.State.Running = running
+	if !running {
+		d.State.ExitCode = 1
+	}
+	if health != "" {
+		d.State.Health = &docker.Health{Status: health}
+	}
+	return d
+}
+
+func updatesFixture() (*fakeDocker, *AppContext) {
+	fake := &fakeDocker{
+		details: map[string]*docker.Details{
+			"web": containerDetails("web", "nginx:latest", "sha256:old", true, ""),
+			"db":  containerDetails("db", "postgres@sha256:1111", "sha256:pg", true, ""),
+			"app": containerDetails("app", "local/app", "sha256:app", true, ""),
+		},
+		images: map[string]*docker.Image{
+			"sha256:old": {ID: "sha256:old", RepoDigests: []string{"nginx@sha256:aaaa"}},
+			"sha256:pg":  {ID: "sha256:pg", RepoDigests: []string{"postgres@sha256:1111"}},
+			"sha256:app": {ID: "sha256:app"},
+		},
+	}
+	ctx := newTestAppContext()
+	ctx.Config.Docker.Updates = DockerUpdatesConfig{Enabled: true, RollbackGraceSeconds: 0}
+	ctx.Docker.Cache.Containers = []ContainerInfo{{Name: "web", Running: true}, {Name: "db", Running: true}, {Name: "app", Running: true}}
+	return fake, ctx
+}
+
+func TestCheckImageUpdates(t *testing.T) {
+	fake, ctx := updatesFixture()
+	defer setDockerClient(fake)()
+	defer setImageRegistry(fakeRegistry{"nginx:latest": "sha256:bbbb"})()
+	bot := &fakeBot{}
+
+	checkImageUpdates(ctx, bot, context.Background())
+	web, db, app := ctx.Docker.Updates["web"], ctx.Docker.Updates["db"], ctx.Docker.Updates["app"]
+	if !web.Outdated || web.Local != "sha256:aaaa" || web.Remote != "sha256:bbbb" {
+		t.Fatalf("web = %+v", web)
+	}
+	if db.Outdated || db.Err != "" {
+		t.Fatalf("digest-pinned image must be skipped: %+v", db)
+	}
+	if app.Outdated || app.Err == "" {
+		t.Fatalf("locally built image should report why it was skipped: %+v", app)
+	}
+	texts := sentTexts(bot)
+	if len(texts) != 1 || !strings.Contains(texts[0], "`web`") {
+		t.Fatalf("unexpected alerts: %q", texts)
+	}
+
+	// The same remote digest is not announced twice.
+	checkImageUpdates(ctx, bot, context.Background())
+	if len(sentTexts(bot)) != 1 {
+		t.Fatalf("outdated image notified again: %q", sentTexts(bot))
+	}
+}
+
+func TestUpdateContainer(t *testing.T) {
+	fake, ctx := updatesFixture()
+	defer setDockerClient(fake)()
+	fake.onPull = func(ref string) error {
+		fake.images[ref] = &docker.Image{ID: "sha256:new"}
+		return nil
+	}
+	fake.onRecreate = func(name, image string) error {
+		fake.details[name] = containerDetails(name, image, fake.images[image].ID, true, "healthy")
+		return nil
+	}
+
+	outcome, err := updateContainer(ctx, "web")
+	if outcome != updateDone || err != nil {
+		t.Fatalf("updateContainer = %v, %v", outcome, err)
+	}
+	want := "[pull nginx:latest tag sha256:old nginx:nasbot-rollback-web recreate web nginx:latest rmi nginx:nasbot-rollback-web]"
+	if fmt.Sprint(fake.actions) != want {
+		t.Fatalf("actions = %v", fake.actions)
+	}
+
+	// Pulling the image the container already runs changes nothing.
+	fake.actions = nil
+	if outcome, err := updateContainer(ctx, "web"); outcome != updateCurrent || err != nil {
+		t.Fatalf("second update = %v, %v", outcome, err)
+	}
+	if fmt.Sprint(fake.actions) != "[pull nginx:latest]" {
+		t.Fatalf("up-to-date container should not be tagged or recreated: %v", fake.actions)
+	}
+}
+
+func TestUpdateContainerRollsBack(t *testing.T) {
+	fake, ctx := updatesFixture()
+	defer setDockerClient(fake)()
+	prevPoll := updateHealthPoll
+	updateHealthPoll = time.Millisecond
+	defer func() { updateHealthPoll = prevPoll }()
+	ctx.Config.Docker.Updates.RollbackGraceSeconds = 60
+
+	fake.onPull = func(ref string) error {
+		fake.images[ref] = &docker.Image{ID: "sha256:new"}
+		return nil
+	}
+	fake.onRecreate = func(name, image string) error {
+		id := fake.images[image].ID
+		// The new image fails its healthcheck, the old one is fine.
+		health := "healthy"
+		if id == "sha256:new" {
+			health = "unhealthy"
+		}
+		fake.details[name] = containerDetails(name, image, id, true, health)
+		return nil
+	}
+
+	outcome, err := updateContainer(ctx, "web")
+	if outcome != updateRolledBack || err == nil || !strings.Contains(err.Error(), "healthcheck") {
+		t.Fatalf("updateContainer = %v, %v", outcome, err)
+	}
+	if got := fake.details["web"].Image; got != "sha256:old" {
+		t.Fatalf("container runs %s after rollback", got)
+	}
+	if last := fake.actions[len(fake.actions)-3:]; fmt.Sprint(last) != "[tag nginx:nasbot-rollback-web nginx:latest recreate web nginx:latest rmi nginx:nasbot-rollback-web]" {
+		t.Fatalf("actions = %v", fake.actions)
+	}
+
+	bot := &fakeBot{}
+	runImageUpdate(ctx, bot, 1, 10, "web")
+	last := bot.sent[len(bot.sent)-1].(tgbotapi.EditMessageTextConfig)
+	if !strings.Contains(last.Text, "rolled back") || len(ctx.Docker.Updating) != 0 {
+		t.Fatalf("unexpected result %q, updating %v", last.Text, ctx.Docker.Updating)
+	}
+}
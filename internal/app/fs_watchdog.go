@@ -12,8 +12,6 @@ import (
 	"time"
 
 	"nasbot/internal/format"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // ═══════════════════════════════════════════════════════════════════
@@ -426,11 +424,10 @@ func (w *FSWatchdog) checkAndAlert(bot BotAPI, path string) {
 				"Free: `%.1fGB`\n\n"+
 				"_Monitoring..._", path, usedPercent, freeGB)
 
-			m := tgbotapi.NewMessage(cfg.AllowedUserID, msg)
-			m.ParseMode = "Markdown"
-			if bot != nil {
-				safeSend(bot, m)
-			} else {
+			m := alertMessage(msg)
+			// External channels still work in the standalone build without a bot
+			sendAlert(bot, &cfg, AlertTopicWarning, m)
+			if bot == nil {
 				slog.Info("Watchdog Alert (No Bot)", "msg", msg)
 			}
 		}
@@ -452,11 +449,9 @@ func (w *FSWatchdog) checkAndAlert(bot BotAPI, path string) {
 				"Free: `%.1fGB`\n\n"+
 				"_Starting deep scan to identify large files..._", path, usedPercent, freeGB)
 
-			m := tgbotapi.NewMessage(cfg.AllowedUserID, msg)
-			m.ParseMode = "Markdown"
-			if bot != nil {
-				safeSend(bot, m)
-			} else {
+			m := alertMessage(msg)
+			sendAlert(bot, &cfg, AlertTopicCritical, m)
+			if bot == nil {
 				slog.Info("Watchdog Critical Alert (No Bot)", "msg", msg)
 			}
 		}
@@ -518,11 +513,9 @@ func (w *FSWatchdog) sendDeepScanReport(bot BotAPI, result *DeepScanResult) {
 	}
 
 	if !isQuietHours() {
-		m := tgbotapi.NewMessage(cfg.AllowedUserID, b.String())
-		m.ParseMode = "Markdown"
-		if bot != nil {
-			safeSend(bot, m)
-		} else {
+		m := alertMessage(b.String())
+		sendAlert(bot, &cfg, AlertTopicWarning, m)
+		if bot == nil {
 			slog.Info("Deep Scan Report (No Bot)", "report", b.String())
 		}
 	}
@@ -0,0 +1,239 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"nasbot/internal/chart"
+	"nasbot/internal/history"
+	"nasbot/internal/smart"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ═══════════════════════════════════════════════════════════════════
+//  /graph — PNG charts from the metric history
+// ═══════════════════════════════════════════════════════════════════
+
+var graphRanges = []struct {
+	key  string
+	span time.Duration
+}{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+const (
+	defaultGraphRange  = "24h"
+	maxGraphContainers = 6
+)
+
+var graphMetrics = []string{"cpu", "ram", "swap", "disk", "net", "io", "load", "temp", "smart", "containers"}
+
+func graphSpan(key string) (time.Duration, bool) {
+	for _, r := range graphRanges {
+		if r.key == key {
+			return r.span, true
+		}
+	}
+	return 0, false
+}
+
+// handleGraphCommand implements /graph <metric> [range].
+func handleGraphCommand(ctx *AppContext, bot BotAPI, chatID int64, args string) {
+	fields := strings.Fields(strings.ToLower(args))
+	if len(fields) == 0 {
+		sendMarkdown(bot, chatID, fmt.Sprintf(ctx.Tr("graph_usage"), "`"+strings.Join(graphMetrics, "`, `")+"`"))
+		return
+	}
+	rangeKey := defaultGraphRange
+	if len(fields) > 1 {
+		rangeKey = fields[1]
+	}
+	sendGraph(ctx, bot, chatID, 0, fields[0], rangeKey)
+}
+
+// handleGraphCallback handles "graph_<metric>_<range>". Buttons on a chart
+// replace the picture in place; the status keyboard sends a new one.
+func handleGraphCallback(ctx *AppContext, bot BotAPI, chatID int64, msgID int, query *tgbotapi.CallbackQuery, data string) bool {
+	metric, rangeKey, ok := strings.Cut(strings.TrimPrefix(data, "graph_"), "_")
+	if !ok {
+		return false
+	}
+	if query == nil || query.Message == nil || len(query.Message.Photo) == 0 {
+		msgID = 0
+	}
+	sendGraph(ctx, bot, chatID, msgID, metric, rangeKey)
+	return true
+}
+
+// sendGraph renders metric over rangeKey and sends it as a photo, or edits
+// the photo in msgID when non-zero.
+func sendGraph(ctx *AppContext, bot BotAPI, chatID int64, msgID int, metric, rangeKey string) {
+	span, ok := graphSpan(rangeKey)
+	if !ok {
+		rangeKey = defaultGraphRange
+		span, _ = graphSpan(rangeKey)
+	}
+	if !slices.Contains(graphMetrics, metric) {
+		sendMarkdown(bot, chatID, fmt.Sprintf(ctx.Tr("graph_unknown"), metric, "`"+strings.Join(graphMetrics, "`, `")+"`"))
+		return
+	}
+	if ctx.History == nil {
+		sendMarkdown(bot, chatID, ctx.Tr("graph_no_history"))
+		return
+	}
+
+	now := time.Now()
+	c := buildGraph(ctx, metric, now.Add(-span), now)
+	c.Title = fmt.Sprintf("%s - %s", ctx.Tr("graph_title_"+metric), rangeKey)
+	img, err := c.PNG()
+	if errors.Is(err, chart.ErrNoData) {
+		sendMarkdown(bot, chatID, fmt.Sprintf(ctx.Tr("graph_no_data"), ctx.Tr("graph_title_"+metric), rangeKey))
+		return
+	}
+	if err != nil {
+		sendMarkdown(bot, chatID, fmt.Sprintf("❌ %v", err))
+		return
+	}
+
+	file := tgbotapi.FileBytes{Name: metric + ".png", Bytes: img}
+	caption := fmt.Sprintf("📈 *%s* — %s", ctx.Tr("graph_title_"+metric), rangeKey)
+	kb := graphKeyboard(metric, rangeKey)
+	if msgID == 0 {
+		photo := tgbotapi.NewPhoto(chatID, file)
+		photo.Caption = caption
+		photo.ParseMode = "Markdown"
+		photo.ReplyMarkup = kb
+		safeSend(bot, photo)
+		return
+	}
+
+	media := tgbotapi.NewInputMediaPhoto(file)
+	media.Caption = caption
+	media.ParseMode = "Markdown"
+	safeSend(bot, tgbotapi.EditMessageMediaConfig{
+		BaseEdit: tgbotapi.BaseEdit{ChatID: chatID, MessageID: msgID, ReplyMarkup: &kb},
+		Media:    media,
+	})
+}
+
+func graphKeyboard(metric, rangeKey string) tgbotapi.InlineKeyboardMarkup {
+	var ranges []tgbotapi.InlineKeyboardButton
+	for _, r := range graphRanges {
+		label := r.key
+		if r.key == rangeKey {
+			label = "• " + label
+		}
+		ranges = append(ranges, tgbotapi.NewInlineKeyboardButtonData(label, "graph_"+metric+"_"+r.key))
+	}
+	btn := func(label, m string) tgbotapi.InlineKeyboardButton {
+		if m == metric {
+			label = "• " + label
+		}
+		return tgbotapi.NewInlineKeyboardButtonData(label, "graph_"+m+"_"+rangeKey)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(
+		ranges,
+		tgbotapi.NewInlineKeyboardRow(btn("CPU", "cpu"), btn("RAM", "ram"), btn("Disk", "disk"), btn("Net", "net")),
+		tgbotapi.NewInlineKeyboardRow(btn("🌡", "temp"), btn("I/O", "io"), btn("Load", "load"), btn("🐳", "containers")),
+	)
+}
+
+// buildGraph collects the series of metric between from and to.
+func buildGraph(ctx *AppContext, metric string, from, to time.Time) chart.Chart {
+	h := ctx.History
+	c := chart.Chart{Location: ctx.State.TimeLocation}
+	add := func(name, key string) {
+		if pts := h.Range(key, from, to); len(pts) > 0 {
+			c.Series = append(c.Series, chart.Series{Name: name, Points: chartPoints(pts)})
+		}
+	}
+	percent := func() { c.YMin, c.YMax = 0, 100 }
+
+	switch metric {
+	case "cpu":
+		add("CPU", HistoryCPU)
+		percent()
+	case "ram":
+		add("RAM", HistoryRAM)
+		percent()
+	case "swap":
+		add("Swap", HistorySwap)
+		percent()
+	case "disk":
+		for _, key := range historyMetricsWithPrefix(h, HistoryVolumeUsed("")) {
+			add(strings.TrimPrefix(key, HistoryVolumeUsed("")), key)
+		}
+		percent()
+	case "net":
+		add("RX", HistoryNetRxMbps)
+		add("TX", HistoryNetTxMbps)
+		c.FromZero = true
+	case "io":
+		add("Read", HistoryDiskReadMBs)
+		add("Write", HistoryDiskWriteMBs)
+		c.FromZero = true
+	case "load":
+		add("1m", HistoryLoad1m)
+		add("5m", HistoryLoad5m)
+		add("15m", HistoryLoad15m)
+		c.FromZero = true
+	case "temp":
+		for _, key := range historyMetricsWithPrefix(h, HistoryTemp("")) {
+			add(strings.TrimPrefix(key, HistoryTemp("")), key)
+		}
+	case "smart":
+		c.FromZero = true
+		for _, name := range smart.CounterNames {
+			prefix := HistorySmart("", name)
+			for _, key := range historyMetricsWithPrefix(h, prefix) {
+				add(strings.TrimPrefix(key, prefix)+" "+strings.ReplaceAll(name, "_", " "), key)
+			}
+		}
+	case "containers":
+		c.FromZero = true
+		type latest struct {
+			key string
+			val float64
+		}
+		var all []latest
+		for _, key := range historyMetricsWithPrefix(h, HistoryContainerMem("")) {
+			if pts := h.Range(key, from, to); len(pts) > 0 {
+				all = append(all, latest{key, pts[len(pts)-1].Avg})
+			}
+		}
+		sort.Slice(all, func(i, j int) bool { return all[i].val > all[j].val })
+		if len(all) > maxGraphContainers {
+			all = all[:maxGraphContainers]
+		}
+		for _, l := range all {
+			add(strings.TrimPrefix(l.key, HistoryContainerMem("")), l.key)
+		}
+	}
+	return c
+}
+
+func historyMetricsWithPrefix(h *history.Store, prefix string) []string {
+	var out []string
+	for _, m := range h.Metrics() {
+		if strings.HasPrefix(m, prefix) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func chartPoints(pts []history.Point) []chart.Point {
+	out := make([]chart.Point, len(pts))
+	for i, p := range pts {
+		out[i] = chart.Point{Time: p.Time, Value: p.Avg}
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"nasbot/internal/docker"
+	"nasbot/internal/history"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func newGraphTestContext() *AppContext {
+	ctx := newTestAppContext()
+	ctx.History = history.New(historyTiers(HistoryConfig{RawRetentionHours: 24, FiveMinRetentionDays: 7, HourlyRetentionDays: 90, DailyRetentionDays: 730}))
+	now := time.Now()
+	for i := 120; i >= 0; i-- {
+		ctx.History.Add(now.Add(-time.Duration(i)*time.Minute), map[string]float64{
+			HistoryCPU:                     float64(i % 100),
+			HistoryVolumeUsed("/Volume1"):  40,
+			HistoryVolumeUsed("/mnt/data"): 60,
+			HistoryContainerMem("db"):      512,
+		})
+	}
+	return ctx
+}
+
+func TestGraphCommandSendsPhoto(t *testing.T) {
+	ctx := newGraphTestContext()
+	bot := &fakeBot{}
+
+	handleGraphCommand(ctx, bot, 1, "cpu 1h")
+	if len(bot.sent) != 1 {
+		t.Fatalf("expected one message, got %d", len(bot.sent))
+	}
+	photo, ok := bot.sent[0].(tgbotapi.PhotoConfig)
+	if !ok {
+		t.Fatalf("expected a photo, got %T", bot.sent[0])
+	}
+	file, ok := photo.File.(tgbotapi.FileBytes)
+	if !ok || len(file.Bytes) < 8 || string(file.Bytes[1:4]) != "PNG" {
+		t.Fatalf("photo is not a PNG")
+	}
+	kb, ok := photo.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	if !ok || *kb.InlineKeyboard[0][0].CallbackData != "graph_cpu_1h" || kb.InlineKeyboard[0][0].Text != "• 1h" {
+		t.Fatalf("unexpected keyboard: %+v", photo.ReplyMarkup)
+	}
+}
+
+func TestGraphCallbackEditsPhotoInPlace(t *testing.T) {
+	ctx := newGraphTestContext()
+	bot := &fakeBot{}
+	query := &tgbotapi.CallbackQuery{Message: &tgbotapi.Message{Photo: []tgbotapi.PhotoSize{{FileID: "x"}}}}
+
+	if !handleGraphCallback(ctx, bot, 1, 42, query, "graph_disk_24h") {
+		t.Fatalf("callback not handled")
+	}
+	edit, ok := bot.sent[0].(tgbotapi.EditMessageMediaConfig)
+	if !ok || edit.MessageID != 42 {
+		t.Fatalf("expected a media edit of message 42, got %T", bot.sent[0])
+	}
+
+	// From the text status keyboard a new photo is sent instead.
+	handleGraphCallback(ctx, bot, 1, 7, &tgbotapi.CallbackQuery{Message: &tgbotapi.Message{Text: "status"}}, "graph_cpu_24h")
+	if _, ok := bot.sent[1].(tgbotapi.PhotoConfig); !ok {
+		t.Fatalf("expected a new photo, got %T", bot.sent[1])
+	}
+}
+
+func TestGraphMessages(t *testing.T) {
+	bot := &fakeBot{}
+	ctx := newTestAppContext()
+
+	handleGraphCommand(ctx, bot, 1, "cpu")
+	if msg := bot.sent[0].(tgbotapi.MessageConfig); msg.Text != ctx.Tr("graph_no_history") {
+		t.Fatalf("expected no-history message, got %q", msg.Text)
+	}
+
+	ctx = newGraphTestContext()
+	handleGraphCommand(ctx, bot, 1, "swap")
+	if _, ok := bot.sent[1].(tgbotapi.MessageConfig); !ok {
+		t.Fatalf("expected a no-data message for an empty metric, got %T", bot.sent[1])
+	}
+	handleGraphCommand(ctx, bot, 1, "bogus")
+	if _, ok := bot.sent[2].(tgbotapi.MessageConfig); !ok {
+		t.Fatalf("expected an unknown-metric message, got %T", bot.sent[2])
+	}
+}
+
+func TestBuildGraphSeries(t *testing.T) {
+	ctx := newGraphTestContext()
+	now := time.Now()
+
+	disk := buildGraph(ctx, "disk", now.Add(-time.Hour), now)
+	if len(disk.Series) != 2 || disk.Series[0].Name != "/Volume1" || disk.YMax != 100 {
+		t.Fatalf("unexpected disk chart: %+v", disk.Series)
+	}
+	mem := buildGraph(ctx, "containers", now.Add(-time.Hour), now)
+	if len(mem.Series) != 1 || mem.Series[0].Name != "db" {
+		t.Fatalf("unexpected container chart: %+v", mem.Series)
+	}
+}
+
+func TestRecordContainerMemory(t *testing.T) {
+	ctx := newGraphTestContext()
+	defer setDockerClient(&fakeDocker{stats: []docker.Stats{
+		{Name: "web", MemUsage: 128 << 20},
+		{Name: "redis", MemUsage: 1536 << 20},
+	}})()
+
+	now := time.Now()
+	recordContainerMemory(ctx, now)
+	pts := ctx.History.Range(HistoryContainerMem("redis"), now.Add(-time.Minute), now)
+	if len(pts) != 1 || pts[0].Avg != 1536 {
+		t.Fatalf("redis memory not recorded: %+v", pts)
+	}
+}
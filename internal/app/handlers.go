@@ -37,6 +37,10 @@ func handleMessage(bot BotAPI, msg *tgbotapi.Message) {
 	}
 
 	action := app.Bot.GetPendingAction()
+	if action != "" && app.Config.UserRole(messageSenderID(msg)) != RoleAdmin {
+		// Pending actions come from admin-only settings menus
+		return
+	}
 	if action == "add_report_time" {
 		app.Bot.ClearPendingAction()
 
@@ -198,7 +202,7 @@ func handleCallback(bot BotAPI, query *tgbotapi.CallbackQuery) {
 	if _, err := bot.Request(tgbotapi.NewCallback(query.ID, "")); err != nil {
 		slog.Warn("Failed to acknowledge callback", "err", err)
 	}
-	if query.From == nil || query.From.ID != int64(app.Config.AllowedUserID) {
+	if query.From == nil || !app.Config.IsAuthorized(query.From.ID) {
 		slog.Warn("Unauthorized callback ignored")
 		return
 	}
@@ -133,9 +133,8 @@ func recordHealthcheckSuccess(ctx *AppContext, bot BotAPI) {
 				downtimeStr,
 				totalPings,
 				periodStr)
-			m := tgbotapi.NewMessage(ctx.Config.AllowedUserID, msg)
-			m.ParseMode = "Markdown"
-			safeSend(bot, m)
+			m := alertMessage(msg)
+			sendAlert(bot, ctx.Config, AlertTopicInfo, m)
 		}
 	}
 
@@ -206,9 +205,8 @@ func recordHealthcheckFailure(ctx *AppContext, bot BotAPI, reason string) {
 				periodStr,
 				totalPings,
 				lastPingAgo)
-			m := tgbotapi.NewMessage(ctx.Config.AllowedUserID, msg)
-			m.ParseMode = "Markdown"
-			safeSend(bot, m)
+			m := alertMessage(msg)
+			sendAlert(bot, ctx.Config, AlertTopicCritical, m)
 		}
 	} else {
 		// ALREADY IN DOWNTIME - check for force reboot timeout (6 minutes)
@@ -230,9 +228,8 @@ func recordHealthcheckFailure(ctx *AppContext, bot BotAPI, reason string) {
 	if shouldForceReboot {
 		slog.Error("Healthchecks down > 6 minutes. Triggering forced reboot!")
 		msg := fmt.Sprintf("💥 *Force Reboot Triggered*\n\nHealthchecks.io down for %s. Executing panic reboot.", downtimeStr)
-		m := tgbotapi.NewMessage(ctx.Config.AllowedUserID, msg)
-		m.ParseMode = "Markdown"
-		safeSend(bot, m)
+		m := alertMessage(msg)
+		sendAlert(bot, ctx.Config, AlertTopicCritical, m)
 		ctx.State.AddEvent("critical", fmt.Sprintf("Forced reboot triggered: Healthchecks down for %s", downtimeStr))
 		saveState(ctx)
 
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"nasbot/internal/history"
+)
+
+// ═══════════════════════════════════════════════════════════════════
+//  Persistent metric history
+// ═══════════════════════════════════════════════════════════════════
+
+func historyFilePath() string {
+	if p := os.Getenv("NASBOT_HISTORY_FILE"); p != "" {
+		return p
+	}
+	return filepath.Join("var", "nasbot_history.gob")
+}
+
+func historyTiers(h HistoryConfig) []history.Tier {
+	day := 24 * time.Hour
+	return []history.Tier{
+		{Step: 0, Retention: time.Duration(h.RawRetentionHours) * time.Hour},
+		{Step: 5 * time.Minute, Retention: time.Duration(h.FiveMinRetentionDays) * day},
+		{Step: time.Hour, Retention: time.Duration(h.HourlyRetentionDays) * day},
+		{Step: day, Retention: time.Duration(h.DailyRetentionDays) * day},
+	}
+}
+
+// initHistory loads the metric history from disk and seeds the in-memory
+// CPU/RAM trend from it, so mini graphs are not empty after a restart.
+func initHistory(ctx *AppContext) {
+	if !ctx.Config.History.Enabled {
+		return
+	}
+	store := history.New(historyTiers(ctx.Config.History))
+	now := time.Now()
+	if err := store.Load(historyFilePath(), now); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("History load error, starting empty", "err", err)
+		store = history.New(historyTiers(ctx.Config.History))
+	}
+	ctx.History = store
+
+	cpu := store.RangeStep(HistoryCPU, 5*time.Minute, now.Add(-6*time.Hour), now)
+	ram := store.RangeStep(HistoryRAM, 5*time.Minute, now.Add(-6*time.Hour), now)
+	ctx.Monitor.Mu.Lock()
+	ctx.Monitor.CPUTrend = historyTrend(cpu)
+	ctx.Monitor.RAMTrend = historyTrend(ram)
+	ctx.Monitor.Mu.Unlock()
+	slog.Info("History loaded", "metrics", len(store.Metrics()))
+}
+
+func historyTrend(points []history.Point) []TrendPoint {
+	out := make([]TrendPoint, 0, 72)
+	for _, p := range points {
+		out = append(out, TrendPoint{Time: p.Time, Value: p.Avg})
+	}
+	return out
+}
+
+// historyValues flattens the current stats into history metrics.
+func historyValues(ctx *AppContext, s Stats) map[string]float64 {
+	v := map[string]float64{
+		HistoryCPU:          s.CPU,
+		HistoryRAM:          s.RAM,
+		HistorySwap:         s.Swap,
+		HistoryRAMFreeMB:    float64(s.RAMFreeMB),
+		HistoryLoad1m:       s.Load1m,
+		HistoryLoad5m:       s.Load5m,
+		HistoryLoad15m:      s.Load15m,
+		HistoryDiskReadMBs:  s.ReadMBs,
+		HistoryDiskWriteMBs: s.WriteMBs,
+		HistoryDiskUtil:     s.DiskUtil,
+		HistoryNetRxMbps:    s.NetRxMbps,
+		HistoryNetTxMbps:    s.NetTxMbps,
+		HistoryNetRxTotalMB: s.NetRxTotalMB,
+		HistoryNetTxTotalMB: s.NetTxTotalMB,
+		HistoryUptimeHours:  float64(s.Uptime) / 3600,
+	}
+	if ssd := ctx.Config.Paths.SSD; ssd != "" {
+		v[HistoryVolumeUsed(ssd)] = s.VolSSD.Used
+		v[HistoryVolumeFree(ssd)] = float64(s.VolSSD.Free)
+	}
+	for mount, vol := range s.SecondaryVols {
+		v[HistoryVolumeUsed(mount)] = vol.Used
+		v[HistoryVolumeFree(mount)] = float64(vol.Free)
+	}
+	if t := readCPUTemp(); t > 0 {
+		v[HistoryTemp("cpu")] = t
+	}
+	ctx.Monitor.Mu.Lock()
+	for dev, res := range ctx.Monitor.SmartCache {
+		if res.Temp > 0 {
+			v[HistoryTemp(dev)] = float64(res.Temp)
+		}
+		for name, n := range res.Counters {
+			v[HistorySmart(dev, name)] = float64(n)
+		}
+		if res.PowerOnHours >= 0 {
+			v[HistorySmart(dev, "power_on_hours")] = float64(res.PowerOnHours)
+		}
+		if res.PercentUsed >= 0 {
+			v[HistorySmart(dev, "percent_used")] = float64(res.PercentUsed)
+		}
+	}
+	ctx.Monitor.Mu.Unlock()
+	return v
+}
+
+func recordHistorySample(ctx *AppContext, now time.Time) {
+	if ctx.History == nil {
+		return
+	}
+	s, ready := ctx.Stats.Get()
+	if !ready {
+		return
+	}
+	ctx.History.Add(now, historyValues(ctx, s))
+}
+
+// recordContainerMemory samples the memory of every running container.
+// docker stats is slow, so this runs less often than the main sampler.
+func recordContainerMemory(ctx *AppContext, now time.Time) {
+	if ctx.History == nil {
+		return
+	}
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	stats, err := dockerClient.Stats(timeoutCtx)
+	if err != nil {
+		slog.Debug("History: docker stats failed", "err", err)
+		return
+	}
+
+	values := make(map[string]float64)
+	for _, st := range stats {
+		values[HistoryContainerMem(st.Name)] = float64(st.MemUsage) / (1 << 20)
+	}
+	if len(values) > 0 {
+		ctx.History.Add(now, values)
+	}
+}
+
+func saveHistory(ctx *AppContext) {
+	if ctx.History == nil {
+		return
+	}
+	if err := ctx.History.Save(historyFilePath()); err != nil {
+		slog.Error("History save error", "err", err)
+	}
+}
+
+// historyRecorder samples the stats into the history and flushes it to disk
+// periodically. The final flush happens on shutdown in RunBot.
+func historyRecorder(ctx *AppContext, runCtx context.Context) {
+	h := ctx.Config.History
+	sampleTicker := time.NewTicker(time.Duration(h.SampleSeconds) * time.Second)
+	flushTicker := time.NewTicker(time.Duration(h.FlushMinutes) * time.Minute)
+	containerTicker := time.NewTicker(5 * time.Minute)
+	defer sampleTicker.Stop()
+	defer flushTicker.Stop()
+	defer containerTicker.Stop()
+
+	for {
+		select {
+		case <-runCtx.Done():
+			return
+		case now := <-sampleTicker.C:
+			recordHistorySample(ctx, now)
+		case now := <-containerTicker.C:
+			recordContainerMemory(ctx, now)
+		case <-flushTicker.C:
+			saveHistory(ctx)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistorySurvivesRestart(t *testing.T) {
+	t.Setenv("NASBOT_HISTORY_FILE", filepath.Join(t.TempDir(), "history.gob"))
+	hc := HistoryConfig{Enabled: true, RawRetentionHours: 24, FiveMinRetentionDays: 7, HourlyRetentionDays: 90, DailyRetentionDays: 730}
+
+	ctx := newTestAppContext()
+	ctx.Config.History = hc
+	ctx.Config.Paths.SSD = "/Volume1"
+	initHistory(ctx)
+	if ctx.History == nil {
+		t.Fatalf("history not initialized")
+	}
+
+	now := time.Now()
+	for i := 60; i >= 0; i-- {
+		recordHistorySample(ctx, now.Add(-time.Duration(i)*time.Minute))
+	}
+	saveHistory(ctx)
+
+	restarted := newTestAppContext()
+	restarted.Config.History = hc
+	initHistory(restarted)
+
+	pts := restarted.History.Range(HistoryVolumeUsed("/mnt/data"), now.Add(-time.Hour), now)
+	if len(pts) != 61 || pts[0].Avg != 20 {
+		t.Fatalf("volume history not restored: %d points", len(pts))
+	}
+	if len(restarted.Monitor.CPUTrend) == 0 || restarted.Monitor.CPUTrend[0].Value != 12 {
+		t.Fatalf("CPU trend not seeded from history: %+v", restarted.Monitor.CPUTrend)
+	}
+
+	got := reportAverages(restarted, now.Add(-2*time.Hour), now)
+	if !strings.Contains(got, "CPU avg 12%") || !strings.Contains(got, "RAM avg 34%") {
+		t.Fatalf("reportAverages = %q", got)
+	}
+}
+
+func TestHistoryDisabled(t *testing.T) {
+	ctx := newTestAppContext()
+	initHistory(ctx)
+	if ctx.History != nil {
+		t.Fatalf("history should stay nil when disabled")
+	}
+	recordHistorySample(ctx, time.Now())
+	if reportAverages(ctx, time.Now().Add(-time.Hour), time.Now()) != "" {
+		t.Fatalf("no averages expected without history")
+	}
+}
@@ -46,6 +46,7 @@ func SetupCallbackRegistry() *CallbackRegistry {
 		editMessage(bot, chatID, msgID, generateReport(ctx, true, nil), &mainKb)
 		return true
 	}))
+	r.RegisterPrefix("graph_", CallbackFunc(handleGraphCallback))
 	r.RegisterExact("show_power", CallbackFunc(func(ctx *AppContext, bot BotAPI, chatID int64, msgID int, query *tgbotapi.CallbackQuery, data string) bool {
 		text, kb := getPowerMenuText(ctx)
 		editMessage(bot, chatID, msgID, text, kb)
@@ -126,6 +127,42 @@ func SetupCallbackRegistry() *CallbackRegistry {
 		return true
 	}))
 
+	r.RegisterPrefix("stack_", CallbackFunc(func(ctx *AppContext, bot BotAPI, chatID int64, msgID int, query *tgbotapi.CallbackQuery, data string) bool {
+		handleStackCallback(ctx, bot, chatID, msgID, data)
+		return true
+	}))
+
+	r.RegisterPrefix("dupdate_", CallbackFunc(func(ctx *AppContext, bot BotAPI, chatID int64, msgID int, query *tgbotapi.CallbackQuery, data string) bool {
+		handleImageUpdateCallback(ctx, bot, chatID, msgID, data)
+		return true
+	}))
+
+	r.RegisterPrefix("ddisk_", CallbackFunc(func(ctx *AppContext, bot BotAPI, chatID int64, msgID int, query *tgbotapi.CallbackQuery, data string) bool {
+		handleDockerDiskCallback(ctx, bot, chatID, msgID, data)
+		return true
+	}))
+
+	r.RegisterPrefix("dmenu_", CallbackFunc(func(ctx *AppContext, bot BotAPI, chatID int64, msgID int, query *tgbotapi.CallbackQuery, data string) bool {
+		handleDockerMenuCallback(ctx, bot, chatID, msgID, data)
+		return true
+	}))
+
+	r.RegisterPrefix("dinspect_", CallbackFunc(func(ctx *AppContext, bot BotAPI, chatID int64, msgID int, query *tgbotapi.CallbackQuery, data string) bool {
+		handleContainerInspectCallback(ctx, bot, chatID, msgID, data)
+		return true
+	}))
+
+	r.RegisterPrefix("dexec_", CallbackFunc(func(ctx *AppContext, bot BotAPI, chatID int64, msgID int, query *tgbotapi.CallbackQuery, data string) bool {
+		handleExecActionCallback(ctx, bot, chatID, msgID, data)
+		return true
+	}))
+
+	r.RegisterPrefix("dlog_", CallbackFunc(func(ctx *AppContext, bot BotAPI, chatID int64, msgID int, query *tgbotapi.CallbackQuery, data string) bool {
+		handleContainerLogCallback(ctx, bot, chatID, msgID, data)
+		return true
+	}))
+
+	r.RegisterPrefix("alert_", CallbackFunc(handleAlertCallback))
 	r.RegisterExact("ai_analyze_critical", CallbackFunc(handleAIAnalyzeCritical))
 	r.RegisterPrefix("proc_manage_", CallbackFunc(handleProcManage))
 	r.RegisterPrefix("proc_kill_", CallbackFunc(handleProcKill))
@@ -17,6 +17,8 @@ func SetupCommandRegistry() *CommandRegistry {
 	// Docker
 	r.Register("docker", &DockerMenuCmd{})
 	r.Register("dstats", &DockerStatsCmd{})
+	r.Register("dupdates", &DockerUpdatesCmd{})
+	r.Register("ddisk", &DockerDiskCmd{})
 	r.Register("container", &ContainerCmd{})
 	r.Register("restartdocker", &RestartDockerCmd{})
 	r.Register("kill", &KillCmd{})
@@ -37,6 +39,9 @@ func SetupCommandRegistry() *CommandRegistry {
 	r.Register("q", &QuickCmd{}) // Alias
 	r.Register("diskpred", &DiskPredCmd{})
 	r.Register("prediction", &DiskPredCmd{}) // Alias
+	r.Register("graph", &GraphCmd{})
+	r.Register("smart", &SmartCmd{})
+	r.Register("raid", &RaidCmd{})
 	r.Register("health", &HealthCmd{})
 	r.Register("healthchecks", &HealthCmd{}) // Alias
 	r.Register("update", &UpdateCmd{})
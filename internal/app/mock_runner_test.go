@@ -1,6 +1,9 @@
 package app
 
-import "context"
+import (
+	"context"
+	"strings"
+)
 
 type mockRunner struct {
 	exists bool
@@ -21,3 +24,28 @@ func (m mockRunner) Output(ctx context.Context, name string, args ...string) ([]
 func (m mockRunner) Run(ctx context.Context, name string, args ...string) error {
 	return m.err
 }
+
+// recordingRunner answers like mockRunner and records each command line.
+type recordingRunner struct {
+	mockRunner
+	calls []string
+}
+
+func (r *recordingRunner) record(name string, args []string) {
+	r.calls = append(r.calls, strings.Join(append([]string{name}, args...), " "))
+}
+
+func (r *recordingRunner) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	r.record(name, args)
+	return r.out, r.err
+}
+
+func (r *recordingRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	r.record(name, args)
+	return r.out, r.err
+}
+
+func (r *recordingRunner) Run(ctx context.Context, name string, args ...string) error {
+	r.record(name, args)
+	return r.err
+}
@@ -11,11 +11,69 @@ type ThreadSafeStats = pmodel.ThreadSafeStats
 type RuntimeState = pmodel.RuntimeState
 type BotContext = pmodel.BotContext
 type DockerManager = pmodel.DockerManager
+type ImageUpdate = pmodel.ImageUpdate
+type RestartSample = pmodel.RestartSample
 type MonitorState = pmodel.MonitorState
+type SmartResult = pmodel.SmartResult
+type SmartTestRun = pmodel.SmartTestRun
+type DiskPowerStats = pmodel.DiskPowerStats
+type RaidSyncRun = pmodel.RaidSyncRun
 type UserSettings = pmodel.UserSettings
 type HealthchecksState = pmodel.HealthchecksState
 type DowntimeLog = pmodel.DowntimeLog
+type AlertManager = pmodel.AlertManager
+type AlertRule = pmodel.AlertRule
+type AlertObservation = pmodel.AlertObservation
+type AlertEvent = pmodel.AlertEvent
+type AlertSilence = pmodel.AlertSilence
+
+func RoleRank(role string) int { return pmodel.RoleRank(role) }
+
+func NewAlertManager() *AlertManager { return pmodel.NewAlertManager() }
+
+func HistoryVolumeUsed(mount string) string  { return pmodel.HistoryVolumeUsed(mount) }
+func HistoryVolumeFree(mount string) string  { return pmodel.HistoryVolumeFree(mount) }
+func HistoryTemp(sensor string) string       { return pmodel.HistoryTemp(sensor) }
+func HistoryContainerMem(name string) string { return pmodel.HistoryContainerMem(name) }
+func HistorySmart(dev, metric string) string { return pmodel.HistorySmart(dev, metric) }
 
 func InitApp(cfg *Config) *AppContext {
 	return pmodel.InitApp(cfg)
 }
+
+const (
+	RoleViewer   = pmodel.RoleViewer
+	RoleOperator = pmodel.RoleOperator
+	RoleAdmin    = pmodel.RoleAdmin
+
+	AlertTopicCritical = pmodel.AlertTopicCritical
+	AlertTopicWarning  = pmodel.AlertTopicWarning
+	AlertTopicInfo     = pmodel.AlertTopicInfo
+	AlertTopicReport   = pmodel.AlertTopicReport
+
+	AlertLevelOK       = pmodel.AlertLevelOK
+	AlertLevelWarning  = pmodel.AlertLevelWarning
+	AlertLevelCritical = pmodel.AlertLevelCritical
+
+	AlertUnchanged = pmodel.AlertUnchanged
+	AlertFired     = pmodel.AlertFired
+	AlertReminder  = pmodel.AlertReminder
+	AlertEscalated = pmodel.AlertEscalated
+	AlertResolved  = pmodel.AlertResolved
+
+	HistoryCPU          = pmodel.HistoryCPU
+	HistoryRAM          = pmodel.HistoryRAM
+	HistorySwap         = pmodel.HistorySwap
+	HistoryRAMFreeMB    = pmodel.HistoryRAMFreeMB
+	HistoryLoad1m       = pmodel.HistoryLoad1m
+	HistoryLoad5m       = pmodel.HistoryLoad5m
+	HistoryLoad15m      = pmodel.HistoryLoad15m
+	HistoryDiskReadMBs  = pmodel.HistoryDiskReadMBs
+	HistoryDiskWriteMBs = pmodel.HistoryDiskWriteMBs
+	HistoryDiskUtil     = pmodel.HistoryDiskUtil
+	HistoryNetRxMbps    = pmodel.HistoryNetRxMbps
+	HistoryNetTxMbps    = pmodel.HistoryNetTxMbps
+	HistoryNetRxTotalMB = pmodel.HistoryNetRxTotalMB
+	HistoryNetTxTotalMB = pmodel.HistoryNetTxTotalMB
+	HistoryUptimeHours  = pmodel.HistoryUptimeHours
+)
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"nasbot/internal/docker"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -59,7 +61,7 @@ func checkDockerHealth(ctx *AppContext, bot BotAPI) {
 				title := ctx.Tr("wd_title")
 				body := fmt.Sprintf(ctx.Tr("wd_no_containers"), cfg.Docker.Watchdog.TimeoutMinutes)
 				footer := ctx.Tr("wd_disabled")
-				safeSend(bot, tgbotapi.NewMessage(cfg.AllowedUserID, title+body+footer))
+				sendAlert(bot, cfg, AlertTopicWarning, tgbotapi.NewMessage(0, title+body+footer))
 			}
 			ctx.State.AddEvent("warning", "Docker watchdog triggered (restart disabled)")
 			return
@@ -69,7 +71,7 @@ func checkDockerHealth(ctx *AppContext, bot BotAPI) {
 			title := ctx.Tr("wd_title")
 			body := fmt.Sprintf(ctx.Tr("wd_no_containers"), cfg.Docker.Watchdog.TimeoutMinutes)
 			footer := ctx.Tr("wd_restarting")
-			safeSend(bot, tgbotapi.NewMessage(cfg.AllowedUserID, title+body+footer))
+			sendAlert(bot, cfg, AlertTopicWarning, tgbotapi.NewMessage(0, title+body+footer))
 		}
 
 		ctx.State.AddEvent("action", "Docker watchdog restart triggered")
@@ -77,22 +79,17 @@ func checkDockerHealth(ctx *AppContext, bot BotAPI) {
 		c, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
 		defer cancel()
 
-		var out []byte
-		var err error
-		if commandExists("systemctl") {
-			out, err = runCommandOutput(c, "systemctl", "restart", "docker")
-		} else {
-			out, err = runCommandOutput(c, "service", "docker", "restart")
-		}
+		out, err := restartContainerService(c, cfg.Docker.Socket)
 		if err != nil {
 			if !ctx.IsQuietHours() {
-				safeSend(bot, tgbotapi.NewMessage(cfg.AllowedUserID, fmt.Sprintf(ctx.Tr("docker_restart_err"), err)))
+				sendAlert(bot, cfg, AlertTopicCritical, tgbotapi.NewMessage(0, fmt.Sprintf(ctx.Tr("docker_restart_err"), err)))
 			}
 			slog.Error("Docker restart fail", "err", err, "output", string(out))
 		} else {
 			if !ctx.IsQuietHours() {
-				safeSend(bot, tgbotapi.NewMessage(cfg.AllowedUserID, ctx.Tr("docker_restart_sent")))
+				sendAlert(bot, cfg, AlertTopicInfo, tgbotapi.NewMessage(0, ctx.Tr("docker_restart_sent")))
 			}
+			goSafe("docker-ordered-start", func() { ensureStartOrder(ctx, bot, context.Background(), "recovery", 0, 0) })
 		}
 	}
 }
@@ -148,7 +145,7 @@ func checkWeeklyPrune(ctx *AppContext, bot BotAPI) {
 				c, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 				defer cancel()
 
-				out, err := runCommandOutput(c, "docker", "system", "prune", "-a", "-f")
+				rep, err := dockerClient.Prune(c)
 
 				var msg string
 				if err != nil {
@@ -158,23 +155,13 @@ func checkWeeklyPrune(ctx *AppContext, bot BotAPI) {
 						msg = fmt.Sprintf("🧹 *Weekly Prune Error*\n\n`%v`", err)
 					}
 				} else {
-					output := string(out)
-					lines := strings.Split(output, "\n")
-					lastLine := ""
-					for i := len(lines) - 1; i >= 0; i-- {
-						if strings.TrimSpace(lines[i]) != "" {
-							lastLine = lines[i]
-							break
-						}
-					}
-					msg = fmt.Sprintf("🧹 *Weekly Prune*\n\nUnused images removed.\n`%s`", lastLine)
+					msg = fmt.Sprintf("🧹 *Weekly Prune*\n\nUnused images removed.\n`Total reclaimed space: %s`", docker.FormatSize(rep.SpaceReclaimed))
 					ctx.State.AddEvent("info", "Weekly docker prune completed")
 				}
 
 				if !ctx.IsQuietHours() {
-					m := tgbotapi.NewMessage(ctx.Config.AllowedUserID, msg)
-					m.ParseMode = "Markdown"
-					safeSend(bot, m)
+					m := alertMessage(msg)
+					sendAlert(bot, ctx.Config, AlertTopicInfo, m)
 				}
 			})
 		}
@@ -7,8 +7,6 @@ import (
 	"regexp"
 	"strings"
 	"time"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // Regex to extract process name from OOM logs
@@ -203,9 +201,8 @@ func processKernelLines(ctx *AppContext, bot BotAPI, lines []string) {
 		}
 
 		// ALWAYS send — critical events ignore quiet hours
-		m := tgbotapi.NewMessage(ctx.Config.AllowedUserID, msg)
-		m.ParseMode = "Markdown"
-		safeSend(bot, m)
+		m := alertMessage(msg)
+		sendAlert(bot, ctx.Config, AlertTopicCritical, m)
 	}
 
 	ctx.Monitor.Mu.Lock()
@@ -238,9 +235,8 @@ func handleOOMLoop(ctx *AppContext, bot BotAPI) {
 		ctx.Monitor.RecentOOMs = []time.Time{}
 
 		// Notify user
-		msg := tgbotapi.NewMessage(ctx.Config.AllowedUserID, ctx.Tr("oom_reboot_warning"))
-		msg.ParseMode = "Markdown"
-		safeSend(bot, msg)
+		msg := alertMessage(ctx.Tr("oom_reboot_warning"))
+		sendAlert(bot, ctx.Config, AlertTopicCritical, msg)
 
 		// Log it
 		slog.Error("OOM Loop detected. Triggering reboot.", "count", len(valid), "window", oomLoopWindow)
@@ -8,8 +8,6 @@ import (
 	"time"
 
 	"nasbot/internal/format"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // ═══════════════════════════════════════════════════════════════════
@@ -36,11 +34,6 @@ func checkNetworkHealth(ctx *AppContext, bot BotAPI) {
 	if threshold <= 0 {
 		threshold = 3
 	}
-	cooldown := time.Duration(cfg.NetworkWatchdog.CooldownMins) * time.Minute
-	if cooldown <= 0 {
-		cooldown = 10 * time.Minute
-	}
-
 	pingOk := false
 	var reasons []string
 
@@ -67,69 +60,60 @@ func checkNetworkHealth(ctx *AppContext, bot BotAPI) {
 		reasons = append(reasons, fmt.Sprintf("DNS lookup failed: %s", dnsHost))
 	}
 
+	quiet := ctx.IsQuietHours()
+	// The host is reachable whenever ping works; a DNS failure alone is
+	// only the "net:dns" warning.
+	if pingOk {
+		if ev := observeAlert(ctx, AlertObservation{ID: "net", Quiet: quiet}); ev.Kind == AlertResolved && ev.Notify {
+			msg := fmt.Sprintf(ctx.Tr("net_recovered"), format.FormatDuration(time.Since(ev.Since)))
+			sendAlert(bot, ctx.Config, AlertTopicInfo, alertMessage(msg))
+		}
+	}
+
 	// Healthy network
 	if pingOk && dnsOk {
-		var shouldNotify bool
-		var downSince time.Time
 		ctx.Monitor.Mu.Lock()
 		ctx.Monitor.NetFailCount = 0
 		ctx.Monitor.NetConsecutiveDegraded = 0
-		if !ctx.Monitor.NetDownSince.IsZero() {
-			shouldNotify = cfg.NetworkWatchdog.RecoveryNotify
-			downSince = ctx.Monitor.NetDownSince
-			ctx.Monitor.NetDownSince = time.Time{}
-			ctx.Monitor.NetForceRebootTriggered = false
-		}
+		ctx.Monitor.NetDownSince = time.Time{}
+		ctx.Monitor.NetForceRebootTriggered = false
 		ctx.Monitor.Mu.Unlock()
 
-		if shouldNotify && !ctx.IsQuietHours() {
-			msg := fmt.Sprintf(ctx.Tr("net_recovered"), format.FormatDuration(time.Since(downSince)))
-			m := tgbotapi.NewMessage(cfg.AllowedUserID, msg)
-			m.ParseMode = "Markdown"
-			safeSend(bot, m)
+		if ev := observeAlert(ctx, AlertObservation{ID: "net:dns", Quiet: quiet}); ev.Kind == AlertResolved && ev.Notify {
+			sendAlert(bot, ctx.Config, AlertTopicInfo, alertMessage(resolvedText(ctx, "DNS "+dnsHost, ev)))
 		}
 		return
 	}
 
 	// DNS-only issue
 	if pingOk && !dnsOk {
-		shouldNotify := false
 		ctx.Monitor.Mu.Lock()
 		ctx.Monitor.NetConsecutiveDegraded++
-		if time.Since(ctx.Monitor.NetDNSAlertTime) >= cooldown {
-			ctx.Monitor.NetDNSAlertTime = time.Now()
-			shouldNotify = true
-		}
 		ctx.Monitor.Mu.Unlock()
 
-		if shouldNotify {
-			msg := fmt.Sprintf(ctx.Tr("net_dns_fail"), dnsHost)
-			m := tgbotapi.NewMessage(cfg.AllowedUserID, msg)
-			m.ParseMode = "Markdown"
-			if !ctx.IsQuietHours() {
-				safeSend(bot, m)
-			}
+		ev := observeAlert(ctx, AlertObservation{ID: "net:dns", Level: AlertLevelWarning, Quiet: quiet})
+		if ev.Notify && ev.Kind != AlertResolved {
+			sendAlert(bot, ctx.Config, AlertTopicWarning, alertMessage(fmt.Sprintf(ctx.Tr("net_dns_fail"), dnsHost)))
+		}
+		if (ev.Notify || ev.Kind == AlertFired) && !ev.Deferred {
 			ctx.State.AddEvent("warning", "DNS lookup failure")
 		}
 		return
 	}
 
 	// Full network failure
-	var shouldAlert bool
+	var down bool
 	var shouldForceReboot bool
 	var downFor time.Duration
 	ctx.Monitor.Mu.Lock()
 	ctx.Monitor.NetFailCount++
 	ctx.Monitor.NetConsecutiveDegraded++
 	if ctx.Monitor.NetFailCount >= threshold {
+		down = true
 		if ctx.Monitor.NetDownSince.IsZero() {
 			ctx.Monitor.NetDownSince = time.Now()
 		}
 		downFor = time.Since(ctx.Monitor.NetDownSince)
-		if time.Since(ctx.Monitor.NetDownAlertTime) >= cooldown {
-			ctx.Monitor.NetDownAlertTime = time.Now()
-			shouldAlert = true
-		}
 		if forceRebootAfter > 0 && downFor >= forceRebootAfter && !ctx.Monitor.NetForceRebootTriggered {
 			ctx.Monitor.NetForceRebootTriggered = true
 			shouldForceReboot = true
@@ -137,21 +121,20 @@ func checkNetworkHealth(ctx *AppContext, bot BotAPI) {
 	}
 	ctx.Monitor.Mu.Unlock()
 
-	if shouldAlert {
-		msg := fmt.Sprintf(ctx.Tr("net_down"), strings.Join(reasons, "\n- "))
-		m := tgbotapi.NewMessage(cfg.AllowedUserID, msg)
-		m.ParseMode = "Markdown"
-		if !ctx.IsQuietHours() {
-			safeSend(bot, m)
+	if down {
+		ev := observeAlert(ctx, AlertObservation{ID: "net", Level: AlertLevelCritical, Quiet: quiet})
+		if ev.Notify && ev.Kind != AlertResolved {
+			notifyCritical(ctx, bot, ev, fmt.Sprintf(ctx.Tr("net_down"), strings.Join(reasons, "\n- ")), false)
+		}
+		if (ev.Notify || ev.Kind == AlertFired) && !ev.Deferred {
+			ctx.State.AddEvent("critical", "Network unreachable")
 		}
-		ctx.State.AddEvent("critical", "Network unreachable")
 	}
 
 	if shouldForceReboot {
 		msg := fmt.Sprintf(ctx.Tr("net_force_reboot"), format.FormatDuration(downFor))
-		m := tgbotapi.NewMessage(cfg.AllowedUserID, msg)
-		m.ParseMode = "Markdown"
-		safeSend(bot, m)
+		m := alertMessage(msg)
+		sendAlert(bot, ctx.Config, AlertTopicCritical, m)
 		executeForcedReboot(ctx, bot, cfg.AllowedUserID, 0, "network-down-timeout")
 	}
 }
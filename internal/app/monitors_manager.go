@@ -33,8 +33,11 @@ func autonomousManager(ctx *AppContext, bot BotAPI, runCtx context.Context) {
 	}
 	kwTicker := time.NewTicker(kwInterval)
 
+	smartTestTicker := time.NewTicker(time.Minute)
+
 	defer ticker.Stop()
 	defer diskTicker.Stop()
+	defer smartTestTicker.Stop()
 	defer trendTicker.Stop()
 	defer kwTicker.Stop()
 	defer netTicker.Stop()
@@ -95,6 +98,7 @@ func autonomousManager(ctx *AppContext, bot BotAPI, runCtx context.Context) {
 			}
 
 			checkContainerStates(ctx, bot)
+			checkRestartLoops(ctx, bot)
 			checkCriticalContainers(ctx, bot)
 
 			if cfg.Docker.WeeklyPrune.Enabled {
@@ -113,6 +117,8 @@ func autonomousManager(ctx *AppContext, bot BotAPI, runCtx context.Context) {
 			if cfg.NetworkWatchdog.Enabled {
 				checkNetworkHealth(ctx, bot)
 			}
+		case now := <-smartTestTicker.C:
+			checkSmartSelfTests(ctx, now)
 		case <-raidTicker.C:
 			if cfg.RaidWatchdog.Enabled {
 				checkRaidHealth(ctx, bot)
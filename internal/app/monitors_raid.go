@@ -3,95 +3,190 @@ package app
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
 	"strings"
 	"time"
 
 	"nasbot/internal/format"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"nasbot/internal/mdstat"
 )
 
+// mdstatPath is read for the md arrays; tests point it at a fixture.
+var mdstatPath = "/proc/mdstat"
+
+// raidSyncNotified are the sync actions whose progress is sent to the
+// chat. Scheduled checks and repairs only leave report events.
+var raidSyncNotified = map[string]bool{mdstat.Recovery: true, mdstat.Resync: true, mdstat.Reshape: true}
+
 func checkRaidHealth(ctx *AppContext, bot BotAPI) {
-	cfg := ctx.Config
-	issues := getRaidIssues()
-
-	if len(issues) == 0 {
-		var shouldNotify bool
-		var downSince time.Time
-		ctx.Monitor.Mu.Lock()
-		if !ctx.Monitor.RaidDownSince.IsZero() {
-			shouldNotify = cfg.RaidWatchdog.RecoveryNotify
-			downSince = ctx.Monitor.RaidDownSince
-			ctx.Monitor.RaidDownSince = time.Time{}
-			ctx.Monitor.RaidLastSignature = ""
-		}
-		ctx.Monitor.Mu.Unlock()
+	arrays, _ := readMdstat()
+	issues := append(mdstatIssues(arrays), zpoolIssues()...)
 
-		if shouldNotify && !ctx.IsQuietHours() {
-			msg := fmt.Sprintf(ctx.Tr("raid_recovered"), format.FormatDuration(time.Since(downSince)))
-			m := tgbotapi.NewMessage(cfg.AllowedUserID, msg)
-			m.ParseMode = "Markdown"
-			safeSend(bot, m)
-		}
-		return
+	obs := AlertObservation{ID: "raid"}
+	if len(issues) > 0 {
+		obs.Level = AlertLevelCritical
+		obs.Signature = strings.Join(issues, " | ")
 	}
+	ev := observeAlert(ctx, obs)
 
-	signature := strings.Join(issues, " | ")
-	cooldown := time.Duration(cfg.RaidWatchdog.CooldownMins) * time.Minute
-	if cooldown <= 0 {
-		cooldown = 30 * time.Minute
+	switch ev.Kind {
+	case AlertResolved:
+		if ev.Notify && !ctx.IsQuietHours() {
+			msg := fmt.Sprintf(ctx.Tr("raid_recovered"), format.FormatDuration(time.Since(ev.Since)))
+			sendAlert(bot, ctx.Config, AlertTopicInfo, alertMessage(msg))
+		}
+	case AlertFired, AlertReminder, AlertEscalated:
+		if ev.Notify {
+			notifyCritical(ctx, bot, ev, fmt.Sprintf(ctx.Tr("raid_alert"), strings.Join(issues, "\n")), false)
+			ctx.State.AddEvent("critical", "RAID issue detected")
+		}
 	}
 
-	shouldAlert := false
-	ctx.Monitor.Mu.Lock()
-	if ctx.Monitor.RaidDownSince.IsZero() {
-		ctx.Monitor.RaidDownSince = time.Now()
+	trackRaidSyncs(ctx, bot, arrays, time.Now())
+}
+
+// readMdstat returns the md arrays; a missing /proc/mdstat means the md
+// driver is not loaded.
+func readMdstat() ([]mdstat.Array, error) {
+	data, err := os.ReadFile(mdstatPath)
+	if err != nil {
+		return nil, err
 	}
-	if signature != ctx.Monitor.RaidLastSignature || time.Since(ctx.Monitor.RaidAlertTime) >= cooldown {
-		shouldAlert = true
-		ctx.Monitor.RaidLastSignature = signature
-		ctx.Monitor.RaidAlertTime = time.Now()
+	return mdstat.Parse(data), nil
+}
+
+// mdstatIssues describes the arrays that need attention. A sync is not
+// an issue by itself: a rebuild shows up as the degraded array it
+// repairs, and trackRaidSyncs reports its progress. The text must not
+// change while the problem stays the same, or the alert fires again.
+func mdstatIssues(arrays []mdstat.Array) []string {
+	var issues []string
+	for _, a := range arrays {
+		faulty := a.Faulty()
+		switch {
+		case !a.Active:
+			issues = append(issues, fmt.Sprintf("mdadm `%s` inactive", a.Name))
+		case a.Degraded():
+			issue := fmt.Sprintf("mdadm `%s` (%s) degraded `[%d/%d] [%s]`", a.Name, a.Level, a.Disks, a.Up, a.Status)
+			if len(faulty) > 0 {
+				issue += ", failed: `" + strings.Join(faulty, "`, `") + "`"
+			}
+			issues = append(issues, issue)
+		case len(faulty) > 0:
+			issues = append(issues, fmt.Sprintf("mdadm `%s` (%s) failed member: `%s`", a.Name, a.Level, strings.Join(faulty, "`, `")))
+		}
 	}
-	ctx.Monitor.Mu.Unlock()
+	return issues
+}
 
-	if shouldAlert {
-		msg := fmt.Sprintf(ctx.Tr("raid_alert"), strings.Join(issues, "\n"))
-		m := tgbotapi.NewMessage(cfg.AllowedUserID, msg)
-		m.ParseMode = "Markdown"
-		safeSend(bot, m)
-		ctx.State.AddEvent("critical", "RAID issue detected")
+func zpoolIssues() []string {
+	if !commandExists("zpool") {
+		return nil
+	}
+	c, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := runCommandStdout(c, "zpool", "status", "-x")
+	if err != nil {
+		return nil
 	}
+	output := strings.TrimSpace(string(out))
+	if output == "" || strings.Contains(strings.ToLower(output), "all pools are healthy") {
+		return nil
+	}
+	return []string{fmt.Sprintf("zpool: %s", output)}
 }
 
-func getRaidIssues() []string {
-	var issues []string
-	if data, err := os.ReadFile("/proc/mdstat"); err == nil {
-		text := string(data)
-		if strings.Contains(text, "md") {
-			lines := strings.Split(text, "\n")
-			for _, line := range lines {
-				if strings.Contains(line, "md") && strings.Contains(line, "[") && strings.Contains(line, "]") {
-					if strings.Contains(line, "_") {
-						issues = append(issues, fmt.Sprintf("mdadm degraded: %s", strings.TrimSpace(line)))
-					}
-					if strings.Contains(line, "recovery") || strings.Contains(line, "resync") || strings.Contains(line, "reshape") {
-						issues = append(issues, fmt.Sprintf("mdadm sync: %s", strings.TrimSpace(line)))
-					}
-				}
+// raidSyncProgress describes a running sync: bar, speed and time left.
+func raidSyncProgress(s mdstat.Sync) string {
+	return fmt.Sprintf("`%s` %.1f%% · %.0f MB/s · ETA `%s`",
+		format.MakeProgressBar(s.Percent), s.Percent, float64(s.Speed)/1024, format.FormatDuration(s.Finish))
+}
+
+// trackRaidSyncs follows the md syncs from one check to the next. It
+// records their start and end as events and, for rebuilds, sends an
+// update each time the progress crosses a multiple of
+// raid_watchdog.progress_step_percent.
+func trackRaidSyncs(ctx *AppContext, bot BotAPI, arrays []mdstat.Array, now time.Time) {
+	tr := ctx.Tr
+	step := float64(ctx.Config.RaidWatchdog.ProgressStepPercent)
+	type event struct{ kind, msg string }
+	var events []event
+	var msgs []string
+
+	byName := make(map[string]mdstat.Array, len(arrays))
+	for _, a := range arrays {
+		byName[a.Name] = a
+	}
+	finish := func(name string, run RaidSyncRun) {
+		a, ok := byName[name]
+		if ok && a.Degraded() {
+			events = append(events, event{"warning", fmt.Sprintf("RAID %s %s stopped, array still degraded", name, run.Action)})
+			if raidSyncNotified[run.Action] {
+				msgs = append(msgs, fmt.Sprintf(tr("raid_sync_stopped"), name, run.Action))
 			}
+			return
+		}
+		took := ""
+		if run.FirstPercent < 1 {
+			took = format.FormatDuration(now.Sub(run.Started))
+			events = append(events, event{"info", fmt.Sprintf("RAID %s %s finished in %s", name, run.Action, took)})
+		} else {
+			events = append(events, event{"info", fmt.Sprintf("RAID %s %s finished", name, run.Action)})
+		}
+		if raidSyncNotified[run.Action] {
+			msg := fmt.Sprintf(tr("raid_sync_done"), name, run.Action)
+			if took != "" {
+				msg += fmt.Sprintf(tr("raid_sync_took"), took)
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+
+	ctx.Monitor.Mu.Lock()
+	if ctx.Monitor.RaidSyncs == nil {
+		ctx.Monitor.RaidSyncs = make(map[string]RaidSyncRun)
+	}
+	for name, run := range ctx.Monitor.RaidSyncs {
+		if a, ok := byName[name]; !ok || a.Sync == nil {
+			delete(ctx.Monitor.RaidSyncs, name)
+			finish(name, run)
 		}
 	}
-	if commandExists("zpool") {
-		c, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		out, err := runCommandStdout(c, "zpool", "status", "-x")
-		if err == nil {
-			output := strings.TrimSpace(string(out))
-			if output != "" && !strings.Contains(strings.ToLower(output), "all pools are healthy") {
-				issues = append(issues, fmt.Sprintf("zpool: %s", output))
+	for _, a := range arrays {
+		s := a.Sync
+		if s == nil || s.Waiting != "" {
+			continue
+		}
+		run, ok := ctx.Monitor.RaidSyncs[a.Name]
+		if ok && run.Action != s.Action {
+			finish(a.Name, run)
+			ok = false
+		}
+		switch {
+		case !ok:
+			run = RaidSyncRun{Action: s.Action, Started: now, FirstPercent: s.Percent, Reported: s.Percent}
+			events = append(events, event{"info", fmt.Sprintf("RAID %s %s running (%.1f%%)", a.Name, s.Action, s.Percent)})
+			if raidSyncNotified[s.Action] {
+				msgs = append(msgs, fmt.Sprintf(tr("raid_sync_started"), a.Name, s.Action, raidSyncProgress(*s)))
+			}
+		case step > 0 && math.Floor(s.Percent/step) > math.Floor(run.Reported/step):
+			run.Reported = s.Percent
+			if raidSyncNotified[s.Action] {
+				msgs = append(msgs, fmt.Sprintf(tr("raid_sync_progress"), a.Name, s.Action, raidSyncProgress(*s)))
 			}
 		}
+		ctx.Monitor.RaidSyncs[a.Name] = run
+	}
+	ctx.Monitor.Mu.Unlock()
+
+	for _, e := range events {
+		ctx.State.AddEvent(e.kind, e.msg)
+	}
+	if ctx.IsQuietHours() {
+		return
+	}
+	for _, msg := range msgs {
+		sendAlert(bot, ctx.Config, AlertTopicInfo, alertMessage(msg))
 	}
-	return issues
 }
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// writeMdstat points mdstatPath at a /proc/mdstat with one raid1 array.
+func writeMdstat(t *testing.T, members, counts, sync string) {
+	t.Helper()
+	data := fmt.Sprintf("Personalities : [raid1]\nmd0 : active raid1 %s\n      976630464 blocks super 1.2 %s\n%s\nunused devices: <none>\n", members, counts, sync)
+	path := t.TempDir() + "/mdstat"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := mdstatPath
+	mdstatPath = path
+	t.Cleanup(func() { mdstatPath = old })
+}
+
+func recoveryLine(percent float64) string {
+	return fmt.Sprintf("      [==>...]  recovery = %.1f%% (1000/976630464) finish=90.0min speed=150000K/sec", percent)
+}
+
+func TestRaidRebuildProgress(t *testing.T) {
+	ctx := newTestAppContext()
+	ctx.Config.RaidWatchdog = RaidWatchdogConfig{Enabled: true, CooldownMins: 30, RecoveryNotify: true, ProgressStepPercent: 25}
+	defer setCommandRunner(mockRunner{exists: false})()
+	bot := &fakeBot{}
+
+	writeMdstat(t, "sdb1[2](F) sda1[0]", "[2/1] [U_]", "")
+	checkRaidHealth(ctx, bot)
+	texts := sentTexts(bot)
+	if len(texts) != 1 || !strings.Contains(texts[0], "mdadm `md0` (raid1) degraded `[2/1] [U_]`, failed: `sdb1`") {
+		t.Fatalf("expected a degraded alert, got %q", texts)
+	}
+
+	// The failed disk is removed: the problem changed, so it is sent again.
+	writeMdstat(t, "sda1[0]", "[2/1] [U_]", "")
+	checkRaidHealth(ctx, bot)
+	if texts = sentTexts(bot); len(texts) != 2 || strings.Contains(texts[1], "sdb1") {
+		t.Fatalf("expected a degraded alert, got %q", texts)
+	}
+
+	progress := []float64{0.2, 10, 26, 30, 55}
+	for _, p := range progress {
+		writeMdstat(t, "sdc1[1] sda1[0]", "[2/1] [U_]", recoveryLine(p))
+		checkRaidHealth(ctx, bot)
+	}
+	texts = sentTexts(bot)[2:]
+	if len(texts) != 3 {
+		t.Fatalf("expected start and two progress updates, got %q", texts)
+	}
+	if !strings.Contains(texts[0], "md0: recovery in progress") || !strings.Contains(texts[1], "26.0%") || !strings.Contains(texts[2], "55.0%") {
+		t.Fatalf("unexpected updates: %q", texts)
+	}
+	if !strings.Contains(texts[1], "146 MB/s") || !strings.Contains(texts[1], "ETA `1h30m`") {
+		t.Fatalf("update lacks speed or ETA: %q", texts[1])
+	}
+
+	writeMdstat(t, "sdc1[1] sda1[0]", "[2/2] [UU]", "")
+	checkRaidHealth(ctx, bot)
+	texts = sentTexts(bot)[5:]
+	if len(texts) != 2 || !strings.Contains(texts[0], "RAID healthy again") || !strings.Contains(texts[1], "md0: recovery finished") || !strings.Contains(texts[1], "Took") {
+		t.Fatalf("expected recovery and completion messages, got %q", texts)
+	}
+	if len(ctx.Monitor.RaidSyncs) != 0 {
+		t.Fatalf("finished sync still tracked: %v", ctx.Monitor.RaidSyncs)
+	}
+}
+
+func TestRaidCheckOnlyLeavesEvents(t *testing.T) {
+	ctx := newTestAppContext()
+	ctx.Config.RaidWatchdog = RaidWatchdogConfig{Enabled: true, CooldownMins: 30, ProgressStepPercent: 25}
+	defer setCommandRunner(mockRunner{exists: false})()
+	bot := &fakeBot{}
+
+	writeMdstat(t, "sdb1[1] sda1[0]", "[2/2] [UU]", "      [>....]  check = 40.0% (1000/976630464) finish=60.0min speed=150000K/sec")
+	checkRaidHealth(ctx, bot)
+	writeMdstat(t, "sdb1[1] sda1[0]", "[2/2] [UU]", "")
+	checkRaidHealth(ctx, bot)
+
+	if texts := sentTexts(bot); len(texts) != 0 {
+		t.Fatalf("a scrub should not message the chat: %q", texts)
+	}
+	events := ctx.State.GetEvents()
+	if len(events) != 2 || events[1].Message != "RAID md0 check finished" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
+
+func TestRaidCommandText(t *testing.T) {
+	ctx := newTestAppContext()
+	defer setCommandRunner(mockRunner{exists: false})()
+	writeMdstat(t, "sdc1[2] sdb1[1](F) sda1[0] sdd1[3](S)", "[2/1] [U_]", recoveryLine(42.5))
+
+	arrays, err := readMdstat()
+	text := getRaidText(ctx, arrays, err)
+	for _, want := range []string{"⚠️ *md0* raid1 `[2/1] [U_]`", "degraded", "`sdb1` ❌", "`sdd1` (spare)", "🔄 recovery", "42.5%"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("/raid lacks %q:\n%s", want, text)
+		}
+	}
+
+	if text := getRaidText(ctx, nil, os.ErrNotExist); !strings.Contains(text, "/proc/mdstat not available") {
+		t.Fatalf("unexpected text without md: %q", text)
+	}
+}
@@ -3,14 +3,17 @@ package app
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"maps"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"nasbot/internal/format"
+	"nasbot/internal/smart"
 	"nasbot/pkg/model"
 
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/disk"
 	"github.com/shirou/gopsutil/v3/host"
@@ -18,78 +21,118 @@ import (
 	"github.com/shirou/gopsutil/v3/mem"
 	gopsnet "github.com/shirou/gopsutil/v3/net"
 	"github.com/shirou/gopsutil/v3/process"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// MonitorAlert is one reading produced by a ResourceMonitor. Healthy readings
+// are reported too, with an empty Level, so firing alerts can resolve.
 type MonitorAlert struct {
-	Level   string // "critical", "warning"
-	Message string
+	ID        string // stable alert ID, e.g. "cpu" or "disk:/mnt/data"
+	Label     string // human-readable subject used in resolved notices
+	Level     string // "critical", "warning" or "" when within limits
+	Message   string
+	Value     float64
+	Threshold float64
+	// Signature identifies the details of the problem; a change while the
+	// alert fires notifies again.
+	Signature string
+	// NotifyWarning sends warning-level alerts too; by default warnings
+	// are only recorded as events.
+	NotifyWarning bool
+	// Actions are extra buttons for the notification, such as a cleanup
+	// of the affected item.
+	Actions []tgbotapi.InlineKeyboardButton
 }
 
 type ResourceMonitor interface {
 	Check(ctx *AppContext, s *Stats) []MonitorAlert
 }
 
+// thresholdLevel classifies value against a warning/critical pair. A
+// non-positive critical threshold disables the critical level.
+func thresholdLevel(value, warning, critical float64) (level string, threshold float64) {
+	if critical > 0 && value >= critical {
+		return AlertLevelCritical, critical
+	}
+	if value >= warning {
+		return AlertLevelWarning, warning
+	}
+	return AlertLevelOK, warning
+}
+
+func (a MonitorAlert) observation() AlertObservation {
+	return AlertObservation{ID: a.ID, Level: a.Level, Value: a.Value, Threshold: a.Threshold, Signature: a.Signature}
+}
+
 type CPUMonitor struct{}
 
 func (m *CPUMonitor) Check(ctx *AppContext, s *Stats) []MonitorAlert {
-	var alerts []MonitorAlert
 	cfg := ctx.Config.Notifications.CPU
 	if !cfg.Enabled {
-		return alerts
+		return nil
 	}
-	if s.CPU >= cfg.CriticalThreshold {
-		alerts = append(alerts, MonitorAlert{"critical", fmt.Sprintf("🧠 CPU critical: `%.1f%%`", s.CPU)})
-	} else if s.CPU >= cfg.WarningThreshold {
-		alerts = append(alerts, MonitorAlert{"warning", fmt.Sprintf("CPU high: %.1f%%", s.CPU)})
+	a := MonitorAlert{ID: "cpu", Label: "CPU", Value: s.CPU}
+	a.Level, a.Threshold = thresholdLevel(s.CPU, cfg.WarningThreshold, cfg.CriticalThreshold)
+	switch a.Level {
+	case AlertLevelCritical:
+		a.Message = fmt.Sprintf("🧠 CPU critical: `%.1f%%`", s.CPU)
+	case AlertLevelWarning:
+		a.Message = fmt.Sprintf("CPU high: %.1f%%", s.CPU)
 	}
-	return alerts
+	return []MonitorAlert{a}
 }
 
 type RAMMonitor struct{}
 
 func (m *RAMMonitor) Check(ctx *AppContext, s *Stats) []MonitorAlert {
-	var alerts []MonitorAlert
 	cfg := ctx.Config.Notifications.RAM
 	if !cfg.Enabled {
-		return alerts
+		return nil
 	}
-	if s.RAM >= cfg.CriticalThreshold {
-		alerts = append(alerts, MonitorAlert{"critical", fmt.Sprintf("💾 RAM critical: `%.1f%%`", s.RAM)})
-	} else if s.RAM >= cfg.WarningThreshold {
-		alerts = append(alerts, MonitorAlert{"warning", fmt.Sprintf("RAM high: %.1f%%", s.RAM)})
+	a := MonitorAlert{ID: "ram", Label: "RAM", Value: s.RAM}
+	a.Level, a.Threshold = thresholdLevel(s.RAM, cfg.WarningThreshold, cfg.CriticalThreshold)
+	switch a.Level {
+	case AlertLevelCritical:
+		a.Message = fmt.Sprintf("💾 RAM critical: `%.1f%%`", s.RAM)
+	case AlertLevelWarning:
+		a.Message = fmt.Sprintf("RAM high: %.1f%%", s.RAM)
 	}
-	return alerts
+	return []MonitorAlert{a}
 }
 
 type SwapMonitor struct{}
 
 func (m *SwapMonitor) Check(ctx *AppContext, s *Stats) []MonitorAlert {
-	var alerts []MonitorAlert
 	cfg := ctx.Config.Notifications.Swap
 	if !cfg.Enabled {
-		return alerts
+		return nil
 	}
 	// Note: Swap has no critical threshold check currently
-	if s.Swap >= cfg.WarningThreshold {
-		alerts = append(alerts, MonitorAlert{"warning", fmt.Sprintf("Swap high: %.1f%%", s.Swap)})
+	a := MonitorAlert{ID: "swap", Label: "Swap", Value: s.Swap}
+	a.Level, a.Threshold = thresholdLevel(s.Swap, cfg.WarningThreshold, 0)
+	if a.Level == AlertLevelWarning {
+		a.Message = fmt.Sprintf("Swap high: %.1f%%", s.Swap)
 	}
-	return alerts
+	return []MonitorAlert{a}
 }
 
 type SSDMonitor struct{}
 
 func (m *SSDMonitor) Check(ctx *AppContext, s *Stats) []MonitorAlert {
-	var alerts []MonitorAlert
 	cfg := ctx.Config.Notifications.DiskSSD
 	if !cfg.Enabled {
-		return alerts
+		return nil
 	}
-	if s.VolSSD.Used >= cfg.CriticalThreshold {
-		alerts = append(alerts, MonitorAlert{"critical", fmt.Sprintf("💿 SSD critical: `%.1f%%`", s.VolSSD.Used)})
-	} else if s.VolSSD.Used >= cfg.WarningThreshold {
-		alerts = append(alerts, MonitorAlert{"warning", fmt.Sprintf("SSD at %.1f%%", s.VolSSD.Used)})
+	a := MonitorAlert{ID: "disk:ssd", Label: "SSD", Value: s.VolSSD.Used}
+	a.Level, a.Threshold = thresholdLevel(s.VolSSD.Used, cfg.WarningThreshold, cfg.CriticalThreshold)
+	switch a.Level {
+	case AlertLevelCritical:
+		a.Message = fmt.Sprintf("💿 SSD critical: `%.1f%%`", s.VolSSD.Used)
+	case AlertLevelWarning:
+		a.Message = fmt.Sprintf("SSD at %.1f%%", s.VolSSD.Used)
 	}
-	return alerts
+	return []MonitorAlert{a}
 }
 
 type SecondaryDiskMonitor struct{}
@@ -101,13 +144,18 @@ func (m *SecondaryDiskMonitor) Check(ctx *AppContext, s *Stats) []MonitorAlert {
 		if !ok {
 			diskCfg = ResourceConfig{Enabled: true, WarningThreshold: 90.0, CriticalThreshold: 95.0}
 		}
-		if diskCfg.Enabled {
-			if volStats.Used >= diskCfg.CriticalThreshold {
-				alerts = append(alerts, MonitorAlert{"critical", fmt.Sprintf("🗄 Disk %s critical: `%.1f%%`", mountPoint, volStats.Used)})
-			} else if volStats.Used >= diskCfg.WarningThreshold {
-				alerts = append(alerts, MonitorAlert{"warning", fmt.Sprintf("Disk %s at %.1f%%", mountPoint, volStats.Used)})
-			}
+		if !diskCfg.Enabled {
+			continue
 		}
+		a := MonitorAlert{ID: "disk:" + mountPoint, Label: "Disk " + mountPoint, Value: volStats.Used}
+		a.Level, a.Threshold = thresholdLevel(volStats.Used, diskCfg.WarningThreshold, diskCfg.CriticalThreshold)
+		switch a.Level {
+		case AlertLevelCritical:
+			a.Message = fmt.Sprintf("🗄 Disk %s critical: `%.1f%%`", mountPoint, volStats.Used)
+		case AlertLevelWarning:
+			a.Message = fmt.Sprintf("Disk %s at %.1f%%", mountPoint, volStats.Used)
+		}
+		alerts = append(alerts, a)
 	}
 	return alerts
 }
@@ -126,16 +174,7 @@ func (m *SMARTMonitor) Check(ctx *AppContext, s *Stats) []MonitorAlert {
 
 	var cache map[string]model.SmartResult
 	if needsCheck {
-		newCache := make(map[string]model.SmartResult)
-		for _, dev := range getSmartDevices(ctx) {
-			temp, health := readDiskSMART(dev)
-			newCache[dev] = model.SmartResult{Temp: temp, Health: health}
-		}
-		ctx.Monitor.Mu.Lock()
-		ctx.Monitor.SmartCache = newCache
-		ctx.Monitor.SmartLastCheckTime = time.Now()
-		ctx.Monitor.Mu.Unlock()
-		cache = newCache
+		cache = readSMARTCache(ctx)
 	} else {
 		ctx.Monitor.Mu.Lock()
 		cache = make(map[string]model.SmartResult)
@@ -146,12 +185,149 @@ func (m *SMARTMonitor) Check(ctx *AppContext, s *Stats) []MonitorAlert {
 	}
 
 	for dev, res := range cache {
+		health := MonitorAlert{ID: "smart:" + dev, Label: "Disk " + dev}
 		if strings.Contains(strings.ToUpper(res.Health), "FAIL") {
-			alerts = append(alerts, MonitorAlert{"critical", fmt.Sprintf("🚨 Disk %s FAILING — backup now!", dev)})
+			health.Level = AlertLevelCritical
+			health.Message = fmt.Sprintf("🚨 Disk %s FAILING — backup now!", dev)
+		}
+		alerts = append(alerts, health)
+
+		// A disk in standby has no reading but is not hot either.
+		if res.Temp > 0 || res.Standby {
+			crit := ctx.Config.Temperature.CriticalThreshold
+			temp := MonitorAlert{ID: "smart_temp:" + dev, Label: fmt.Sprintf("Disk %s temperature", dev), Value: float64(res.Temp), Threshold: crit}
+			if temp.Value >= crit {
+				temp.Level = AlertLevelCritical
+				temp.Message = fmt.Sprintf("🔥 Disk %s temp critical: %d°C", dev, res.Temp)
+			}
+			alerts = append(alerts, temp)
+		}
+		alerts = append(alerts, smartCounterAlerts(dev, res)...)
+
+		selfTest := MonitorAlert{ID: "smart_selftest:" + dev, Label: fmt.Sprintf("Disk %s self-test", dev)}
+		if res.SelfTestFailed {
+			selfTest.Level = AlertLevelCritical
+			selfTest.Message = fmt.Sprintf("🧪 Disk %s failed its self-test: `%s`", dev, res.LastSelfTest)
+		}
+		alerts = append(alerts, selfTest)
+
+		if res.PercentUsed >= 0 {
+			warn := ctx.Config.Notifications.SMART.WearWarningPercent
+			wear := MonitorAlert{ID: "smart_wear:" + dev, Label: fmt.Sprintf("Disk %s wear", dev), Value: float64(res.PercentUsed), Threshold: float64(warn), NotifyWarning: true}
+			if warn > 0 && res.PercentUsed >= warn {
+				wear.Level = AlertLevelWarning
+				wear.Message = fmt.Sprintf("🧮 Disk %s has used %d%% of its rated life", dev, res.PercentUsed)
+			}
+			alerts = append(alerts, wear)
+		}
+	}
+	return alerts
+}
+
+// readSMARTCache reads every disk, compares its counters with the last
+// persisted reading and stores the result as the new SMART cache. Disks
+// in standby are left asleep and keep their previous reading.
+func readSMARTCache(ctx *AppContext) map[string]model.SmartResult {
+	now := time.Now()
+	cache := make(map[string]model.SmartResult)
+	counters := make(map[string]map[string]int64)
+	power := make(map[string]smart.Report)
+	for _, dev := range getSmartDevices(ctx) {
+		r, err := readSMARTReport(dev, false)
+		if err != nil {
+			slog.Warn("smartctl read failed", "device", dev, "err", err)
+			cache[dev] = model.SmartResult{Temp: -1, Health: smart.HealthUnknown, PowerOnHours: -1, PercentUsed: -1}
+			continue
+		}
+		power[dev] = r
+		if r.Standby {
+			continue
+		}
+		res := model.SmartResult{
+			Temp:         r.Temp,
+			Health:       r.Health,
+			Model:        r.Model,
+			PowerOnHours: r.PowerOnHours,
+			PercentUsed:  r.PercentUsed,
+			Counters:     r.Counters,
+		}
+		if len(r.SelfTests) > 0 {
+			res.LastSelfTest = r.SelfTests[0].Type + ": " + r.SelfTests[0].Status
+			res.SelfTestFailed = r.SelfTests[0].Failed
+		}
+		cache[dev] = res
+		counters[dev] = r.Counters
+	}
+
+	ctx.Monitor.Mu.Lock()
+	if ctx.Monitor.SmartCounters == nil {
+		ctx.Monitor.SmartCounters = make(map[string]map[string]int64)
+	}
+	if ctx.Monitor.DiskPower == nil {
+		ctx.Monitor.DiskPower = make(map[string]*DiskPowerStats)
+	}
+	for dev, r := range power {
+		if r.Standby {
+			prev, ok := ctx.Monitor.SmartCache[dev]
+			if !ok {
+				prev = model.SmartResult{Health: smart.HealthStandby, PowerOnHours: -1, PercentUsed: -1}
+			}
+			prev.Standby, prev.Temp, prev.Increases = true, -1, nil
+			cache[dev] = prev
+		}
+		stats := ctx.Monitor.DiskPower[dev]
+		if stats == nil {
+			stats = &DiskPowerStats{}
+			ctx.Monitor.DiskPower[dev] = stats
+		}
+		stats.Observe(now, r.Standby, r.StartStops)
+	}
+	changed := false
+	for dev, cur := range counters {
+		res := cache[dev]
+		res.Increases = smart.Increases(ctx.Monitor.SmartCounters[dev], cur)
+		cache[dev] = res
+		if !maps.Equal(ctx.Monitor.SmartCounters[dev], cur) {
+			ctx.Monitor.SmartCounters[dev] = cur
+			changed = true
+		}
+	}
+	ctx.Monitor.SmartCache = cache
+	ctx.Monitor.SmartLastCheckTime = now
+	ctx.Monitor.Mu.Unlock()
+
+	if changed {
+		saveState(ctx)
+	}
+	return cache
+}
+
+// smartCounterAlerts reports the SMART counters that grew at the last
+// reading. Any new bad sector means the disk is wearing out; CRC errors
+// usually point to a cable, so those are warnings. The alerts have no
+// threshold to hold them: the next reading without growth resolves them,
+// and the counter value as signature notifies every new increase.
+func smartCounterAlerts(dev string, res model.SmartResult) []MonitorAlert {
+	var alerts []MonitorAlert
+	for _, name := range smart.CounterNames {
+		cur, ok := res.Counters[name]
+		if !ok {
+			continue
 		}
-		if res.Temp > 0 && float64(res.Temp) >= ctx.Config.Temperature.CriticalThreshold {
-			alerts = append(alerts, MonitorAlert{"critical", fmt.Sprintf("🔥 Disk %s temp critical: %d°C", dev, res.Temp)})
+		label := strings.ReplaceAll(name, "_", " ")
+		a := MonitorAlert{ID: "smart_counter:" + dev + ":" + name, Label: fmt.Sprintf("Disk %s %s", dev, label), Value: float64(cur)}
+		if prev, grew := res.Increases[name]; grew {
+			a.Signature = strconv.FormatInt(cur, 10)
+			a.Message = fmt.Sprintf("💽 Disk %s %s: `%d` → `%d`", dev, label, prev, cur)
+			if name == smart.CRCErrors {
+				a.Level = AlertLevelWarning
+				a.NotifyWarning = true
+				a.Message += " (check the cable)"
+			} else {
+				a.Level = AlertLevelCritical
+			}
 		}
+		alerts = append(alerts, a)
 	}
 	return alerts
 }
@@ -167,6 +343,8 @@ func monitorAlerts(ctx *AppContext, bot BotAPI, runCtx context.Context) {
 		&SSDMonitor{},
 		&SecondaryDiskMonitor{},
 		&SMARTMonitor{},
+		&ContainerLimitsMonitor{},
+		&ContainerLogsMonitor{},
 	}
 
 	for {
@@ -178,53 +356,99 @@ func monitorAlerts(ctx *AppContext, bot BotAPI, runCtx context.Context) {
 			if !ready {
 				continue
 			}
-
-			var criticalAlerts []string
-			var allAlerts []MonitorAlert
-
+			var alerts []MonitorAlert
 			for _, m := range monitors {
-				alerts := m.Check(ctx, &s)
-				allAlerts = append(allAlerts, alerts...)
-				for _, a := range alerts {
-					if a.Level == "critical" {
-						criticalAlerts = append(criticalAlerts, a.Message)
-					}
-				}
+				alerts = append(alerts, m.Check(ctx, &s)...)
 			}
+			processMonitorAlerts(ctx, bot, alerts)
+		}
+	}
+}
 
-			cfg := ctx.Config
-			cooldown := time.Duration(cfg.Intervals.CriticalAlertCooldownMins) * time.Minute
-			ctx.Monitor.Mu.Lock()
-			lastAlert := ctx.Monitor.LastCriticalAlert
-			ctx.Monitor.Mu.Unlock()
-
-			if len(criticalAlerts) > 0 && time.Since(lastAlert) >= cooldown && !ctx.IsQuietHours() {
-				msg := "🚨 *Critical*\n\n" + strings.Join(criticalAlerts, "\n")
-				m := tgbotapi.NewMessage(cfg.AllowedUserID, msg)
-				m.ParseMode = "Markdown"
-
-				kb := tgbotapi.NewInlineKeyboardMarkup(
-					tgbotapi.NewInlineKeyboardRow(
-						tgbotapi.NewInlineKeyboardButtonData("🤖 "+ctx.Tr("analyze_with_ai"), "ai_analyze_critical"),
-					),
-				)
-				m.ReplyMarkup = kb
-
-				safeSend(bot, m)
-				ctx.Monitor.Mu.Lock()
-				ctx.Monitor.LastCriticalAlert = time.Now()
-				ctx.Monitor.Mu.Unlock()
+// processMonitorAlerts runs one round of monitor readings through the alert
+// manager. Critical notifications of the same round are merged into a single
+// message, as are escalations and resolved notices.
+func processMonitorAlerts(ctx *AppContext, bot BotAPI, alerts []MonitorAlert) {
+	var criticalAlerts, criticalIDs, escalated, escalatedIDs, warnings, resolved []string
+	var criticalActions, warningActions []tgbotapi.InlineKeyboardButton
+	quiet := ctx.IsQuietHours()
+	for _, a := range alerts {
+		obs := a.observation()
+		obs.Quiet = quiet
+		ev := observeAlert(ctx, obs)
+		switch ev.Kind {
+		case AlertFired, AlertReminder, AlertEscalated:
+			if ev.Kind == AlertFired && !ev.Deferred {
+				ctx.State.AddEvent(ev.Level, strings.ReplaceAll(a.Message, "`", ""))
 			}
-
-			for _, a := range allAlerts {
-				// We still add them to state, mapping critical string appropriately if needed
-				// For the UI state, it expects "critical" or "warning" with the raw text, but without markdown
-				// We'll clean up markdown for state log slightly, or just use as is.
-				cleanMsg := strings.ReplaceAll(a.Message, "`", "")
-				ctx.State.AddEvent(a.Level, cleanMsg)
+			if ev.Notify && ev.Level == AlertLevelWarning && a.NotifyWarning {
+				warnings = append(warnings, a.Message)
+				warningActions = append(warningActions, a.Actions...)
+				continue
+			}
+			if !ev.Notify || ev.Level != AlertLevelCritical {
+				continue
+			}
+			if ev.Kind == AlertEscalated {
+				escalated = append(escalated, a.Message)
+				escalatedIDs = append(escalatedIDs, a.ID)
+			} else {
+				criticalAlerts = append(criticalAlerts, a.Message)
+				criticalIDs = append(criticalIDs, a.ID)
+				criticalActions = append(criticalActions, a.Actions...)
 			}
+		case AlertResolved:
+			ctx.State.AddEvent("info", a.Label+" back to normal")
+			if ev.Notify {
+				resolved = append(resolved, resolvedText(ctx, a.Label, ev))
+			}
+		}
+	}
+
+	if len(criticalAlerts) > 0 {
+		msg := "🚨 *Critical*\n\n" + strings.Join(criticalAlerts, "\n")
+		m := criticalAlertMessage(ctx, msg, true, criticalIDs...)
+		kb := m.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+		m.ReplyMarkup = withActionRows(kb, criticalActions)
+		sendAlert(bot, ctx.Config, AlertTopicCritical, m)
+	}
+	if len(escalated) > 0 {
+		msg := fmt.Sprintf(ctx.Tr("alert_escalated"), strings.Join(escalated, "\n"))
+		sendEscalation(bot, ctx.Config, criticalAlertMessage(ctx, msg, true, escalatedIDs...))
+	}
+	if len(warnings) > 0 {
+		msg := "⚠️ *Warning*\n\n" + strings.Join(warnings, "\n")
+		m := alertMessage(msg)
+		if len(warningActions) > 0 {
+			m.ReplyMarkup = withActionRows(tgbotapi.NewInlineKeyboardMarkup(), warningActions)
+		}
+		sendAlert(bot, ctx.Config, AlertTopicWarning, m)
+	}
+	if len(resolved) > 0 {
+		sendAlert(bot, ctx.Config, AlertTopicInfo, alertMessage(strings.Join(resolved, "\n")))
+	}
+}
+
+// withActionRows appends alert action buttons to kb, two per row. Alerts
+// about the same container can offer the same button; it is shown once.
+func withActionRows(kb tgbotapi.InlineKeyboardMarkup, actions []tgbotapi.InlineKeyboardButton) tgbotapi.InlineKeyboardMarkup {
+	seen := make(map[string]bool)
+	unique := actions[:0:0]
+	for _, b := range actions {
+		key := b.Text
+		if b.CallbackData != nil {
+			key = *b.CallbackData
 		}
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, b)
+		}
+	}
+	actions = unique
+	for i := 0; i < len(actions); i += 2 {
+		kb.InlineKeyboard = append(kb.InlineKeyboard, actions[i:min(i+2, len(actions))])
 	}
+	return kb
 }
 
 func statsCollector(ctx *AppContext, runCtx context.Context) {
@@ -409,51 +633,34 @@ func checkTemperatureAlert(ctx *AppContext, bot BotAPI) {
 		return
 	}
 
-	ctx.Monitor.Mu.Lock()
-	if time.Since(ctx.Monitor.LastTempAlert) < 30*time.Minute {
-		ctx.Monitor.Mu.Unlock()
-		return
-	}
-	ctx.Monitor.Mu.Unlock()
-
 	cfg := ctx.Config
-	if temp >= cfg.Temperature.CriticalThreshold {
-		var m tgbotapi.MessageConfig
-		sendMsg := false
-		if !ctx.IsQuietHours() {
-			msg := fmt.Sprintf("🔥 *CPU Temperature Critical!*\n\nCurrent: `%.1f°C`\nThreshold: `%.0f°C`\n\n_Consider checking cooling or reducing load_", temp, cfg.Temperature.CriticalThreshold)
-			m = tgbotapi.NewMessage(cfg.AllowedUserID, msg)
-			m.ParseMode = "Markdown"
-			sendMsg = true
-		}
-
-		ctx.Monitor.Mu.Lock()
-		ctx.Monitor.LastTempAlert = time.Now()
-		ctx.Monitor.Mu.Unlock()
-
-		if sendMsg {
-			safeSend(bot, m)
+	level, threshold := thresholdLevel(temp, cfg.Temperature.WarningThreshold, cfg.Temperature.CriticalThreshold)
+	ev := observeAlert(ctx, AlertObservation{ID: "temp:cpu", Level: level, Value: temp, Threshold: threshold, Quiet: ctx.IsQuietHours()})
+
+	var msg, topic string
+	switch {
+	case ev.Kind == AlertResolved:
+		ctx.State.AddEvent("info", fmt.Sprintf("CPU temp back to normal: %.1f°C", temp))
+		if ev.Notify {
+			msg, topic = resolvedText(ctx, "CPU temperature", ev), AlertTopicInfo
 		}
+	case ev.Kind == AlertUnchanged:
+		return
+	case ev.Level == AlertLevelCritical:
 		ctx.State.AddEvent("critical", fmt.Sprintf("CPU temp critical: %.1f°C", temp))
-	} else if temp >= cfg.Temperature.WarningThreshold {
-		var m tgbotapi.MessageConfig
-		sendMsg := false
-		if !ctx.IsQuietHours() {
-			msg := fmt.Sprintf("🌡 *CPU Temperature Warning*\n\nCurrent: `%.1f°C`\nThreshold: `%.0f°C`", temp, cfg.Temperature.WarningThreshold)
-			m = tgbotapi.NewMessage(cfg.AllowedUserID, msg)
-			m.ParseMode = "Markdown"
-			sendMsg = true
-		}
-
-		ctx.Monitor.Mu.Lock()
-		ctx.Monitor.LastTempAlert = time.Now()
-		ctx.Monitor.Mu.Unlock()
-
-		if sendMsg {
-			safeSend(bot, m)
+		if ev.Notify {
+			notifyCritical(ctx, bot, ev, fmt.Sprintf("🔥 *CPU Temperature Critical!*\n\nCurrent: `%.1f°C`\nThreshold: `%.0f°C`\n\n_Consider checking cooling or reducing load_", temp, cfg.Temperature.CriticalThreshold), false)
 		}
+		return
+	default:
+		msg = fmt.Sprintf("🌡 *CPU Temperature Warning*\n\nCurrent: `%.1f°C`\nThreshold: `%.0f°C`", temp, cfg.Temperature.WarningThreshold)
+		topic = AlertTopicWarning
 		ctx.State.AddEvent("warning", fmt.Sprintf("CPU temp high: %.1f°C", temp))
 	}
+
+	if ev.Notify && msg != "" {
+		sendAlert(bot, ctx.Config, topic, alertMessage(msg))
+	}
 }
 
 func recordTrendPoint(ctx *AppContext) {
@@ -518,43 +725,47 @@ func checkCriticalContainers(ctx *AppContext, bot BotAPI) {
 	}
 
 	containers := getCachedContainerList(ctx)
-	containerMap := make(map[string]bool)
+	containerMap := make(map[string]ContainerInfo)
 	for _, c := range containers {
-		containerMap[c.Name] = c.Running
+		containerMap[c.Name] = c
 	}
 
 	for _, name := range ctx.Config.CriticalContainers {
-		running, exists := containerMap[name]
-		if !exists || !running {
-			ctx.Monitor.Mu.Lock()
-			lastAlert, ok := ctx.Monitor.LastCriticalContainerAlert[name]
-			ctx.Monitor.Mu.Unlock()
-
-			if ok && time.Since(lastAlert) < 10*time.Minute {
-				continue
-			}
+		c, exists := containerMap[name]
+		unhealthy := c.Running && c.Health == "unhealthy"
+		level := AlertLevelOK
+		if !exists || !c.Running || unhealthy {
+			level = AlertLevelCritical
+		}
+		ev := observeAlert(ctx, AlertObservation{ID: "container:" + name, Level: level, Quiet: ctx.IsQuietHours()})
+		if unhealthy && ctx.Config.Docker.AutoRestartUnhealthy {
+			restartUnhealthyContainer(ctx, bot, name)
+		}
 
-			var m tgbotapi.MessageConfig
-			sendMsg := false
-			if !ctx.IsQuietHours() {
+		switch ev.Kind {
+		case AlertFired, AlertReminder, AlertEscalated:
+			if ev.Notify {
 				status := ctx.Tr("status_not_running")
 				if !exists {
 					status = ctx.Tr("status_not_found")
+				} else if unhealthy {
+					status = ctx.Tr("status_unhealthy")
 				}
-				msg := fmt.Sprintf(ctx.Tr("crit_cont_alert"), name, status)
-				m = tgbotapi.NewMessage(ctx.Config.AllowedUserID, msg)
-				m.ParseMode = "Markdown"
-				sendMsg = true
+				notifyCritical(ctx, bot, ev, fmt.Sprintf(ctx.Tr("crit_cont_alert"), name, status), false)
 			}
-
-			ctx.Monitor.Mu.Lock()
-			ctx.Monitor.LastCriticalContainerAlert[name] = time.Now()
-			ctx.Monitor.Mu.Unlock()
-
-			if sendMsg {
-				safeSend(bot, m)
+			if ev.Deferred {
+				break
+			}
+			if unhealthy {
+				ctx.State.AddEvent("critical", fmt.Sprintf("Critical container %s unhealthy", name))
+			} else {
+				ctx.State.AddEvent("critical", fmt.Sprintf("Critical container %s down", name))
+			}
+		case AlertResolved:
+			if ev.Notify {
+				sendAlert(bot, ctx.Config, AlertTopicInfo, alertMessage(resolvedText(ctx, "Container "+name, ev)))
 			}
-			ctx.State.AddEvent("critical", fmt.Sprintf("Critical container %s down", name))
+			ctx.State.AddEvent("info", fmt.Sprintf("Critical container %s back up", name))
 		}
 	}
 }
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"nasbot/internal/smart"
+)
+
+func smartJSON(reallocated, crc int) []byte {
+	return []byte(fmt.Sprintf(`{
+  "device": {"protocol": "ATA"},
+  "model_name": "WDC WD40EFRX",
+  "smart_status": {"passed": true},
+  "temperature": {"current": 35},
+  "power_on_time": {"hours": 36012},
+  "ata_smart_attributes": {"table": [
+    {"id": 5, "value": 200, "raw": {"value": %d}},
+    {"id": 199, "value": 200, "raw": {"value": %d}}
+  ]}
+}`, reallocated, crc))
+}
+
+func TestSMARTMonitorCounterIncreases(t *testing.T) {
+	t.Setenv("NASBOT_STATE_FILE", t.TempDir()+"/state.json")
+	ctx := newTestAppContext()
+	ctx.Config.Notifications.SMART = SmartConfig{Enabled: true, Devices: []string{"sda"}, WearWarningPercent: 80}
+	ctx.Config.Temperature.CriticalThreshold = 60
+
+	check := func(reallocated, crc int) map[string]MonitorAlert {
+		defer setCommandRunner(mockRunner{exists: true, out: smartJSON(reallocated, crc)})()
+		ctx.Monitor.SmartLastCheckTime = time.Time{}
+		byID := make(map[string]MonitorAlert)
+		for _, a := range (&SMARTMonitor{}).Check(ctx, nil) {
+			byID[a.ID] = a
+		}
+		return byID
+	}
+
+	alerts := check(8, 1)
+	if a := alerts["smart_counter:sda:reallocated_sectors"]; a.Level != AlertLevelOK || a.Value != 8 {
+		t.Fatalf("first reading should only set the baseline: %+v", a)
+	}
+	if res := ctx.Monitor.SmartCache["sda"]; res.PowerOnHours != 36012 || res.Model != "WDC WD40EFRX" {
+		t.Fatalf("unexpected cache: %+v", res)
+	}
+
+	// The baseline survives a restart.
+	ctx.Monitor.SmartCounters = nil
+	loadState(ctx)
+	if ctx.Monitor.SmartCounters["sda"][smart.Reallocated] != 8 {
+		t.Fatalf("counters not persisted: %v", ctx.Monitor.SmartCounters)
+	}
+
+	alerts = check(12, 3)
+	realloc := alerts["smart_counter:sda:reallocated_sectors"]
+	if realloc.Level != AlertLevelCritical || !strings.Contains(realloc.Message, "`8` → `12`") {
+		t.Fatalf("reallocated growth not reported: %+v", realloc)
+	}
+	if crc := alerts["smart_counter:sda:crc_errors"]; crc.Level != AlertLevelWarning || !crc.NotifyWarning {
+		t.Fatalf("CRC growth should warn: %+v", crc)
+	}
+	if _, ok := alerts["smart_wear:sda"]; ok {
+		t.Fatalf("HDD without a wear attribute got a wear alert")
+	}
+
+	if a := check(12, 3)["smart_counter:sda:reallocated_sectors"]; a.Level != AlertLevelOK {
+		t.Fatalf("stable counter still alerting: %+v", a)
+	}
+}
+
+func TestSMARTCounterAlertResolves(t *testing.T) {
+	t.Setenv("NASBOT_STATE_FILE", t.TempDir()+"/state.json")
+	ctx := newTestAppContext()
+	ctx.Config.Notifications.SMART = SmartConfig{Enabled: true, Devices: []string{"sda"}, WearWarningPercent: 80}
+	ctx.Config.Temperature.CriticalThreshold = 60
+	ctx.Config.Alerts.Defaults = AlertRuleConfig{Hysteresis: 2, NotifyResolved: true}
+	bot := &fakeBot{}
+
+	read := func(reallocated int) {
+		defer setCommandRunner(mockRunner{exists: true, out: smartJSON(reallocated, 1)})()
+		ctx.Monitor.SmartLastCheckTime = time.Time{}
+		processMonitorAlerts(ctx, bot, (&SMARTMonitor{}).Check(ctx, nil))
+	}
+
+	read(8)
+	read(12)
+	if texts := sentTexts(bot); len(texts) != 1 || !strings.Contains(texts[0], "`8` → `12`") {
+		t.Fatalf("growth not reported: %q", texts)
+	}
+	// Growing again while the alert fires is reported again.
+	read(20)
+	if texts := sentTexts(bot); len(texts) != 2 || !strings.Contains(texts[1], "`12` → `20`") {
+		t.Fatalf("second growth not reported: %q", texts)
+	}
+	// The next reading without growth resolves the alert, even though the
+	// counter stays above its old value.
+	read(20)
+	texts := sentTexts(bot)
+	if len(texts) != 3 || !strings.Contains(texts[2], "reallocated sectors") {
+		t.Fatalf("growth alert not resolved: %q", texts)
+	}
+}
+
+func TestSMARTMonitorWear(t *testing.T) {
+	ctx := newTestAppContext()
+	ctx.Config.Notifications.SMART = SmartConfig{Enabled: true, Devices: []string{"nvme0n1"}, WearWarningPercent: 80}
+	ctx.Monitor.SmartLastCheckTime = time.Now()
+	ctx.Monitor.SmartCache = map[string]SmartResult{"nvme0n1": {Temp: 40, Health: "PASSED", PercentUsed: 85, PowerOnHours: -1}}
+
+	for _, a := range (&SMARTMonitor{}).Check(ctx, nil) {
+		if a.ID == "smart_wear:nvme0n1" {
+			if a.Level != AlertLevelWarning || !strings.Contains(a.Message, "85%") {
+				t.Fatalf("unexpected wear alert: %+v", a)
+			}
+			return
+		}
+	}
+	t.Fatalf("no wear alert")
+}
+
+const smartStandbyJSON = `{
+  "smartctl": {"messages": [{"string": "Device is in STANDBY mode, exit(2)", "severity": "information"}], "exit_status": 2},
+  "device": {"name": "/dev/sda", "protocol": "ATA"}
+}`
+
+func TestSMARTMonitorStandby(t *testing.T) {
+	t.Setenv("NASBOT_STATE_FILE", t.TempDir()+"/state.json")
+	ctx := newTestAppContext()
+	ctx.Config.Notifications.SMART = SmartConfig{Enabled: true, Devices: []string{"sda"}, WearWarningPercent: 80}
+	ctx.Config.Temperature.CriticalThreshold = 60
+
+	check := func(out []byte) map[string]MonitorAlert {
+		defer setCommandRunner(mockRunner{exists: true, out: out})()
+		ctx.Monitor.SmartLastCheckTime = time.Time{}
+		byID := make(map[string]MonitorAlert)
+		for _, a := range (&SMARTMonitor{}).Check(ctx, nil) {
+			byID[a.ID] = a
+		}
+		return byID
+	}
+
+	check(smartJSON(8, 1))
+	alerts := check([]byte(smartStandbyJSON))
+	res := ctx.Monitor.SmartCache["sda"]
+	if !res.Standby || res.Temp != -1 || res.Model != "WDC WD40EFRX" || res.Counters[smart.Reallocated] != 8 {
+		t.Fatalf("standby disk should keep its last reading: %+v", res)
+	}
+	if a, ok := alerts["smart_temp:sda"]; !ok || a.Level != AlertLevelOK {
+		t.Fatalf("temperature alert should resolve in standby: %+v", a)
+	}
+	if ctx.Monitor.SmartCounters["sda"][smart.Reallocated] != 8 {
+		t.Fatalf("baseline changed in standby: %v", ctx.Monitor.SmartCounters)
+	}
+
+	check(smartJSON(8, 1))
+	p := ctx.Monitor.DiskPower["sda"]
+	if p == nil || p.Standby || p.SpinUps != 1 {
+		t.Fatalf("spin-up not counted: %+v", p)
+	}
+
+	*p = DiskPowerStats{Tracked: 4 * time.Hour, StandbyTime: 3 * time.Hour, SpinUps: 2}
+	if got := getDiskSpinSummary(ctx); got != "💤 sda: standby 75% · 2 spin-ups" {
+		t.Fatalf("getDiskSpinSummary() = %q", got)
+	}
+	resetDiskSpinStats(ctx)
+	if got := getDiskSpinSummary(ctx); got != "" {
+		t.Fatalf("summary after reset = %q", got)
+	}
+}
@@ -6,8 +6,6 @@ import (
 	"time"
 
 	"nasbot/internal/format"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 func checkResourceStress(ctx *AppContext, bot BotAPI, resource string, currentValue, threshold float64) {
@@ -80,9 +78,8 @@ func checkResourceStress(ctx *AppContext, bot BotAPI, resource string, currentVa
 
 	// Perform I/O and non-reentrant lock calls outside the locked section
 	if notifyMsg != "" {
-		m := tgbotapi.NewMessage(ctx.Config.AllowedUserID, notifyMsg)
-		m.ParseMode = "Markdown"
-		safeSend(bot, m)
+		m := alertMessage(notifyMsg)
+		sendAlert(bot, ctx.Config, AlertTopicWarning, m)
 	}
 
 	if eventType != "" {
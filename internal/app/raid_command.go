@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"nasbot/internal/format"
+	"nasbot/internal/mdstat"
+)
+
+// handleRaidCommand handles /raid.
+func handleRaidCommand(ctx *AppContext, bot BotAPI, chatID int64) {
+	arrays, err := readMdstat()
+	sendMarkdown(bot, chatID, getRaidText(ctx, arrays, err))
+}
+
+func getRaidText(ctx *AppContext, arrays []mdstat.Array, err error) string {
+	tr := ctx.Tr
+	var b strings.Builder
+	b.WriteString(tr("raid_title"))
+	switch {
+	case err != nil:
+		b.WriteString(tr("raid_no_md"))
+	case len(arrays) == 0:
+		b.WriteString(tr("raid_none"))
+	}
+	for _, a := range arrays {
+		b.WriteString(getRaidArrayText(ctx, a))
+	}
+
+	if commandExists("zpool") {
+		c, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		out, zerr := runCommandStdout(c, "zpool", "status", "-x")
+		cancel()
+		if zerr == nil {
+			b.WriteString(fmt.Sprintf("\n*ZFS:* `%s`\n", format.Truncate(strings.TrimSpace(string(out)), 300)))
+		}
+	}
+	return b.String()
+}
+
+func getRaidArrayText(ctx *AppContext, a mdstat.Array) string {
+	tr := ctx.Tr
+	icon, state := "✅", ""
+	switch {
+	case !a.Active:
+		icon, state = "🚨", tr("raid_inactive")
+	case a.Degraded():
+		icon, state = "⚠️", tr("raid_degraded")
+	case len(a.Faulty()) > 0:
+		icon = "⚠️"
+	}
+
+	line := fmt.Sprintf("%s *%s*", icon, a.Name)
+	if a.Level != "" {
+		line += " " + a.Level
+	}
+	if a.Status != "" {
+		line += fmt.Sprintf(" `[%d/%d] [%s]`", a.Disks, a.Up, a.Status)
+	}
+	if a.Blocks > 0 {
+		line += " · " + format.FormatBytes(uint64(a.Blocks)*1024)
+	}
+	if a.ReadOnly {
+		line += " · " + tr("raid_read_only")
+	}
+	if state != "" {
+		line += " — " + state
+	}
+
+	members := make([]string, 0, len(a.Members))
+	for _, m := range a.Members {
+		name := "`" + m.Name + "`"
+		switch {
+		case m.Faulty:
+			name += " ❌"
+		case m.Spare:
+			name += " " + tr("raid_spare")
+		}
+		members = append(members, name)
+	}
+
+	var b strings.Builder
+	b.WriteString(line + "\n")
+	if len(members) > 0 {
+		b.WriteString("    " + strings.Join(members, " · ") + "\n")
+	}
+	if s := a.Sync; s != nil {
+		if s.Waiting != "" {
+			b.WriteString(fmt.Sprintf(tr("raid_sync_waiting"), s.Action))
+		} else {
+			b.WriteString(fmt.Sprintf("    🔄 %s %s\n", s.Action, raidSyncProgress(*s)))
+		}
+	}
+	return b.String()
+}
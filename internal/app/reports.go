@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"nasbot/internal/compose"
 	"nasbot/internal/format"
 )
 
@@ -29,11 +30,13 @@ func generateDailyReport(ctx *AppContext, greeting string, onModelChange func(st
 	events = filterSignificantEvents(events)
 
 	periodDesc := ""
+	periodStart := lastReportTime
 	if !lastReportTime.IsZero() {
 		periodDesc = fmt.Sprintf("%s → %s", lastReportTime.In(ctx.State.TimeLocation).Format("15:04"), now.Format("15:04"))
 	} else {
 		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, ctx.State.TimeLocation)
 		periodDesc = fmt.Sprintf("%s → %s", midnight.Format("15:04"), now.Format("15:04"))
+		periodStart = midnight
 	}
 
 	aiReport, aiErr := generateAIReport(ctx, events, onModelChange)
@@ -104,12 +107,22 @@ func generateDailyReport(ctx *AppContext, greeting string, onModelChange func(st
 	if stopped > 0 {
 		b.WriteString(fmt.Sprintf(", %d %s", stopped, ctx.Tr("containers_stopped")))
 	}
+	if stacks, _ := compose.Group(containers); len(stacks) > 0 {
+		b.WriteString("\nStacks: " + compose.Summary(stacks))
+	}
+	if avg := reportAverages(ctx, periodStart, now); avg != "" {
+		b.WriteString("\n" + avg)
+	}
 
 	stressSummary := getStressSummary(ctx)
 	if stressSummary != "" {
 		b.WriteString(fmt.Sprintf("\n\n*%s*\n", ctx.Tr("report_stress")))
 		b.WriteString(stressSummary)
 	}
+	if spin := getDiskSpinSummary(ctx); spin != "" {
+		b.WriteString(fmt.Sprintf("\n\n*%s*\n", ctx.Tr("report_disks")))
+		b.WriteString(spin)
+	}
 
 	b.WriteString(fmt.Sprintf("\n\n_Up for %s_\n", format.FormatUptime(s.Uptime)))
 	if periodDesc != "" {
@@ -117,9 +130,24 @@ func generateDailyReport(ctx *AppContext, greeting string, onModelChange func(st
 	}
 
 	resetStressCounters(ctx)
+	resetDiskSpinStats(ctx)
 	return b.String()
 }
 
+// reportAverages summarizes CPU and RAM over the report period from the
+// persistent history, so the figures survive restarts.
+func reportAverages(ctx *AppContext, from, to time.Time) string {
+	if ctx.History == nil {
+		return ""
+	}
+	cpu, okCPU := ctx.History.Summary(HistoryCPU, from, to)
+	ram, okRAM := ctx.History.Summary(HistoryRAM, from, to)
+	if !okCPU || !okRAM {
+		return ""
+	}
+	return fmt.Sprintf(ctx.Tr("report_averages"), cpu.Avg, cpu.Max, ram.Avg, ram.Max)
+}
+
 // generateReport generates an on-demand, explicit NAS snapshot, typically triggered
 // by direct commands like /report. Reuses daily routines to fetch events and queries the AI.
 func generateReport(ctx *AppContext, manual bool, onModelChange func(string)) string {
@@ -158,7 +186,8 @@ func generateReport(ctx *AppContext, manual bool, onModelChange func(string)) st
 	}
 
 	running, stopped := 0, 0
-	for _, c := range getCachedContainerList(ctx) {
+	containers := getCachedContainerList(ctx)
+	for _, c := range containers {
 		if c.Running {
 			running++
 		} else {
@@ -166,6 +195,12 @@ func generateReport(ctx *AppContext, manual bool, onModelChange func(string)) st
 		}
 	}
 	b.WriteString(fmt.Sprintf("Containers: %d running, %d stopped\n", running, stopped))
+	if stacks, _ := compose.Group(containers); len(stacks) > 0 {
+		b.WriteString("Stacks: " + compose.Summary(stacks) + "\n")
+	}
+	if spin := getDiskSpinSummary(ctx); spin != "" {
+		b.WriteString(fmt.Sprintf("\n*%s*\n%s\n", ctx.Tr("report_disks"), spin))
+	}
 
 	b.WriteString(fmt.Sprintf("\n_Up for %s_\n", format.FormatUptime(s.Uptime)))
 	if periodDesc != "" {
@@ -6,6 +6,8 @@ import (
 	"os/exec"
 	"strings"
 	"time"
+
+	"nasbot/internal/docker"
 )
 
 // AnalyzeCriticalAlerts fetches system context and asks Gemini to diagnose the issue.
@@ -89,10 +91,11 @@ func fetchSystemContextForAI() string {
 	// Docker stats
 	ctxExec3, cancel3 := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel3()
-	outDocker, err := exec.CommandContext(ctxExec3, "docker", "stats", "--no-stream", "--format", "table {{.Name}}\t{{.CPUPerc}}\t{{.MemUsage}}").CombinedOutput()
-	if err == nil {
+	if stats, err := dockerClient.Stats(ctxExec3); err == nil {
 		sb.WriteString("Docker Stats:\n")
-		sb.WriteString(string(outDocker))
+		for _, st := range stats {
+			sb.WriteString(fmt.Sprintf("%s\t%.2f%%\t%s / %s\n", st.Name, st.CPUPercent, docker.FormatSize(st.MemUsage), docker.FormatSize(st.MemLimit)))
+		}
 	}
 
 	return sb.String()
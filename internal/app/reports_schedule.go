@@ -141,9 +141,15 @@ func periodicReport(ctx *AppContext, bot BotAPI, runCtx context.Context) {
 		}
 
 		report := generateDailyReport(ctx, greeting, nil)
-		if err := sendScheduledReport(bot, ctx.Config.AllowedUserID, report); err != nil {
-			slog.Error("Failed to send scheduled report", "err", err)
-		} else {
+		delivered := false
+		for _, chatID := range ctx.Config.AlertRecipients(AlertTopicReport) {
+			if err := sendScheduledReport(bot, chatID, report); err != nil {
+				slog.Error("Failed to send scheduled report", "chat", chatID, "err", err)
+				continue
+			}
+			delivered = true
+		}
+		if delivered {
 			ctx.State.Mu.Lock()
 			ctx.State.LastReport = time.Now()
 			ctx.State.Mu.Unlock()
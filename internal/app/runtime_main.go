@@ -30,6 +30,7 @@ func RunBot() {
 			slog.Error("PANIC recovered", "err", r, "stack", string(debug.Stack()))
 			if app != nil {
 				saveState(app)
+				saveHistory(app)
 			}
 			closeLogger()
 		}
@@ -52,9 +53,12 @@ func RunBot() {
 		loc = time.UTC
 	}
 	app.State.TimeLocation = loc
+	configureDockerClient(app.Config)
+	configureImageRegistry(app.Config)
 
 	// Load persistent state
 	loadState(app)
+	initHistory(app)
 	addPowerLifecycleEvent(app, "boot", false, "system", "startup", "process-start")
 	saveState(app)
 
@@ -103,9 +107,20 @@ func RunBot() {
 	goSafe("stats-collector", func() { statsCollector(app, rootCtx) })
 	goSafe("monitor-alerts", func() { monitorAlerts(app, bot, rootCtx) })
 	goSafe("autonomous-manager", func() { autonomousManager(app, bot, rootCtx) })
+	goSafe("docker-events", func() { dockerEventWatcher(app, bot, rootCtx) })
+	goSafe("image-updates", func() { imageUpdateChecker(app, bot, rootCtx) })
+	if app.Config.Docker.Dependencies.StartOnBoot {
+		goSafe("docker-boot-start", func() { ensureStartOrder(app, bot, rootCtx, "boot", 0, 0) })
+	}
 	goSafeResilient("periodic-report", rootCtx, 5*time.Second, func() { periodicReport(app, bot, rootCtx) })
 	goSafe("healthchecks-pinger", func() { startHealthchecksPinger(app, bot, rootCtx) })
 	goSafe("release-update-notifier", func() { updaterLoop(app, bot, rootCtx) })
+	if app.History != nil {
+		goSafe("history-recorder", func() { historyRecorder(app, rootCtx) })
+	}
+	if app.Config.API.Enabled {
+		goSafe("api-server", func() { startAPIServer(app, rootCtx) })
+	}
 
 	// Send /start signal to healthchecks.io
 	goSafe("healthchecks-start-ping", func() { pingHealthchecksStart(app) })
@@ -136,7 +151,7 @@ func RunBot() {
 				return
 			}
 
-			if update.Message.Chat.ID != int64(app.Config.AllowedUserID) {
+			if !app.Config.IsAuthorized(messageSenderID(update.Message)) {
 				return
 			}
 
@@ -151,6 +166,7 @@ func RunBot() {
 	}
 
 	saveState(app)
+	saveHistory(app)
 	select {
 	case <-shutdownDone:
 		slog.Info("NASBot shutdown complete")
@@ -238,9 +254,7 @@ func sendStartupNotification(ctx *AppContext, bot BotAPI) {
 	crashInfo := checkPreviousBootCrash(ctx)
 	startupText := fmt.Sprintf(ctx.Tr("boot_online"), nextReportStr, quietInfo) + updateInfo + crashInfo
 
-	msg := tgbotapi.NewMessage(int64(ctx.Config.AllowedUserID), startupText)
-	msg.ParseMode = "Markdown"
-	safeSend(bot, msg)
+	sendAlert(bot, ctx.Config, AlertTopicInfo, alertMessage(startupText))
 }
 
 func registerBotCommands(ctx *AppContext, bot BotAPI) {
@@ -249,6 +263,8 @@ func registerBotCommands(ctx *AppContext, bot BotAPI) {
 		{Command: "quick", Description: ctx.Tr("cmd_quick_desc")},
 		{Command: "docker", Description: ctx.Tr("cmd_docker_desc")},
 		{Command: "dstats", Description: ctx.Tr("cmd_docker_desc")}, // fallback description
+		{Command: "dupdates", Description: ctx.Tr("cmd_dupdates_desc")},
+		{Command: "ddisk", Description: ctx.Tr("cmd_ddisk_desc")},
 		{Command: "top", Description: ctx.Tr("cmd_top_desc")},
 		{Command: "temp", Description: ctx.Tr("cmd_temp_desc")},
 		{Command: "net", Description: ctx.Tr("cmd_net_desc")},
@@ -259,6 +275,9 @@ func registerBotCommands(ctx *AppContext, bot BotAPI) {
 		{Command: "config", Description: ctx.Tr("cmd_config_desc")},
 		{Command: "sysinfo", Description: ctx.Tr("cmd_sysinfo_desc")},
 		{Command: "diskpred", Description: ctx.Tr("cmd_diskpred_desc")},
+		{Command: "graph", Description: ctx.Tr("cmd_graph_desc")},
+		{Command: "smart", Description: ctx.Tr("cmd_smart_desc")},
+		{Command: "raid", Description: ctx.Tr("cmd_raid_desc")},
 		{Command: "settings", Description: ctx.Tr("cmd_settings_desc")},
 		{Command: "update", Description: ctx.Tr("cmd_update_desc")},
 		{Command: "changelog", Description: ctx.Tr("cmd_changelog_desc")},
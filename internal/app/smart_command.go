@@ -0,0 +1,126 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"nasbot/internal/smart"
+)
+
+// smartLogLines is how many self-test log entries /smart shows.
+const smartLogLines = 10
+
+// handleSmartCommand handles /smart [device].
+func handleSmartCommand(ctx *AppContext, bot BotAPI, chatID int64, args string) {
+	devices := getSmartDevices(ctx)
+	dev := strings.TrimPrefix(strings.TrimSpace(args), "/dev/")
+	if dev == "" {
+		sendMarkdown(bot, chatID, getSmartSummaryText(ctx, devices))
+		return
+	}
+	if !slices.Contains(devices, dev) {
+		sendMarkdown(bot, chatID, fmt.Sprintf(ctx.Tr("smart_unknown_dev"), dev, "`"+strings.Join(devices, "`, `")+"`"))
+		return
+	}
+	r, err := readSMARTReport(dev, true)
+	if err != nil {
+		sendMarkdown(bot, chatID, fmt.Sprintf(ctx.Tr("smart_read_err"), dev, err))
+		return
+	}
+	sendMarkdown(bot, chatID, getSmartDeviceText(ctx, dev, r))
+}
+
+func smartHealthIcon(health string) string {
+	switch health {
+	case smart.HealthPassed:
+		return "✅"
+	case smart.HealthFailed:
+		return "🚨"
+	case smart.HealthStandby:
+		return "💤"
+	}
+	return "❔"
+}
+
+func getSmartSummaryText(ctx *AppContext, devices []string) string {
+	var b strings.Builder
+	b.WriteString(ctx.Tr("smart_title"))
+	for _, dev := range devices {
+		r, err := readSMARTReport(dev, false)
+		if err != nil {
+			b.WriteString(fmt.Sprintf("❔ `%s` — %s\n", dev, ctx.Tr("smart_unavailable")))
+			continue
+		}
+		if r.Standby {
+			b.WriteString(fmt.Sprintf("%s `%s` — %s\n", smartHealthIcon(r.Health), dev, ctx.Tr("temp_disk_standby")))
+			continue
+		}
+		line := fmt.Sprintf("%s `%s` %s — %s", smartHealthIcon(r.Health), dev, r.Model, r.Health)
+		if r.Temp > 0 {
+			line += fmt.Sprintf(", %d°C", r.Temp)
+		}
+		if len(r.SelfTests) > 0 && r.SelfTests[0].Failed {
+			line += " · 🧪❌"
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n" + ctx.Tr("smart_usage"))
+	return b.String()
+}
+
+func getSmartDeviceText(ctx *AppContext, dev string, r smart.Report) string {
+	tr := ctx.Tr
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(tr("smart_dev_title"), dev))
+	if r.Model != "" {
+		model := "`" + r.Model + "`"
+		if r.Serial != "" {
+			model += " · `" + r.Serial + "`"
+		}
+		b.WriteString(fmt.Sprintf(tr("smart_model"), model))
+	}
+	b.WriteString(fmt.Sprintf(tr("smart_health"), smartHealthIcon(r.Health), r.Health))
+	if r.Temp > 0 {
+		b.WriteString(fmt.Sprintf(tr("smart_temp"), r.Temp))
+	}
+	if r.PowerOnHours >= 0 {
+		b.WriteString(fmt.Sprintf(tr("smart_power_on"), r.PowerOnHours, float64(r.PowerOnHours)/(24*365)))
+	}
+	if r.PercentUsed >= 0 {
+		b.WriteString(fmt.Sprintf(tr("smart_wear"), r.PercentUsed))
+	}
+	for _, name := range smart.CounterNames {
+		if n, ok := r.Counters[name]; ok {
+			b.WriteString(fmt.Sprintf("%s: `%d`\n", strings.ReplaceAll(name, "_", " "), n))
+		}
+	}
+
+	if r.Running() {
+		b.WriteString(fmt.Sprintf(tr("smart_test_running"), r.SelfTestRemaining))
+	}
+	if st := ctx.Config.Notifications.SMART.SelfTests; st.Enabled {
+		schedules := selfTestSchedules(st, dev)
+		b.WriteString(fmt.Sprintf(tr("smart_schedule"), testScheduleText(ctx, schedules[smart.TestShort]), testScheduleText(ctx, schedules[smart.TestLong])))
+	}
+
+	b.WriteString(tr("smart_log_title"))
+	if len(r.SelfTests) == 0 {
+		b.WriteString(tr("smart_log_empty"))
+		return b.String()
+	}
+	for i, t := range r.SelfTests {
+		if i == smartLogLines {
+			b.WriteString(fmt.Sprintf(tr("smart_log_more"), len(r.SelfTests)-i))
+			break
+		}
+		icon := "⚠️"
+		if t.Passed {
+			icon = "✅"
+		} else if t.Failed {
+			icon = "❌"
+		}
+		b.WriteString(fmt.Sprintf("%s %s — %s — `%dh`\n", icon, t.Type, t.Status, t.Hours))
+	}
+	return b.String()
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"nasbot/internal/format"
+	"nasbot/internal/smart"
+)
+
+const (
+	// smartTestPollInterval is how often a running self-test is checked.
+	smartTestPollInterval = 5 * time.Minute
+	// smartTestGiveUp is how long after the start a test that is neither
+	// running nor logged is reported as lost.
+	smartTestGiveUp = 30 * time.Minute
+)
+
+// selfTestSchedules returns the short and long schedules of dev, with
+// the per-disk overrides applied.
+func selfTestSchedules(cfg SmartSelfTestConfig, dev string) map[string]SmartTestSchedule {
+	out := map[string]SmartTestSchedule{smart.TestShort: cfg.Short, smart.TestLong: cfg.Long}
+	if d, ok := cfg.Devices[dev]; ok {
+		if d.Short.Day != "" {
+			out[smart.TestShort] = d.Short
+		}
+		if d.Long.Day != "" {
+			out[smart.TestLong] = d.Long
+		}
+	}
+	return out
+}
+
+// testScheduleDue reports whether sch fires in the hour of now.
+func testScheduleDue(sch SmartTestSchedule, now time.Time) bool {
+	if sch.Hour != now.Hour() {
+		return false
+	}
+	switch sch.Day {
+	case "daily":
+		return true
+	case "never", "":
+		return false
+	}
+	if n, err := strconv.Atoi(sch.Day); err == nil {
+		return n == now.Day()
+	}
+	return sch.Day == strings.ToLower(now.Weekday().String())
+}
+
+// testScheduleText describes sch for /smart.
+func testScheduleText(ctx *AppContext, sch SmartTestSchedule) string {
+	switch sch.Day {
+	case "never", "":
+		return ctx.Tr("smart_schedule_never")
+	case "daily":
+		return fmt.Sprintf("%s %02d:00", ctx.Tr("smart_schedule_daily"), sch.Hour)
+	}
+	if _, err := strconv.Atoi(sch.Day); err == nil {
+		return fmt.Sprintf(ctx.Tr("smart_schedule_monthly"), sch.Day, sch.Hour)
+	}
+	return fmt.Sprintf("%s %02d:00", ctx.Tr(sch.Day), sch.Hour)
+}
+
+// selfTestHead identifies the newest log entry.
+func selfTestHead(r smart.Report) string {
+	if len(r.SelfTests) == 0 {
+		return ""
+	}
+	t := r.SelfTests[0]
+	return fmt.Sprintf("%s|%s|%d", t.Type, t.Status, t.Hours)
+}
+
+// checkSmartSelfTests starts the self-tests that are due and follows the
+// ones already running until their result shows up in the disk's log.
+func checkSmartSelfTests(ctx *AppContext, now time.Time) {
+	cfg := ctx.Config.Notifications.SMART
+	if !cfg.Enabled || !cfg.SelfTests.Enabled {
+		return
+	}
+	local := now.In(ctx.State.TimeLocation)
+
+	ctx.Monitor.Mu.Lock()
+	if ctx.Monitor.SmartTestsStarted == nil {
+		ctx.Monitor.SmartTestsStarted = make(map[string]time.Time)
+	}
+	if ctx.Monitor.SmartTestsRunning == nil {
+		ctx.Monitor.SmartTestsRunning = make(map[string]SmartTestRun)
+	}
+	running := make(map[string]SmartTestRun, len(ctx.Monitor.SmartTestsRunning))
+	for dev, run := range ctx.Monitor.SmartTestsRunning {
+		running[dev] = run
+	}
+	ctx.Monitor.Mu.Unlock()
+
+	for dev, run := range running {
+		if now.Sub(run.LastPoll) >= smartTestPollInterval {
+			pollSmartSelfTest(ctx, dev, run, now)
+		}
+	}
+
+	for _, dev := range getSmartDevices(ctx) {
+		if _, busy := running[dev]; busy {
+			continue
+		}
+		schedules := selfTestSchedules(cfg.SelfTests, dev)
+		// A long test covers a short one due in the same hour.
+		for _, kind := range []string{smart.TestLong, smart.TestShort} {
+			key := dev + ":" + kind
+			ctx.Monitor.Mu.Lock()
+			last := ctx.Monitor.SmartTestsStarted[key]
+			ctx.Monitor.Mu.Unlock()
+			if !testScheduleDue(schedules[kind], local) || now.Sub(last) < time.Hour {
+				continue
+			}
+			ctx.Monitor.Mu.Lock()
+			ctx.Monitor.SmartTestsStarted[key] = now
+			ctx.Monitor.Mu.Unlock()
+			if startSmartSelfTest(ctx, dev, kind, now) {
+				break
+			}
+		}
+	}
+}
+
+// startSmartSelfTest runs `smartctl -t kind` on dev.
+func startSmartSelfTest(ctx *AppContext, dev, kind string, now time.Time) bool {
+	before, err := readSMARTReport(dev, true)
+	if err != nil {
+		slog.Warn("SMART self-test: cannot read disk", "device", dev, "err", err)
+		ctx.State.AddEvent("warning", fmt.Sprintf("SMART %s self-test on %s not started: %v", kind, dev, err))
+		return false
+	}
+	if before.Running() {
+		slog.Info("SMART self-test already running", "device", dev)
+		return false
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	out, err := runCommandOutput(timeoutCtx, "sudo", "-n", "smartctl", "-t", kind, "/dev/"+dev)
+	cancel()
+	if err != nil {
+		slog.Warn("SMART self-test start failed", "device", dev, "kind", kind, "err", err, "output", string(out))
+		ctx.State.AddEvent("warning", fmt.Sprintf("SMART %s self-test on %s not started: %v", kind, dev, err))
+		return false
+	}
+
+	slog.Info("SMART self-test started", "device", dev, "kind", kind)
+	ctx.State.AddEvent("info", fmt.Sprintf("SMART %s self-test started on %s", kind, dev))
+	ctx.Monitor.Mu.Lock()
+	ctx.Monitor.SmartTestsRunning[dev] = SmartTestRun{Kind: kind, Started: now, LastPoll: now, LogLen: len(before.SelfTests), LogHead: selfTestHead(before)}
+	ctx.Monitor.Mu.Unlock()
+	return true
+}
+
+// pollSmartSelfTest checks whether the test on dev has finished and
+// records its result as an event. A failure also makes the SMART monitor
+// re-read the disk at once, so its alert goes out without waiting.
+func pollSmartSelfTest(ctx *AppContext, dev string, run SmartTestRun, now time.Time) {
+	r, err := readSMARTReport(dev, true)
+	run.LastPoll = now
+	logged := len(r.SelfTests) > 0 && (len(r.SelfTests) != run.LogLen || selfTestHead(r) != run.LogHead)
+	done := err == nil && !r.Running() && (logged || now.Sub(run.Started) >= smartTestGiveUp)
+	failed := done && logged && r.SelfTests[0].Failed
+
+	ctx.Monitor.Mu.Lock()
+	if done {
+		delete(ctx.Monitor.SmartTestsRunning, dev)
+	} else {
+		ctx.Monitor.SmartTestsRunning[dev] = run
+	}
+	if failed {
+		ctx.Monitor.SmartLastCheckTime = time.Time{}
+	}
+	ctx.Monitor.Mu.Unlock()
+
+	if err != nil {
+		slog.Warn("SMART self-test poll failed", "device", dev, "err", err)
+		return
+	}
+	if !done {
+		return
+	}
+	if !logged {
+		ctx.State.AddEvent("warning", fmt.Sprintf("SMART %s self-test on %s left no result in the log", run.Kind, dev))
+		return
+	}
+	t := r.SelfTests[0]
+	switch {
+	case t.Failed:
+		ctx.State.AddEvent("critical", fmt.Sprintf("SMART %s self-test on %s failed: %s", run.Kind, dev, t.Status))
+	case t.Passed:
+		ctx.State.AddEvent("info", fmt.Sprintf("SMART %s self-test on %s passed in %s", run.Kind, dev, format.FormatDuration(now.Sub(run.Started))))
+	default:
+		ctx.State.AddEvent("warning", fmt.Sprintf("SMART %s self-test on %s ended: %s", run.Kind, dev, t.Status))
+	}
+}
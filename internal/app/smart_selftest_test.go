@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// smartctlRunner answers `smartctl --json -a` with report and records
+// the self-tests started with `smartctl -t`.
+type smartctlRunner struct {
+	mockRunner
+	report  string
+	started []string
+}
+
+func (r *smartctlRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return []byte(r.report), nil
+}
+
+func (r *smartctlRunner) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	r.started = append(r.started, strings.Join(args, " "))
+	return []byte("Testing has begun."), nil
+}
+
+const smartLogBefore = `{"device": {"protocol": "ATA"}, "smart_status": {"passed": true},
+  "ata_smart_self_test_log": {"standard": {"table": [
+    {"type": {"string": "Short offline"}, "status": {"value": 0, "string": "Completed without error", "passed": true}, "lifetime_hours": 100}
+  ]}}}`
+
+const smartLogRunning = `{"device": {"protocol": "ATA"}, "smart_status": {"passed": true},
+  "ata_smart_data": {"self_test": {"status": {"value": 249, "remaining_percent": 90}}},
+  "ata_smart_self_test_log": {"standard": {"table": [
+    {"type": {"string": "Short offline"}, "status": {"value": 0, "string": "Completed without error", "passed": true}, "lifetime_hours": 100}
+  ]}}}`
+
+const smartLogFailed = `{"device": {"protocol": "ATA"}, "smart_status": {"passed": true},
+  "ata_smart_self_test_log": {"standard": {"table": [
+    {"type": {"string": "Extended offline"}, "status": {"value": 121, "string": "Completed: read failure", "passed": false}, "lifetime_hours": 110},
+    {"type": {"string": "Short offline"}, "status": {"value": 0, "string": "Completed without error", "passed": true}, "lifetime_hours": 100}
+  ]}}}`
+
+func TestTestScheduleDue(t *testing.T) {
+	sunday3 := time.Date(2026, 3, 1, 3, 10, 0, 0, time.UTC) // a Sunday, the 1st
+	cases := []struct {
+		sch  SmartTestSchedule
+		want bool
+	}{
+		{SmartTestSchedule{Day: "daily", Hour: 3}, true},
+		{SmartTestSchedule{Day: "sunday", Hour: 3}, true},
+		{SmartTestSchedule{Day: "monday", Hour: 3}, false},
+		{SmartTestSchedule{Day: "1", Hour: 3}, true},
+		{SmartTestSchedule{Day: "2", Hour: 3}, false},
+		{SmartTestSchedule{Day: "sunday", Hour: 4}, false},
+		{SmartTestSchedule{Day: "never", Hour: 3}, false},
+	}
+	for _, c := range cases {
+		if got := testScheduleDue(c.sch, sunday3); got != c.want {
+			t.Errorf("testScheduleDue(%+v) = %v", c.sch, got)
+		}
+	}
+
+	cfg := SmartSelfTestConfig{
+		Short:   SmartTestSchedule{Day: "sunday", Hour: 3},
+		Long:    SmartTestSchedule{Day: "1", Hour: 2},
+		Devices: map[string]SmartDeviceSchedule{"sdb": {Long: SmartTestSchedule{Day: "never"}}},
+	}
+	if s := selfTestSchedules(cfg, "sdb"); s["long"].Day != "never" || s["short"].Day != "sunday" {
+		t.Fatalf("device override not applied: %+v", s)
+	}
+}
+
+func TestSmartSelfTestLifecycle(t *testing.T) {
+	ctx := newTestAppContext()
+	ctx.Config.Notifications.SMART = SmartConfig{Enabled: true, Devices: []string{"sda"}, SelfTests: SmartSelfTestConfig{
+		Enabled: true,
+		Short:   SmartTestSchedule{Day: "daily", Hour: 3},
+		Long:    SmartTestSchedule{Day: "daily", Hour: 3},
+	}}
+	runner := &smartctlRunner{report: smartLogBefore}
+	defer setCommandRunner(runner)()
+	now := time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC)
+
+	checkSmartSelfTests(ctx, now)
+	if len(runner.started) != 1 || runner.started[0] != "-n smartctl -t long /dev/sda" {
+		t.Fatalf("expected only the long test, got %v", runner.started)
+	}
+
+	runner.report = smartLogRunning
+	checkSmartSelfTests(ctx, now.Add(time.Minute))
+	checkSmartSelfTests(ctx, now.Add(6*time.Minute))
+	if len(runner.started) != 1 || len(ctx.Monitor.SmartTestsRunning) != 1 {
+		t.Fatalf("test restarted or dropped while running: %v %v", runner.started, ctx.Monitor.SmartTestsRunning)
+	}
+
+	runner.report = smartLogFailed
+	ctx.Monitor.SmartLastCheckTime = now
+	checkSmartSelfTests(ctx, now.Add(12*time.Minute))
+	if len(ctx.Monitor.SmartTestsRunning) != 0 || !ctx.Monitor.SmartLastCheckTime.IsZero() {
+		t.Fatalf("finished test not recorded: %v", ctx.Monitor.SmartTestsRunning)
+	}
+	events := ctx.State.GetEvents()
+	if last := events[len(events)-1]; last.Type != "critical" || !strings.Contains(last.Message, "SMART long self-test on sda failed: Completed: read failure") {
+		t.Fatalf("unexpected event: %+v", last)
+	}
+
+	alerts := (&SMARTMonitor{}).Check(ctx, nil)
+	found := false
+	for _, a := range alerts {
+		if a.ID == "smart_selftest:sda" {
+			found = a.Level == AlertLevelCritical && strings.Contains(a.Message, "Extended offline: Completed: read failure")
+		}
+	}
+	if !found {
+		t.Fatalf("no self-test alert in %+v", alerts)
+	}
+}
+
+func TestSmartCommand(t *testing.T) {
+	ctx := newTestAppContext()
+	ctx.Config.Notifications.SMART = SmartConfig{Enabled: true, Devices: []string{"sda"}, SelfTests: SmartSelfTestConfig{
+		Enabled: true,
+		Short:   SmartTestSchedule{Day: "sunday", Hour: 3},
+		Long:    SmartTestSchedule{Day: "1", Hour: 2},
+	}}
+	defer setCommandRunner(&smartctlRunner{report: smartLogFailed})()
+	bot := &fakeBot{}
+
+	handleSmartCommand(ctx, bot, 1, "/dev/sda")
+	text := bot.sent[len(bot.sent)-1].(tgbotapi.MessageConfig).Text
+	for _, want := range []string{"SMART — sda", "short Sunday 03:00 · long day 1 of the month 02:00",
+		"❌ Extended offline — Completed: read failure — `110h`", "✅ Short offline — Completed without error — `100h`"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("/smart sda lacks %q:\n%s", want, text)
+		}
+	}
+
+	handleSmartCommand(ctx, bot, 1, "sdz")
+	if text := bot.sent[len(bot.sent)-1].(tgbotapi.MessageConfig).Text; !strings.Contains(text, "Unknown disk `sdz`") {
+		t.Fatalf("unexpected reply: %q", text)
+	}
+}
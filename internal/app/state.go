@@ -3,6 +3,7 @@ package app
 import (
 	"encoding/json"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"time"
@@ -39,6 +40,12 @@ type BotState struct {
 
 	// Healthchecks.io tracking
 	Healthchecks HealthchecksState `json:"healthchecks"`
+
+	// Acknowledged, snoozed and muted alerts by alert ID
+	AlertSilences map[string]AlertSilence `json:"alert_silences,omitempty"`
+
+	// Last SMART counters per disk, so growth across restarts is noticed
+	SmartCounters map[string]map[string]int64 `json:"smart_counters,omitempty"`
 }
 
 func stateFilePath() string {
@@ -79,7 +86,11 @@ func loadState(ctx *AppContext) {
 
 	ctx.Monitor.Mu.Lock()
 	ctx.Monitor.Healthchecks = state.Healthchecks
+	if state.SmartCounters != nil {
+		ctx.Monitor.SmartCounters = state.SmartCounters
+	}
 	ctx.Monitor.Mu.Unlock()
+	ctx.Monitor.Alerts.RestoreSilences(state.AlertSilences)
 
 	ctx.Settings.Mu.Lock()
 	if state.Language != "" {
@@ -153,7 +164,12 @@ func saveState(ctx *AppContext) {
 		copy(downtimeCopy, ctx.Monitor.Healthchecks.DowntimeEvents)
 		healthchecks.DowntimeEvents = downtimeCopy
 	}
+	smartCounters := make(map[string]map[string]int64, len(ctx.Monitor.SmartCounters))
+	for dev, counters := range ctx.Monitor.SmartCounters {
+		smartCounters[dev] = maps.Clone(counters)
+	}
 	ctx.Monitor.Mu.Unlock()
+	alertSilences := ctx.Monitor.Alerts.Silences(time.Now())
 
 	ctx.Settings.Mu.RLock()
 	language := ctx.Settings.Language
@@ -183,6 +199,8 @@ func saveState(ctx *AppContext) {
 		DockerPruneDay:      dockerPrune.Day,
 		DockerPruneHour:     dockerPrune.Hour,
 		Healthchecks:        healthchecks,
+		AlertSilences:       alertSilences,
+		SmartCounters:       smartCounters,
 	}
 
 	data, err := json.MarshalIndent(state, "", "  ")
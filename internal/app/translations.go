@@ -31,6 +31,7 @@ var translations = map[string]map[string]string{
 		"restart":               "🔄 Restart",
 		"kill":                  "💀 Force Kill",
 		"logs":                  "📝 Logs",
+		"inspect":               "🔎 Inspect",
 		"yes":                   "✅ Yes",
 		"no":                    "❌ No",
 		"confirm_action":        "%s *%s*?",
@@ -77,6 +78,9 @@ var translations = map[string]map[string]string{
 		"report_title":          "*Report*\n",
 		"report_resources":      "Resources",
 		"report_stress":         "Been under stress:",
+		"report_disks":          "Disk spin-down:",
+		"report_disk_spin":      "💤 %s: standby %.0f%% · %d spin-ups",
+		"report_averages":       "CPU avg %.0f%% (peak %.0f%%) · RAM avg %.0f%% (peak %.0f%%)",
 		"llm_error":             "⚠️ LLM error: %s\n\n",
 		"containers_running":    "containers running",
 		"container_running":     "container running",
@@ -107,19 +111,53 @@ var translations = map[string]map[string]string{
 		"generating_report":     "⏳ Generating report using *%s*...",
 
 		// Main keyboard buttons
-		"btn_refresh":          "🔄 Refresh",
-		"btn_temp":             "🌡 Temp",
-		"btn_net":              "🌐 Net",
-		"btn_docker":           "🐳 Docker",
-		"btn_dstats":           "📊 D-Stats",
-		"btn_top":              "🔥 Top",
-		"btn_power":            "⚡ Power",
-		"power_title":          "⚡ *Power Management*\n\nBe careful, these actions affect the physical system.",
-		"power_reboot":         "🔄 Reboot NAS",
-		"power_shutdown":       "🛑 Shutdown NAS",
-		"power_force_reboot":   "💥 Force Reboot",
-		"docker_no_containers": "_No containers found. Is Docker running?_",
-		"docker_stats_title":   "Container Resources",
+		"btn_refresh":            "🔄 Refresh",
+		"btn_temp":               "🌡 Temp",
+		"btn_net":                "🌐 Net",
+		"btn_docker":             "🐳 Docker",
+		"btn_dstats":             "📊 D-Stats",
+		"btn_top":                "🔥 Top",
+		"btn_power":              "⚡ Power",
+		"btn_graphs":             "📈 Graphs",
+		"graph_usage":            "📈 *Graphs*\n\nUsage: `/graph <metric> [1h|24h|7d|30d]`\nMetrics: %s",
+		"graph_unknown":          "❓ Unknown metric `%s`.\nAvailable: %s",
+		"graph_no_history":       "📈 Metric history is disabled (`history.enabled`), no graphs available.",
+		"graph_no_data":          "📈 No data for *%s* in the last %s yet.",
+		"graph_title_cpu":        "CPU %",
+		"graph_title_ram":        "RAM %",
+		"graph_title_swap":       "Swap %",
+		"graph_title_disk":       "Disk usage %",
+		"graph_title_net":        "Network Mbps",
+		"graph_title_io":         "Disk I/O MB/s",
+		"graph_title_load":       "Load average",
+		"graph_title_temp":       "Temperature °C",
+		"graph_title_smart":      "SMART error counters",
+		"smart_title":            "💽 *SMART*\n\n",
+		"smart_unavailable":      "not readable",
+		"smart_usage":            "_Details and self-test log:_ `/smart <disk>`",
+		"smart_unknown_dev":      "❓ Unknown disk `%s`. SMART disks: %s",
+		"smart_read_err":         "❌ Could not read SMART data of `%s`:\n`%v`",
+		"smart_dev_title":        "💽 *SMART — %s*\n\n",
+		"smart_model":            "Model: %s\n",
+		"smart_health":           "Health: %s %s\n",
+		"smart_temp":             "Temperature: %d°C\n",
+		"smart_power_on":         "Power-on: `%dh` (%.1f years)\n",
+		"smart_wear":             "Wear: `%d%%` of rated life used\n",
+		"smart_test_running":     "\n🧪 Self-test running, %d%% left\n",
+		"smart_schedule":         "\n🗓 Self-tests: short %s · long %s\n",
+		"smart_schedule_never":   "never",
+		"smart_schedule_daily":   "daily",
+		"smart_schedule_monthly": "day %s of the month %02d:00",
+		"smart_log_title":        "\n*Self-test log*\n",
+		"smart_log_empty":        "_No self-tests logged_",
+		"smart_log_more":         "_…and %d older_\n",
+		"graph_title_containers": "Container memory MiB",
+		"power_title":            "⚡ *Power Management*\n\nBe careful, these actions affect the physical system.",
+		"power_reboot":           "🔄 Reboot NAS",
+		"power_shutdown":         "🛑 Shutdown NAS",
+		"power_force_reboot":     "💥 Force Reboot",
+		"docker_no_containers":   "_No containers found. Is Docker running?_",
+		"docker_stats_title":     "Container Resources",
 
 		// New additions
 		"temp_title":        "🌡 *Temperatures*\n\n",
@@ -131,6 +169,7 @@ var translations = map[string]map[string]string{
 		"temp_disk_healthy": "healthy",
 		"temp_disk_fail":    "FAILING!",
 		"temp_disk_warm":    "warm",
+		"temp_disk_standby": "spun down",
 
 		"net_title":        "🌐 *Network*\n\n",
 		"net_local":        "🏠 Local: `%s`\n",
@@ -162,6 +201,31 @@ var translations = map[string]map[string]string{
 		"kw_started":               "[KernelWatchdog] Started (check every %ds)",
 		"raid_alert":               "🧩 *RAID issue detected*\n\n%s\n\n_⚠️ Check disks/arrays now._",
 		"raid_recovered":           "✅ *RAID healthy again*\n\nDowntime: `%s`",
+		"raid_sync_started":        "🔄 *%s: %s in progress*\n\n%s",
+		"raid_sync_progress":       "🔄 *%s: %s*\n\n%s",
+		"raid_sync_done":           "✅ *%s: %s finished*",
+		"raid_sync_took":           "\n\nTook `%s`",
+		"raid_sync_stopped":        "⚠️ *%s: %s stopped*\n\nThe array is still degraded.",
+		"raid_sync_waiting":        "    ⏳ %s queued\n",
+		"raid_title":               "🧩 *RAID*\n\n",
+		"raid_none":                "No md arrays.\n",
+		"raid_no_md":               "_/proc/mdstat not available._\n",
+		"raid_degraded":            "degraded",
+		"raid_inactive":            "inactive",
+		"raid_read_only":           "read-only",
+		"raid_spare":               "(spare)",
+		"alert_resolved":           "✅ *Resolved:* `%s`\n_Lasted %s_",
+		"alert_escalated":          "⏫ *Escalation: still unacknowledged*\n\n%s",
+		"alert_btn_ack":            "✅ Ack",
+		"alert_btn_mute":           "🔕 Mute",
+		"alert_btn_unmute":         "🔔 Unmute",
+		"alert_btn_snooze":         "💤 Snooze 1h",
+		"alert_btn_tomorrow":       "🌙 Until tomorrow",
+		"alert_acked":              "✅ Acknowledged %s",
+		"alert_snoozed":            "💤 %s snoozed until %s",
+		"alert_muted":              "🔕 %s muted until you unmute it",
+		"alert_unmuted":            "🔔 %s unmuted",
+		"alert_expired":            "This alert is no longer tracked.",
 		"raidwd_started":           "[RAIDWatchdog] Started (check every %ds)",
 
 		"top_title":  "🔥 *Top Processes (by CPU)*\n\n",
@@ -198,6 +262,7 @@ var translations = map[string]map[string]string{
 		"cfg_quiet_fmt":    "✅ %02d:%02d — %02d:%02d\n",
 
 		"unknown_command":        "Hmm, I don't know that one. Try /help",
+		"permission_denied":      "⛔ Your role doesn't allow this action.",
 		"cancelled":              "❌ Cancelled",
 		"force_reboot_triggered": "💥 *Forced reboot triggered*\n\nNo confirmation requested. Rebooting now.",
 		"docker_restart_cancel":  "❌ Docker restart cancelled",
@@ -238,6 +303,112 @@ var translations = map[string]map[string]string{
 		"docker_status":                "Status: %s\n",
 		"docker_image":                 "Image: `%s`\n",
 		"docker_id":                    "ID: `%s`\n",
+		"docker_health":                "Health: %s\n",
+		"docker_health_streak":         "Failing probes: `%d`\n",
+		"docker_health_probe":          "Last probe: `%s`\n",
+		"docker_standalone":            "\n📦 *Standalone*\n",
+		"stack_title":                  "📚 *Stack %s*\n\n",
+		"stack_state":                  "State: %s `%s` · %d/%d running\n",
+		"stack_dir":                    "Folder: `%s`\n",
+		"stack_up":                     "▶️ Up",
+		"stack_down":                   "⏹ Down",
+		"stack_restart":                "🔄 Restart",
+		"stack_pull":                   "⬇️ Pull",
+		"stack_confirm":                "⚠️ Run `docker compose %s` on stack *%s*?",
+		"stack_down_warn":              "\n\n_Down removes the stack's containers: it leaves this list until it is started again._",
+		"stack_running":                "⏳ `docker compose %s` on *%s*...",
+		"stack_ok":                     "✅ Stack *%s*: `%s` done",
+		"stack_err":                    "❌ Stack *%s*: `%s` failed\n`%v`",
+		"stack_not_found":              "❓ Stack not found",
+		"docker_update_image":          "⬆️ Update image",
+		"dupdates_title":               "🆕 *Image updates*\n\n",
+		"dupdates_never":               "_Not checked yet._\n",
+		"dupdates_checked":             "_Checked %s ago_\n\n",
+		"dupdates_none":                "✅ Every image is up to date\n",
+		"dupdates_failed":              "\n_%d images could not be checked_\n",
+		"dupdates_alert":               "🆕 *New images available*\n\n",
+		"dupdates_open":                "🆕 Updates",
+		"dupdates_check":               "🔍 Check now",
+		"dupdates_checking":            "🔍 Checking registries...",
+		"dupdates_confirm":             "⬆️ Pull the new image and recreate *%s*?\n\n_The previous image is kept: if the container is not running and healthy within %d s it is rolled back._",
+		"dupdates_running":             "⏳ Updating *%s*...",
+		"dupdates_busy":                "⏳ *%s* is already being updated",
+		"dupdates_current":             "✅ *%s* already runs the latest image",
+		"dupdates_ok":                  "✅ *%s* updated",
+		"dupdates_rolled_back":         "↩️ *%s* rolled back to the previous image\n`%v`",
+		"dupdates_err":                 "❌ Update of *%s* failed\n`%v`",
+		"ddisk_title":                  "💽 *Docker disk usage*\n\n",
+		"ddisk_err":                    "❌ Docker disk usage unavailable\n`%v`",
+		"ddisk_images":                 "🖼 *Images* — %d, %s\n",
+		"ddisk_unused":                 "   %d unused (%s)\n",
+		"ddisk_dangling":               "   %d dangling (%s)\n",
+		"ddisk_build":                  "\n🧱 *Build cache* — %s (%s not in use)\n",
+		"ddisk_volumes":                "\n📁 *Volumes* — %d, %s\n",
+		"ddisk_orphans":                "   %d orphan (%s)\n",
+		"ddisk_logs":                   "\n📜 *Container logs* — %s\n",
+		"ddisk_open":                   "💽 Disk",
+		"ddisk_back":                   "Disk usage",
+		"ddisk_btn_dangling":           "🗑 Dangling images",
+		"ddisk_btn_unused":             "🗑 Unused images",
+		"ddisk_btn_build":              "🗑 Build cache",
+		"ddisk_btn_volumes":            "🗑 Orphan volumes",
+		"ddisk_confirm_img":            "🗑 Delete image `%s`?",
+		"ddisk_confirm_vol":            "🗑 Delete volume `%s`?\n\n_Its data is lost for good._",
+		"ddisk_confirm_dangling":       "🗑 Delete every dangling image?",
+		"ddisk_confirm_unused":         "🗑 Delete every image no container uses?\n\n_They will be pulled again when needed._",
+		"ddisk_confirm_build":          "🗑 Clear the whole build cache?",
+		"ddisk_confirm_volumes":        "🗑 Delete every volume no container mounts?\n\n_Their data is lost for good._",
+		"ddisk_deleting":               "⏳ Cleaning up...",
+		"ddisk_delete_ok":              "✅ Deleted %s\n%s reclaimed",
+		"ddisk_delete_err":             "❌ Could not delete %s\n`%v`",
+		"dlog_confirm":                 "✂️ Truncate the log of *%s*?\n\n_Its past output is lost; new lines keep being written._",
+		"dlog_ok":                      "✅ Log of *%s* truncated, %s freed",
+		"dlog_err":                     "❌ Could not truncate the log of *%s*\n`%v`",
+		"order_title_boot":             "🚀 *Starting containers after boot*",
+		"order_title_recovery":         "🚀 *Starting containers after the Docker restart*",
+		"docker_exec_confirm":          "⚙️ Run *%s* in *%s*?\n\n`%s`",
+		"docker_exec_user":             "\nas user `%s`",
+		"docker_exec_unknown":          "❌ This action is no longer configured",
+		"docker_exec_busy":             "⏳ *%s* is already running",
+		"docker_exec_running":          "⏳ Running *%s* in *%s*...",
+		"docker_exec_done":             "%s *%s* exited with code %d in %s",
+		"docker_exec_timeout":          "⏱ *%s* did not finish within %s\n_The command may still be running in the container._",
+		"docker_exec_err":              "❌ *%s* could not be run\n`%v`",
+		"docker_exec_no_output":        "\n_No output._",
+		"docker_exec_truncated":        "\n_Output truncated._",
+		"inspect_title":                "🔎 *%s*",
+		"inspect_err":                  "❌ Could not inspect `%s`\n`%v`",
+		"inspect_image":                "Image: %s",
+		"inspect_created":              "Created: %s",
+		"inspect_restart":              "Restart policy: %s · %d restarts",
+		"inspect_ports":                "*Ports*",
+		"inspect_port_internal":        "%s (not published)",
+		"inspect_mounts":               "*Mounts*",
+		"inspect_networks":             "*Networks*",
+		"inspect_limits":               "*Resource limits*",
+		"inspect_memory":               "Memory: %s",
+		"inspect_memory_reservation":   "Memory reservation: %s",
+		"inspect_cpus":                 "CPUs: %s",
+		"inspect_cpuset":               "CPU set: %s",
+		"inspect_cpu_shares":           "CPU shares: %d",
+		"inspect_pids":                 "Processes: %d",
+		"inspect_unlimited":            "_unlimited_",
+		"inspect_labels":               "*Labels*",
+		"inspect_env":                  "*Environment* _(secrets hidden)_",
+		"inspect_none":                 "_none_",
+		"docker_filter_empty":          "_No containers match this filter._\n",
+		"docker_filter_page":           "\n_%s · page %d/%d_",
+		"docker_filter_all":            "All",
+		"docker_filter_run":            "Running",
+		"docker_filter_stop":           "Stopped",
+		"docker_filter_sick":           "Unhealthy",
+		"docker_stack_actions":         "⚙️ Stack %s",
+		"docker_stacks_title":          "📚 *Stacks*\n\n",
+		"docker_no_stacks":             "_No compose stacks._\n",
+		"docker_find_title":            "🔎 *%d containers match* `%s`\n\n",
+		"docker_find_more":             "\n_…and %d more, refine the search._",
+		"docker_find_none":             "❓ No container matches `%s`",
+		"docker_find_usage":            "Usage: `/container find <text>`\n\nSearches container names, images and compose stacks.",
 		"docker_action_err":            "❌ Couldn't %s *%s*\n`%s`",
 		"docker_action_ok":             "✅ *%s* %s",
 		"docker_started":               "started ▶️",
@@ -257,6 +428,7 @@ var translations = map[string]map[string]string{
 		"boot_online":        "*NASBot is online* 👋\n\nI'll keep an eye on things.%s%s\n\n_Type /help to see what I can do_",
 		"boot_quiet_fmt":     "\n🌙 _Quiet: %02d:%02d — %02d:%02d_",
 		"status_not_running": "not running",
+		"status_unhealthy":   "unhealthy",
 		"status_not_found":   "not found",
 		"crit_cont_alert":    "🚨 *Critical Container Alert*\n\nContainer `%s` is %s!\n\n_This container is marked as critical_",
 		"reprt_disabled":     "\n📭 _Reports disabled_",
@@ -321,6 +493,11 @@ var translations = map[string]map[string]string{
 		"cmd_config_desc":           "Show current configuration",
 		"cmd_sysinfo_desc":          "Detailed system information",
 		"cmd_diskpred_desc":         "Disk space prediction",
+		"cmd_graph_desc":            "Metric charts (CPU, RAM, disk, network...)",
+		"cmd_smart_desc":            "Disk health and self-test log",
+		"cmd_raid_desc":             "RAID arrays and rebuild progress",
+		"cmd_dupdates_desc":         "Container image updates",
+		"cmd_ddisk_desc":            "Docker disk usage and cleanup",
 		"cmd_shutdown_desc":         "Shutdown the system",
 		"cmd_help_desc":             "Show all available commands",
 		"settings_thresholds":       "Alert Thresholds",
@@ -380,6 +557,7 @@ var translations = map[string]map[string]string{
 		"restart":               "🔄 Riavvia",
 		"kill":                  "💀 Uccidi",
 		"logs":                  "📝 Logs",
+		"inspect":               "🔎 Ispeziona",
 		"yes":                   "✅ Sì",
 		"no":                    "❌ No",
 		"confirm_action":        "%s *%s*?",
@@ -426,6 +604,9 @@ var translations = map[string]map[string]string{
 		"report_title":          "*Report*\n",
 		"report_resources":      "Risorse",
 		"report_stress":         "Sotto stress:",
+		"report_disks":          "Spin-down dischi:",
+		"report_disk_spin":      "💤 %s: in standby %.0f%% · %d riavvii",
+		"report_averages":       "CPU media %.0f%% (picco %.0f%%) · RAM media %.0f%% (picco %.0f%%)",
 		"llm_error":             "⚠️ Errore LLM: %s\n\n",
 		"containers_running":    "container attivi",
 		"container_running":     "container attivo",
@@ -456,19 +637,53 @@ var translations = map[string]map[string]string{
 		"generating_report":     "⏳ Generazione report con *%s* in corso...",
 
 		// Pulsanti interfaccia principale
-		"btn_refresh":          "🔄 Aggiorna",
-		"btn_temp":             "🌡 Temp",
-		"btn_net":              "🌐 Rete",
-		"btn_docker":           "🐳 Docker",
-		"btn_dstats":           "📊 D-Stats",
-		"btn_top":              "🔥 Top",
-		"btn_power":            "⚡ Power",
-		"power_title":          "⚡ *Gestione Alimentazione*\n\nAttenzione, queste azioni hanno effetto sul sistema fisico.",
-		"power_reboot":         "🔄 Riavvia NAS",
-		"power_shutdown":       "🛑 Spegni NAS",
-		"power_force_reboot":   "💥 Riavvio Forzato",
-		"docker_no_containers": "_Nessun container trovato. Docker è attivo?_",
-		"docker_stats_title":   "Risorse container",
+		"btn_refresh":            "🔄 Aggiorna",
+		"btn_temp":               "🌡 Temp",
+		"btn_net":                "🌐 Rete",
+		"btn_docker":             "🐳 Docker",
+		"btn_dstats":             "📊 D-Stats",
+		"btn_top":                "🔥 Top",
+		"btn_power":              "⚡ Power",
+		"btn_graphs":             "📈 Grafici",
+		"graph_usage":            "📈 *Grafici*\n\nUso: `/graph <metrica> [1h|24h|7d|30d]`\nMetriche: %s",
+		"graph_unknown":          "❓ Metrica `%s` sconosciuta.\nDisponibili: %s",
+		"graph_no_history":       "📈 Lo storico delle metriche è disattivato (`history.enabled`), grafici non disponibili.",
+		"graph_no_data":          "📈 Ancora nessun dato per *%s* nelle ultime %s.",
+		"graph_title_cpu":        "CPU %",
+		"graph_title_ram":        "RAM %",
+		"graph_title_swap":       "Swap %",
+		"graph_title_disk":       "Utilizzo disco %",
+		"graph_title_net":        "Rete Mbps",
+		"graph_title_io":         "I/O disco MB/s",
+		"graph_title_load":       "Carico medio",
+		"graph_title_temp":       "Temperatura °C",
+		"graph_title_smart":      "Contatori errori SMART",
+		"smart_title":            "💽 *SMART*\n\n",
+		"smart_unavailable":      "non leggibile",
+		"smart_usage":            "_Dettagli e log dei self-test:_ `/smart <disco>`",
+		"smart_unknown_dev":      "❓ Disco `%s` sconosciuto. Dischi SMART: %s",
+		"smart_read_err":         "❌ Impossibile leggere i dati SMART di `%s`:\n`%v`",
+		"smart_dev_title":        "💽 *SMART — %s*\n\n",
+		"smart_model":            "Modello: %s\n",
+		"smart_health":           "Salute: %s %s\n",
+		"smart_temp":             "Temperatura: %d°C\n",
+		"smart_power_on":         "Accensione: `%dh` (%.1f anni)\n",
+		"smart_wear":             "Usura: `%d%%` della vita nominale\n",
+		"smart_test_running":     "\n🧪 Self-test in corso, manca il %d%%\n",
+		"smart_schedule":         "\n🗓 Self-test: breve %s · lungo %s\n",
+		"smart_schedule_never":   "mai",
+		"smart_schedule_daily":   "ogni giorno",
+		"smart_schedule_monthly": "il %s del mese alle %02d:00",
+		"smart_log_title":        "\n*Log dei self-test*\n",
+		"smart_log_empty":        "_Nessun self-test registrato_",
+		"smart_log_more":         "_…e altri %d più vecchi_\n",
+		"graph_title_containers": "Memoria container MiB",
+		"power_title":            "⚡ *Gestione Alimentazione*\n\nAttenzione, queste azioni hanno effetto sul sistema fisico.",
+		"power_reboot":           "🔄 Riavvia NAS",
+		"power_shutdown":         "🛑 Spegni NAS",
+		"power_force_reboot":     "💥 Riavvio Forzato",
+		"docker_no_containers":   "_Nessun container trovato. Docker è attivo?_",
+		"docker_stats_title":     "Risorse container",
 
 		// Nuove aggiunte
 		"temp_title":        "🌡 *Temperature*\n\n",
@@ -480,6 +695,7 @@ var translations = map[string]map[string]string{
 		"temp_disk_healthy": "sani",
 		"temp_disk_fail":    "IN ERRORE!",
 		"temp_disk_warm":    "caldo",
+		"temp_disk_standby": "in standby",
 
 		"net_title":        "🌐 *Rete*\n\n",
 		"net_local":        "🏠 Locale: `%s`\n",
@@ -524,6 +740,31 @@ var translations = map[string]map[string]string{
 		"version_os":             "*OS:* %s %s\n",
 		"version_uptime":         "*Uptime bot:* `%s`\n",
 		"raid_recovered":         "✅ *RAID tornato sano*\n\nDowntime: `%s`",
+		"raid_sync_started":      "🔄 *%s: %s in corso*\n\n%s",
+		"raid_sync_progress":     "🔄 *%s: %s*\n\n%s",
+		"raid_sync_done":         "✅ *%s: %s completato*",
+		"raid_sync_took":         "\n\nDurata `%s`",
+		"raid_sync_stopped":      "⚠️ *%s: %s interrotto*\n\nL'array è ancora degradato.",
+		"raid_sync_waiting":      "    ⏳ %s in coda\n",
+		"raid_title":             "🧩 *RAID*\n\n",
+		"raid_none":              "Nessun array md.\n",
+		"raid_no_md":             "_/proc/mdstat non disponibile._\n",
+		"raid_degraded":          "degradato",
+		"raid_inactive":          "inattivo",
+		"raid_read_only":         "sola lettura",
+		"raid_spare":             "(riserva)",
+		"alert_resolved":         "✅ *Risolto:* `%s`\n_Durata %s_",
+		"alert_escalated":        "⏫ *Escalation: ancora non confermato*\n\n%s",
+		"alert_btn_ack":          "✅ Conferma",
+		"alert_btn_mute":         "🔕 Silenzia",
+		"alert_btn_unmute":       "🔔 Riattiva",
+		"alert_btn_snooze":       "💤 Posticipa 1h",
+		"alert_btn_tomorrow":     "🌙 Fino a domani",
+		"alert_acked":            "✅ Confermato %s",
+		"alert_snoozed":          "💤 %s posticipato fino a %s",
+		"alert_muted":            "🔕 %s silenziato finché non lo riattivi",
+		"alert_unmuted":          "🔔 %s riattivato",
+		"alert_expired":          "Questo avviso non è più tracciato.",
 		"raidwd_started":         "[RAIDWatchdog] Avviato (check ogni %ds)",
 
 		"top_title":  "🔥 *Processi Top (cpu)*\n\n",
@@ -548,6 +789,7 @@ var translations = map[string]map[string]string{
 		"cfg_quiet_fmt":    "✅ %02d:%02d — %02d:%02d\n",
 
 		"unknown_command":        "Mmh, non conosco questo comando. Prova /help",
+		"permission_denied":      "⛔ Il tuo ruolo non consente questa azione.",
 		"cancelled":              "❌ Annullato",
 		"force_reboot_triggered": "💥 *Riavvio forzato avviato*\n\nNessuna conferma richiesta. Riavvio in corso.",
 		"docker_restart_cancel":  "❌ Riavvio Docker annullato",
@@ -587,6 +829,112 @@ var translations = map[string]map[string]string{
 		"docker_status":                "Stato: %s\n",
 		"docker_image":                 "Immagine: `%s`\n",
 		"docker_id":                    "ID: `%s`\n",
+		"docker_health":                "Salute: %s\n",
+		"docker_health_streak":         "Controlli falliti: `%d`\n",
+		"docker_health_probe":          "Ultimo controllo: `%s`\n",
+		"docker_standalone":            "\n📦 *Singoli*\n",
+		"stack_title":                  "📚 *Stack %s*\n\n",
+		"stack_state":                  "Stato: %s `%s` · %d/%d attivi\n",
+		"stack_dir":                    "Cartella: `%s`\n",
+		"stack_up":                     "▶️ Avvia",
+		"stack_down":                   "⏹ Rimuovi",
+		"stack_restart":                "🔄 Riavvia",
+		"stack_pull":                   "⬇️ Aggiorna immagini",
+		"stack_confirm":                "⚠️ Eseguire `docker compose %s` sullo stack *%s*?",
+		"stack_down_warn":              "\n\n_Down rimuove i container dello stack: sparirà da questa lista finché non verrà riavviato._",
+		"stack_running":                "⏳ `docker compose %s` su *%s*...",
+		"stack_ok":                     "✅ Stack *%s*: `%s` completato",
+		"stack_err":                    "❌ Stack *%s*: `%s` non riuscito\n`%v`",
+		"stack_not_found":              "❓ Stack non trovato",
+		"docker_update_image":          "⬆️ Aggiorna immagine",
+		"dupdates_title":               "🆕 *Aggiornamenti immagini*\n\n",
+		"dupdates_never":               "_Controllo non ancora eseguito._\n",
+		"dupdates_checked":             "_Controllato %s fa_\n\n",
+		"dupdates_none":                "✅ Tutte le immagini sono aggiornate\n",
+		"dupdates_failed":              "\n_%d immagini non verificabili_\n",
+		"dupdates_alert":               "🆕 *Nuove immagini disponibili*\n\n",
+		"dupdates_open":                "🆕 Aggiornamenti",
+		"dupdates_check":               "🔍 Controlla ora",
+		"dupdates_checking":            "🔍 Controllo dei registry...",
+		"dupdates_confirm":             "⬆️ Scaricare la nuova immagine e ricreare *%s*?\n\n_L'immagine precedente viene conservata: se il container non è attivo e sano entro %d s si torna indietro._",
+		"dupdates_running":             "⏳ Aggiornamento di *%s*...",
+		"dupdates_busy":                "⏳ *%s* è già in aggiornamento",
+		"dupdates_current":             "✅ *%s* usa già l'immagine più recente",
+		"dupdates_ok":                  "✅ *%s* aggiornato",
+		"dupdates_rolled_back":         "↩️ *%s* riportato all'immagine precedente\n`%v`",
+		"dupdates_err":                 "❌ Aggiornamento di *%s* non riuscito\n`%v`",
+		"ddisk_title":                  "💽 *Spazio disco Docker*\n\n",
+		"ddisk_err":                    "❌ Spazio disco Docker non disponibile\n`%v`",
+		"ddisk_images":                 "🖼 *Immagini* — %d, %s\n",
+		"ddisk_unused":                 "   %d inutilizzate (%s)\n",
+		"ddisk_dangling":               "   %d orfane (%s)\n",
+		"ddisk_build":                  "\n🧱 *Cache di build* — %s (%s non in uso)\n",
+		"ddisk_volumes":                "\n📁 *Volumi* — %d, %s\n",
+		"ddisk_orphans":                "   %d orfani (%s)\n",
+		"ddisk_logs":                   "\n📜 *Log dei container* — %s\n",
+		"ddisk_open":                   "💽 Disco",
+		"ddisk_back":                   "Spazio disco",
+		"ddisk_btn_dangling":           "🗑 Immagini orfane",
+		"ddisk_btn_unused":             "🗑 Immagini inutilizzate",
+		"ddisk_btn_build":              "🗑 Cache di build",
+		"ddisk_btn_volumes":            "🗑 Volumi orfani",
+		"ddisk_confirm_img":            "🗑 Eliminare l'immagine `%s`?",
+		"ddisk_confirm_vol":            "🗑 Eliminare il volume `%s`?\n\n_I suoi dati andranno persi._",
+		"ddisk_confirm_dangling":       "🗑 Eliminare tutte le immagini orfane?",
+		"ddisk_confirm_unused":         "🗑 Eliminare tutte le immagini non usate da alcun container?\n\n_Verranno scaricate di nuovo quando servono._",
+		"ddisk_confirm_build":          "🗑 Svuotare tutta la cache di build?",
+		"ddisk_confirm_volumes":        "🗑 Eliminare tutti i volumi non montati da alcun container?\n\n_I loro dati andranno persi._",
+		"ddisk_deleting":               "⏳ Pulizia in corso...",
+		"ddisk_delete_ok":              "✅ Eliminato: %s\n%s liberati",
+		"ddisk_delete_err":             "❌ Impossibile eliminare %s\n`%v`",
+		"dlog_confirm":                 "✂️ Svuotare il log di *%s*?\n\n_L'output passato va perso; le nuove righe continuano a essere scritte._",
+		"dlog_ok":                      "✅ Log di *%s* svuotato, %s liberati",
+		"dlog_err":                     "❌ Impossibile svuotare il log di *%s*\n`%v`",
+		"order_title_boot":             "🚀 *Avvio dei container dopo il boot*",
+		"order_title_recovery":         "🚀 *Avvio dei container dopo il riavvio di Docker*",
+		"docker_exec_confirm":          "⚙️ Eseguire *%s* in *%s*?\n\n`%s`",
+		"docker_exec_user":             "\ncome utente `%s`",
+		"docker_exec_unknown":          "❌ Questa azione non è più configurata",
+		"docker_exec_busy":             "⏳ *%s* è già in esecuzione",
+		"docker_exec_running":          "⏳ Esecuzione di *%s* in *%s*...",
+		"docker_exec_done":             "%s *%s* terminato con codice %d in %s",
+		"docker_exec_timeout":          "⏱ *%s* non è terminato entro %s\n_Il comando potrebbe essere ancora in esecuzione nel container._",
+		"docker_exec_err":              "❌ Impossibile eseguire *%s*\n`%v`",
+		"docker_exec_no_output":        "\n_Nessun output._",
+		"docker_exec_truncated":        "\n_Output troncato._",
+		"inspect_title":                "🔎 *%s*",
+		"inspect_err":                  "❌ Impossibile ispezionare `%s`\n`%v`",
+		"inspect_image":                "Immagine: %s",
+		"inspect_created":              "Creato: %s",
+		"inspect_restart":              "Riavvio: %s · %d riavvii",
+		"inspect_ports":                "*Porte*",
+		"inspect_port_internal":        "%s (non pubblicata)",
+		"inspect_mounts":               "*Mount*",
+		"inspect_networks":             "*Reti*",
+		"inspect_limits":               "*Limiti di risorse*",
+		"inspect_memory":               "Memoria: %s",
+		"inspect_memory_reservation":   "Memoria riservata: %s",
+		"inspect_cpus":                 "CPU: %s",
+		"inspect_cpuset":               "Set di CPU: %s",
+		"inspect_cpu_shares":           "CPU shares: %d",
+		"inspect_pids":                 "Processi: %d",
+		"inspect_unlimited":            "_nessun limite_",
+		"inspect_labels":               "*Label*",
+		"inspect_env":                  "*Ambiente* _(segreti nascosti)_",
+		"inspect_none":                 "_nessuno_",
+		"docker_filter_empty":          "_Nessun container corrisponde al filtro._\n",
+		"docker_filter_page":           "\n_%s · pagina %d/%d_",
+		"docker_filter_all":            "Tutti",
+		"docker_filter_run":            "Attivi",
+		"docker_filter_stop":           "Fermi",
+		"docker_filter_sick":           "Non in salute",
+		"docker_stack_actions":         "⚙️ Stack %s",
+		"docker_stacks_title":          "📚 *Stack*\n\n",
+		"docker_no_stacks":             "_Nessuno stack compose._\n",
+		"docker_find_title":            "🔎 *%d container corrispondono a* `%s`\n\n",
+		"docker_find_more":             "\n_…e altri %d, affina la ricerca._",
+		"docker_find_none":             "❓ Nessun container corrisponde a `%s`",
+		"docker_find_usage":            "Uso: `/container find <testo>`\n\nCerca nei nomi dei container, nelle immagini e negli stack compose.",
 		"docker_action_err":            "❌ Impossibile %s *%s*\n`%s`",
 		"docker_action_ok":             "✅ *%s* %s",
 		"docker_started":               "avviato ▶️",
@@ -607,6 +955,7 @@ var translations = map[string]map[string]string{
 		"boot_quiet_fmt": "\n🌙 _Silenzioso: %02d:%02d — %02d:%02d_",
 
 		"status_not_running": "non attivo",
+		"status_unhealthy":   "non in salute",
 		"status_not_found":   "non trovato",
 		"crit_cont_alert":    "🚨 *Allarme Container Critico*\n\nIl container `%s` risulta %s!\n\n_Questo container è monitorato come critico_",
 
@@ -673,6 +1022,11 @@ var translations = map[string]map[string]string{
 		"cmd_config_desc":           "Mostra la configurazione attuale",
 		"cmd_sysinfo_desc":          "Informazioni dettagliate sul sistema",
 		"cmd_diskpred_desc":         "Previsione spazio su disco",
+		"cmd_graph_desc":            "Grafici delle metriche (CPU, RAM, disco, rete...)",
+		"cmd_smart_desc":            "Salute dei dischi e log dei self-test",
+		"cmd_raid_desc":             "Array RAID e avanzamento della ricostruzione",
+		"cmd_dupdates_desc":         "Aggiornamenti immagini dei container",
+		"cmd_ddisk_desc":            "Spazio disco Docker e pulizia",
 		"cmd_shutdown_desc":         "Spegni il sistema",
 		"cmd_help_desc":             "Mostra tutti i comandi disponibili",
 		"settings_thresholds":       "Soglie Allarmi",
@@ -784,6 +1138,11 @@ var translations = map[string]map[string]string{
 		"cmd_config_desc":     "Mostrar la configuración actual",
 		"cmd_sysinfo_desc":    "Información detallada del sistema",
 		"cmd_diskpred_desc":   "Predicción de espacio en disco",
+		"cmd_graph_desc":      "Gráficos de métricas (CPU, RAM, disco, red...)",
+		"cmd_smart_desc":      "Salud de los discos y registro de autopruebas",
+		"cmd_raid_desc":       "Arrays RAID y progreso de la reconstrucción",
+		"cmd_dupdates_desc":   "Actualizaciones de imágenes de contenedores",
+		"cmd_ddisk_desc":      "Uso de disco de Docker y limpieza",
 		"cmd_shutdown_desc":   "Apagar el sistema",
 		"cmd_help_desc":       "Mostrar todos los comandos",
 		"report_enabled_fmt":  "Cada %d días (%d veces/día)",
@@ -889,6 +1248,11 @@ var translations = map[string]map[string]string{
 		"cmd_config_desc":     "Aktuelle Konfiguration anzeigen",
 		"cmd_sysinfo_desc":    "Detaillierte Systeminformationen",
 		"cmd_diskpred_desc":   "Speicherplatzvorhersage",
+		"cmd_graph_desc":      "Metrik-Diagramme (CPU, RAM, Festplatte, Netzwerk...)",
+		"cmd_smart_desc":      "Festplattenzustand und Selbsttest-Protokoll",
+		"cmd_raid_desc":       "RAID-Arrays und Fortschritt der Wiederherstellung",
+		"cmd_dupdates_desc":   "Container-Image-Updates",
+		"cmd_ddisk_desc":      "Docker-Speicherbelegung und Bereinigung",
 		"cmd_shutdown_desc":   "System herunterfahren",
 		"cmd_help_desc":       "Alle verfügbaren Befehle anzeigen",
 		"report_enabled_fmt":  "Alle %d Tage (%d mal/Tag)",
@@ -994,6 +1358,11 @@ var translations = map[string]map[string]string{
 		"cmd_config_desc":     "显示当前配置",
 		"cmd_sysinfo_desc":    "详细系统信息",
 		"cmd_diskpred_desc":   "磁盘空间预测",
+		"cmd_graph_desc":      "指标图表（CPU、内存、磁盘、网络…）",
+		"cmd_smart_desc":      "磁盘健康与自检日志",
+		"cmd_raid_desc":       "RAID 阵列与重建进度",
+		"cmd_dupdates_desc":   "容器镜像更新",
+		"cmd_ddisk_desc":      "Docker 磁盘占用与清理",
 		"cmd_shutdown_desc":   "关闭系统",
 		"cmd_help_desc":       "显示所有可用命令",
 		"report_enabled_fmt":  "每 %d 天 (%d 次/天)",
@@ -1099,6 +1468,11 @@ var translations = map[string]map[string]string{
 		"cmd_config_desc":     "Показати поточну конфігурацію",
 		"cmd_sysinfo_desc":    "Детальна інформація про систему",
 		"cmd_diskpred_desc":   "Прогнозування вільного місця",
+		"cmd_graph_desc":      "Графіки метрик (CPU, RAM, диск, мережа...)",
+		"cmd_smart_desc":      "Стан дисків і журнал самотестів",
+		"cmd_raid_desc":       "RAID-масиви та хід відновлення",
+		"cmd_dupdates_desc":   "Оновлення образів контейнерів",
+		"cmd_ddisk_desc":      "Використання диска Docker і очищення",
 		"cmd_shutdown_desc":   "Вимкнути систему",
 		"cmd_help_desc":       "Показати всі доступні команди",
 		"report_enabled_fmt":  "Кожні %d дні (%d разів/день)",
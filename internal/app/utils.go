@@ -10,6 +10,7 @@ import (
 
 	"nasbot/internal/cmdexec"
 	"nasbot/internal/format"
+	"nasbot/internal/smart"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -42,78 +43,36 @@ func readCPUTemp() float64 {
 	return 0
 }
 
-// readDiskSMART reads disk SMART data
-func readDiskSMART(device string) (temp int, health string) {
-	temp = -1
-	health = "UNKNOWN"
-
-	// Use separate contexts for sequential commands to avoid timeout overlaps
-	ctxA, cancelA := context.WithTimeout(context.Background(), 2*time.Second)
-	outA, attrErr := runCommandStdout(ctxA, "sudo", "-n", "smartctl", "-A", "/dev/"+device)
-	cancelA()
-
-	for _, line := range strings.Split(string(outA), "\n") {
-		trimmed := strings.TrimSpace(line)
-		if strings.Contains(line, "Temperature_Celsius") || strings.Contains(line, "Temperature_Internal") {
-			fields := strings.Fields(line)
-			if len(fields) >= 10 {
-				t, _ := strconv.Atoi(fields[9])
-				if t > 0 {
-					temp = t
-				}
-			}
-		} else if strings.HasPrefix(trimmed, "Temperature:") {
-			fields := strings.Fields(trimmed)
-			if len(fields) >= 2 {
-				t, _ := strconv.Atoi(fields[1])
-				if t > 0 {
-					temp = t
-				}
-			}
-		} else if strings.HasPrefix(trimmed, "Temperature Sensor") { // NVMe extended sensors
-			fields := strings.Fields(trimmed)
-			// Example: "Temperature Sensor 1:   35 Celsius"
-			for i, f := range fields {
-				if f == "Celsius" && i > 0 {
-					t, _ := strconv.Atoi(fields[i-1])
-					if t > 0 {
-						temp = t
-					}
-				}
-			}
-		}
+// readSMARTReport runs smartctl in JSON mode on a disk. smartctl exits
+// non-zero for conditions such as old errors in the log while still
+// printing a full report, so the output is parsed even then. Unless wake
+// is set, a spun-down disk is left asleep and reported as Standby.
+func readSMARTReport(device string, wake bool) (smart.Report, error) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	args := []string{"-n", "smartctl", "--json", "-a"}
+	if !wake {
+		args = append(args, "-n", "standby")
 	}
-
-	ctxH, cancelH := context.WithTimeout(context.Background(), 2*time.Second)
-	outH, healthErr := runCommandStdout(ctxH, "sudo", "-n", "smartctl", "-H", "/dev/"+device)
-	cancelH()
-
-	passed := false
-	failed := false
-	for _, line := range strings.Split(string(outH), "\n") {
-		if strings.Contains(line, "PASSED") {
-			passed = true
-		} else if strings.Contains(line, "FAILED") {
-			failed = true
-		}
-	}
-
-	if failed {
-		health = "FAILED!"
-	} else if passed {
-		health = "PASSED"
-	} else if healthErr == nil {
-		health = "OK"
+	out, runErr := runCommandStdout(timeoutCtx, "sudo", append(args, "/dev/"+device)...)
+	r, err := smart.Parse(out)
+	if err != nil && runErr != nil {
+		err = runErr
 	}
+	return r, err
+}
 
-	if attrErr != nil {
-		slog.Warn("smartctl attribute read failed", "device", device, "err", attrErr)
+// readDiskSMART reads disk temperature and health
+func readDiskSMART(device string) (temp int, health string) {
+	r, err := readSMARTReport(device, false)
+	if err != nil {
+		slog.Warn("smartctl read failed", "device", device, "err", err)
+		return -1, smart.HealthUnknown
 	}
-	if healthErr != nil {
-		slog.Warn("smartctl health read failed", "device", device, "err", healthErr)
+	if r.Health == smart.HealthUnknown {
+		return r.Temp, "OK"
 	}
-
-	return temp, health
+	return r.Temp, r.Health
 }
 
 // parseUptime parses Docker container uptime
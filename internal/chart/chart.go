@@ -0,0 +1,353 @@
+// Package chart renders simple time-series line charts as PNG images using
+// only the standard library.
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+	"time"
+)
+
+// ErrNoData is returned when no series has any point to draw.
+var ErrNoData = errors.New("chart: no data")
+
+// Point is one value of a series.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is one line of the chart.
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// Chart describes a line chart. When YMax > YMin the Y axis is fixed to that
+// range, otherwise it is fitted to the data (starting at zero if FromZero).
+type Chart struct {
+	Title    string
+	Series   []Series
+	YMin     float64
+	YMax     float64
+	FromZero bool
+	Location *time.Location
+	Width    int
+	Height   int
+}
+
+const (
+	defaultWidth  = 800
+	defaultHeight = 400
+	fontScale     = 2
+	padLeft       = 64
+	padRight      = 24
+	padTop        = 56
+	padBottom     = 36
+)
+
+var (
+	colBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	colGrid       = color.RGBA{0xe4, 0xe4, 0xe4, 0xff}
+	colAxis       = color.RGBA{0x99, 0x99, 0x99, 0xff}
+	colText       = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	palette       = []color.RGBA{
+		{0x1f, 0x77, 0xb4, 0xff},
+		{0xff, 0x7f, 0x0e, 0xff},
+		{0x2c, 0xa0, 0x2c, 0xff},
+		{0xd6, 0x27, 0x28, 0xff},
+		{0x94, 0x67, 0xbd, 0xff},
+		{0x8c, 0x56, 0x4b, 0xff},
+	}
+)
+
+// PNG renders the chart.
+func (c Chart) PNG() ([]byte, error) {
+	w, h := c.Width, c.Height
+	if w <= 0 {
+		w = defaultWidth
+	}
+	if h <= 0 {
+		h = defaultHeight
+	}
+	loc := c.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	tMin, tMax, vMin, vMax, ok := c.bounds()
+	if !ok {
+		return nil, ErrNoData
+	}
+	if c.YMax > c.YMin {
+		vMin, vMax = c.YMin, c.YMax
+	} else if c.FromZero && vMin > 0 {
+		vMin = 0
+	}
+	yTicks, vMin, vMax := niceTicks(vMin, vMax, 5)
+	if !tMax.After(tMin) {
+		tMin = tMin.Add(-time.Minute)
+		tMax = tMax.Add(time.Minute)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	fillRect(img, img.Bounds(), colBackground)
+	plot := image.Rect(padLeft, padTop, w-padRight, h-padBottom)
+
+	xOf := func(t time.Time) int {
+		f := float64(t.Sub(tMin)) / float64(tMax.Sub(tMin))
+		return plot.Min.X + int(math.Round(f*float64(plot.Dx()-1)))
+	}
+	yOf := func(v float64) int {
+		f := (v - vMin) / (vMax - vMin)
+		f = math.Max(0, math.Min(1, f))
+		return plot.Max.Y - 1 - int(math.Round(f*float64(plot.Dy()-1)))
+	}
+
+	// Grid and Y labels
+	for _, v := range yTicks {
+		y := yOf(v)
+		hLine(img, plot.Min.X, plot.Max.X-1, y, colGrid)
+		label := formatTick(v, yTicks)
+		drawText(img, plot.Min.X-8-textWidth(label, fontScale), y-glyphH*fontScale/2, label, fontScale, colText)
+	}
+
+	// X labels
+	for _, t := range timeTicks(tMin, tMax, loc) {
+		x := xOf(t)
+		vLine(img, x, plot.Min.Y, plot.Max.Y-1, colGrid)
+		label := formatTime(t, tMax.Sub(tMin), loc)
+		drawText(img, x-textWidth(label, fontScale)/2, plot.Max.Y+8, label, fontScale, colText)
+	}
+
+	hLine(img, plot.Min.X, plot.Max.X-1, plot.Max.Y-1, colAxis)
+	vLine(img, plot.Min.X, plot.Min.Y, plot.Max.Y-1, colAxis)
+
+	// Series
+	for i, s := range c.Series {
+		col := palette[i%len(palette)]
+		gap := gapThreshold(s.Points)
+		for k := 1; k < len(s.Points); k++ {
+			a, b := s.Points[k-1], s.Points[k]
+			if b.Time.Sub(a.Time) > gap {
+				continue
+			}
+			thickLine(img, xOf(a.Time), yOf(a.Value), xOf(b.Time), yOf(b.Value), col)
+		}
+		if len(s.Points) == 1 {
+			p := s.Points[0]
+			fillRect(img, image.Rect(xOf(p.Time)-2, yOf(p.Value)-2, xOf(p.Time)+3, yOf(p.Value)+3), col)
+		}
+	}
+
+	// Title and legend
+	drawText(img, padLeft, 12, c.Title, fontScale, colText)
+	if len(c.Series) > 1 {
+		x := padLeft
+		y := 12 + glyphH*fontScale + 8
+		for i, s := range c.Series {
+			col := palette[i%len(palette)]
+			fillRect(img, image.Rect(x, y+2, x+10, y+12), col)
+			drawText(img, x+14, y, s.Name, fontScale, colText)
+			x += 14 + textWidth(s.Name, fontScale) + 18
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c Chart) bounds() (tMin, tMax time.Time, vMin, vMax float64, ok bool) {
+	for _, s := range c.Series {
+		for _, p := range s.Points {
+			if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+				continue
+			}
+			if !ok {
+				tMin, tMax, vMin, vMax, ok = p.Time, p.Time, p.Value, p.Value, true
+				continue
+			}
+			if p.Time.Before(tMin) {
+				tMin = p.Time
+			}
+			if p.Time.After(tMax) {
+				tMax = p.Time
+			}
+			vMin = math.Min(vMin, p.Value)
+			vMax = math.Max(vMax, p.Value)
+		}
+	}
+	return
+}
+
+// gapThreshold returns the distance above which two consecutive points are
+// not joined, so missing data (e.g. the bot was down) shows as a gap.
+func gapThreshold(pts []Point) time.Duration {
+	if len(pts) < 3 {
+		return math.MaxInt64
+	}
+	deltas := make([]time.Duration, 0, len(pts)-1)
+	for k := 1; k < len(pts); k++ {
+		deltas = append(deltas, pts[k].Time.Sub(pts[k-1].Time))
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i] < deltas[j] })
+	return 3 * deltas[len(deltas)/2]
+}
+
+// niceTicks returns about n round tick values covering [lo, hi] and the
+// widened range.
+func niceTicks(lo, hi float64, n int) ([]float64, float64, float64) {
+	if hi <= lo {
+		pad := math.Max(math.Abs(hi)*0.1, 1)
+		lo, hi = lo-pad, hi+pad
+	}
+	step := niceStep((hi - lo) / float64(n))
+	lo = math.Floor(lo/step) * step
+	hi = math.Ceil(hi/step) * step
+	ticks := make([]float64, 0, n+2)
+	for v := lo; v <= hi+step/2; v += step {
+		ticks = append(ticks, v)
+	}
+	return ticks, lo, hi
+}
+
+func niceStep(raw float64) float64 {
+	exp := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch f := raw / exp; {
+	case f <= 1:
+		return exp
+	case f <= 2:
+		return 2 * exp
+	case f <= 5:
+		return 5 * exp
+	}
+	return 10 * exp
+}
+
+func formatTick(v float64, ticks []float64) string {
+	step := 1.0
+	if len(ticks) > 1 {
+		step = ticks[1] - ticks[0]
+	}
+	switch {
+	case math.Abs(v) >= 10000:
+		return fmt.Sprintf("%.0fK", v/1000)
+	case step < 1:
+		return fmt.Sprintf("%.1f", v)
+	}
+	return fmt.Sprintf("%.0f", v)
+}
+
+var timeSteps = []time.Duration{
+	5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+	24 * time.Hour, 2 * 24 * time.Hour, 7 * 24 * time.Hour, 14 * 24 * time.Hour,
+}
+
+// timeTicks returns at most 6 round times between from and to.
+func timeTicks(from, to time.Time, loc *time.Location) []time.Time {
+	span := to.Sub(from)
+	step := timeSteps[len(timeSteps)-1]
+	for _, s := range timeSteps {
+		if span/s <= 6 {
+			step = s
+			break
+		}
+	}
+
+	local := from.In(loc)
+	var t time.Time
+	if step >= 24*time.Hour {
+		t = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	} else {
+		_, offset := local.Zone()
+		shift := time.Duration(offset) * time.Second
+		t = from.Add(shift).Truncate(step).Add(-shift)
+	}
+	var out []time.Time
+	for ; !t.After(to); t = t.Add(step) {
+		if !t.Before(from) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func formatTime(t time.Time, span time.Duration, loc *time.Location) string {
+	if span > 36*time.Hour {
+		return t.In(loc).Format("02/01")
+	}
+	return t.In(loc).Format("15:04")
+}
+
+// ═══════════════════════════════════════════════════════════════════
+//  Drawing primitives
+// ═══════════════════════════════════════════════════════════════════
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+func hLine(img *image.RGBA, x0, x1, y int, c color.Color) {
+	for x := x0; x <= x1; x++ {
+		img.Set(x, y, c)
+	}
+}
+
+func vLine(img *image.RGBA, x, y0, y1 int, c color.Color) {
+	for y := y0; y <= y1; y++ {
+		img.Set(x, y, c)
+	}
+}
+
+// thickLine draws a 2px line with Bresenham's algorithm.
+func thickLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		img.Set(x0+1, y0, c)
+		img.Set(x0, y0+1, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
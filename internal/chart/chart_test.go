@@ -0,0 +1,87 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestChartPNG(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var rx, tx []Point
+	for i := 0; i < 288; i++ {
+		ts := t0.Add(time.Duration(i) * 5 * time.Minute)
+		rx = append(rx, Point{Time: ts, Value: float64(i % 50)})
+		tx = append(tx, Point{Time: ts, Value: float64(i % 20)})
+	}
+	c := Chart{
+		Title:    "Network Mbps - 24h",
+		Series:   []Series{{Name: "RX", Points: rx}, {Name: "TX", Points: tx}},
+		FromZero: true,
+		Location: time.UTC,
+	}
+	b, err := c.PNG()
+	if err != nil {
+		t.Fatalf("PNG: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if img.Bounds().Dx() != defaultWidth || img.Bounds().Dy() != defaultHeight {
+		t.Fatalf("unexpected size %v", img.Bounds())
+	}
+	// The first series colour must appear somewhere inside the plot.
+	found := false
+	for y := padTop; y < defaultHeight-padBottom && !found; y++ {
+		for x := padLeft; x < defaultWidth-padRight; x++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r>>8 == 0x1f && g>>8 == 0x77 && b>>8 == 0xb4 {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("series line not drawn")
+	}
+}
+
+func TestChartNoData(t *testing.T) {
+	if _, err := (Chart{Title: "empty", Series: []Series{{Name: "x"}}}).PNG(); !errors.Is(err, ErrNoData) {
+		t.Fatalf("expected ErrNoData, got %v", err)
+	}
+}
+
+func TestNiceTicks(t *testing.T) {
+	ticks, lo, hi := niceTicks(3, 97, 5)
+	if lo != 0 || hi != 100 || len(ticks) != 6 || ticks[1] != 20 {
+		t.Fatalf("niceTicks = %v [%v, %v]", ticks, lo, hi)
+	}
+	if ticks, _, _ := niceTicks(42, 42, 5); len(ticks) < 2 {
+		t.Fatalf("flat data should still produce a range, got %v", ticks)
+	}
+}
+
+func TestTimeTicks(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	from := time.Date(2024, 1, 1, 0, 7, 0, 0, time.UTC)
+	ticks := timeTicks(from, from.Add(24*time.Hour), loc)
+	if len(ticks) == 0 || len(ticks) > 6 {
+		t.Fatalf("unexpected tick count %d", len(ticks))
+	}
+	for _, tk := range ticks {
+		if tk.In(loc).Minute() != 0 || tk.In(loc).Hour()%6 != 0 {
+			t.Fatalf("tick %v not aligned to local 6h", tk.In(loc))
+		}
+	}
+}
+
+func TestGapThreshold(t *testing.T) {
+	t0 := time.Now()
+	pts := []Point{{Time: t0}, {Time: t0.Add(time.Minute)}, {Time: t0.Add(2 * time.Minute)}, {Time: t0.Add(time.Hour)}}
+	if g := gapThreshold(pts); g != 3*time.Minute {
+		t.Fatalf("gapThreshold = %v", g)
+	}
+}
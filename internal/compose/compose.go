@@ -0,0 +1,182 @@
+// Package compose groups containers into Docker Compose projects using the
+// labels compose puts on them, and builds the `docker compose` invocations
+// for stack-level actions.
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"nasbot/internal/model"
+)
+
+// Labels set by docker compose on every container it creates.
+const (
+	LabelProject     = "com.docker.compose.project"
+	LabelWorkingDir  = "com.docker.compose.project.working_dir"
+	LabelConfigFiles = "com.docker.compose.project.config_files"
+	LabelService     = "com.docker.compose.service"
+)
+
+// Stack states reported by Stack.State.
+const (
+	StateUp       = "up"
+	StateDegraded = "degraded"
+	StateDown     = "down"
+)
+
+// ErrNoWorkingDir is returned for actions that need the compose file when
+// the containers do not carry the project's working directory.
+var ErrNoWorkingDir = errors.New("compose project working directory unknown")
+
+// Stack is a compose project and its containers.
+type Stack struct {
+	Name        string
+	WorkingDir  string
+	ConfigFiles []string
+	Containers  []model.ContainerInfo
+}
+
+// Running returns how many containers of the stack are running.
+func (s Stack) Running() int {
+	n := 0
+	for _, c := range s.Containers {
+		if c.Running {
+			n++
+		}
+	}
+	return n
+}
+
+// Unhealthy returns how many running containers fail their healthcheck.
+func (s Stack) Unhealthy() int {
+	n := 0
+	for _, c := range s.Containers {
+		if c.Running && c.Health == "unhealthy" {
+			n++
+		}
+	}
+	return n
+}
+
+// State summarises the stack: up when every container runs and none is
+// unhealthy, down when none runs, degraded otherwise.
+func (s Stack) State() string {
+	running := s.Running()
+	switch {
+	case running == 0:
+		return StateDown
+	case running < len(s.Containers) || s.Unhealthy() > 0:
+		return StateDegraded
+	}
+	return StateUp
+}
+
+// Group splits containers into compose stacks, sorted by name, and the
+// containers that belong to no project.
+func Group(containers []model.ContainerInfo) (stacks []Stack, standalone []model.ContainerInfo) {
+	byName := make(map[string]*Stack)
+	for _, c := range containers {
+		project := c.Labels[LabelProject]
+		if project == "" {
+			standalone = append(standalone, c)
+			continue
+		}
+		s, ok := byName[project]
+		if !ok {
+			s = &Stack{Name: project}
+			byName[project] = s
+		}
+		if s.WorkingDir == "" {
+			s.WorkingDir = c.Labels[LabelWorkingDir]
+		}
+		if len(s.ConfigFiles) == 0 {
+			s.ConfigFiles = splitFiles(c.Labels[LabelConfigFiles])
+		}
+		s.Containers = append(s.Containers, c)
+	}
+
+	for _, s := range byName {
+		sort.Slice(s.Containers, func(i, j int) bool { return s.Containers[i].Name < s.Containers[j].Name })
+		stacks = append(stacks, *s)
+	}
+	sort.Slice(stacks, func(i, j int) bool { return stacks[i].Name < stacks[j].Name })
+	return stacks, standalone
+}
+
+// Find returns the stack called name.
+func Find(containers []model.ContainerInfo, name string) (Stack, bool) {
+	stacks, _ := Group(containers)
+	for _, s := range stacks {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Stack{}, false
+}
+
+// Args returns the docker arguments running action ("up", "down",
+// "restart" or "pull") on the stack from its working directory.
+func Args(s Stack, action string) ([]string, error) {
+	var verb []string
+	switch action {
+	case "up":
+		verb = []string{"up", "-d"}
+	case "down":
+		verb = []string{"down"}
+	case "restart":
+		verb = []string{"restart"}
+	case "pull":
+		verb = []string{"pull"}
+	default:
+		return nil, errors.New("unknown compose action: " + action)
+	}
+
+	args := []string{"compose", "-p", s.Name}
+	if s.WorkingDir != "" {
+		args = append(args, "--project-directory", s.WorkingDir)
+		for _, f := range s.ConfigFiles {
+			args = append(args, "-f", f)
+		}
+	} else if action == "up" || action == "pull" {
+		return nil, ErrNoWorkingDir
+	}
+	return append(args, verb...), nil
+}
+
+func splitFiles(label string) []string {
+	var files []string
+	for _, f := range strings.Split(label, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// Icon is the emoji for the stack state.
+func (s Stack) Icon() string {
+	switch s.State() {
+	case StateUp:
+		return "✅"
+	case StateDegraded:
+		return "⚠️"
+	}
+	return "🔴"
+}
+
+// Summary renders one entry per stack, e.g. "media ✅ · cloud ⚠️ 1/2";
+// the running count is only shown for stacks that are not fully up.
+func Summary(stacks []Stack) string {
+	parts := make([]string, 0, len(stacks))
+	for _, s := range stacks {
+		part := s.Name + " " + s.Icon()
+		if s.State() != StateUp {
+			part += fmt.Sprintf(" %d/%d", s.Running(), len(s.Containers))
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,87 @@
+package compose
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"nasbot/internal/model"
+)
+
+func member(name, project string, running bool) model.ContainerInfo {
+	c := model.ContainerInfo{Name: name, Running: running}
+	if project != "" {
+		c.Labels = map[string]string{
+			LabelProject:     project,
+			LabelWorkingDir:  "/srv/" + project,
+			LabelConfigFiles: "/srv/" + project + "/compose.yml,/srv/" + project + "/override.yml",
+		}
+	}
+	return c
+}
+
+func TestGroup(t *testing.T) {
+	stacks, standalone := Group([]model.ContainerInfo{
+		member("sonarr", "media", true),
+		member("portainer", "", true),
+		member("jellyfin", "media", true),
+		member("db", "cloud", false),
+	})
+	if len(stacks) != 2 || stacks[0].Name != "cloud" || stacks[1].Name != "media" {
+		t.Fatalf("unexpected stacks: %+v", stacks)
+	}
+	if len(standalone) != 1 || standalone[0].Name != "portainer" {
+		t.Fatalf("unexpected standalone: %+v", standalone)
+	}
+	media := stacks[1]
+	if media.Containers[0].Name != "jellyfin" || media.WorkingDir != "/srv/media" || len(media.ConfigFiles) != 2 {
+		t.Fatalf("unexpected media stack: %+v", media)
+	}
+}
+
+func TestStackState(t *testing.T) {
+	up := Stack{Containers: []model.ContainerInfo{member("a", "p", true), member("b", "p", true)}}
+	if up.State() != StateUp {
+		t.Fatalf("all running should be up")
+	}
+	up.Containers[1].Health = "unhealthy"
+	if up.State() != StateDegraded || up.Unhealthy() != 1 {
+		t.Fatalf("an unhealthy member should degrade the stack")
+	}
+	down := Stack{Containers: []model.ContainerInfo{member("a", "p", false)}}
+	if down.State() != StateDown {
+		t.Fatalf("no running member should be down")
+	}
+}
+
+func TestArgs(t *testing.T) {
+	stacks, _ := Group([]model.ContainerInfo{member("web", "site", true)})
+	args, err := Args(stacks[0], "up")
+	want := []string{"compose", "-p", "site", "--project-directory", "/srv/site",
+		"-f", "/srv/site/compose.yml", "-f", "/srv/site/override.yml", "up", "-d"}
+	if err != nil || !reflect.DeepEqual(args, want) {
+		t.Fatalf("Args = %v, %v", args, err)
+	}
+
+	bare := Stack{Name: "old"}
+	if args, err := Args(bare, "restart"); err != nil || !reflect.DeepEqual(args, []string{"compose", "-p", "old", "restart"}) {
+		t.Fatalf("restart without working dir = %v, %v", args, err)
+	}
+	if _, err := Args(bare, "pull"); !errors.Is(err, ErrNoWorkingDir) {
+		t.Fatalf("pull without working dir should fail, got %v", err)
+	}
+	if _, err := Args(bare, "rm"); err == nil {
+		t.Fatalf("unknown action should fail")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	stacks, _ := Group([]model.ContainerInfo{
+		member("jellyfin", "media", true),
+		member("db", "cloud", false),
+		member("app", "cloud", true),
+	})
+	if got := Summary(stacks); got != "cloud ⚠️ 1/2 · media ✅" {
+		t.Fatalf("Summary = %q", got)
+	}
+}
@@ -0,0 +1,530 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxParallelStats bounds concurrent stats requests: each one waits for the
+// daemon to take two CPU samples.
+const maxParallelStats = 8
+
+// APIClient speaks the Docker Engine API over a unix socket.
+type APIClient struct {
+	socket string
+	http   *http.Client
+}
+
+// NewAPIClient returns a client for the daemon listening on socket.
+func NewAPIClient(socket string) *APIClient {
+	dialer := &net.Dialer{Timeout: 2 * time.Second}
+	return &APIClient{
+		socket: socket,
+		http: &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+			MaxIdleConns:    4,
+			IdleConnTimeout: 30 * time.Second,
+		}},
+	}
+}
+
+// apiError is the error body returned by the daemon.
+type apiError struct {
+	Status  int
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("docker API %d: %s", e.Status, e.Message)
+}
+
+// do performs a request and returns the response when the status is 2xx or
+// 304. Dial failures are reported as ErrUnavailable.
+func (c *APIClient) do(ctx context.Context, method, path string, query url.Values) (*http.Response, error) {
+	return c.doJSON(ctx, method, path, query, nil)
+}
+
+// doJSON is do with body, when not nil, sent as JSON.
+func (c *APIClient) doJSON(ctx context.Context, method, path string, query url.Values, body any) (*http.Response, error) {
+	u := "http://docker" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+		}
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotModified {
+		defer resp.Body.Close()
+		e := &apiError{Status: resp.StatusCode}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(body, e) != nil || e.Message == "" {
+			e.Message = strings.TrimSpace(string(body))
+		}
+		return nil, e
+	}
+	return resp, nil
+}
+
+func (c *APIClient) getJSON(ctx context.Context, path string, query url.Values, v any) error {
+	resp, err := c.do(ctx, http.MethodGet, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *APIClient) post(ctx context.Context, path string, query url.Values, v any) error {
+	resp, err := c.do(ctx, http.MethodPost, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if v == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func containerPath(name, action string) string {
+	p := "/containers/" + url.PathEscape(name)
+	if action != "" {
+		p += "/" + action
+	}
+	return p
+}
+
+// Ping checks that the daemon answers.
+func (c *APIClient) Ping(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodGet, "/_ping", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+type apiContainer struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	State  string            `json:"State"`
+	Status string            `json:"Status"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// List returns all containers.
+func (c *APIClient) List(ctx context.Context) ([]Container, error) {
+	var raw []apiContainer
+	if err := c.getJSON(ctx, "/containers/json", url.Values{"all": {"1"}}, &raw); err != nil {
+		return nil, err
+	}
+	out := make([]Container, 0, len(raw))
+	for _, r := range raw {
+		name := r.ID
+		if len(r.Names) > 0 {
+			name = strings.TrimPrefix(r.Names[0], "/")
+		}
+		out = append(out, Container{ID: r.ID, Name: name, Image: r.Image, State: r.State, Status: r.Status, Labels: r.Labels})
+	}
+	return out, nil
+}
+
+// Inspect returns the details of a container.
+func (c *APIClient) Inspect(ctx context.Context, name string) (*Details, error) {
+	resp, err := c.do(ctx, http.MethodGet, containerPath(name, "json"), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseDetails(raw)
+}
+
+func parseDetails(raw []byte) (*Details, error) {
+	d := &Details{}
+	if err := json.Unmarshal(raw, d); err != nil {
+		return nil, err
+	}
+	d.Name = strings.TrimPrefix(d.Name, "/")
+	d.Raw = raw
+	return d, nil
+}
+
+type apiCPUStats struct {
+	CPUUsage struct {
+		TotalUsage  uint64   `json:"total_usage"`
+		PercpuUsage []uint64 `json:"percpu_usage"`
+	} `json:"cpu_usage"`
+	SystemUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs  int    `json:"online_cpus"`
+}
+
+type apiStats struct {
+	Name        string      `json:"name"`
+	CPUStats    apiCPUStats `json:"cpu_stats"`
+	PreCPUStats apiCPUStats `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+	Networks map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+	BlkioStats struct {
+		IOServiceBytesRecursive []struct {
+			Op    string `json:"op"`
+			Value uint64 `json:"value"`
+		} `json:"io_service_bytes_recursive"`
+	} `json:"blkio_stats"`
+}
+
+// toStats computes the figures the same way `docker stats` does.
+func (s apiStats) toStats() Stats {
+	out := Stats{Name: strings.TrimPrefix(s.Name, "/")}
+
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	sysDelta := float64(s.CPUStats.SystemUsage) - float64(s.PreCPUStats.SystemUsage)
+	cpus := s.CPUStats.OnlineCPUs
+	if cpus == 0 {
+		cpus = len(s.CPUStats.CPUUsage.PercpuUsage)
+	}
+	if cpuDelta > 0 && sysDelta > 0 {
+		out.CPUPercent = cpuDelta / sysDelta * float64(cpus) * 100
+	}
+
+	// Page cache is not counted as used memory (cgroup v1 and v2 keys).
+	mem := s.MemoryStats.Usage
+	for _, k := range []string{"total_inactive_file", "inactive_file"} {
+		if v, ok := s.MemoryStats.Stats[k]; ok && v < mem {
+			mem -= v
+			break
+		}
+	}
+	out.MemUsage = mem
+	out.MemLimit = s.MemoryStats.Limit
+	if out.MemLimit > 0 {
+		out.MemPercent = float64(mem) / float64(out.MemLimit) * 100
+	}
+
+	for _, n := range s.Networks {
+		out.NetRx += n.RxBytes
+		out.NetTx += n.TxBytes
+	}
+	for _, e := range s.BlkioStats.IOServiceBytesRecursive {
+		switch strings.ToLower(e.Op) {
+		case "read":
+			out.BlockRead += e.Value
+		case "write":
+			out.BlockWrite += e.Value
+		}
+	}
+	return out
+}
+
+// Stats queries the containers concurrently.
+func (c *APIClient) Stats(ctx context.Context, names ...string) ([]Stats, error) {
+	if len(names) == 0 {
+		list, err := c.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, ct := range list {
+			if ct.Running() {
+				names = append(names, ct.Name)
+			}
+		}
+	}
+
+	results := make([]Stats, len(names))
+	errs := make([]error, len(names))
+	sem := make(chan struct{}, maxParallelStats)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			var raw apiStats
+			if err := c.getJSON(ctx, containerPath(name, "stats"), url.Values{"stream": {"false"}}, &raw); err != nil {
+				errs[i] = err
+				return
+			}
+			results[i] = raw.toStats()
+			if results[i].Name == "" {
+				results[i].Name = name
+			}
+		}(i, name)
+	}
+	wg.Wait()
+
+	// A container stopping mid-query is not a failure of the whole call.
+	out := make([]Stats, 0, len(names))
+	var firstErr error
+	for i := range names {
+		if errs[i] != nil {
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+		out = append(out, results[i])
+	}
+	if len(out) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return out, nil
+}
+
+// Logs returns the last tail lines of stdout and stderr.
+func (c *APIClient) Logs(ctx context.Context, name string, tail int) ([]byte, error) {
+	q := url.Values{"stdout": {"1"}, "stderr": {"1"}, "tail": {strconv.Itoa(tail)}}
+	resp, err := c.do(ctx, http.MethodGet, containerPath(name, "logs"), q)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return demuxLogs(raw), nil
+}
+
+// demuxLogs strips the 8-byte frame headers the daemon adds to the logs of
+// containers without a TTY. TTY logs are returned unchanged.
+func demuxLogs(raw []byte) []byte {
+	if !isMultiplexed(raw) {
+		return raw
+	}
+	var out bytes.Buffer
+	for len(raw) >= 8 {
+		size := int(binary.BigEndian.Uint32(raw[4:8]))
+		raw = raw[8:]
+		if size > len(raw) {
+			size = len(raw)
+		}
+		out.Write(raw[:size])
+		raw = raw[size:]
+	}
+	return out.Bytes()
+}
+
+func isMultiplexed(raw []byte) bool {
+	return len(raw) >= 8 && raw[0] <= 2 && raw[1] == 0 && raw[2] == 0 && raw[3] == 0
+}
+
+// Start starts a container; starting a running one is not an error.
+func (c *APIClient) Start(ctx context.Context, name string) error {
+	return c.post(ctx, containerPath(name, "start"), nil, nil)
+}
+
+// Stop stops a container.
+func (c *APIClient) Stop(ctx context.Context, name string) error {
+	return c.post(ctx, containerPath(name, "stop"), nil, nil)
+}
+
+// Restart restarts a container.
+func (c *APIClient) Restart(ctx context.Context, name string) error {
+	return c.post(ctx, containerPath(name, "restart"), nil, nil)
+}
+
+// Kill sends SIGKILL to a container.
+func (c *APIClient) Kill(ctx context.Context, name string) error {
+	return c.post(ctx, containerPath(name, "kill"), nil, nil)
+}
+
+// Prune mirrors `docker system prune -a -f`.
+func (c *APIClient) Prune(ctx context.Context) (PruneReport, error) {
+	var rep PruneReport
+
+	var containers struct {
+		ContainersDeleted []string `json:"ContainersDeleted"`
+		SpaceReclaimed    uint64   `json:"SpaceReclaimed"`
+	}
+	if err := c.post(ctx, "/containers/prune", nil, &containers); err != nil {
+		return rep, err
+	}
+	rep.ContainersDeleted = len(containers.ContainersDeleted)
+	rep.SpaceReclaimed += containers.SpaceReclaimed
+
+	var networks struct {
+		NetworksDeleted []string `json:"NetworksDeleted"`
+	}
+	if err := c.post(ctx, "/networks/prune", nil, &networks); err != nil {
+		return rep, err
+	}
+	rep.NetworksDeleted = len(networks.NetworksDeleted)
+
+	var images struct {
+		ImagesDeleted []struct {
+			Deleted string `json:"Deleted"`
+		} `json:"ImagesDeleted"`
+		SpaceReclaimed uint64 `json:"SpaceReclaimed"`
+	}
+	if err := c.post(ctx, "/images/prune", url.Values{"filters": {`{"dangling":["false"]}`}}, &images); err != nil {
+		return rep, err
+	}
+	for _, img := range images.ImagesDeleted {
+		if img.Deleted != "" {
+			rep.ImagesDeleted++
+		}
+	}
+	rep.SpaceReclaimed += images.SpaceReclaimed
+
+	// Old daemons have no build cache endpoint; that is not a failure.
+	var build struct {
+		SpaceReclaimed uint64 `json:"SpaceReclaimed"`
+	}
+	if err := c.post(ctx, "/build/prune", url.Values{"all": {"1"}}, &build); err == nil {
+		rep.SpaceReclaimed += build.SpaceReclaimed
+	}
+	return rep, nil
+}
+
+// ImageInspect returns the local image ref resolves to.
+func (c *APIClient) ImageInspect(ctx context.Context, ref string) (*Image, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/images/"+ref+"/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	img := &Image{}
+	if err := json.Unmarshal(raw, img); err != nil {
+		return nil, err
+	}
+	img.Raw = raw
+	return img, nil
+}
+
+// Pull pulls ref. The daemon answers 200 and reports failures inside the
+// progress stream, so the stream is read to the end.
+func (c *APIClient) Pull(ctx context.Context, ref string) error {
+	repo, tag := SplitRef(ref)
+	resp, err := c.do(ctx, http.MethodPost, "/images/create", url.Values{"fromImage": {repo}, "tag": {tag}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+	}
+}
+
+// Tag tags the image source as target.
+func (c *APIClient) Tag(ctx context.Context, source, target string) error {
+	repo, tag := SplitRef(target)
+	return c.post(ctx, "/images/"+source+"/tag", url.Values{"repo": {repo}, "tag": {tag}}, nil)
+}
+
+type apiEvent struct {
+	Type   string `json:"Type"`
+	Action string `json:"Action"`
+	Actor  struct {
+		ID         string            `json:"ID"`
+		Attributes map[string]string `json:"Attributes"`
+	} `json:"Actor"`
+	TimeNano int64 `json:"timeNano"`
+}
+
+// Events streams container events.
+func (c *APIClient) Events(ctx context.Context) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(events)
+
+		resp, err := c.do(ctx, http.MethodGet, "/events", url.Values{"filters": {`{"type":["container"]}`}})
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer resp.Body.Close()
+
+		dec := json.NewDecoder(bufio.NewReader(resp.Body))
+		for {
+			var raw apiEvent
+			if err := dec.Decode(&raw); err != nil {
+				if ctx.Err() != nil {
+					err = ctx.Err()
+				}
+				errs <- err
+				return
+			}
+			ev := Event{
+				Type:       raw.Type,
+				Action:     raw.Action,
+				ID:         raw.Actor.ID,
+				Name:       raw.Actor.Attributes["name"],
+				Attributes: raw.Actor.Attributes,
+				Time:       time.Unix(0, raw.TimeNano),
+			}
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return events, errs
+}
@@ -0,0 +1,372 @@
+package docker
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDaemon serves a minimal Engine API on a unix socket.
+type fakeDaemon struct {
+	mu      sync.Mutex
+	actions []string
+	created map[string]any
+	// deleteStatus answers DELETE /containers/{name} when set.
+	deleteStatus map[string]int
+}
+
+func (d *fakeDaemon) record(action string) {
+	d.mu.Lock()
+	d.actions = append(d.actions, action)
+	d.mu.Unlock()
+}
+
+func (d *fakeDaemon) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /_ping", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "OK") })
+	mux.HandleFunc("GET /containers/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("all") != "1" {
+			http.Error(w, "expected all=1", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"Id":"aaa","Names":["/web"],"Image":"nginx","State":"running","Status":"Up 2 hours","Labels":{"com.docker.compose.project":"site"}},
+			{"Id":"bbb","Names":["/db"],"Image":"postgres","State":"exited","Status":"Exited (0) 1 hour ago"}
+		]`)
+	})
+	mux.HandleFunc("GET /containers/{name}/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("name") != "web" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"No such container: `+r.PathValue("name")+`"}`)
+			return
+		}
+		fmt.Fprint(w, `{"Id":"aaa","Name":"/web","Image":"sha256:old","RestartCount":3,"LogPath":"/var/lib/docker/containers/aaa/aaa-json.log",
+			"State":{"Status":"running","Running":true,"StartedAt":"2024-01-01T10:00:00Z","Health":{"Status":"healthy"}},
+			"Config":{"Image":"nginx:latest","Env":["A=1"]},"HostConfig":{"NetworkMode":"site_default","RestartPolicy":{"Name":"unless-stopped"},"Memory":536870912,"PidsLimit":200},
+			"Mounts":[{"Type":"bind","Source":"/srv/web","Destination":"/usr/share/nginx/html","RW":false}],
+			"NetworkSettings":{"Ports":{"80/tcp":[{"HostIp":"0.0.0.0","HostPort":"8080"}],"443/tcp":null},"Networks":{"site_default":{"IPAddress":"172.18.0.2"}}}}`)
+	})
+	mux.HandleFunc("GET /containers/{name}/stats", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"name":"/%s",
+			"cpu_stats":{"cpu_usage":{"total_usage":300},"system_cpu_usage":2000,"online_cpus":4},
+			"precpu_stats":{"cpu_usage":{"total_usage":100},"system_cpu_usage":1000},
+			"memory_stats":{"usage":1200,"limit":4000,"stats":{"inactive_file":200}},
+			"networks":{"eth0":{"rx_bytes":10,"tx_bytes":20},"eth1":{"rx_bytes":1,"tx_bytes":2}},
+			"blkio_stats":{"io_service_bytes_recursive":[{"op":"Read","value":5},{"op":"Write","value":7}]}}`, r.PathValue("name"))
+	})
+	mux.HandleFunc("GET /containers/{name}/logs", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("tail") != "2" {
+			http.Error(w, "bad tail", http.StatusBadRequest)
+			return
+		}
+		writeFrame(w, 1, "hello\n")
+		writeFrame(w, 2, "oops\n")
+	})
+	mux.HandleFunc("POST /containers/{name}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		d.record(r.PathValue("action") + " " + r.PathValue("name"))
+		if r.PathValue("name") == "gone" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"No such container: gone"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /containers/create", func(w http.ResponseWriter, r *http.Request) {
+		d.record("create " + r.URL.Query().Get("name"))
+		d.mu.Lock()
+		json.NewDecoder(r.Body).Decode(&d.created)
+		d.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"Id":"new"}`)
+	})
+	mux.HandleFunc("DELETE /containers/{name}", func(w http.ResponseWriter, r *http.Request) {
+		d.record("delete " + r.PathValue("name") + " force=" + r.URL.Query().Get("force"))
+		if status := d.deleteStatus[r.PathValue("name")]; status != 0 {
+			w.WriteHeader(status)
+			fmt.Fprint(w, `{"message":"cannot remove `+r.PathValue("name")+`"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /images/{name}/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"Id":"%s","RepoTags":["nginx:latest"],"RepoDigests":["nginx@sha256:abc"],"Config":{"Env":["PATH=/bin"]}}`, r.PathValue("name"))
+	})
+	mux.HandleFunc("POST /images/create", func(w http.ResponseWriter, r *http.Request) {
+		d.record("pull " + r.URL.Query().Get("fromImage") + ":" + r.URL.Query().Get("tag"))
+		fmt.Fprint(w, `{"status":"Pulling from library/nginx"}`+"\n")
+		if r.URL.Query().Get("fromImage") == "private/app" {
+			fmt.Fprint(w, `{"errorDetail":{"message":"denied"},"error":"pull access denied"}`+"\n")
+		}
+	})
+	mux.HandleFunc("POST /images/{name}/tag", func(w http.ResponseWriter, r *http.Request) {
+		d.record("tag " + r.PathValue("name") + " " + r.URL.Query().Get("repo") + ":" + r.URL.Query().Get("tag"))
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("POST /containers/prune", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ContainersDeleted":["x","y"],"SpaceReclaimed":100}`)
+	})
+	mux.HandleFunc("POST /networks/prune", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"NetworksDeleted":["n"]}`)
+	})
+	mux.HandleFunc("POST /images/prune", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ImagesDeleted":[{"Untagged":"a"},{"Deleted":"sha256:1"}],"SpaceReclaimed":1000}`)
+	})
+	mux.HandleFunc("GET /system/df", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"LayersSize":3000,
+			"Images":[{"Id":"sha256:aaa","RepoTags":["nginx:latest"],"Size":2000,"SharedSize":500,"Containers":1},
+				{"Id":"sha256:bbb","RepoTags":null,"Size":1000,"SharedSize":-1,"Containers":0}],
+			"Volumes":[{"Name":"data","UsageData":{"Size":400,"RefCount":0}},{"Name":"cache","UsageData":{"Size":-1,"RefCount":-1}}],
+			"BuildCache":[{"ID":"c1","Size":50,"InUse":false}]}`)
+	})
+	mux.HandleFunc("DELETE /images/{name}", func(w http.ResponseWriter, r *http.Request) {
+		d.record("rmi " + r.PathValue("name") + " force=" + r.URL.Query().Get("force"))
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("DELETE /volumes/{name}", func(w http.ResponseWriter, r *http.Request) {
+		d.record("rmv " + r.PathValue("name"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /volumes/prune", func(w http.ResponseWriter, r *http.Request) {
+		d.record("prune volumes " + r.URL.Query().Get("filters"))
+		fmt.Fprint(w, `{"VolumesDeleted":["data"],"SpaceReclaimed":400}`)
+	})
+	mux.HandleFunc("POST /containers/{name}/exec", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Cmd  []string
+			User string
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		d.record(fmt.Sprintf("exec %s %q user=%s", r.PathValue("name"), body.Cmd, body.User))
+		fmt.Fprint(w, `{"Id":"e1"}`)
+	})
+	mux.HandleFunc("POST /exec/{id}/start", func(w http.ResponseWriter, r *http.Request) {
+		writeFrame(w, 1, "maintenance mode disabled\n")
+		writeFrame(w, 2, "warning: cache stale\n")
+	})
+	mux.HandleFunc("GET /exec/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ExitCode":3,"Running":false}`)
+	})
+	mux.HandleFunc("GET /events", func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		for _, action := range []string{"start", "die"} {
+			json.NewEncoder(w).Encode(map[string]any{
+				"Type": "container", "Action": action, "timeNano": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano(),
+				"Actor": map[string]any{"ID": "aaa", "Attributes": map[string]string{"name": "web", "exitCode": "137"}},
+			})
+			flusher.Flush()
+		}
+		<-r.Context().Done()
+	})
+	return mux
+}
+
+func writeFrame(w http.ResponseWriter, stream byte, s string) {
+	hdr := make([]byte, 8)
+	hdr[0] = stream
+	binary.BigEndian.PutUint32(hdr[4:], uint32(len(s)))
+	w.Write(hdr)
+	w.Write([]byte(s))
+}
+
+func startFakeDaemon(t *testing.T) (*APIClient, *fakeDaemon) {
+	t.Helper()
+	sock := filepath.Join(t.TempDir(), "docker.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	d := &fakeDaemon{}
+	srv := httptest.NewUnstartedServer(d.handler())
+	srv.Listener = l
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return NewAPIClient(sock), d
+}
+
+func TestAPIListAndInspect(t *testing.T) {
+	c, _ := startFakeDaemon(t)
+	ctx := context.Background()
+
+	list, err := c.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "web" || !list[0].Running() || list[1].Running() {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+	if list[0].Labels["com.docker.compose.project"] != "site" {
+		t.Fatalf("labels not parsed: %+v", list[0].Labels)
+	}
+
+	d, err := c.Inspect(ctx, "web")
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if d.Name != "web" || d.RestartCount != 3 || d.State.Health == nil || d.State.Health.Status != "healthy" ||
+		d.HostConfig.RestartPolicy.Name != "unless-stopped" || len(d.Raw) == 0 {
+		t.Fatalf("unexpected details: %+v", d)
+	}
+	if len(d.Mounts) != 1 || d.Mounts[0].Destination != "/usr/share/nginx/html" || d.Mounts[0].RW ||
+		d.NetworkSettings.Ports["80/tcp"][0].HostPort != "8080" || d.NetworkSettings.Networks["site_default"].IPAddress != "172.18.0.2" ||
+		d.HostConfig.PidsLimit == nil || *d.HostConfig.PidsLimit != 200 {
+		t.Fatalf("unexpected mounts or network settings: %+v", d)
+	}
+
+	_, err = c.Inspect(ctx, "nope")
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.Status != 404 || apiErr.Message != "No such container: nope" {
+		t.Fatalf("expected 404 API error, got %v", err)
+	}
+}
+
+func TestAPIStats(t *testing.T) {
+	c, _ := startFakeDaemon(t)
+
+	stats, err := c.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected only the running container, got %+v", stats)
+	}
+	s := stats[0]
+	// (300-100)/(2000-1000) * 4 CPUs * 100
+	if s.Name != "web" || s.CPUPercent != 80 {
+		t.Fatalf("unexpected CPU: %+v", s)
+	}
+	if s.MemUsage != 1000 || s.MemLimit != 4000 || s.MemPercent != 25 {
+		t.Fatalf("unexpected memory: %+v", s)
+	}
+	if s.NetRx != 11 || s.NetTx != 22 || s.BlockRead != 5 || s.BlockWrite != 7 {
+		t.Fatalf("unexpected I/O: %+v", s)
+	}
+}
+
+func TestAPILogsDemux(t *testing.T) {
+	c, _ := startFakeDaemon(t)
+	out, err := c.Logs(context.Background(), "web", 2)
+	if err != nil || string(out) != "hello\noops\n" {
+		t.Fatalf("Logs = %q, %v", out, err)
+	}
+	if tty := []byte("plain tty output"); string(demuxLogs(tty)) != string(tty) {
+		t.Fatalf("TTY logs must be returned unchanged")
+	}
+}
+
+func TestAPIActions(t *testing.T) {
+	c, d := startFakeDaemon(t)
+	ctx := context.Background()
+
+	for _, f := range []func(context.Context, string) error{c.Start, c.Stop, c.Restart, c.Kill} {
+		if err := f(ctx, "web"); err != nil {
+			t.Fatalf("action failed: %v", err)
+		}
+	}
+	if err := c.Restart(ctx, "gone"); err == nil {
+		t.Fatalf("expected an error for a missing container")
+	}
+	want := []string{"start web", "stop web", "restart web", "kill web", "restart gone"}
+	if fmt.Sprint(d.actions) != fmt.Sprint(want) {
+		t.Fatalf("actions = %v, want %v", d.actions, want)
+	}
+
+	rep, err := c.Prune(ctx)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	// The fake daemon has no /build/prune, which must be tolerated.
+	if rep.ContainersDeleted != 2 || rep.NetworksDeleted != 1 || rep.ImagesDeleted != 1 || rep.SpaceReclaimed != 1100 {
+		t.Fatalf("unexpected prune report: %+v", rep)
+	}
+}
+
+func TestAPIImages(t *testing.T) {
+	c, d := startFakeDaemon(t)
+	ctx := context.Background()
+
+	img, err := c.ImageInspect(ctx, "sha256:old")
+	if err != nil || img.ID != "sha256:old" || len(img.RepoDigests) != 1 || len(img.Raw) == 0 {
+		t.Fatalf("ImageInspect = %+v, %v", img, err)
+	}
+	if err := c.Pull(ctx, "nginx"); err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if err := c.Pull(ctx, "private/app:1.2"); err == nil || err.Error() != "pull access denied" {
+		t.Fatalf("expected the stream error, got %v", err)
+	}
+	if err := c.Tag(ctx, "sha256:old", "nginx:nasbot-rollback"); err != nil {
+		t.Fatalf("Tag: %v", err)
+	}
+	want := []string{"pull nginx:latest", "pull private/app:1.2", "tag sha256:old nginx:nasbot-rollback"}
+	if fmt.Sprint(d.actions) != fmt.Sprint(want) {
+		t.Fatalf("actions = %v, want %v", d.actions, want)
+	}
+}
+
+func TestAPIRecreate(t *testing.T) {
+	c, d := startFakeDaemon(t)
+
+	if err := c.Recreate(context.Background(), "web", "nginx:1.27"); err != nil {
+		t.Fatalf("Recreate: %v", err)
+	}
+	want := []string{"delete web-nasbot-old force=1", "stop aaa", "rename aaa", "create web", "start new", "delete aaa force=1"}
+	if fmt.Sprint(d.actions) != fmt.Sprint(want) {
+		t.Fatalf("actions = %v, want %v", d.actions, want)
+	}
+	if d.created["Image"] != "nginx:1.27" {
+		t.Fatalf("created from %v", d.created["Image"])
+	}
+
+	// No leftover copy to remove, and the replaced container cannot be
+	// removed: the replacement is running, so the update succeeded.
+	d.actions = nil
+	d.deleteStatus = map[string]int{"web-nasbot-old": http.StatusNotFound, "aaa": http.StatusConflict}
+	if err := c.Recreate(context.Background(), "web", "nginx:1.27"); err != nil {
+		t.Fatalf("Recreate with a stuck old container: %v", err)
+	}
+	if n := len(d.actions); n != 6 || d.actions[n-1] != "delete aaa force=1" {
+		t.Fatalf("actions = %v", d.actions)
+	}
+}
+
+func TestAPIEvents(t *testing.T) {
+	c, _ := startFakeDaemon(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, errs := c.Events(ctx)
+	var got []Event
+	for len(got) < 2 {
+		select {
+		case ev := <-events:
+			got = append(got, ev)
+		case err := <-errs:
+			t.Fatalf("stream error: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out, got %+v", got)
+		}
+	}
+	if got[0].Action != "start" || got[1].Action != "die" || got[1].Name != "web" || got[1].Attributes["exitCode"] != "137" {
+		t.Fatalf("unexpected events: %+v", got)
+	}
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+}
+
+func TestAPIUnavailable(t *testing.T) {
+	c := NewAPIClient(filepath.Join(t.TempDir(), "missing.sock"))
+	if err := c.Ping(context.Background()); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+}
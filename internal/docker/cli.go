@@ -0,0 +1,291 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"nasbot/internal/cmdexec"
+)
+
+// CLIClient shells out to the docker binary, or to Binary when set. It is
+// the fallback for hosts where the socket is not reachable (e.g. rootless
+// or remote contexts).
+type CLIClient struct {
+	Binary string
+}
+
+func (c CLIClient) bin() string {
+	if c.Binary == "" {
+		return Docker.Binary
+	}
+	return c.Binary
+}
+
+// run returns stdout, or an error carrying the CLI's own message.
+func (c CLIClient) run(ctx context.Context, args ...string) ([]byte, error) {
+	out, err := cmdexec.CombinedOutput(ctx, c.bin(), args...)
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return out, fmt.Errorf("%s: %w", msg, err)
+		}
+		return out, err
+	}
+	return out, nil
+}
+
+// Ping checks that the CLI can reach a daemon. Local podman has no server
+// version, but `podman info` fails the same way when the engine does.
+func (c CLIClient) Ping(ctx context.Context) error {
+	args := []string{"version", "--format", "{{.Server.Version}}"}
+	if c.bin() == Podman.Binary {
+		args = []string{"info", "--format", "{{.Version.Version}}"}
+	}
+	_, err := c.run(ctx, args...)
+	return err
+}
+
+// cliContainer is one line of `docker ps --format "{{json .}}"`.
+type cliContainer struct {
+	ID     string          `json:"ID"`
+	Names  string          `json:"Names"`
+	Image  string          `json:"Image"`
+	State  string          `json:"State"`
+	Status string          `json:"Status"`
+	Labels json.RawMessage `json:"Labels"` // "k=v,..."; podman prints an object
+}
+
+// listFormat names every field, as `{{json .}}` differs between docker and
+// podman while the field names do not.
+const listFormat = `{"ID":{{json .ID}},"Names":{{json .Names}},"Image":{{json .Image}},"State":{{json .State}},"Status":{{json .Status}},"Labels":{{json .Labels}}}`
+
+// List returns all containers.
+func (c CLIClient) List(ctx context.Context) ([]Container, error) {
+	out, err := cmdexec.Output(ctx, c.bin(), "ps", "-a", "--no-trunc", "--format", listFormat)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListJSON(string(out)), nil
+}
+
+// ParseListJSON parses `docker ps --format "{{json .}}"` output, one object
+// per line. Malformed lines are logged and skipped.
+func ParseListJSON(output string) []Container {
+	var containers []Container
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var raw cliContainer
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			slog.Warn("Failed to unmarshal docker line", "line", line, "err", err)
+			continue
+		}
+		containers = append(containers, Container{
+			ID:     raw.ID,
+			Name:   raw.Names,
+			Image:  raw.Image,
+			State:  strings.ToLower(raw.State),
+			Status: raw.Status,
+			Labels: parseLabelsJSON(raw.Labels),
+		})
+	}
+	return containers
+}
+
+// parseLabelsJSON accepts the labels as the string docker prints or the
+// object podman does.
+func parseLabelsJSON(raw json.RawMessage) map[string]string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return parseLabels(s)
+	}
+	var m map[string]string
+	if json.Unmarshal(raw, &m) == nil && len(m) > 0 {
+		return m
+	}
+	return nil
+}
+
+// parseLabels parses the "k=v,k2=v2" form printed by the CLI.
+func parseLabels(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	labels := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		k, v, _ := strings.Cut(kv, "=")
+		if k = strings.TrimSpace(k); k != "" {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// Inspect returns the details of a container.
+func (c CLIClient) Inspect(ctx context.Context, name string) (*Details, error) {
+	out, err := cmdexec.Output(ctx, c.bin(), "inspect", "--type", "container", name)
+	if err != nil {
+		return nil, err
+	}
+	var docs []json.RawMessage
+	if err := json.Unmarshal(out, &docs); err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no such container: %s", name)
+	}
+	return parseDetails(docs[0])
+}
+
+// cliStats is one line of `docker stats --format "{{json .}}"`.
+type cliStats struct {
+	Name     string `json:"Name"`
+	CPUPerc  string `json:"CPUPerc"`
+	MemUsage string `json:"MemUsage"`
+	MemPerc  string `json:"MemPerc"`
+	NetIO    string `json:"NetIO"`
+	BlockIO  string `json:"BlockIO"`
+}
+
+// statsFormat spells out the fields for the same reason as listFormat.
+const statsFormat = `{"Name":{{json .Name}},"CPUPerc":{{json .CPUPerc}},"MemUsage":{{json .MemUsage}},"MemPerc":{{json .MemPerc}},"NetIO":{{json .NetIO}},"BlockIO":{{json .BlockIO}}}`
+
+// Stats returns a single snapshot.
+func (c CLIClient) Stats(ctx context.Context, names ...string) ([]Stats, error) {
+	args := append([]string{"stats", "--no-stream", "--format", statsFormat}, names...)
+	out, err := cmdexec.Output(ctx, c.bin(), args...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatsJSON(string(out)), nil
+}
+
+// ParseStatsJSON parses `docker stats --format "{{json .}}"` output.
+func ParseStatsJSON(output string) []Stats {
+	var stats []Stats
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var raw cliStats
+		if json.Unmarshal([]byte(strings.TrimSpace(line)), &raw) != nil || raw.Name == "" {
+			continue
+		}
+		s := Stats{Name: raw.Name}
+		s.CPUPercent = parsePercent(raw.CPUPerc)
+		s.MemPercent = parsePercent(raw.MemPerc)
+		s.MemUsage, s.MemLimit = parsePair(raw.MemUsage)
+		s.NetRx, s.NetTx = parsePair(raw.NetIO)
+		s.BlockRead, s.BlockWrite = parsePair(raw.BlockIO)
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+func parsePercent(s string) float64 {
+	v, _ := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	return v
+}
+
+// parsePair parses "12.5MiB / 1.9GiB".
+func parsePair(s string) (uint64, uint64) {
+	a, b, _ := strings.Cut(s, "/")
+	x, _ := ParseSize(a)
+	y, _ := ParseSize(b)
+	return x, y
+}
+
+// Logs returns the last tail lines of stdout and stderr.
+func (c CLIClient) Logs(ctx context.Context, name string, tail int) ([]byte, error) {
+	return cmdexec.CombinedOutput(ctx, c.bin(), "logs", "--tail", strconv.Itoa(tail), name)
+}
+
+// Start starts a container.
+func (c CLIClient) Start(ctx context.Context, name string) error {
+	_, err := c.run(ctx, "start", name)
+	return err
+}
+
+// Stop stops a container.
+func (c CLIClient) Stop(ctx context.Context, name string) error {
+	_, err := c.run(ctx, "stop", name)
+	return err
+}
+
+// Restart restarts a container.
+func (c CLIClient) Restart(ctx context.Context, name string) error {
+	_, err := c.run(ctx, "restart", name)
+	return err
+}
+
+// Kill sends SIGKILL to a container.
+func (c CLIClient) Kill(ctx context.Context, name string) error {
+	_, err := c.run(ctx, "kill", name)
+	return err
+}
+
+// Prune runs `docker system prune -a -f`. Only the reclaimed space can be
+// read back from the CLI output.
+func (c CLIClient) Prune(ctx context.Context) (PruneReport, error) {
+	out, err := c.run(ctx, "system", "prune", "-a", "-f")
+	if err != nil {
+		return PruneReport{}, err
+	}
+	var rep PruneReport
+	for _, line := range strings.Split(string(out), "\n") {
+		if _, size, ok := strings.Cut(line, "Total reclaimed space:"); ok {
+			rep.SpaceReclaimed, _ = ParseSize(size)
+		}
+	}
+	return rep, nil
+}
+
+// Events is not available through the CLI backend; callers poll instead.
+func (c CLIClient) Events(ctx context.Context) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errs := make(chan error, 1)
+	errs <- ErrNotSupported
+	close(events)
+	close(errs)
+	return events, errs
+}
+
+// ImageInspect returns the local image ref resolves to.
+func (c CLIClient) ImageInspect(ctx context.Context, ref string) (*Image, error) {
+	out, err := cmdexec.Output(ctx, c.bin(), "image", "inspect", ref)
+	if err != nil {
+		return nil, err
+	}
+	var docs []json.RawMessage
+	if err := json.Unmarshal(out, &docs); err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no such image: %s", ref)
+	}
+	img := &Image{}
+	if err := json.Unmarshal(docs[0], img); err != nil {
+		return nil, err
+	}
+	img.Raw = docs[0]
+	return img, nil
+}
+
+// Pull pulls ref.
+func (c CLIClient) Pull(ctx context.Context, ref string) error {
+	_, err := c.run(ctx, "pull", ref)
+	return err
+}
+
+// Tag tags the image source as target.
+func (c CLIClient) Tag(ctx context.Context, source, target string) error {
+	_, err := c.run(ctx, "tag", source, target)
+	return err
+}
+
+// Recreate needs the full create API and is not offered by the CLI backend.
+func (c CLIClient) Recreate(ctx context.Context, name, image string) error {
+	return ErrNotSupported
+}
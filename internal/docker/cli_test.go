@@ -0,0 +1,186 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nasbot/internal/cmdexec"
+)
+
+// scriptRunner answers docker CLI invocations by their first argument.
+type scriptRunner struct {
+	out   map[string]string
+	calls []string
+}
+
+func (r *scriptRunner) Exists(string) bool { return true }
+
+func (r *scriptRunner) CombinedOutput(_ context.Context, name string, args ...string) ([]byte, error) {
+	r.calls = append(r.calls, name+" "+strings.Join(args, " "))
+	out, ok := r.out[args[0]]
+	if !ok {
+		return []byte("Error: unknown"), errors.New("exit status 1")
+	}
+	return []byte(out), nil
+}
+
+func (r *scriptRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return r.CombinedOutput(ctx, name, args...)
+}
+
+func (r *scriptRunner) Run(ctx context.Context, name string, args ...string) error {
+	_, err := r.CombinedOutput(ctx, name, args...)
+	return err
+}
+
+func TestCLIClient(t *testing.T) {
+	r := &scriptRunner{out: map[string]string{
+		"ps":      `{"ID":"aaa","Names":"web","Image":"nginx","State":"running","Status":"Up","Labels":"a=1,b=x=y"}` + "\n",
+		"stats":   `{"Name":"web","CPUPerc":"1.50%","MemUsage":"512MiB / 2GiB","MemPerc":"25.00%","NetIO":"1kB / 2MB","BlockIO":"0B / 1.5GB"}` + "\n",
+		"inspect": `[{"Id":"aaa","Name":"/web","RestartCount":1}]`,
+		"system":  "Deleted Images:\nuntagged: x\n\nTotal reclaimed space: 1.5GB\n",
+		"restart": "web\n",
+	}}
+	defer cmdexec.SetRunner(r)()
+	c := CLIClient{}
+	ctx := context.Background()
+
+	list, err := c.List(ctx)
+	if err != nil || len(list) != 1 || !list[0].Running() || list[0].Labels["b"] != "x=y" {
+		t.Fatalf("List = %+v, %v", list, err)
+	}
+	stats, err := c.Stats(ctx)
+	if err != nil || len(stats) != 1 {
+		t.Fatalf("Stats = %+v, %v", stats, err)
+	}
+	s := stats[0]
+	if s.CPUPercent != 1.5 || s.MemUsage != 512<<20 || s.MemLimit != 2<<30 || s.NetTx != 2e6 || s.BlockWrite != 1.5e9 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+	d, err := c.Inspect(ctx, "web")
+	if err != nil || d.Name != "web" || d.RestartCount != 1 {
+		t.Fatalf("Inspect = %+v, %v", d, err)
+	}
+	rep, err := c.Prune(ctx)
+	if err != nil || rep.SpaceReclaimed != 1.5e9 {
+		t.Fatalf("Prune = %+v, %v", rep, err)
+	}
+	if err := c.Restart(ctx, "web"); err != nil {
+		t.Fatalf("Restart: %v", err)
+	}
+	if err := c.Kill(ctx, "web"); err == nil || !strings.Contains(err.Error(), "Error: unknown") {
+		t.Fatalf("expected the CLI message in the error, got %v", err)
+	}
+}
+
+func TestFallbackToCLI(t *testing.T) {
+	r := &scriptRunner{out: map[string]string{"ps": `{"Names":"web","State":"running"}`}}
+	defer cmdexec.SetRunner(r)()
+
+	c := New(Docker, filepath.Join(t.TempDir(), "missing.sock"))
+	list, err := c.List(context.Background())
+	if err != nil || len(list) != 1 || list[0].Name != "web" {
+		t.Fatalf("List = %+v, %v", list, err)
+	}
+	_, errs := c.Events(context.Background())
+	if err := <-errs; !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("expected CLI events to be unsupported, got %v", err)
+	}
+}
+
+func TestPodmanCLI(t *testing.T) {
+	r := &scriptRunner{out: map[string]string{
+		"ps":   `{"ID":"aaa","Names":"web","Image":"docker.io/library/nginx:latest","State":"running","Status":"Up 2 hours","Labels":{"com.docker.compose.project":"site"}}` + "\n",
+		"info": "4.9.3\n",
+	}}
+	defer cmdexec.SetRunner(r)()
+	ctx := context.Background()
+
+	c := New(Podman, filepath.Join(t.TempDir(), "missing.sock"))
+	list, err := c.List(ctx)
+	if err != nil || len(list) != 1 || !list[0].Running() || list[0].Labels["com.docker.compose.project"] != "site" {
+		t.Fatalf("List = %+v, %v", list, err)
+	}
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if _, err := c.DiskUsage(ctx); !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("expected podman df to be unsupported, got %v", err)
+	}
+	for _, call := range r.calls {
+		if !strings.HasPrefix(call, "podman ") {
+			t.Fatalf("ran %q", call)
+		}
+	}
+}
+
+func TestRuntimeByName(t *testing.T) {
+	if rt, ok := RuntimeByName(" Podman "); !ok || rt.Binary != "podman" || rt.Socket != "/run/podman/podman.sock" {
+		t.Fatalf("RuntimeByName(podman) = %+v, %v", rt, ok)
+	}
+	if _, ok := RuntimeByName("containerd"); ok {
+		t.Fatalf("containerd is not a runtime")
+	}
+	if !Rootless("/run/user/1000/podman/podman.sock") || Rootless(DefaultSocket) {
+		t.Fatalf("Rootless misdetects sockets")
+	}
+}
+
+func TestFallbackKeepsAPIErrors(t *testing.T) {
+	api, _ := startFakeDaemon(t)
+	r := &scriptRunner{}
+	defer cmdexec.SetRunner(r)()
+
+	c := &fallbackClient{primary: api, secondary: CLIClient{}}
+	if err := c.Restart(context.Background(), "gone"); err == nil {
+		t.Fatalf("expected the API error")
+	}
+	if len(r.calls) != 0 {
+		t.Fatalf("CLI must not be used when the daemon answered: %v", r.calls)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]uint64{
+		"0B":      0,
+		"512MiB":  512 << 20,
+		"1.5GiB":  3 << 29,
+		"2kB":     2000,
+		" 1.9GB ": 1.9e9,
+	}
+	for in, want := range cases {
+		if got, ok := ParseSize(in); !ok || got != want {
+			t.Errorf("ParseSize(%q) = %v, %v; want %v", in, got, ok, want)
+		}
+	}
+	for _, bad := range []string{"", "n/a", "12XB"} {
+		if _, ok := ParseSize(bad); ok {
+			t.Errorf("ParseSize(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for in, want := range map[uint64]string{0: "0B", 512 << 20: "512MiB", 3 << 29: "1.5GiB", 1500: "1.46KiB"} {
+		if got := FormatSize(in); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestContainerHealthStatus(t *testing.T) {
+	for status, want := range map[string]string{
+		"Up 2 hours (healthy)":            "healthy",
+		"Up 3 minutes (unhealthy)":        "unhealthy",
+		"Up 5 seconds (health: starting)": "starting",
+		"Up 2 hours":                      "",
+		"Exited (1) 2 minutes ago":        "",
+	} {
+		if got := (Container{Status: status}).HealthStatus(); got != want {
+			t.Errorf("HealthStatus(%q) = %q, want %q", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"nasbot/internal/cmdexec"
+)
+
+// DiskUsage is what `docker system df -v` reports.
+type DiskUsage struct {
+	// LayersSize is the space all image layers take, shared ones counted
+	// once; zero when the backend does not report it.
+	LayersSize uint64
+	Images     []ImageUsage
+	Volumes    []VolumeUsage
+	BuildCache []BuildCacheUsage
+}
+
+// ImageUsage is one local image.
+type ImageUsage struct {
+	ID         string
+	Tags       []string // "repo:tag"; empty for dangling images
+	Size       uint64
+	SharedSize uint64 // layers shared with other images
+	Containers int    // containers using the image, -1 when unknown
+}
+
+// Dangling reports whether the image has no tag left.
+func (i ImageUsage) Dangling() bool {
+	for _, t := range i.Tags {
+		if t != "<none>:<none>" {
+			return false
+		}
+	}
+	return true
+}
+
+// Unused reports whether no container, running or stopped, uses the image.
+func (i ImageUsage) Unused() bool { return i.Containers == 0 }
+
+// VolumeUsage is one volume.
+type VolumeUsage struct {
+	Name     string
+	Size     uint64
+	RefCount int // containers mounting it, -1 when unknown
+}
+
+// Orphan reports whether no container mounts the volume.
+func (v VolumeUsage) Orphan() bool { return v.RefCount == 0 }
+
+// BuildCacheUsage is one build cache record.
+type BuildCacheUsage struct {
+	ID    string
+	Size  uint64
+	InUse bool
+}
+
+// ImagesSize is the space taken by images: LayersSize when known, else
+// the sum of image sizes, which counts shared layers more than once.
+func (d *DiskUsage) ImagesSize() uint64 {
+	if d.LayersSize > 0 {
+		return d.LayersSize
+	}
+	var n uint64
+	for _, img := range d.Images {
+		n += img.Size
+	}
+	return n
+}
+
+type apiDiskUsage struct {
+	LayersSize int64 `json:"LayersSize"`
+	Images     []struct {
+		ID         string   `json:"Id"`
+		RepoTags   []string `json:"RepoTags"`
+		Size       int64    `json:"Size"`
+		SharedSize int64    `json:"SharedSize"`
+		Containers int      `json:"Containers"`
+	} `json:"Images"`
+	Volumes []struct {
+		Name      string `json:"Name"`
+		UsageData struct {
+			Size     int64 `json:"Size"`
+			RefCount int   `json:"RefCount"`
+		} `json:"UsageData"`
+	} `json:"Volumes"`
+	BuildCache []struct {
+		ID    string `json:"ID"`
+		Size  int64  `json:"Size"`
+		InUse bool   `json:"InUse"`
+	} `json:"BuildCache"`
+}
+
+// The API reports -1 for sizes it did not compute.
+func nonNegative(n int64) uint64 {
+	if n < 0 {
+		return 0
+	}
+	return uint64(n)
+}
+
+// DiskUsage returns images, volumes and build cache with their sizes.
+func (c *APIClient) DiskUsage(ctx context.Context) (*DiskUsage, error) {
+	var raw apiDiskUsage
+	if err := c.getJSON(ctx, "/system/df", nil, &raw); err != nil {
+		return nil, err
+	}
+	du := &DiskUsage{LayersSize: nonNegative(raw.LayersSize)}
+	for _, img := range raw.Images {
+		du.Images = append(du.Images, ImageUsage{
+			ID:         img.ID,
+			Tags:       img.RepoTags,
+			Size:       nonNegative(img.Size),
+			SharedSize: nonNegative(img.SharedSize),
+			Containers: img.Containers,
+		})
+	}
+	for _, v := range raw.Volumes {
+		du.Volumes = append(du.Volumes, VolumeUsage{Name: v.Name, Size: nonNegative(v.UsageData.Size), RefCount: v.UsageData.RefCount})
+	}
+	for _, b := range raw.BuildCache {
+		du.BuildCache = append(du.BuildCache, BuildCacheUsage{ID: b.ID, Size: nonNegative(b.Size), InUse: b.InUse})
+	}
+	return du, nil
+}
+
+// RemoveImage deletes an image by ID or reference; tags of an ID are
+// removed along with it.
+func (c *APIClient) RemoveImage(ctx context.Context, id string) error {
+	resp, err := c.do(ctx, http.MethodDelete, "/images/"+url.PathEscape(id), url.Values{"force": {"1"}})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// RemoveVolume deletes an unused volume.
+func (c *APIClient) RemoveVolume(ctx context.Context, name string) error {
+	resp, err := c.do(ctx, http.MethodDelete, "/volumes/"+url.PathEscape(name), nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PruneImages removes dangling images, or every image without a container
+// when all is set, and returns the space reclaimed.
+func (c *APIClient) PruneImages(ctx context.Context, all bool) (uint64, error) {
+	var out struct {
+		SpaceReclaimed uint64 `json:"SpaceReclaimed"`
+	}
+	err := c.post(ctx, "/images/prune", url.Values{"filters": {`{"dangling":["` + strconv.FormatBool(!all) + `"]}`}}, &out)
+	return out.SpaceReclaimed, err
+}
+
+// PruneVolumes removes every volume no container mounts, named ones
+// included.
+func (c *APIClient) PruneVolumes(ctx context.Context) (uint64, error) {
+	var out struct {
+		SpaceReclaimed uint64 `json:"SpaceReclaimed"`
+	}
+	// Since API 1.42 only anonymous volumes are pruned unless all is set;
+	// older daemons reject the filter and prune named ones anyway.
+	err := c.post(ctx, "/volumes/prune", url.Values{"filters": {`{"all":["true"]}`}}, &out)
+	if err != nil && !errors.Is(err, ErrUnavailable) {
+		err = c.post(ctx, "/volumes/prune", nil, &out)
+	}
+	return out.SpaceReclaimed, err
+}
+
+// PruneBuildCache removes the whole build cache.
+func (c *APIClient) PruneBuildCache(ctx context.Context) (uint64, error) {
+	var out struct {
+		SpaceReclaimed uint64 `json:"SpaceReclaimed"`
+	}
+	err := c.post(ctx, "/build/prune", url.Values{"all": {"1"}}, &out)
+	return out.SpaceReclaimed, err
+}
+
+// cliDiskUsage is `docker system df -v --format "{{json .}}"`, where every
+// value is a string.
+type cliDiskUsage struct {
+	Images []struct {
+		ID         string `json:"ID"`
+		Repository string `json:"Repository"`
+		Tag        string `json:"Tag"`
+		Size       string `json:"Size"`
+		SharedSize string `json:"SharedSize"`
+		Containers string `json:"Containers"`
+	} `json:"Images"`
+	Volumes []struct {
+		Name  string `json:"Name"`
+		Size  string `json:"Size"`
+		Links string `json:"Links"`
+	} `json:"Volumes"`
+	BuildCache []struct {
+		ID    string `json:"ID"`
+		Size  string `json:"Size"`
+		InUse string `json:"InUse"`
+	} `json:"BuildCache"`
+}
+
+func cliCount(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func cliSize(s string) uint64 {
+	n, _ := ParseSize(s)
+	return n
+}
+
+// DiskUsage parses `docker system df -v`. The CLI lists one row per tag,
+// merged here by image ID. Podman's verbose df has no JSON form; its API
+// serves the breakdown instead.
+func (c CLIClient) DiskUsage(ctx context.Context) (*DiskUsage, error) {
+	if c.bin() == Podman.Binary {
+		return nil, ErrNotSupported
+	}
+	out, err := cmdexec.Output(ctx, c.bin(), "system", "df", "-v", "--format", "{{json .}}")
+	if err != nil {
+		return nil, err
+	}
+	var raw cliDiskUsage
+	if err := json.Unmarshal(out, &raw); err != nil {
+		return nil, err
+	}
+	du := &DiskUsage{}
+	index := make(map[string]int)
+	for _, img := range raw.Images {
+		i, ok := index[img.ID]
+		if !ok {
+			i = len(du.Images)
+			index[img.ID] = i
+			du.Images = append(du.Images, ImageUsage{
+				ID:         img.ID,
+				Size:       cliSize(img.Size),
+				SharedSize: cliSize(img.SharedSize),
+				Containers: cliCount(img.Containers),
+			})
+		}
+		if img.Repository != "<none>" {
+			du.Images[i].Tags = append(du.Images[i].Tags, img.Repository+":"+img.Tag)
+		}
+	}
+	for _, v := range raw.Volumes {
+		du.Volumes = append(du.Volumes, VolumeUsage{Name: v.Name, Size: cliSize(v.Size), RefCount: cliCount(v.Links)})
+	}
+	for _, b := range raw.BuildCache {
+		du.BuildCache = append(du.BuildCache, BuildCacheUsage{ID: b.ID, Size: cliSize(b.Size), InUse: b.InUse == "true"})
+	}
+	return du, nil
+}
+
+// RemoveImage deletes an image by ID or reference.
+func (c CLIClient) RemoveImage(ctx context.Context, id string) error {
+	_, err := c.run(ctx, "image", "rm", "-f", id)
+	return err
+}
+
+// RemoveVolume deletes an unused volume.
+func (c CLIClient) RemoveVolume(ctx context.Context, name string) error {
+	_, err := c.run(ctx, "volume", "rm", name)
+	return err
+}
+
+// PruneImages removes dangling images, or all unused ones when all is set.
+func (c CLIClient) PruneImages(ctx context.Context, all bool) (uint64, error) {
+	args := []string{"image", "prune", "-f"}
+	if all {
+		args = append(args, "-a")
+	}
+	return c.prune(ctx, args...)
+}
+
+// PruneVolumes removes every volume no container mounts.
+func (c CLIClient) PruneVolumes(ctx context.Context) (uint64, error) {
+	n, err := c.prune(ctx, "volume", "prune", "-f", "--all")
+	if err != nil {
+		// CLIs before 23.0 have no --all and prune named volumes anyway.
+		return c.prune(ctx, "volume", "prune", "-f")
+	}
+	return n, nil
+}
+
+// PruneBuildCache removes the whole build cache.
+func (c CLIClient) PruneBuildCache(ctx context.Context) (uint64, error) {
+	return c.prune(ctx, "builder", "prune", "-a", "-f")
+}
+
+// prune runs a prune command and parses its "Total reclaimed space" line.
+func (c CLIClient) prune(ctx context.Context, args ...string) (uint64, error) {
+	out, err := c.run(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if _, size, ok := strings.Cut(line, "Total reclaimed space:"); ok {
+			n, _ := ParseSize(size)
+			return n, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,82 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"nasbot/internal/cmdexec"
+)
+
+func TestAPIDiskUsage(t *testing.T) {
+	c, d := startFakeDaemon(t)
+	ctx := context.Background()
+
+	du, err := c.DiskUsage(ctx)
+	if err != nil {
+		t.Fatalf("DiskUsage: %v", err)
+	}
+	if du.ImagesSize() != 3000 || len(du.Images) != 2 || len(du.Volumes) != 2 || len(du.BuildCache) != 1 {
+		t.Fatalf("unexpected usage: %+v", du)
+	}
+	if nginx, bare := du.Images[0], du.Images[1]; nginx.Dangling() || nginx.Unused() || !bare.Dangling() || !bare.Unused() || bare.SharedSize != 0 {
+		t.Fatalf("unexpected images: %+v", du.Images)
+	}
+	// An unknown reference count is not an orphan.
+	if data, cache := du.Volumes[0], du.Volumes[1]; !data.Orphan() || cache.Orphan() || cache.Size != 0 {
+		t.Fatalf("unexpected volumes: %+v", du.Volumes)
+	}
+
+	if err := c.RemoveImage(ctx, "sha256:bbb"); err != nil {
+		t.Fatalf("RemoveImage: %v", err)
+	}
+	if err := c.RemoveVolume(ctx, "data"); err != nil {
+		t.Fatalf("RemoveVolume: %v", err)
+	}
+	if n, err := c.PruneVolumes(ctx); err != nil || n != 400 {
+		t.Fatalf("PruneVolumes = %d, %v", n, err)
+	}
+	if n, err := c.PruneImages(ctx, true); err != nil || n != 1000 {
+		t.Fatalf("PruneImages = %d, %v", n, err)
+	}
+	want := []string{"rmi sha256:bbb force=1", "rmv data", `prune volumes {"all":["true"]}`}
+	if fmt.Sprint(d.actions) != fmt.Sprint(want) {
+		t.Fatalf("actions = %v, want %v", d.actions, want)
+	}
+}
+
+func TestCLIDiskUsage(t *testing.T) {
+	r := &scriptRunner{out: map[string]string{
+		"system": `{"Images":[{"ID":"sha256:aaa","Repository":"nginx","Tag":"latest","Size":"2kB","SharedSize":"0B","Containers":"1"},` +
+			`{"ID":"sha256:aaa","Repository":"nginx","Tag":"1.27","Size":"2kB","SharedSize":"0B","Containers":"1"},` +
+			`{"ID":"sha256:bbb","Repository":"<none>","Tag":"<none>","Size":"1kB","SharedSize":"0B","Containers":"0"}],` +
+			`"Volumes":[{"Name":"data","Size":"400B","Links":"0"}],` +
+			`"BuildCache":[{"ID":"c1","Size":"50B","InUse":"true"}]}`,
+		"builder": "Total:\t1.2GB\n",
+		"image":   "Deleted Images:\ndeleted: sha256:bbb\n\nTotal reclaimed space: 1kB\n",
+	}}
+	defer cmdexec.SetRunner(r)()
+	c := CLIClient{}
+	ctx := context.Background()
+
+	du, err := c.DiskUsage(ctx)
+	if err != nil {
+		t.Fatalf("DiskUsage: %v", err)
+	}
+	if len(du.Images) != 2 || len(du.Images[0].Tags) != 2 || !du.Images[1].Dangling() || !du.Images[1].Unused() || du.ImagesSize() != 3000 {
+		t.Fatalf("unexpected images: %+v", du.Images)
+	}
+	if len(du.Volumes) != 1 || !du.Volumes[0].Orphan() || du.Volumes[0].Size != 400 {
+		t.Fatalf("unexpected volumes: %+v", du.Volumes)
+	}
+	if len(du.BuildCache) != 1 || !du.BuildCache[0].InUse {
+		t.Fatalf("unexpected build cache: %+v", du.BuildCache)
+	}
+	if n, err := c.PruneImages(ctx, true); err != nil || n != 1000 {
+		t.Fatalf("PruneImages = %d, %v", n, err)
+	}
+	// Without a "Total reclaimed space" line nothing is reported.
+	if n, err := c.PruneBuildCache(ctx); err != nil || n != 0 {
+		t.Fatalf("PruneBuildCache = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,403 @@
+// Package docker talks to the Docker daemon, or Podman's compatible
+// service, natively over its unix socket when reachable and through the
+// CLI otherwise.
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSocket is the standard Docker Engine API socket.
+const DefaultSocket = "/var/run/docker.sock"
+
+var (
+	// ErrUnavailable reports that the daemon could not be reached at all
+	// (missing socket, permission denied, ...), as opposed to an API error.
+	ErrUnavailable = errors.New("docker: daemon unavailable")
+	// ErrNotSupported is returned by backends that cannot perform a call.
+	ErrNotSupported = errors.New("docker: not supported by this backend")
+)
+
+// Container is one entry of the container list.
+type Container struct {
+	ID     string
+	Name   string
+	Image  string
+	State  string // running, exited, restarting, ...
+	Status string // human readable, e.g. "Up 2 hours (healthy)"
+	Labels map[string]string
+}
+
+// Running reports whether the container is running.
+func (c Container) Running() bool {
+	return strings.EqualFold(c.State, "running")
+}
+
+// HealthStatus extracts the healthcheck state from Status: "starting",
+// "healthy", "unhealthy", or "" when the container has no healthcheck.
+func (c Container) HealthStatus() string {
+	switch {
+	case strings.Contains(c.Status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.Contains(c.Status, "(healthy)"):
+		return "healthy"
+	case strings.Contains(c.Status, "(health: starting)"):
+		return "starting"
+	}
+	return ""
+}
+
+// Stats is a resource usage snapshot of a running container.
+type Stats struct {
+	Name       string
+	CPUPercent float64
+	MemUsage   uint64 // bytes
+	MemLimit   uint64 // bytes
+	MemPercent float64
+	NetRx      uint64 // bytes
+	NetTx      uint64 // bytes
+	BlockRead  uint64 // bytes
+	BlockWrite uint64 // bytes
+}
+
+// Health is the healthcheck state of a container.
+type Health struct {
+	Status        string        `json:"Status"` // starting, healthy, unhealthy
+	FailingStreak int           `json:"FailingStreak"`
+	Log           []HealthProbe `json:"Log"`
+}
+
+// HealthProbe is one healthcheck run.
+type HealthProbe struct {
+	Start    time.Time `json:"Start"`
+	End      time.Time `json:"End"`
+	ExitCode int       `json:"ExitCode"`
+	Output   string    `json:"Output"`
+}
+
+// LastOutput returns the output of the most recent probe.
+func (h *Health) LastOutput() string {
+	if h == nil || len(h.Log) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(h.Log[len(h.Log)-1].Output)
+}
+
+// Details is the subset of `docker inspect` the bot uses. Raw keeps the full
+// document.
+type Details struct {
+	ID      string    `json:"Id"`
+	Name    string    `json:"Name"`
+	Image   string    `json:"Image"`
+	Created time.Time `json:"Created"`
+	State   struct {
+		Status     string    `json:"Status"`
+		Running    bool      `json:"Running"`
+		Restarting bool      `json:"Restarting"`
+		OOMKilled  bool      `json:"OOMKilled"`
+		ExitCode   int       `json:"ExitCode"`
+		StartedAt  time.Time `json:"StartedAt"`
+		FinishedAt time.Time `json:"FinishedAt"`
+		Health     *Health   `json:"Health"`
+	} `json:"State"`
+	RestartCount int    `json:"RestartCount"`
+	LogPath      string `json:"LogPath"`
+	Config       struct {
+		Image  string            `json:"Image"`
+		Env    []string          `json:"Env"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	HostConfig struct {
+		RestartPolicy struct {
+			Name              string `json:"Name"`
+			MaximumRetryCount int    `json:"MaximumRetryCount"`
+		} `json:"RestartPolicy"`
+		LogConfig struct {
+			Type string `json:"Type"`
+		} `json:"LogConfig"`
+		NetworkMode       string `json:"NetworkMode"`
+		Memory            int64  `json:"Memory"`
+		MemoryReservation int64  `json:"MemoryReservation"`
+		NanoCpus          int64  `json:"NanoCpus"`
+		CPUShares         int64  `json:"CpuShares"`
+		CpusetCpus        string `json:"CpusetCpus"`
+		PidsLimit         *int64 `json:"PidsLimit"`
+	} `json:"HostConfig"`
+	Mounts          []Mount `json:"Mounts"`
+	NetworkSettings struct {
+		Ports    map[string][]PortBinding `json:"Ports"` // "80/tcp"; nil when not published
+		Networks map[string]Endpoint      `json:"Networks"`
+	} `json:"NetworkSettings"`
+
+	Raw []byte `json:"-"`
+}
+
+// Mount is a volume or bind mount of a container.
+type Mount struct {
+	Type        string `json:"Type"`
+	Name        string `json:"Name"`
+	Source      string `json:"Source"`
+	Destination string `json:"Destination"`
+	RW          bool   `json:"RW"`
+}
+
+// PortBinding is a host address a container port is published on.
+type PortBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+// Endpoint is a container's attachment to a network.
+type Endpoint struct {
+	IPAddress string `json:"IPAddress"`
+}
+
+// Image is the subset of `docker image inspect` used for update checks.
+type Image struct {
+	ID          string   `json:"Id"`
+	RepoTags    []string `json:"RepoTags"`
+	RepoDigests []string `json:"RepoDigests"` // "repo@sha256:..."
+
+	Raw []byte `json:"-"`
+}
+
+// Event is a daemon event, e.g. a container dying or being started.
+type Event struct {
+	Type       string
+	Action     string
+	ID         string
+	Name       string
+	Attributes map[string]string
+	Time       time.Time
+}
+
+// PruneReport summarizes a system prune.
+type PruneReport struct {
+	ContainersDeleted int
+	ImagesDeleted     int
+	NetworksDeleted   int
+	SpaceReclaimed    uint64 // bytes
+}
+
+// Client is implemented by the Engine API and CLI backends.
+type Client interface {
+	Ping(ctx context.Context) error
+	// List returns all containers, stopped ones included.
+	List(ctx context.Context) ([]Container, error)
+	Inspect(ctx context.Context, name string) (*Details, error)
+	// Stats returns usage of the named containers, or of every running
+	// container when no name is given.
+	Stats(ctx context.Context, names ...string) ([]Stats, error)
+	Logs(ctx context.Context, name string, tail int) ([]byte, error)
+	Start(ctx context.Context, name string) error
+	Stop(ctx context.Context, name string) error
+	Restart(ctx context.Context, name string) error
+	Kill(ctx context.Context, name string) error
+	// Prune removes stopped containers, unused networks, unused images and
+	// the build cache, like `docker system prune -a -f`.
+	Prune(ctx context.Context) (PruneReport, error)
+	// Events streams container events until ctx is done. The error channel
+	// receives at most one value, after which both channels are closed.
+	Events(ctx context.Context) (<-chan Event, <-chan error)
+	// ImageInspect returns the local image ref (a name or an ID) resolves to.
+	ImageInspect(ctx context.Context, ref string) (*Image, error)
+	// Pull fetches ref from its registry; a ref without tag means :latest.
+	Pull(ctx context.Context, ref string) error
+	// Tag adds the target reference to the image source resolves to.
+	Tag(ctx context.Context, source, target string) error
+	// Recreate replaces the container with a new one running image, keeping
+	// its name, configuration, networks and volumes.
+	Recreate(ctx context.Context, name, image string) error
+	DiskUsage(ctx context.Context) (*DiskUsage, error)
+	RemoveImage(ctx context.Context, id string) error
+	RemoveVolume(ctx context.Context, name string) error
+	// PruneImages removes dangling images, or every image no container
+	// uses when all is set. Prune calls return the space reclaimed.
+	PruneImages(ctx context.Context, all bool) (uint64, error)
+	PruneVolumes(ctx context.Context) (uint64, error)
+	PruneBuildCache(ctx context.Context) (uint64, error)
+	// Exec runs cmd inside the running container, as user when set. A
+	// non-zero exit code is reported in the result, not as an error.
+	Exec(ctx context.Context, name string, cmd []string, user string) (ExecResult, error)
+}
+
+// New returns a client using the Engine API of rt on socket, falling back
+// to its CLI whenever the socket cannot be reached.
+func New(rt Runtime, socket string) Client {
+	if socket == "" {
+		socket = rt.Socket
+	}
+	return &fallbackClient{primary: NewAPIClient(socket), secondary: CLIClient{Binary: rt.Binary}}
+}
+
+// fallbackClient retries a call on secondary when primary is unavailable.
+type fallbackClient struct {
+	primary   Client
+	secondary Client
+}
+
+func fallback[T any](primary, secondary func() (T, error)) (T, error) {
+	v, err := primary()
+	if errors.Is(err, ErrUnavailable) {
+		return secondary()
+	}
+	return v, err
+}
+
+func fallbackErr(primary, secondary func() error) error {
+	if err := primary(); !errors.Is(err, ErrUnavailable) {
+		return err
+	}
+	return secondary()
+}
+
+func (f *fallbackClient) Ping(ctx context.Context) error {
+	return fallbackErr(func() error { return f.primary.Ping(ctx) }, func() error { return f.secondary.Ping(ctx) })
+}
+
+func (f *fallbackClient) List(ctx context.Context) ([]Container, error) {
+	return fallback(func() ([]Container, error) { return f.primary.List(ctx) }, func() ([]Container, error) { return f.secondary.List(ctx) })
+}
+
+func (f *fallbackClient) Inspect(ctx context.Context, name string) (*Details, error) {
+	return fallback(func() (*Details, error) { return f.primary.Inspect(ctx, name) }, func() (*Details, error) { return f.secondary.Inspect(ctx, name) })
+}
+
+func (f *fallbackClient) Stats(ctx context.Context, names ...string) ([]Stats, error) {
+	return fallback(func() ([]Stats, error) { return f.primary.Stats(ctx, names...) }, func() ([]Stats, error) { return f.secondary.Stats(ctx, names...) })
+}
+
+func (f *fallbackClient) Logs(ctx context.Context, name string, tail int) ([]byte, error) {
+	return fallback(func() ([]byte, error) { return f.primary.Logs(ctx, name, tail) }, func() ([]byte, error) { return f.secondary.Logs(ctx, name, tail) })
+}
+
+func (f *fallbackClient) Start(ctx context.Context, name string) error {
+	return fallbackErr(func() error { return f.primary.Start(ctx, name) }, func() error { return f.secondary.Start(ctx, name) })
+}
+
+func (f *fallbackClient) Stop(ctx context.Context, name string) error {
+	return fallbackErr(func() error { return f.primary.Stop(ctx, name) }, func() error { return f.secondary.Stop(ctx, name) })
+}
+
+func (f *fallbackClient) Restart(ctx context.Context, name string) error {
+	return fallbackErr(func() error { return f.primary.Restart(ctx, name) }, func() error { return f.secondary.Restart(ctx, name) })
+}
+
+func (f *fallbackClient) Kill(ctx context.Context, name string) error {
+	return fallbackErr(func() error { return f.primary.Kill(ctx, name) }, func() error { return f.secondary.Kill(ctx, name) })
+}
+
+func (f *fallbackClient) Prune(ctx context.Context) (PruneReport, error) {
+	return fallback(func() (PruneReport, error) { return f.primary.Prune(ctx) }, func() (PruneReport, error) { return f.secondary.Prune(ctx) })
+}
+
+// Events is decided once up front: a stream cannot be retried transparently.
+func (f *fallbackClient) Events(ctx context.Context) (<-chan Event, <-chan error) {
+	if errors.Is(f.primary.Ping(ctx), ErrUnavailable) {
+		return f.secondary.Events(ctx)
+	}
+	return f.primary.Events(ctx)
+}
+
+func (f *fallbackClient) ImageInspect(ctx context.Context, ref string) (*Image, error) {
+	return fallback(func() (*Image, error) { return f.primary.ImageInspect(ctx, ref) }, func() (*Image, error) { return f.secondary.ImageInspect(ctx, ref) })
+}
+
+func (f *fallbackClient) Pull(ctx context.Context, ref string) error {
+	return fallbackErr(func() error { return f.primary.Pull(ctx, ref) }, func() error { return f.secondary.Pull(ctx, ref) })
+}
+
+func (f *fallbackClient) Tag(ctx context.Context, source, target string) error {
+	return fallbackErr(func() error { return f.primary.Tag(ctx, source, target) }, func() error { return f.secondary.Tag(ctx, source, target) })
+}
+
+func (f *fallbackClient) Recreate(ctx context.Context, name, image string) error {
+	return fallbackErr(func() error { return f.primary.Recreate(ctx, name, image) }, func() error { return f.secondary.Recreate(ctx, name, image) })
+}
+
+func (f *fallbackClient) DiskUsage(ctx context.Context) (*DiskUsage, error) {
+	return fallback(func() (*DiskUsage, error) { return f.primary.DiskUsage(ctx) }, func() (*DiskUsage, error) { return f.secondary.DiskUsage(ctx) })
+}
+
+func (f *fallbackClient) RemoveImage(ctx context.Context, id string) error {
+	return fallbackErr(func() error { return f.primary.RemoveImage(ctx, id) }, func() error { return f.secondary.RemoveImage(ctx, id) })
+}
+
+func (f *fallbackClient) RemoveVolume(ctx context.Context, name string) error {
+	return fallbackErr(func() error { return f.primary.RemoveVolume(ctx, name) }, func() error { return f.secondary.RemoveVolume(ctx, name) })
+}
+
+func (f *fallbackClient) PruneImages(ctx context.Context, all bool) (uint64, error) {
+	return fallback(func() (uint64, error) { return f.primary.PruneImages(ctx, all) }, func() (uint64, error) { return f.secondary.PruneImages(ctx, all) })
+}
+
+func (f *fallbackClient) PruneVolumes(ctx context.Context) (uint64, error) {
+	return fallback(func() (uint64, error) { return f.primary.PruneVolumes(ctx) }, func() (uint64, error) { return f.secondary.PruneVolumes(ctx) })
+}
+
+func (f *fallbackClient) PruneBuildCache(ctx context.Context) (uint64, error) {
+	return fallback(func() (uint64, error) { return f.primary.PruneBuildCache(ctx) }, func() (uint64, error) { return f.secondary.PruneBuildCache(ctx) })
+}
+
+// SplitRef splits an image reference into repository and tag, defaulting
+// the tag to "latest". A digest reference ("repo@sha256:...") is returned
+// as repository with the digest as tag.
+func SplitRef(ref string) (repo, tag string) {
+	if repo, digest, ok := strings.Cut(ref, "@"); ok {
+		return repo, digest
+	}
+	// A colon before the last slash belongs to a registry port.
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, "latest"
+}
+
+// ParseSize parses sizes printed by the docker CLI ("1.5GiB", "512MB",
+// "0B") into bytes.
+func ParseSize(s string) (uint64, bool) {
+	s = strings.TrimSpace(s)
+	i := len(s)
+	for i > 0 && (s[i-1] < '0' || s[i-1] > '9') {
+		i--
+	}
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil || v < 0 {
+		return 0, false
+	}
+	unit := strings.ToLower(strings.TrimSpace(s[i:]))
+	mult := map[string]float64{
+		"b": 1, "": 1,
+		"kb": 1e3, "mb": 1e6, "gb": 1e9, "tb": 1e12,
+		"kib": 1 << 10, "mib": 1 << 20, "gib": 1 << 30, "tib": 1 << 40,
+	}[unit]
+	if mult == 0 {
+		return 0, false
+	}
+	return uint64(v * mult), true
+}
+
+// FormatSize prints bytes with binary units, as `docker stats` does for
+// memory ("512MiB", "1.5GiB").
+func FormatSize(b uint64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	v := float64(b)
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%dB", b)
+	}
+	return fmt.Sprintf("%.3g%s", v, units[i])
+}
+
+func (f *fallbackClient) Exec(ctx context.Context, name string, cmd []string, user string) (ExecResult, error) {
+	return fallback(func() (ExecResult, error) { return f.primary.Exec(ctx, name, cmd, user) }, func() (ExecResult, error) { return f.secondary.Exec(ctx, name, cmd, user) })
+}
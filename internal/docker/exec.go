@@ -0,0 +1,170 @@
+package docker
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+
+	"nasbot/internal/cmdexec"
+)
+
+// MaxExecOutput bounds the output kept from a command run with Exec. The
+// end of the output is kept, where errors and summaries usually are; the
+// start is read and dropped so the command is not blocked on its writes.
+const MaxExecOutput = 64 << 10
+
+// ExecResult is the outcome of a command run inside a container.
+type ExecResult struct {
+	Output    []byte // stdout and stderr, interleaved
+	ExitCode  int
+	Truncated bool
+}
+
+// tailBuffer keeps the last n bytes written to it.
+type tailBuffer struct {
+	buf       []byte
+	n         int
+	truncated bool
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	written := len(p)
+	if len(p) >= b.n {
+		b.truncated = b.truncated || len(p) > b.n || len(b.buf) > 0
+		b.buf = append(b.buf[:0], p[len(p)-b.n:]...)
+		return written, nil
+	}
+	if over := len(b.buf) + len(p) - b.n; over > 0 {
+		b.buf = b.buf[:copy(b.buf, b.buf[over:])]
+		b.truncated = true
+	}
+	b.buf = append(b.buf, p...)
+	return written, nil
+}
+
+// Bytes returns the kept output. Once the start was dropped, it begins at
+// the next rune boundary so no character is split.
+func (b *tailBuffer) Bytes() []byte {
+	if !b.truncated {
+		return b.buf
+	}
+	for i := 0; i < len(b.buf) && i < utf8.UTFMax; i++ {
+		if utf8.RuneStart(b.buf[i]) {
+			return b.buf[i:]
+		}
+	}
+	return b.buf
+}
+
+// frameWriter strips the 8-byte headers of a multiplexed stdout/stderr
+// stream and writes the payloads to w. Frames may span several writes.
+type frameWriter struct {
+	w      io.Writer
+	header []byte
+	left   int // payload bytes left in the current frame
+}
+
+func (f *frameWriter) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		if f.left == 0 {
+			take := min(8-len(f.header), len(p))
+			f.header = append(f.header, p[:take]...)
+			p = p[take:]
+			if len(f.header) == 8 {
+				f.left = int(binary.BigEndian.Uint32(f.header[4:8]))
+				f.header = f.header[:0]
+			}
+			continue
+		}
+		take := min(f.left, len(p))
+		if _, err := f.w.Write(p[:take]); err != nil {
+			return written - len(p), err
+		}
+		f.left -= take
+		p = p[take:]
+	}
+	return written, nil
+}
+
+// Exec runs cmd, without a shell, inside the running container and waits
+// for it to exit.
+func (c *APIClient) Exec(ctx context.Context, name string, cmd []string, user string) (ExecResult, error) {
+	body := map[string]any{"AttachStdout": true, "AttachStderr": true, "Cmd": cmd}
+	if user != "" {
+		body["User"] = user
+	}
+	resp, err := c.doJSON(ctx, http.MethodPost, containerPath(name, "exec"), nil, body)
+	if err != nil {
+		return ExecResult{}, err
+	}
+	var created struct {
+		ID string `json:"Id"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+	if err != nil {
+		return ExecResult{}, err
+	}
+
+	path := "/exec/" + url.PathEscape(created.ID)
+	resp, err = c.doJSON(ctx, http.MethodPost, path+"/start", nil, map[string]any{"Detach": false, "Tty": false})
+	if err != nil {
+		return ExecResult{}, err
+	}
+	// Without a TTY the stream is always multiplexed.
+	out := &tailBuffer{n: MaxExecOutput}
+	_, err = io.Copy(&frameWriter{w: out}, resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return ExecResult{}, err
+	}
+
+	var state struct {
+		ExitCode int  `json:"ExitCode"`
+		Running  bool `json:"Running"`
+	}
+	if err := c.getJSON(ctx, path+"/json", nil, &state); err != nil {
+		return ExecResult{}, err
+	}
+	if state.Running {
+		return ExecResult{}, errors.New("exec stream closed while the command was still running")
+	}
+	return ExecResult{Output: out.Bytes(), ExitCode: state.ExitCode, Truncated: out.truncated}, nil
+}
+
+// Exec runs `docker exec`. Exit code 125 is the CLI's own error, such as a
+// stopped container. 126 and 127, a command that cannot be run or found,
+// are returned like any other exit code: the command may use them too.
+func (c CLIClient) Exec(ctx context.Context, name string, cmd []string, user string) (ExecResult, error) {
+	args := []string{"exec"}
+	if user != "" {
+		args = append(args, "--user", user)
+	}
+	args = append(args, name)
+	args = append(args, cmd...)
+	raw, err := cmdexec.CombinedOutput(ctx, c.bin(), args...)
+	out := &tailBuffer{n: MaxExecOutput}
+	out.Write(raw)
+	res := ExecResult{Output: out.Bytes(), Truncated: out.truncated}
+	if err == nil {
+		return res, nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() == 125 {
+		if msg := strings.TrimSpace(string(raw)); msg != "" {
+			return ExecResult{}, fmt.Errorf("%s: %w", msg, err)
+		}
+		return ExecResult{}, err
+	}
+	res.ExitCode = exitErr.ExitCode()
+	return res, nil
+}
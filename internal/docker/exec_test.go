@@ -0,0 +1,106 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"nasbot/internal/cmdexec"
+)
+
+func TestAPIExec(t *testing.T) {
+	c, d := startFakeDaemon(t)
+
+	res, err := c.Exec(context.Background(), "nextcloud", []string{"occ", "maintenance:mode", "--off"}, "www-data")
+	if err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	if string(res.Output) != "maintenance mode disabled\nwarning: cache stale\n" || res.ExitCode != 3 || res.Truncated {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	want := []string{`exec nextcloud ["occ" "maintenance:mode" "--off"] user=www-data`}
+	if fmt.Sprint(d.actions) != fmt.Sprint(want) {
+		t.Fatalf("actions = %v, want %v", d.actions, want)
+	}
+}
+
+// exitRunner answers every command with output and the given error.
+type exitRunner struct {
+	scriptRunner
+	output string
+	err    error
+}
+
+func (r *exitRunner) CombinedOutput(_ context.Context, name string, args ...string) ([]byte, error) {
+	r.calls = append(r.calls, name+" "+strings.Join(args, " "))
+	return []byte(r.output), r.err
+}
+
+func TestCLIExec(t *testing.T) {
+	exitErr := exec.Command("false").Run()
+	if _, ok := exitErr.(*exec.ExitError); !ok {
+		t.Skipf("no false binary: %v", exitErr)
+	}
+	r := &exitRunner{output: "not in maintenance\n", err: exitErr}
+	defer cmdexec.SetRunner(r)()
+
+	res, err := CLIClient{}.Exec(context.Background(), "nextcloud", []string{"occ", "status"}, "www-data")
+	if err != nil || res.ExitCode != 1 || string(res.Output) != "not in maintenance\n" {
+		t.Fatalf("Exec = %+v, %v", res, err)
+	}
+	if r.calls[0] != "docker exec --user www-data nextcloud occ status" {
+		t.Fatalf("unexpected call: %q", r.calls[0])
+	}
+
+	r.output, r.err = "Error response from daemon: container is not running\n", fmt.Errorf("exit status 1")
+	if _, err := (CLIClient{}).Exec(context.Background(), "nextcloud", []string{"occ"}, ""); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Fatalf("expected the CLI error, got %v", err)
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{n: 5}
+	b.Write([]byte("abc"))
+	if string(b.buf) != "abc" || b.truncated {
+		t.Fatalf("buffer = %q, truncated %v", b.buf, b.truncated)
+	}
+	b.Write([]byte("defg"))
+	b.Write([]byte("h"))
+	if string(b.buf) != "defgh" || !b.truncated {
+		t.Fatalf("buffer = %q, truncated %v", b.buf, b.truncated)
+	}
+	b.Write([]byte("0123456789"))
+	if string(b.buf) != "56789" {
+		t.Fatalf("buffer = %q", b.buf)
+	}
+
+	// "è" is two bytes; the kept tail starts after the half of it.
+	b = &tailBuffer{n: 3}
+	b.Write([]byte("caffè!"))
+	if got := string(b.Bytes()); got != "è!" {
+		t.Fatalf("Bytes() = %q", got)
+	}
+	b = &tailBuffer{n: 2}
+	b.Write([]byte("caffè!"))
+	if got := string(b.Bytes()); got != "!" {
+		t.Fatalf("Bytes() = %q", got)
+	}
+}
+
+func TestFrameWriter(t *testing.T) {
+	frame := func(stream byte, s string) []byte {
+		return append([]byte{stream, 0, 0, 0, 0, 0, 0, byte(len(s))}, s...)
+	}
+	raw := append(append(frame(1, "out\n"), frame(2, "")...), frame(2, "err\n")...)
+	b := &tailBuffer{n: 64}
+	w := &frameWriter{w: b}
+	// One byte at a time, so headers and payloads are split across writes.
+	for i := range raw {
+		w.Write(raw[i : i+1])
+	}
+	if string(b.buf) != "out\nerr\n" {
+		t.Fatalf("payload = %q", b.buf)
+	}
+}
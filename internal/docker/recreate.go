@@ -0,0 +1,304 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// recreateSuffix is appended to the old container's name while its
+// replacement is created, so it can be restored if that fails.
+const recreateSuffix = "-nasbot-old"
+
+// rawContainer is the part of the inspect document a copy is created from.
+type rawContainer struct {
+	ID         string         `json:"Id"`
+	Config     map[string]any `json:"Config"`
+	HostConfig map[string]any `json:"HostConfig"`
+	Mounts     []struct {
+		Type        string `json:"Type"`
+		Name        string `json:"Name"`
+		Destination string `json:"Destination"`
+	} `json:"Mounts"`
+	NetworkSettings struct {
+		Networks map[string]map[string]any `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// createSpec is the body of POST /containers/create and the networks to
+// connect once the container exists: older daemons accept a single
+// endpoint at creation.
+type createSpec struct {
+	Body  map[string]any
+	Extra map[string]map[string]any
+}
+
+// buildCreateSpec derives the create request for a copy of container
+// running image. Values the container only inherited from oldImage (env,
+// labels, cmd, ...) are dropped so the new image's defaults apply, as
+// docker compose and Watchtower do.
+func buildCreateSpec(container, oldImage []byte, image string) (*createSpec, error) {
+	var c rawContainer
+	if err := json.Unmarshal(container, &c); err != nil {
+		return nil, err
+	}
+	var img struct {
+		Config map[string]any `json:"Config"`
+	}
+	if len(oldImage) > 0 {
+		if err := json.Unmarshal(oldImage, &img); err != nil {
+			return nil, err
+		}
+	}
+
+	body := make(map[string]any, len(c.Config)+2)
+	for k, v := range c.Config {
+		body[k] = v
+	}
+	stripImageDefaults(body, img.Config)
+	body["Image"] = image
+	shortID := c.ID
+	if len(shortID) > 12 {
+		shortID = shortID[:12]
+	}
+	if h, _ := body["Hostname"].(string); h != "" && h == shortID {
+		delete(body, "Hostname")
+	}
+
+	host := c.HostConfig
+	if host == nil {
+		host = map[string]any{}
+	}
+	keepAnonymousVolumes(host, c)
+	body["HostConfig"] = host
+
+	spec := &createSpec{Body: body, Extra: map[string]map[string]any{}}
+	mode, _ := host["NetworkMode"].(string)
+	if mode == "host" || mode == "none" || strings.HasPrefix(mode, "container:") {
+		return spec, nil
+	}
+	primary := mode
+	if primary == "" || primary == "default" {
+		primary = "bridge"
+	}
+	for name, ep := range c.NetworkSettings.Networks {
+		ep = endpointConfig(ep, shortID)
+		if name == primary {
+			body["NetworkingConfig"] = map[string]any{"EndpointsConfig": map[string]any{name: ep}}
+		} else {
+			spec.Extra[name] = ep
+		}
+	}
+	return spec, nil
+}
+
+// stripImageDefaults removes from cfg what equals the image configuration.
+func stripImageDefaults(cfg, image map[string]any) {
+	if image == nil {
+		return
+	}
+	if env, ok := cfg["Env"].([]any); ok {
+		inherited := make(map[any]bool)
+		if imgEnv, ok := image["Env"].([]any); ok {
+			for _, e := range imgEnv {
+				inherited[e] = true
+			}
+		}
+		kept := make([]any, 0, len(env))
+		for _, e := range env {
+			if !inherited[e] {
+				kept = append(kept, e)
+			}
+		}
+		cfg["Env"] = kept
+	}
+	if labels, ok := cfg["Labels"].(map[string]any); ok {
+		imgLabels, _ := image["Labels"].(map[string]any)
+		for k, v := range labels {
+			if iv, ok := imgLabels[k]; ok && iv == v {
+				delete(labels, k)
+			}
+		}
+	}
+	for _, key := range []string{"ExposedPorts", "Volumes"} {
+		m, ok := cfg[key].(map[string]any)
+		imgM, _ := image[key].(map[string]any)
+		if !ok {
+			continue
+		}
+		for k := range m {
+			if _, ok := imgM[k]; ok {
+				delete(m, k)
+			}
+		}
+	}
+	for _, key := range []string{"Cmd", "Entrypoint", "WorkingDir", "User", "Healthcheck", "StopSignal"} {
+		if v, ok := cfg[key]; ok && reflect.DeepEqual(v, image[key]) {
+			delete(cfg, key)
+		}
+	}
+}
+
+// keepAnonymousVolumes binds the old container's anonymous volumes into
+// the copy; they are not listed in HostConfig and would otherwise be
+// replaced by empty ones.
+func keepAnonymousVolumes(host map[string]any, c rawContainer) {
+	covered := make(map[string]bool)
+	binds, _ := host["Binds"].([]any)
+	for _, b := range binds {
+		if s, ok := b.(string); ok {
+			parts := strings.Split(s, ":")
+			if len(parts) >= 2 {
+				covered[parts[1]] = true
+			}
+		}
+	}
+	mounts, _ := host["Mounts"].([]any)
+	for _, m := range mounts {
+		if mm, ok := m.(map[string]any); ok {
+			if target, ok := mm["Target"].(string); ok {
+				covered[target] = true
+			}
+		}
+	}
+	for _, m := range c.Mounts {
+		if m.Type == "volume" && m.Name != "" && !covered[m.Destination] {
+			binds = append(binds, m.Name+":"+m.Destination)
+		}
+	}
+	if len(binds) > 0 {
+		host["Binds"] = binds
+	}
+}
+
+// endpointConfig keeps the user-set parts of a network endpoint; addresses
+// and IDs are assigned again by the daemon.
+func endpointConfig(ep map[string]any, shortID string) map[string]any {
+	out := make(map[string]any)
+	for _, key := range []string{"IPAMConfig", "Links", "DriverOpts"} {
+		if v, ok := ep[key]; ok && v != nil {
+			out[key] = v
+		}
+	}
+	if aliases, ok := ep["Aliases"].([]any); ok {
+		var kept []any
+		for _, a := range aliases {
+			if a != shortID {
+				kept = append(kept, a)
+			}
+		}
+		if len(kept) > 0 {
+			out["Aliases"] = kept
+		}
+	}
+	return out
+}
+
+// Recreate replaces the container with a copy running image. The old
+// container is renamed aside until its replacement has started and is
+// restored when any step before that fails.
+func (c *APIClient) Recreate(ctx context.Context, name, image string) error {
+	old, err := c.Inspect(ctx, name)
+	if err != nil {
+		return err
+	}
+	var oldImage []byte
+	if img, err := c.ImageInspect(ctx, old.Image); err == nil {
+		oldImage = img.Raw
+	}
+	spec, err := buildCreateSpec(old.Raw, oldImage, image)
+	if err != nil {
+		return err
+	}
+
+	// A copy left aside by an interrupted run would block the rename.
+	stale := old.Name + recreateSuffix
+	var apiErr *apiError
+	if err := c.remove(ctx, stale, true); err != nil && !(errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound) {
+		return fmt.Errorf("remove %s: %w", stale, err)
+	}
+
+	if old.State.Running {
+		if err := c.Stop(ctx, old.ID); err != nil {
+			return fmt.Errorf("stop: %w", err)
+		}
+	}
+	// Restoring must not be cut short by the caller's deadline.
+	bg := context.WithoutCancel(ctx)
+	if err := c.rename(ctx, old.ID, old.Name+recreateSuffix); err != nil {
+		if old.State.Running {
+			_ = c.Start(bg, old.ID)
+		}
+		return fmt.Errorf("rename: %w", err)
+	}
+	restore := func() {
+		_ = c.rename(bg, old.ID, old.Name)
+		if old.State.Running {
+			_ = c.Start(bg, old.ID)
+		}
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	resp, err := c.doJSON(ctx, http.MethodPost, "/containers/create", url.Values{"name": {old.Name}}, spec.Body)
+	if err != nil {
+		restore()
+		return fmt.Errorf("create: %w", err)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+	if err != nil {
+		restore()
+		return fmt.Errorf("create: %w", err)
+	}
+
+	fail := func(err error) error {
+		_ = c.remove(bg, created.ID, true)
+		restore()
+		return err
+	}
+	for network, ep := range spec.Extra {
+		body := map[string]any{"Container": created.ID, "EndpointConfig": ep}
+		resp, err := c.doJSON(ctx, http.MethodPost, "/networks/"+url.PathEscape(network)+"/connect", nil, body)
+		if err != nil {
+			return fail(fmt.Errorf("connect %s: %w", network, err))
+		}
+		resp.Body.Close()
+	}
+	if old.State.Running {
+		if err := c.Start(ctx, created.ID); err != nil {
+			return fail(fmt.Errorf("start: %w", err))
+		}
+	}
+	// The replacement is live: a copy that cannot be removed is only left
+	// behind, and the next run removes it.
+	if err := c.remove(bg, old.ID, true); err != nil {
+		slog.Warn("Could not remove the replaced container", "container", stale, "err", err)
+	}
+	return nil
+}
+
+func (c *APIClient) rename(ctx context.Context, id, name string) error {
+	return c.post(ctx, containerPath(id, "rename"), url.Values{"name": {name}}, nil)
+}
+
+// remove deletes a container; its anonymous volumes are kept.
+func (c *APIClient) remove(ctx context.Context, id string, force bool) error {
+	q := url.Values{}
+	if force {
+		q.Set("force", "1")
+	}
+	resp, err := c.do(ctx, http.MethodDelete, containerPath(id, ""), q)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,83 @@
+package docker
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBuildCreateSpec(t *testing.T) {
+	container := []byte(`{
+		"Id": "0123456789abcdef",
+		"Config": {
+			"Hostname": "0123456789ab",
+			"Image": "nginx:1.25",
+			"Env": ["PATH=/usr/bin", "NGINX_VERSION=1.25", "TZ=Europe/Rome"],
+			"Labels": {"maintainer": "nginx", "com.docker.compose.project": "site"},
+			"Cmd": ["nginx", "-g", "daemon off;"],
+			"ExposedPorts": {"80/tcp": {}, "8080/tcp": {}}
+		},
+		"HostConfig": {"NetworkMode": "site_default", "Binds": ["/srv/site:/usr/share/nginx/html:ro"]},
+		"Mounts": [
+			{"Type": "bind", "Destination": "/usr/share/nginx/html"},
+			{"Type": "volume", "Name": "f00d", "Destination": "/var/cache/nginx"}
+		],
+		"NetworkSettings": {"Networks": {
+			"site_default": {"Aliases": ["web", "0123456789ab"], "IPAddress": "172.18.0.2", "NetworkID": "n1"},
+			"proxy": {"Aliases": ["site-web"], "IPAMConfig": {"IPv4Address": "10.0.0.5"}}
+		}}
+	}`)
+	oldImage := []byte(`{"Config": {
+		"Env": ["PATH=/usr/bin", "NGINX_VERSION=1.25"],
+		"Labels": {"maintainer": "nginx"},
+		"Cmd": ["nginx", "-g", "daemon off;"],
+		"ExposedPorts": {"80/tcp": {}}
+	}}`)
+
+	spec, err := buildCreateSpec(container, oldImage, "nginx:1.27")
+	if err != nil {
+		t.Fatalf("buildCreateSpec: %v", err)
+	}
+	b := spec.Body
+	if b["Image"] != "nginx:1.27" || b["Hostname"] != nil || b["Cmd"] != nil {
+		t.Fatalf("image defaults not reset: %v", b)
+	}
+	if fmt.Sprint(b["Env"]) != "[TZ=Europe/Rome]" {
+		t.Fatalf("Env = %v", b["Env"])
+	}
+	if fmt.Sprint(b["Labels"]) != "map[com.docker.compose.project:site]" || fmt.Sprint(b["ExposedPorts"]) != "map[8080/tcp:map[]]" {
+		t.Fatalf("labels/ports = %v %v", b["Labels"], b["ExposedPorts"])
+	}
+	host := b["HostConfig"].(map[string]any)
+	if fmt.Sprint(host["Binds"]) != "[/srv/site:/usr/share/nginx/html:ro f00d:/var/cache/nginx]" {
+		t.Fatalf("anonymous volume not kept: %v", host["Binds"])
+	}
+	primary := b["NetworkingConfig"].(map[string]any)["EndpointsConfig"].(map[string]any)["site_default"]
+	if fmt.Sprint(primary) != "map[Aliases:[web]]" {
+		t.Fatalf("primary endpoint = %v", primary)
+	}
+	if len(spec.Extra) != 1 || fmt.Sprint(spec.Extra["proxy"]) != "map[Aliases:[site-web] IPAMConfig:map[IPv4Address:10.0.0.5]]" {
+		t.Fatalf("extra networks = %v", spec.Extra)
+	}
+}
+
+func TestBuildCreateSpecHostNetwork(t *testing.T) {
+	spec, err := buildCreateSpec([]byte(`{"Id":"x","Config":{},"HostConfig":{"NetworkMode":"host"},
+		"NetworkSettings":{"Networks":{"host":{}}}}`), nil, "app:2")
+	if err != nil || spec.Body["NetworkingConfig"] != nil || len(spec.Extra) != 0 {
+		t.Fatalf("host network must not get endpoints: %+v, %v", spec, err)
+	}
+}
+
+func TestSplitRef(t *testing.T) {
+	for ref, want := range map[string][2]string{
+		"nginx":                       {"nginx", "latest"},
+		"nginx:1.27":                  {"nginx", "1.27"},
+		"localhost:5000/app":          {"localhost:5000/app", "latest"},
+		"localhost:5000/app:v2":       {"localhost:5000/app", "v2"},
+		"ghcr.io/org/app@sha256:abcd": {"ghcr.io/org/app", "sha256:abcd"},
+	} {
+		if repo, tag := SplitRef(ref); repo != want[0] || tag != want[1] {
+			t.Errorf("SplitRef(%q) = %q, %q", ref, repo, tag)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package docker
+
+import "strings"
+
+// Runtime is a container engine serving the Docker Engine API. Podman does
+// so on its own socket and its CLI takes the same commands, so both go
+// through the same clients.
+type Runtime struct {
+	Name   string // as configured: "docker" or "podman"
+	Binary string // CLI used when the socket cannot be reached
+	Socket string // default API socket
+	// Services are the systemd units that restart the engine. Podman has
+	// no daemon: its API service is restarted, containers keep running.
+	Services []string
+}
+
+var (
+	Docker = Runtime{Name: "docker", Binary: "docker", Socket: DefaultSocket, Services: []string{"docker"}}
+	Podman = Runtime{Name: "podman", Binary: "podman", Socket: "/run/podman/podman.sock", Services: []string{"podman.socket", "podman.service"}}
+)
+
+// RuntimeByName returns the runtime called name, ignoring case.
+func RuntimeByName(name string) (Runtime, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case Docker.Name:
+		return Docker, true
+	case Podman.Name:
+		return Podman, true
+	}
+	return Runtime{}, false
+}
+
+// Rootless reports whether socket belongs to a per-user service, as
+// $XDG_RUNTIME_DIR/podman/podman.sock does.
+func Rootless(socket string) bool {
+	return strings.HasPrefix(socket, "/run/user/")
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 )
 
 // FormatUptime formats uptime in a readable format.
@@ -81,6 +82,21 @@ func Truncate(s string, max int) string {
 	return s[:max-1] + "~"
 }
 
+// Tail returns at most the last max bytes of s, starting on a rune
+// boundary so a multi-byte character is never split.
+func Tail(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	s = s[len(s)-max:]
+	for i := 0; i < len(s) && i < utf8.UTFMax; i++ {
+		if utf8.RuneStart(s[i]) {
+			return s[i:]
+		}
+	}
+	return s
+}
+
 // SafeFloat safely gets a float from an array.
 func SafeFloat(arr []float64, def float64) float64 {
 	if len(arr) > 0 {
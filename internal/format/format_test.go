@@ -38,3 +38,13 @@ func TestTruncate(t *testing.T) {
 		t.Fatalf("Truncate = %q, want %q", got, "abc~")
 	}
 }
+
+func TestTail(t *testing.T) {
+	if got := Tail("abcd", 4); got != "abcd" {
+		t.Fatalf("Tail = %q, want %q", got, "abcd")
+	}
+	// "è" is two bytes; cutting into it skips to the next character.
+	if got := Tail("caffè latte", 7); got != " latte" {
+		t.Fatalf("Tail = %q, want %q", got, " latte")
+	}
+}
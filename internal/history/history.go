@@ -0,0 +1,313 @@
+// Package history keeps a persistent, downsampled time series of named
+// metrics. Every sample is written to each tier: the raw tier keeps the
+// samples as-is, the others aggregate them into fixed buckets (avg/min/max),
+// and each tier drops data older than its retention.
+package history
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Point is one sample or one aggregated bucket. For raw samples Avg, Min and
+// Max are equal.
+type Point struct {
+	Time time.Time
+	Avg  float64
+	Min  float64
+	Max  float64
+}
+
+// Tier is one resolution of the store. Step 0 keeps every sample.
+type Tier struct {
+	Step      time.Duration
+	Retention time.Duration
+}
+
+type bucket struct {
+	Start time.Time
+	Sum   float64
+	Min   float64
+	Max   float64
+	N     int
+}
+
+func (b *bucket) add(v float64) {
+	if b.N == 0 || v < b.Min {
+		b.Min = v
+	}
+	if b.N == 0 || v > b.Max {
+		b.Max = v
+	}
+	b.Sum += v
+	b.N++
+}
+
+func (b bucket) point() Point {
+	return Point{Time: b.Start, Avg: b.Sum / float64(b.N), Min: b.Min, Max: b.Max}
+}
+
+type series struct {
+	points [][]Point // per tier
+	open   []bucket  // per tier, unused for raw tiers
+}
+
+// Store holds every metric at every tier. It is safe for concurrent use.
+type Store struct {
+	mu     sync.Mutex
+	tiers  []Tier
+	series map[string]*series
+}
+
+// New returns an empty store with the given tiers, finest first.
+func New(tiers []Tier) *Store {
+	t := append([]Tier(nil), tiers...)
+	sort.SliceStable(t, func(i, j int) bool { return t[i].Step < t[j].Step })
+	return &Store{tiers: t, series: make(map[string]*series)}
+}
+
+// Tiers returns the configured tiers, finest first.
+func (s *Store) Tiers() []Tier {
+	return append([]Tier(nil), s.tiers...)
+}
+
+func (s *Store) get(metric string) *series {
+	sr, ok := s.series[metric]
+	if !ok {
+		sr = &series{points: make([][]Point, len(s.tiers)), open: make([]bucket, len(s.tiers))}
+		s.series[metric] = sr
+	}
+	return sr
+}
+
+// Add records one sample of every metric in values at time now. NaN and
+// infinite values are ignored.
+func (s *Store) Add(now time.Time, values map[string]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for metric, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		sr := s.get(metric)
+		for i, tier := range s.tiers {
+			if tier.Step <= 0 {
+				sr.points[i] = append(sr.points[i], Point{Time: now, Avg: v, Min: v, Max: v})
+				continue
+			}
+			start := now.Truncate(tier.Step)
+			open := &sr.open[i]
+			if open.N > 0 && !open.Start.Equal(start) {
+				sr.points[i] = append(sr.points[i], open.point())
+				*open = bucket{}
+			}
+			if open.N == 0 {
+				open.Start = start
+			}
+			open.add(v)
+		}
+	}
+	s.pruneLocked(now)
+}
+
+func (s *Store) pruneLocked(now time.Time) {
+	for metric, sr := range s.series {
+		empty := true
+		for i, tier := range s.tiers {
+			cutoff := now.Add(-tier.Retention)
+			pts := sr.points[i]
+			n := sort.Search(len(pts), func(k int) bool { return !pts[k].Time.Before(cutoff) })
+			if n > 0 {
+				sr.points[i] = append([]Point(nil), pts[n:]...)
+			}
+			if sr.open[i].N > 0 && sr.open[i].Start.Before(cutoff.Add(-tier.Step)) {
+				sr.open[i] = bucket{}
+			}
+			if len(sr.points[i]) > 0 || sr.open[i].N > 0 {
+				empty = false
+			}
+		}
+		if empty {
+			delete(s.series, metric)
+		}
+	}
+}
+
+// Metrics returns the names of all stored metrics, sorted.
+func (s *Store) Metrics() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, 0, len(s.series))
+	for m := range s.series {
+		out = append(out, m)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// tierPointsLocked returns the points of tier i including its open bucket.
+func (s *Store) tierPointsLocked(sr *series, i int) []Point {
+	pts := sr.points[i]
+	if sr.open[i].N > 0 {
+		pts = append(pts[:len(pts):len(pts)], sr.open[i].point())
+	}
+	return pts
+}
+
+// between returns the points whose bucket overlaps [from, to].
+func between(pts []Point, step time.Duration, from, to time.Time) []Point {
+	out := make([]Point, 0, len(pts))
+	for _, p := range pts {
+		overlaps := !p.Time.Before(from) || p.Time.Add(step).After(from)
+		if overlaps && !p.Time.After(to) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Range returns the points of metric between from and to, using the finest
+// tier whose retention covers the whole window (the coarsest one otherwise).
+func (s *Store) Range(metric string, from, to time.Time) []Point {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sr, ok := s.series[metric]
+	if !ok || len(s.tiers) == 0 {
+		return nil
+	}
+
+	i := len(s.tiers) - 1
+	for k, tier := range s.tiers {
+		if tier.Retention >= to.Sub(from) {
+			i = k
+			break
+		}
+	}
+	return between(s.tierPointsLocked(sr, i), s.tiers[i].Step, from, to)
+}
+
+// RangeStep is like Range but always reads the tier with the given step.
+func (s *Store) RangeStep(metric string, step time.Duration, from, to time.Time) []Point {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sr, ok := s.series[metric]
+	if !ok {
+		return nil
+	}
+	for i, tier := range s.tiers {
+		if tier.Step == step {
+			return between(s.tierPointsLocked(sr, i), tier.Step, from, to)
+		}
+	}
+	return nil
+}
+
+// Summary aggregates the points of metric between from and to.
+func (s *Store) Summary(metric string, from, to time.Time) (Point, bool) {
+	pts := s.Range(metric, from, to)
+	if len(pts) == 0 {
+		return Point{}, false
+	}
+	var b bucket
+	for _, p := range pts {
+		b.add(p.Avg)
+		b.Min = math.Min(b.Min, p.Min)
+		b.Max = math.Max(b.Max, p.Max)
+	}
+	out := b.point()
+	out.Time = pts[0].Time
+	return out, true
+}
+
+// ═══════════════════════════════════════════════════════════════════
+//  Persistence
+// ═══════════════════════════════════════════════════════════════════
+
+const fileVersion = 1
+
+type fileTier struct {
+	Step   time.Duration
+	Points []Point
+	Open   bucket
+}
+
+type fileData struct {
+	Version int
+	Series  map[string][]fileTier
+}
+
+// Save writes the store to path atomically.
+func (s *Store) Save(path string) error {
+	s.mu.Lock()
+	data := fileData{Version: fileVersion, Series: make(map[string][]fileTier, len(s.series))}
+	for metric, sr := range s.series {
+		tiers := make([]fileTier, len(s.tiers))
+		for i, tier := range s.tiers {
+			tiers[i] = fileTier{Step: tier.Step, Points: sr.points[i], Open: sr.open[i]}
+		}
+		data.Series[metric] = tiers
+	}
+	s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".history-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load replaces the contents of the store with the file at path. Data for
+// tiers that are no longer configured is dropped, and the current retention
+// is applied relative to now.
+func (s *Store) Load(path string, now time.Time) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var data fileData
+	if err := gob.NewDecoder(f).Decode(&data); err != nil {
+		return err
+	}
+	if data.Version != fileVersion {
+		return fmt.Errorf("unsupported history version %d", data.Version)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.series = make(map[string]*series, len(data.Series))
+	for metric, tiers := range data.Series {
+		sr := s.get(metric)
+		for _, ft := range tiers {
+			for i, tier := range s.tiers {
+				if tier.Step == ft.Step {
+					sr.points[i] = ft.Points
+					if tier.Step > 0 {
+						sr.open[i] = ft.Open
+					}
+				}
+			}
+		}
+	}
+	s.pruneLocked(now)
+	return nil
+}
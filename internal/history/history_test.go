@@ -0,0 +1,102 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var testTiers = []Tier{
+	{Step: 0, Retention: time.Hour},
+	{Step: 5 * time.Minute, Retention: 24 * time.Hour},
+	{Step: time.Hour, Retention: 30 * 24 * time.Hour},
+}
+
+func TestStoreRollups(t *testing.T) {
+	s := New(testTiers)
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		s.Add(t0.Add(time.Duration(i)*time.Minute), map[string]float64{"cpu": float64(i * 10)})
+	}
+
+	raw := s.RangeStep("cpu", 0, t0, t0.Add(time.Hour))
+	if len(raw) != 10 || raw[9].Avg != 90 {
+		t.Fatalf("raw tier = %+v", raw)
+	}
+
+	five := s.RangeStep("cpu", 5*time.Minute, t0, t0.Add(time.Hour))
+	if len(five) != 2 {
+		t.Fatalf("expected a closed and an open 5m bucket, got %+v", five)
+	}
+	if five[0].Avg != 20 || five[0].Min != 0 || five[0].Max != 40 {
+		t.Fatalf("first bucket = %+v", five[0])
+	}
+	if five[1].Avg != 70 || !five[1].Time.Equal(t0.Add(5*time.Minute)) {
+		t.Fatalf("open bucket = %+v", five[1])
+	}
+
+	sum, ok := s.Summary("cpu", t0, t0.Add(time.Hour))
+	if !ok || sum.Avg != 45 || sum.Max != 90 || sum.Min != 0 {
+		t.Fatalf("Summary = %+v, %v", sum, ok)
+	}
+}
+
+func TestStoreRetentionAndTierChoice(t *testing.T) {
+	s := New(testTiers)
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= 3*60; i++ {
+		s.Add(t0.Add(time.Duration(i)*time.Minute), map[string]float64{"ram": 50})
+	}
+	now := t0.Add(3 * time.Hour)
+
+	if raw := s.RangeStep("ram", 0, t0, now); len(raw) != 61 {
+		t.Fatalf("raw tier should keep one hour, got %d points", len(raw))
+	}
+	if pts := s.Range("ram", now.Add(-30*time.Minute), now); len(pts) != 31 {
+		t.Fatalf("recent range should come from the raw tier, got %d points", len(pts))
+	}
+	pts := s.Range("ram", now.Add(-2*time.Hour), now)
+	if len(pts) != 25 || pts[1].Time.Sub(pts[0].Time) != 5*time.Minute {
+		t.Fatalf("older range should come from the 5m tier, got %d points", len(pts))
+	}
+	if pts := s.Range("ram", now.Add(-7*24*time.Hour), now); len(pts) != 4 {
+		t.Fatalf("week range should come from the hourly tier, got %d points", len(pts))
+	}
+}
+
+func TestStoreSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.gob")
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := New(testTiers)
+	s.Add(t0, map[string]float64{"cpu": 10, "vol_free:/mnt": 100})
+	s.Add(t0.Add(time.Minute), map[string]float64{"cpu": 30, "vol_free:/mnt": 90})
+	if err := s.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// Drop the hourly tier on reload.
+	loaded := New(testTiers[:2])
+	if err := loaded.Load(path, t0.Add(2*time.Minute)); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if m := loaded.Metrics(); len(m) != 2 || m[0] != "cpu" {
+		t.Fatalf("Metrics() = %v", m)
+	}
+	loaded.Add(t0.Add(6*time.Minute), map[string]float64{"cpu": 50})
+	five := loaded.RangeStep("cpu", 5*time.Minute, t0, t0.Add(time.Hour))
+	if len(five) != 2 || five[0].Avg != 20 {
+		t.Fatalf("open bucket not restored: %+v", five)
+	}
+	if pts := loaded.RangeStep("cpu", time.Hour, t0, t0.Add(time.Hour)); pts != nil {
+		t.Fatalf("dropped tier returned data: %+v", pts)
+	}
+
+	// Retention is applied on load.
+	if err := loaded.Load(path, t0.Add(48*time.Hour)); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if m := loaded.Metrics(); len(m) != 0 {
+		t.Fatalf("expired series kept: %v", m)
+	}
+}
@@ -0,0 +1,177 @@
+// Package mdstat parses /proc/mdstat, the kernel's view of the Linux
+// software RAID (md) arrays: their level, members and any running
+// resync, recovery, reshape or check.
+package mdstat
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sync actions, as named in /proc/mdstat.
+const (
+	Recovery = "recovery" // rebuilding onto a replacement disk
+	Resync   = "resync"
+	Reshape  = "reshape"
+	Check    = "check" // scrub, usually scheduled by the distro
+	Repair   = "repair"
+)
+
+// Member is a device of an array. Faulty and spare members are still
+// listed by the kernel.
+type Member struct {
+	Name        string
+	Slot        int
+	Faulty      bool // (F)
+	Spare       bool // (S)
+	WriteMostly bool // (W)
+	Replacement bool // (R)
+	Journal     bool // (J)
+}
+
+// Sync is the resync, recovery, reshape or check of an array.
+type Sync struct {
+	Action  string
+	Percent float64
+	Done    int64 // 1K blocks
+	Total   int64
+	Finish  time.Duration // kernel estimate of the time left
+	Speed   int64         // KiB/s
+	// Waiting is "DELAYED" or "PENDING" when the sync is queued behind
+	// another array's; the progress fields are then zero.
+	Waiting string
+}
+
+// Array is one md device.
+type Array struct {
+	Name     string
+	Active   bool
+	ReadOnly bool // "(read-only)" or "(auto-read-only)"
+	Level    string
+	Members  []Member
+	Blocks   int64
+	// Disks and Up are the "[n/m]" counts; they stay zero for levels
+	// without redundancy, such as raid0 and linear.
+	Disks  int
+	Up     int
+	Status string // "UU_", one letter per slot
+	Sync   *Sync
+}
+
+// Degraded reports whether a redundant array is missing members.
+func (a Array) Degraded() bool { return a.Up < a.Disks }
+
+// Faulty returns the names of the failed members.
+func (a Array) Faulty() []string { return a.members(func(m Member) bool { return m.Faulty }) }
+
+// Spares returns the names of the spare members.
+func (a Array) Spares() []string {
+	return a.members(func(m Member) bool { return m.Spare && !m.Faulty })
+}
+
+func (a Array) members(keep func(Member) bool) []string {
+	var out []string
+	for _, m := range a.Members {
+		if keep(m) {
+			out = append(out, m.Name)
+		}
+	}
+	return out
+}
+
+var (
+	memberRe = regexp.MustCompile(`^(\S+)\[(\d+)\]((?:\([A-Z]\))*)$`)
+	countRe  = regexp.MustCompile(`\[(\d+)/(\d+)\]\s+\[([U_]+)\]`)
+	syncRe   = regexp.MustCompile(`(\w+)\s*=\s*([\d.]+)%\s*\((\d+)/(\d+)\)\s*finish=([\d.]+)min\s*speed=(\d+)K/sec`)
+	queuedRe = regexp.MustCompile(`(\w+)\s*=\s*(DELAYED|PENDING)`)
+)
+
+// Parse reads the content of /proc/mdstat. Lines it does not know, such
+// as the bitmap ones, are skipped.
+func Parse(data []byte) []Array {
+	var arrays []Array
+	var cur *Array
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			cur = nil
+		case line[0] != ' ' && line[0] != '\t':
+			cur = nil
+			if name, rest, ok := strings.Cut(line, " : "); ok && strings.HasPrefix(name, "md") {
+				arrays = append(arrays, parseHeader(strings.TrimSpace(name), rest))
+				cur = &arrays[len(arrays)-1]
+			}
+		case cur != nil:
+			parseDetail(cur, trimmed)
+		}
+	}
+	return arrays
+}
+
+// parseHeader reads "active raid5 sdb1[0] sdc1[1] sde1[3](S)".
+func parseHeader(name, rest string) Array {
+	a := Array{Name: name}
+	fields := strings.Fields(rest)
+	if len(fields) > 0 {
+		a.Active = fields[0] == "active"
+		fields = fields[1:]
+	}
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "(") {
+		if strings.Contains(fields[0], "read-only") {
+			a.ReadOnly = true
+		}
+		fields = fields[1:]
+	}
+	for _, f := range fields {
+		m := memberRe.FindStringSubmatch(f)
+		if m == nil {
+			if a.Level == "" && len(a.Members) == 0 {
+				a.Level = f
+			}
+			continue
+		}
+		slot, _ := strconv.Atoi(m[2])
+		flags := m[3]
+		a.Members = append(a.Members, Member{
+			Name:        m[1],
+			Slot:        slot,
+			Faulty:      strings.Contains(flags, "(F)"),
+			Spare:       strings.Contains(flags, "(S)"),
+			WriteMostly: strings.Contains(flags, "(W)"),
+			Replacement: strings.Contains(flags, "(R)"),
+			Journal:     strings.Contains(flags, "(J)"),
+		})
+	}
+	return a
+}
+
+// parseDetail reads the indented lines below a header: the size and slot
+// status, and the progress of a sync.
+func parseDetail(a *Array, line string) {
+	if strings.Contains(line, " blocks") {
+		a.Blocks, _ = strconv.ParseInt(strings.Fields(line)[0], 10, 64)
+		if m := countRe.FindStringSubmatch(line); m != nil {
+			a.Disks, _ = strconv.Atoi(m[1])
+			a.Up, _ = strconv.Atoi(m[2])
+			a.Status = m[3]
+		}
+		return
+	}
+	if m := syncRe.FindStringSubmatch(line); m != nil {
+		s := &Sync{Action: m[1]}
+		s.Percent, _ = strconv.ParseFloat(m[2], 64)
+		s.Done, _ = strconv.ParseInt(m[3], 10, 64)
+		s.Total, _ = strconv.ParseInt(m[4], 10, 64)
+		minutes, _ := strconv.ParseFloat(m[5], 64)
+		s.Finish = time.Duration(minutes * float64(time.Minute))
+		s.Speed, _ = strconv.ParseInt(m[6], 10, 64)
+		a.Sync = s
+		return
+	}
+	if m := queuedRe.FindStringSubmatch(line); m != nil {
+		a.Sync = &Sync{Action: m[1], Waiting: m[2]}
+	}
+}
@@ -0,0 +1,70 @@
+package mdstat
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const sample = `Personalities : [raid1] [raid6] [raid5] [raid4] [linear]
+md1 : active raid5 sdd1[3] sdc1[1] sdb1[0] sde1[4](S) sdf1[2](F)
+      1953258496 blocks super 1.2 level 5, 512k chunk, algorithm 2 [3/2] [UU_]
+      [==>..................]  recovery = 12.6% (123456789/976629248) finish=127.5min speed=104128K/sec
+      bitmap: 0/8 pages [0KB], 65536KB chunk
+
+md0 : active (auto-read-only) raid1 sdb2[1] sda2[0]
+      1048512 blocks super 1.2 [2/2] [UU]
+      	resync=DELAYED
+
+md2 : active linear sdg[0] sdh[1]
+      3906764800 blocks super 1.2 0k rounding
+
+md127 : inactive sdi[0](S)
+      976630488 blocks super 1.2
+
+unused devices: <none>
+`
+
+func TestParse(t *testing.T) {
+	arrays := Parse([]byte(sample))
+	if len(arrays) != 4 {
+		t.Fatalf("got %d arrays: %+v", len(arrays), arrays)
+	}
+
+	md1 := arrays[0]
+	if md1.Name != "md1" || !md1.Active || md1.Level != "raid5" || md1.Blocks != 1953258496 {
+		t.Fatalf("md1 = %+v", md1)
+	}
+	if md1.Disks != 3 || md1.Up != 2 || md1.Status != "UU_" || !md1.Degraded() {
+		t.Fatalf("md1 counts = %d/%d %q", md1.Disks, md1.Up, md1.Status)
+	}
+	if got := md1.Faulty(); !reflect.DeepEqual(got, []string{"sdf1"}) {
+		t.Fatalf("md1 faulty = %v", got)
+	}
+	if got := md1.Spares(); !reflect.DeepEqual(got, []string{"sde1"}) {
+		t.Fatalf("md1 spares = %v", got)
+	}
+	want := &Sync{Action: Recovery, Percent: 12.6, Done: 123456789, Total: 976629248, Finish: 127*time.Minute + 30*time.Second, Speed: 104128}
+	if !reflect.DeepEqual(md1.Sync, want) {
+		t.Fatalf("md1 sync = %+v", md1.Sync)
+	}
+
+	md0 := arrays[1]
+	if !md0.ReadOnly || md0.Level != "raid1" || md0.Degraded() || len(md0.Members) != 2 || md0.Members[0] != (Member{Name: "sdb2", Slot: 1}) {
+		t.Fatalf("md0 = %+v", md0)
+	}
+	if md0.Sync == nil || md0.Sync.Action != Resync || md0.Sync.Waiting != "DELAYED" {
+		t.Fatalf("md0 sync = %+v", md0.Sync)
+	}
+
+	if md2 := arrays[2]; md2.Level != "linear" || md2.Disks != 0 || md2.Degraded() || md2.Sync != nil {
+		t.Fatalf("md2 = %+v", md2)
+	}
+	if md127 := arrays[3]; md127.Active || md127.Level != "" || len(md127.Spares()) != 1 {
+		t.Fatalf("md127 = %+v", md127)
+	}
+
+	if got := Parse([]byte("Personalities : \nunused devices: <none>\n")); len(got) != 0 {
+		t.Fatalf("no arrays expected, got %+v", got)
+	}
+}
@@ -0,0 +1,92 @@
+// Package metrics renders values in the Prometheus text exposition format
+// without pulling in the full client library.
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the Content-Type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Label is a single name="value" pair attached to a sample.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// L is shorthand for Label{name, value}.
+func L(name, value string) Label { return Label{Name: name, Value: value} }
+
+// Writer accumulates metric families. Samples of the same family must be
+// written consecutively; HELP and TYPE are emitted before the first one.
+type Writer struct {
+	b    strings.Builder
+	seen map[string]bool
+}
+
+// Gauge writes a gauge sample.
+func (w *Writer) Gauge(name, help string, value float64, labels ...Label) {
+	w.sample(name, help, "gauge", value, labels)
+}
+
+// Counter writes a counter sample.
+func (w *Writer) Counter(name, help string, value float64, labels ...Label) {
+	w.sample(name, help, "counter", value, labels)
+}
+
+// Bool writes a gauge that is 1 when v is true and 0 otherwise.
+func (w *Writer) Bool(name, help string, v bool, labels ...Label) {
+	value := 0.0
+	if v {
+		value = 1
+	}
+	w.Gauge(name, help, value, labels...)
+}
+
+// String returns the rendered exposition.
+func (w *Writer) String() string { return w.b.String() }
+
+func (w *Writer) sample(name, help, kind string, value float64, labels []Label) {
+	if w.seen == nil {
+		w.seen = make(map[string]bool)
+	}
+	if !w.seen[name] {
+		w.seen[name] = true
+		w.b.WriteString("# HELP " + name + " " + strings.ReplaceAll(help, "\n", " ") + "\n")
+		w.b.WriteString("# TYPE " + name + " " + kind + "\n")
+	}
+	w.b.WriteString(name)
+	if len(labels) > 0 {
+		sorted := append([]Label(nil), labels...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+		w.b.WriteByte('{')
+		for i, l := range sorted {
+			if i > 0 {
+				w.b.WriteByte(',')
+			}
+			w.b.WriteString(l.Name + `="` + escapeLabel(l.Value) + `"`)
+		}
+		w.b.WriteByte('}')
+	}
+	w.b.WriteString(" " + formatValue(value) + "\n")
+}
+
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWriterRendersFamilies(t *testing.T) {
+	var w Writer
+	w.Gauge("nas_volume_used_percent", "Used space", 42.5, L("mount", "/mnt/data"))
+	w.Gauge("nas_volume_used_percent", "Used space", 10, L("mount", `/mnt/"odd"`))
+	w.Counter("nas_pings_total", "Pings", 3, L("result", "ok"), L("kind", "start"))
+	w.Bool("nas_up", "Up", true)
+	w.Gauge("nas_nan", "NaN value", math.NaN())
+
+	want := `# HELP nas_volume_used_percent Used space
+# TYPE nas_volume_used_percent gauge
+nas_volume_used_percent{mount="/mnt/data"} 42.5
+nas_volume_used_percent{mount="/mnt/\"odd\""} 10
+# HELP nas_pings_total Pings
+# TYPE nas_pings_total counter
+nas_pings_total{kind="start",result="ok"} 3
+# HELP nas_up Up
+# TYPE nas_up gauge
+nas_up 1
+# HELP nas_nan NaN value
+# TYPE nas_nan gauge
+nas_nan NaN
+`
+	if got := w.String(); got != want {
+		t.Fatalf("unexpected exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
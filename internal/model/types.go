@@ -36,6 +36,8 @@ type ContainerInfo struct {
 	Image   string
 	ID      string
 	Running bool
+	Health  string // healthcheck state: starting, healthy, unhealthy or ""
+	Labels  map[string]string
 }
 
 type DiskUsagePoint struct {
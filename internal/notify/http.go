@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var defaultHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+func httpClientOrDefault(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return defaultHTTPClient
+}
+
+// post sends body to url and treats any non-2xx status as an error.
+func post(ctx context.Context, client *http.Client, url, contentType string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClientOrDefault(client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
+
+// ═══════════════════════════════════════════════════════════════════
+//  GENERIC JSON WEBHOOK
+// ═══════════════════════════════════════════════════════════════════
+
+// Webhook POSTs a JSON document describing the alert to URL.
+type Webhook struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+type webhookPayload struct {
+	Topic string    `json:"topic"`
+	Title string    `json:"title"`
+	Text  string    `json:"text"`
+	Time  time.Time `json:"time"`
+}
+
+func (w *Webhook) Name() string { return "webhook" }
+
+func (w *Webhook) Notify(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(webhookPayload{
+		Topic: msg.Topic,
+		Title: msg.Subject(),
+		Text:  msg.PlainText(),
+		Time:  time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, w.Client, w.URL, "application/json", body, w.Headers)
+}
+
+// ═══════════════════════════════════════════════════════════════════
+//  NTFY
+// ═══════════════════════════════════════════════════════════════════
+
+// Ntfy publishes alerts to an ntfy topic (https://ntfy.sh or self-hosted).
+type Ntfy struct {
+	Server string
+	Topic  string
+	Token  string
+	Client *http.Client
+}
+
+func (n *Ntfy) Name() string { return "ntfy" }
+
+func (n *Ntfy) Notify(ctx context.Context, msg Message) error {
+	server := strings.TrimRight(n.Server, "/")
+	if server == "" {
+		server = "https://ntfy.sh"
+	}
+	headers := map[string]string{
+		"Title":    msg.Subject(),
+		"Priority": ntfyPriority(msg.Topic),
+		"Tags":     msg.Topic,
+	}
+	if n.Token != "" {
+		headers["Authorization"] = "Bearer " + n.Token
+	}
+	return post(ctx, n.Client, server+"/"+url.PathEscape(n.Topic), "text/plain; charset=utf-8", []byte(msg.Body()), headers)
+}
+
+func ntfyPriority(topic string) string {
+	switch topic {
+	case TopicCritical:
+		return "urgent"
+	case TopicWarning:
+		return "high"
+	case TopicReport:
+		return "low"
+	}
+	return "default"
+}
+
+// ═══════════════════════════════════════════════════════════════════
+//  GOTIFY
+// ═══════════════════════════════════════════════════════════════════
+
+// Gotify pushes alerts to a Gotify server using an application token.
+type Gotify struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+type gotifyPayload struct {
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority"`
+}
+
+func (g *Gotify) Name() string { return "gotify" }
+
+func (g *Gotify) Notify(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(gotifyPayload{
+		Title:    msg.Subject(),
+		Message:  msg.Body(),
+		Priority: gotifyPriority(msg.Topic),
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimRight(g.URL, "/") + "/message"
+	return post(ctx, g.Client, endpoint, "application/json", body, map[string]string{"X-Gotify-Key": g.Token})
+}
+
+func gotifyPriority(topic string) int {
+	switch topic {
+	case TopicCritical:
+		return 8
+	case TopicWarning:
+		return 5
+	case TopicReport:
+		return 1
+	}
+	return 3
+}
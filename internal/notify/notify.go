@@ -0,0 +1,93 @@
+// Package notify delivers alerts to Telegram and to external channels
+// (JSON webhooks, ntfy, Gotify and e-mail).
+package notify
+
+import (
+	"context"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Alert topics, mirrored from the users' subscription topics.
+const (
+	TopicCritical = "critical"
+	TopicWarning  = "warning"
+	TopicInfo     = "info"
+	TopicReport   = "report"
+)
+
+// Message is a channel-agnostic alert. Text uses Telegram Markdown; channels
+// that cannot render it should use PlainText.
+type Message struct {
+	Topic    string
+	Title    string
+	Text     string
+	Markdown bool
+	// Keyboard is only rendered by the Telegram notifier.
+	Keyboard *tgbotapi.InlineKeyboardMarkup
+}
+
+// Notifier is a single delivery channel.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Route binds a notifier to the topics it should receive. An empty topic
+// list accepts everything.
+type Route struct {
+	Notifier Notifier
+	Topics   []string
+}
+
+// Accepts reports whether the route should deliver messages for topic.
+func (r Route) Accepts(topic string) bool {
+	if len(r.Topics) == 0 {
+		return true
+	}
+	for _, t := range r.Topics {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == topic || t == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// PlainText returns the message text with bold and code markers removed.
+// Underscores are kept since they are common in container and file names.
+func (m Message) PlainText() string {
+	if !m.Markdown {
+		return m.Text
+	}
+	return strings.NewReplacer("*", "", "`", "").Replace(m.Text)
+}
+
+// Subject returns the title, or the first line of the text when no title
+// was set.
+func (m Message) Subject() string {
+	if m.Title != "" {
+		return m.Title
+	}
+	text := strings.TrimSpace(m.PlainText())
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	if text == "" {
+		return "NASBot alert"
+	}
+	return text
+}
+
+// Body returns the plain text without the line used as subject.
+func (m Message) Body() string {
+	text := strings.TrimSpace(m.PlainText())
+	if m.Title != "" {
+		return text
+	}
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		return strings.TrimSpace(text[i+1:])
+	}
+	return text
+}
@@ -0,0 +1,282 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var testMsg = Message{
+	Topic:    TopicCritical,
+	Text:     "🚨 *Critical*\n\nDisk `/mnt/data` at 97%",
+	Markdown: true,
+}
+
+func TestMessagePlainTextAndSubject(t *testing.T) {
+	if got := testMsg.Subject(); got != "🚨 Critical" {
+		t.Fatalf("Subject() = %q", got)
+	}
+	if got := testMsg.Body(); got != "Disk /mnt/data at 97%" {
+		t.Fatalf("Body() = %q", got)
+	}
+	titled := Message{Title: "RAID", Text: "md0 degraded"}
+	if titled.Subject() != "RAID" || titled.Body() != "md0 degraded" {
+		t.Fatalf("titled message = %q / %q", titled.Subject(), titled.Body())
+	}
+}
+
+func TestRouteAccepts(t *testing.T) {
+	if !(Route{}).Accepts(TopicInfo) {
+		t.Fatalf("empty topic list should accept everything")
+	}
+	r := Route{Topics: []string{"Critical"}}
+	if !r.Accepts(TopicCritical) || r.Accepts(TopicWarning) {
+		t.Fatalf("route topic filtering is wrong")
+	}
+}
+
+type fakeSender struct {
+	sent []tgbotapi.MessageConfig
+	// rejectMarkdown fails Markdown messages, as Telegram does when the
+	// entities cannot be parsed.
+	rejectMarkdown bool
+}
+
+func (f *fakeSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	m := c.(tgbotapi.MessageConfig)
+	f.sent = append(f.sent, m)
+	if f.rejectMarkdown && m.ParseMode != "" {
+		return tgbotapi.Message{}, errors.New("Bad Request: can't parse entities")
+	}
+	return tgbotapi.Message{}, nil
+}
+
+func TestTelegramNotify(t *testing.T) {
+	bot := &fakeSender{}
+	kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("x", "y")))
+	msg := testMsg
+	msg.Keyboard = &kb
+	n := &Telegram{Bot: bot, Recipients: func(topic string) []int64 {
+		if topic != TopicCritical {
+			t.Fatalf("unexpected topic %q", topic)
+		}
+		return []int64{1, 2}
+	}}
+
+	if err := n.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(bot.sent) != 2 || bot.sent[1].ChatID != 2 || bot.sent[0].ParseMode != "Markdown" {
+		t.Fatalf("unexpected telegram messages: %+v", bot.sent)
+	}
+	if _, ok := bot.sent[0].ReplyMarkup.(tgbotapi.InlineKeyboardMarkup); !ok {
+		t.Fatalf("keyboard not attached")
+	}
+}
+
+func TestTelegramPlainTextRetry(t *testing.T) {
+	bot := &fakeSender{rejectMarkdown: true}
+	n := &Telegram{Bot: bot, Recipients: func(string) []int64 { return []int64{1} }}
+	msg := Message{Topic: TopicInfo, Text: "✅ *Resolved:* home_assistant CPU\n_Lasted 5m_", Markdown: true}
+
+	if err := n.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(bot.sent) != 2 || bot.sent[1].ParseMode != "" || bot.sent[1].Text != msg.Text {
+		t.Fatalf("expected a plain-text retry: %+v", bot.sent)
+	}
+}
+
+func TestWebhookNotify(t *testing.T) {
+	var got webhookPayload
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	n := &Webhook{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer abc"}, Client: srv.Client()}
+	if err := n.Notify(context.Background(), testMsg); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got.Topic != TopicCritical || got.Title != "🚨 Critical" || strings.Contains(got.Text, "*") {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+	if auth != "Bearer abc" {
+		t.Fatalf("custom header not forwarded: %q", auth)
+	}
+}
+
+func TestWebhookNotifyHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	n := &Webhook{URL: srv.URL, Client: srv.Client()}
+	err := n.Notify(context.Background(), testMsg)
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("expected HTTP 502 error, got %v", err)
+	}
+}
+
+func TestNtfyNotify(t *testing.T) {
+	var path, title, priority, auth, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		title = r.Header.Get("Title")
+		priority = r.Header.Get("Priority")
+		auth = r.Header.Get("Authorization")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer srv.Close()
+
+	n := &Ntfy{Server: srv.URL + "/", Topic: "nas-alerts", Token: "tk", Client: srv.Client()}
+	if err := n.Notify(context.Background(), testMsg); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if path != "/nas-alerts" || title != "🚨 Critical" || priority != "urgent" || auth != "Bearer tk" {
+		t.Fatalf("unexpected ntfy request: path=%q title=%q priority=%q auth=%q", path, title, priority, auth)
+	}
+	if body != "Disk /mnt/data at 97%" {
+		t.Fatalf("unexpected ntfy body %q", body)
+	}
+}
+
+func TestGotifyNotify(t *testing.T) {
+	var got gotifyPayload
+	var path, key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		key = r.Header.Get("X-Gotify-Key")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	n := &Gotify{URL: srv.URL, Token: "app-token", Client: srv.Client()}
+	if err := n.Notify(context.Background(), testMsg); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if path != "/message" || key != "app-token" {
+		t.Fatalf("unexpected gotify request: path=%q key=%q", path, key)
+	}
+	if got.Priority != 8 || got.Title != "🚨 Critical" {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+}
+
+// fakeSMTPServer accepts a single message and records the envelope and data.
+type fakeSMTPServer struct {
+	ln   net.Listener
+	wg   sync.WaitGroup
+	from string
+	to   []string
+	data string
+}
+
+func startFakeSMTP(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeSMTPServer{ln: ln}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer s.wg.Done()
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	write := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	write("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			write("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			write("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.to = append(s.to, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			write("250 OK")
+		case cmd == "DATA":
+			write("354 End data with <CR><LF>.<CR><LF>")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			s.data = b.String()
+			write("250 OK")
+		case cmd == "QUIT":
+			write("221 Bye")
+			return
+		default:
+			write("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotify(t *testing.T) {
+	srv := startFakeSMTP(t)
+	defer srv.ln.Close()
+
+	host, portStr, _ := net.SplitHostPort(srv.ln.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	n := &SMTP{Host: host, Port: port, From: "nas@example.com", To: []string{"ops@example.com"}}
+
+	if err := n.Notify(context.Background(), testMsg); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	srv.wg.Wait()
+
+	if srv.from != "nas@example.com" || len(srv.to) != 1 || srv.to[0] != "ops@example.com" {
+		t.Fatalf("unexpected envelope: from=%q to=%v", srv.from, srv.to)
+	}
+	if !strings.Contains(srv.data, "Subject: 🚨 Critical\r\n") || !strings.Contains(srv.data, "Disk /mnt/data at 97%") {
+		t.Fatalf("unexpected message data:\n%s", srv.data)
+	}
+}
+
+func TestSMTPNotifyNoRecipients(t *testing.T) {
+	n := &SMTP{Host: "127.0.0.1", From: "nas@example.com"}
+	if err := n.Notify(context.Background(), testMsg); err == nil {
+		t.Fatalf("expected error without recipients")
+	}
+}
@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Sender is the subset of the Telegram bot API used to deliver alerts.
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+}
+
+// Telegram sends alerts to every chat returned by Recipients for the topic.
+type Telegram struct {
+	Bot        Sender
+	Recipients func(topic string) []int64
+}
+
+func (t *Telegram) Name() string { return "telegram" }
+
+func (t *Telegram) Notify(_ context.Context, msg Message) error {
+	if t.Bot == nil || t.Recipients == nil {
+		return nil
+	}
+	var errs []error
+	for _, chatID := range t.Recipients(msg.Topic) {
+		m := tgbotapi.NewMessage(chatID, msg.Text)
+		if msg.Markdown {
+			m.ParseMode = "Markdown"
+		}
+		if msg.Keyboard != nil {
+			m.ReplyMarkup = *msg.Keyboard
+		}
+		_, err := t.Bot.Send(m)
+		if err != nil && m.ParseMode != "" {
+			// Text from container names or logs can break the Markdown;
+			// the alert still goes out, as plain text.
+			m.ParseMode = ""
+			_, err = t.Bot.Send(m)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("chat %d: %w", chatID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
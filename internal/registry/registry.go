@@ -0,0 +1,226 @@
+// Package registry resolves image tags to the manifest digest their
+// registry currently serves, through the OCI distribution API.
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrPinned is returned for references that name a digest: they cannot
+// become outdated.
+var ErrPinned = errors.New("registry: reference is pinned to a digest")
+
+// Client resolves image references to manifest digests.
+type Client interface {
+	// Digest returns the "sha256:..." digest ref resolves to on its registry.
+	Digest(ctx context.Context, ref string) (string, error)
+}
+
+// Accepted manifest types; indexes come first so multi-arch images
+// resolve to the same digest docker records on pull.
+var manifestTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// Reference is an image reference split into its parts.
+type Reference struct {
+	Host       string // registry host, e.g. "registry-1.docker.io"
+	Repository string // e.g. "library/nginx"
+	Tag        string
+}
+
+// ParseReference parses ref the way docker does: a first component with a
+// dot, a port or "localhost" is the registry, anything else is on Docker
+// Hub, where official images live under "library/".
+func ParseReference(ref string) (Reference, error) {
+	if strings.Contains(ref, "@") || strings.HasPrefix(ref, "sha256:") {
+		return Reference{}, ErrPinned
+	}
+	name, tag := ref, "latest"
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		name, tag = ref[:i], ref[i+1:]
+	}
+	if name == "" || tag == "" {
+		return Reference{}, fmt.Errorf("registry: invalid reference %q", ref)
+	}
+
+	host := "docker.io"
+	if first, rest, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		host, name = first, rest
+	}
+	if host == "docker.io" || host == "index.docker.io" {
+		host = "registry-1.docker.io"
+		if !strings.Contains(name, "/") {
+			name = "library/" + name
+		}
+	}
+	return Reference{Host: host, Repository: name, Tag: tag}, nil
+}
+
+// HTTPClient queries registries over HTTPS, obtaining anonymous bearer
+// tokens when the registry asks for them.
+type HTTPClient struct {
+	HTTP *http.Client
+	// Insecure lists registry hosts reached over plain HTTP.
+	Insecure map[string]bool
+}
+
+// New returns a client; insecure registries are given as "host:port".
+func New(insecure []string) *HTTPClient {
+	c := &HTTPClient{HTTP: &http.Client{Timeout: 30 * time.Second}, Insecure: make(map[string]bool)}
+	for _, h := range insecure {
+		if h = strings.TrimSpace(h); h != "" {
+			c.Insecure[h] = true
+		}
+	}
+	return c
+}
+
+// Digest implements Client.
+func (c *HTTPClient) Digest(ctx context.Context, ref string) (string, error) {
+	r, err := ParseReference(ref)
+	if err != nil {
+		return "", err
+	}
+	scheme := "https"
+	if c.Insecure[r.Host] {
+		scheme = "http"
+	}
+	manifest := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, r.Host, r.Repository, r.Tag)
+
+	var token string
+	resp, err := c.manifest(ctx, http.MethodHead, manifest, token)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		if token, err = c.token(ctx, resp.Header.Get("WWW-Authenticate")); err != nil {
+			return "", err
+		}
+		if resp, err = c.manifest(ctx, http.MethodHead, manifest, token); err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry: %s: %s", ref, resp.Status)
+	}
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+	return c.digestFromBody(ctx, manifest, token)
+}
+
+func (c *HTTPClient) manifest(ctx context.Context, method, u, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.HTTP.Do(req)
+}
+
+// digestFromBody hashes the manifest for registries that do not send
+// Docker-Content-Digest.
+func (c *HTTPClient) digestFromBody(ctx context.Context, u, token string) (string, error) {
+	resp, err := c.manifest(ctx, http.MethodGet, u, token)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry: %s: %s", u, resp.Status)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// token fetches an anonymous token from the realm of a Bearer challenge.
+func (c *HTTPClient) token(ctx context.Context, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("registry: unsupported auth challenge %q", challenge)
+	}
+	p := parseChallenge(params)
+	if p["realm"] == "" {
+		return "", fmt.Errorf("registry: auth challenge without realm")
+	}
+	u, err := url.Parse(p["realm"])
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for _, k := range []string{"service", "scope"} {
+		if p[k] != "" {
+			q.Set(k, p[k])
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry: token: %s", resp.Status)
+	}
+	var t struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", err
+	}
+	if t.Token == "" {
+		t.Token = t.AccessToken
+	}
+	return t.Token, nil
+}
+
+// parseChallenge parses the key="value" list of a WWW-Authenticate header.
+func parseChallenge(s string) map[string]string {
+	out := make(map[string]string)
+	for s != "" {
+		key, rest, ok := strings.Cut(strings.TrimLeft(s, " ,"), "=")
+		if !ok {
+			break
+		}
+		var val string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				break
+			}
+			val, s = rest[1:end+1], rest[end+2:]
+		} else {
+			val, s, _ = strings.Cut(rest, ",")
+		}
+		out[strings.ToLower(strings.TrimSpace(key))] = val
+	}
+	return out
+}
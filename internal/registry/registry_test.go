@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	for ref, want := range map[string]Reference{
+		"nginx":                        {"registry-1.docker.io", "library/nginx", "latest"},
+		"linuxserver/sonarr:4":         {"registry-1.docker.io", "linuxserver/sonarr", "4"},
+		"ghcr.io/home-assistant/ha:1":  {"ghcr.io", "home-assistant/ha", "1"},
+		"localhost:5000/app":           {"localhost:5000", "app", "latest"},
+		"registry.lan:5000/tools/x:v2": {"registry.lan:5000", "tools/x", "v2"},
+	} {
+		if got, err := ParseReference(ref); err != nil || got != want {
+			t.Errorf("ParseReference(%q) = %+v, %v", ref, got, err)
+		}
+	}
+	if _, err := ParseReference("nginx@sha256:abc"); !errors.Is(err, ErrPinned) {
+		t.Errorf("digest reference should be pinned, got %v", err)
+	}
+}
+
+func TestDigestWithToken(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.URL.Query().Get("scope") != "repository:tools/app:pull" {
+				http.Error(w, "bad scope", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"token":"t0k"}`)
+		case "/v2/tools/app/manifests/1.0":
+			if r.Header.Get("Authorization") != "Bearer t0k" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:tools/app:pull"`, srv.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !strings.Contains(r.Header.Get("Accept"), "manifest.list.v2+json") {
+				http.Error(w, "index not accepted", http.StatusNotAcceptable)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:feed")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	c := New([]string{host})
+	digest, err := c.Digest(context.Background(), host+"/tools/app:1.0")
+	if err != nil || digest != "sha256:feed" {
+		t.Fatalf("Digest = %q, %v", digest, err)
+	}
+	if _, err := c.Digest(context.Background(), host+"/tools/app:2.0"); err == nil {
+		t.Fatalf("missing tag should fail")
+	}
+}
+
+func TestDigestFromBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "{}")
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	digest, err := New([]string{host}).Digest(context.Background(), host+"/app")
+	// sha256 of "{}"
+	if err != nil || digest != "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a" {
+		t.Fatalf("Digest = %q, %v", digest, err)
+	}
+}
@@ -0,0 +1,63 @@
+package smart
+
+// Self-test kinds, as passed to `smartctl -t`.
+const (
+	TestShort = "short"
+	TestLong  = "long"
+)
+
+// SelfTest is one entry of a disk's self-test log. A test that neither
+// passed nor failed was aborted or interrupted.
+type SelfTest struct {
+	Type   string // "Short offline", "Extended offline", "Short"...
+	Status string
+	Passed bool
+	Failed bool
+	Hours  int64 // power-on hours when the test ended
+}
+
+// Running reports whether a self-test is in progress.
+func (r Report) Running() bool { return r.SelfTestRemaining >= 0 }
+
+// nvmeFailed are the NVMe self-test results that mean the test found a
+// fault; the other non-zero results are aborts.
+var nvmeFailed = map[int]bool{5: true, 6: true, 7: true}
+
+func parseSelfTests(raw *rawReport, r *Report) {
+	if raw.ATAData != nil {
+		// The high nibble 0xF of the status means "in progress".
+		if st := raw.ATAData.SelfTest.Status; st.Value>>4 == 0xF {
+			r.SelfTestRemaining = st.RemainingPercent
+		}
+	}
+	if raw.ATASelfTestLog != nil {
+		for _, e := range raw.ATASelfTestLog.Standard.Table {
+			t := SelfTest{Type: e.Type.String, Status: e.Status.String, Hours: e.LifetimeHours}
+			if e.Status.Passed != nil {
+				t.Passed = *e.Status.Passed
+				t.Failed = !*e.Status.Passed
+			}
+			r.SelfTests = append(r.SelfTests, t)
+		}
+	}
+
+	n := raw.NVMeSelfTestLog
+	if n == nil {
+		return
+	}
+	if n.Current.Value != 0 {
+		r.SelfTestRemaining = 100 - n.CompletionPercent
+	}
+	for _, e := range n.Table {
+		if e.Result.Value == 0xF { // unused entry
+			continue
+		}
+		r.SelfTests = append(r.SelfTests, SelfTest{
+			Type:   e.Code.String,
+			Status: e.Result.String,
+			Passed: e.Result.Value == 0,
+			Failed: nvmeFailed[e.Result.Value],
+			Hours:  e.PowerOnHours,
+		})
+	}
+}
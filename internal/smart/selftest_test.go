@@ -0,0 +1,60 @@
+package smart
+
+import "testing"
+
+const ataSelfTests = `{
+  "device": {"protocol": "ATA"},
+  "smart_status": {"passed": true},
+  "ata_smart_data": {"self_test": {"status": {"value": 249, "string": "in progress, 90% remaining", "remaining_percent": 90}}},
+  "ata_smart_self_test_log": {"standard": {"table": [
+    {"type": {"value": 2, "string": "Extended offline"}, "status": {"value": 121, "string": "Completed: read failure", "passed": false}, "lifetime_hours": 36010},
+    {"type": {"value": 1, "string": "Short offline"}, "status": {"value": 33, "string": "Interrupted (host reset)"}, "lifetime_hours": 36000},
+    {"type": {"value": 1, "string": "Short offline"}, "status": {"value": 0, "string": "Completed without error", "passed": true}, "lifetime_hours": 35990}
+  ]}}
+}`
+
+const nvmeSelfTests = `{
+  "device": {"protocol": "NVMe"},
+  "smart_status": {"passed": true},
+  "nvme_self_test_log": {
+    "current_self_test_operation": {"value": 0, "string": "No self-test in progress"},
+    "table": [
+      {"self_test_code": {"value": 1, "string": "Short"}, "self_test_result": {"value": 0, "string": "Completed without error"}, "power_on_hours": 4242},
+      {"self_test_code": {"value": 2, "string": "Extended"}, "self_test_result": {"value": 7, "string": "Completed: failed segments"}, "power_on_hours": 4200}
+    ]
+  }
+}`
+
+func TestParseSelfTests(t *testing.T) {
+	r, err := Parse([]byte(ataSelfTests))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !r.Running() || r.SelfTestRemaining != 90 {
+		t.Fatalf("running test not detected: %d", r.SelfTestRemaining)
+	}
+	if len(r.SelfTests) != 3 {
+		t.Fatalf("self-tests = %+v", r.SelfTests)
+	}
+	if got := r.SelfTests[0]; !got.Failed || got.Passed || got.Type != "Extended offline" || got.Hours != 36010 {
+		t.Fatalf("failed test = %+v", got)
+	}
+	if got := r.SelfTests[1]; got.Failed || got.Passed {
+		t.Fatalf("interrupted test = %+v", got)
+	}
+	if !r.SelfTests[2].Passed {
+		t.Fatalf("passed test = %+v", r.SelfTests[2])
+	}
+
+	r, err = Parse([]byte(nvmeSelfTests))
+	if err != nil || r.Running() || len(r.SelfTests) != 2 {
+		t.Fatalf("Parse(nvme) = %+v, %v", r, err)
+	}
+	if !r.SelfTests[0].Passed || !r.SelfTests[1].Failed {
+		t.Fatalf("nvme self-tests = %+v", r.SelfTests)
+	}
+
+	if r, _ := Parse([]byte(ataHDD)); r.Running() || r.SelfTests != nil {
+		t.Fatalf("report without a log: %+v", r)
+	}
+}
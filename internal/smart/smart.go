@@ -0,0 +1,250 @@
+// Package smart reads disk health from the JSON output of smartctl
+// (smartmontools 7.0+): overall status, temperature, wear and the error
+// counters that grow long before a disk reports itself as failing.
+package smart
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Counters tracked by Report.Counters. They only ever grow, so any
+// increase is worth an alert.
+const (
+	Reallocated   = "reallocated_sectors"
+	Pending       = "pending_sectors"
+	Uncorrectable = "uncorrectable_sectors"
+	CRCErrors     = "crc_errors"
+	MediaErrors   = "media_errors" // NVMe
+)
+
+// CounterNames lists the counters in display order.
+var CounterNames = []string{Reallocated, Pending, Uncorrectable, CRCErrors, MediaErrors}
+
+// Health values, as shown since before the JSON parser.
+const (
+	HealthPassed  = "PASSED"
+	HealthFailed  = "FAILED!"
+	HealthUnknown = "UNKNOWN"
+	// HealthStandby is reported for a spun-down disk, which smartctl
+	// leaves alone instead of waking it.
+	HealthStandby = "STANDBY"
+)
+
+// ataCounters maps ATA attribute IDs to counters.
+var ataCounters = map[int]string{
+	5:   Reallocated,
+	197: Pending,
+	198: Uncorrectable,
+	199: CRCErrors,
+}
+
+// ataStartStops is Start_Stop_Count, the number of spin-ups.
+const ataStartStops = 4
+
+// ataWear are the ATA attributes whose normalized value is the life left
+// in percent: Wear_Leveling_Count, SSD_Life_Left, Media_Wearout_Indicator
+// and Percent_Lifetime_Remain.
+var ataWear = []int{177, 231, 233, 202}
+
+// Report is what one smartctl run says about a disk. Unknown numbers
+// are -1; Counters holds only those the disk reports.
+type Report struct {
+	Model        string
+	Serial       string
+	Protocol     string // ATA, NVMe or SCSI
+	Health       string
+	Temp         int
+	PowerOnHours int64
+	PercentUsed  int // SSD wear
+	Counters     map[string]int64
+	// SelfTests is the disk's self-test log, newest first.
+	SelfTests []SelfTest
+	// SelfTestRemaining is the percentage left of the self-test in
+	// progress, or -1 when none is running.
+	SelfTestRemaining int
+	// StartStops is the spindle Start_Stop_Count of an HDD.
+	StartStops int64
+	// Standby is set when the disk was spun down and left alone: the
+	// report then holds nothing else.
+	Standby bool
+}
+
+type rawReport struct {
+	Smartctl struct {
+		ExitStatus int `json:"exit_status"`
+		Messages   []struct {
+			String   string `json:"string"`
+			Severity string `json:"severity"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	Device struct {
+		Protocol string `json:"protocol"`
+	} `json:"device"`
+	ModelName    string `json:"model_name"`
+	SerialNumber string `json:"serial_number"`
+	SmartStatus  *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature *struct {
+		Current int `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime *struct {
+		Hours int64 `json:"hours"`
+	} `json:"power_on_time"`
+	ATAAttributes *struct {
+		Table []struct {
+			ID    int    `json:"id"`
+			Name  string `json:"name"`
+			Value int    `json:"value"`
+			Raw   struct {
+				Value int64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeLog *struct {
+		PercentageUsed int   `json:"percentage_used"`
+		MediaErrors    int64 `json:"media_errors"`
+		PowerOnHours   int64 `json:"power_on_hours"`
+		Temperature    int   `json:"temperature"`
+	} `json:"nvme_smart_health_information_log"`
+	SCSIGrownDefects *int64 `json:"scsi_grown_defect_list"`
+	ATAData          *struct {
+		SelfTest struct {
+			Status rawStatus `json:"status"`
+		} `json:"self_test"`
+	} `json:"ata_smart_data"`
+	ATASelfTestLog *struct {
+		Standard struct {
+			Table []struct {
+				Type          rawStatus `json:"type"`
+				Status        rawStatus `json:"status"`
+				LifetimeHours int64     `json:"lifetime_hours"`
+			} `json:"table"`
+		} `json:"standard"`
+	} `json:"ata_smart_self_test_log"`
+	NVMeSelfTestLog *struct {
+		Current           rawStatus `json:"current_self_test_operation"`
+		CompletionPercent int       `json:"current_self_test_completion_percent"`
+		Table             []struct {
+			Code         rawStatus `json:"self_test_code"`
+			Result       rawStatus `json:"self_test_result"`
+			PowerOnHours int64     `json:"power_on_hours"`
+		} `json:"table"`
+	} `json:"nvme_self_test_log"`
+}
+
+type rawStatus struct {
+	Value            int    `json:"value"`
+	String           string `json:"string"`
+	Passed           *bool  `json:"passed"`
+	RemainingPercent int    `json:"remaining_percent"`
+}
+
+// Parse reads `smartctl --json -a` output. smartctl sets bits of its exit
+// status for conditions such as past errors in the log, so the output is
+// parsed whatever the exit status; an error is returned only when it
+// holds no device data. A disk that `-n standby` left asleep gives a
+// Standby Report with no readings.
+func Parse(data []byte) (Report, error) {
+	var raw rawReport
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Report{}, fmt.Errorf("smartctl output: %w", err)
+	}
+	r := Report{
+		Model:             raw.ModelName,
+		Serial:            raw.SerialNumber,
+		Protocol:          raw.Device.Protocol,
+		Health:            HealthUnknown,
+		Temp:              -1,
+		PowerOnHours:      -1,
+		PercentUsed:       -1,
+		Counters:          make(map[string]int64),
+		SelfTestRemaining: -1,
+		StartStops:        -1,
+	}
+	for _, m := range raw.Smartctl.Messages {
+		// "Device is in STANDBY mode, exit(2)", printed for -n standby
+		if strings.HasPrefix(m.String, "Device is in ") && (strings.Contains(m.String, "STANDBY") || strings.Contains(m.String, "SLEEP")) {
+			r.Health = HealthStandby
+			r.Standby = true
+			return r, nil
+		}
+	}
+	if raw.SmartStatus != nil {
+		r.Health = HealthPassed
+		if !raw.SmartStatus.Passed {
+			r.Health = HealthFailed
+		}
+	}
+	if raw.Temperature != nil && raw.Temperature.Current > 0 {
+		r.Temp = raw.Temperature.Current
+	}
+	if raw.PowerOnTime != nil {
+		r.PowerOnHours = raw.PowerOnTime.Hours
+	}
+
+	if raw.ATAAttributes != nil {
+		byID := make(map[int]int, len(raw.ATAAttributes.Table))
+		for _, a := range raw.ATAAttributes.Table {
+			byID[a.ID] = a.Value
+			if a.ID == ataStartStops {
+				r.StartStops = a.Raw.Value & 0xFFFFFFFF
+			}
+			if name, ok := ataCounters[a.ID]; ok {
+				// Some vendors pack extra data in the upper bytes.
+				r.Counters[name] = a.Raw.Value & 0xFFFFFFFF
+			}
+		}
+		for _, id := range ataWear {
+			if v, ok := byID[id]; ok && v > 0 && v <= 100 {
+				r.PercentUsed = 100 - v
+				break
+			}
+		}
+	}
+	if n := raw.NVMeLog; n != nil {
+		r.PercentUsed = n.PercentageUsed
+		r.Counters[MediaErrors] = n.MediaErrors
+		if r.PowerOnHours < 0 {
+			r.PowerOnHours = n.PowerOnHours
+		}
+		if r.Temp < 0 && n.Temperature > 0 {
+			r.Temp = n.Temperature
+		}
+	}
+	if raw.SCSIGrownDefects != nil {
+		r.Counters[Reallocated] = *raw.SCSIGrownDefects
+	}
+	parseSelfTests(&raw, &r)
+
+	if r.Protocol == "" && raw.SmartStatus == nil && len(r.Counters) == 0 {
+		var msgs []string
+		for _, m := range raw.Smartctl.Messages {
+			msgs = append(msgs, m.String)
+		}
+		if len(msgs) > 0 {
+			return r, errors.New(strings.Join(msgs, "; "))
+		}
+		return r, fmt.Errorf("smartctl exit status %d without device data", raw.Smartctl.ExitStatus)
+	}
+	return r, nil
+}
+
+// Increases returns, for every counter of cur above its value in prev,
+// the previous value. Counters prev does not know are not increases:
+// there is nothing to compare with yet.
+func Increases(prev, cur map[string]int64) map[string]int64 {
+	var out map[string]int64
+	for name, v := range cur {
+		if p, ok := prev[name]; ok && v > p {
+			if out == nil {
+				out = make(map[string]int64)
+			}
+			out[name] = p
+		}
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+package smart
+
+import (
+	"fmt"
+	"testing"
+)
+
+const ataHDD = `{
+  "smartctl": {"version": [7, 3], "exit_status": 64},
+  "device": {"name": "/dev/sda", "type": "sat", "protocol": "ATA"},
+  "model_name": "WDC WD40EFRX-68N32N0",
+  "serial_number": "WD-WCC7K1234567",
+  "smart_status": {"passed": true},
+  "ata_smart_attributes": {"table": [
+    {"id": 1, "name": "Raw_Read_Error_Rate", "value": 200, "raw": {"value": 0}},
+    {"id": 4, "name": "Start_Stop_Count", "value": 99, "raw": {"value": 1523}},
+    {"id": 5, "name": "Reallocated_Sector_Ct", "value": 200, "raw": {"value": 8}},
+    {"id": 9, "name": "Power_On_Hours", "value": 51, "raw": {"value": 36012}},
+    {"id": 197, "name": "Current_Pending_Sector", "value": 200, "raw": {"value": 2}},
+    {"id": 198, "name": "Offline_Uncorrectable", "value": 100, "raw": {"value": 0}},
+    {"id": 199, "name": "UDMA_CRC_Error_Count", "value": 200, "raw": {"value": 4294967299}}
+  ]},
+  "power_on_time": {"hours": 36012},
+  "temperature": {"current": 34}
+}`
+
+const ataSSD = `{
+  "smartctl": {"exit_status": 0},
+  "device": {"protocol": "ATA"},
+  "model_name": "Samsung SSD 860 EVO 1TB",
+  "smart_status": {"passed": true},
+  "ata_smart_attributes": {"table": [
+    {"id": 5, "name": "Reallocated_Sector_Ct", "value": 100, "raw": {"value": 0}},
+    {"id": 177, "name": "Wear_Leveling_Count", "value": 93, "raw": {"value": 61}}
+  ]},
+  "power_on_time": {"hours": 20000},
+  "temperature": {"current": 29}
+}`
+
+const nvme = `{
+  "smartctl": {"exit_status": 0},
+  "device": {"protocol": "NVMe"},
+  "model_name": "Samsung SSD 980 PRO 1TB",
+  "smart_status": {"passed": false},
+  "nvme_smart_health_information_log": {
+    "critical_warning": 4, "temperature": 41, "percentage_used": 12,
+    "power_on_hours": 4242, "media_errors": 3
+  }
+}`
+
+const standby = `{
+  "smartctl": {"exit_status": 2, "messages": [{"string": "Device is in STANDBY mode, exit(2)", "severity": "information"}]},
+  "device": {"name": "/dev/sdb", "type": "sat", "protocol": "ATA"}
+}`
+
+const noDevice = `{
+  "smartctl": {"exit_status": 2, "messages": [{"string": "Smartctl open device: /dev/sdz failed: No such device", "severity": "error"}]}
+}`
+
+func TestParse(t *testing.T) {
+	r, err := Parse([]byte(ataHDD))
+	if err != nil {
+		t.Fatalf("Parse(ataHDD): %v", err)
+	}
+	if r.Health != HealthPassed || r.Temp != 34 || r.PowerOnHours != 36012 || r.PercentUsed != -1 || r.Serial != "WD-WCC7K1234567" || r.StartStops != 1523 || r.Standby {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	want := map[string]int64{Reallocated: 8, Pending: 2, Uncorrectable: 0, CRCErrors: 3}
+	if fmt.Sprint(r.Counters) != fmt.Sprint(want) {
+		t.Fatalf("counters = %v, want %v", r.Counters, want)
+	}
+
+	r, err = Parse([]byte(ataSSD))
+	if err != nil || r.PercentUsed != 7 || r.Counters[Reallocated] != 0 {
+		t.Fatalf("Parse(ataSSD) = %+v, %v", r, err)
+	}
+
+	r, err = Parse([]byte(nvme))
+	if err != nil || r.Health != HealthFailed || r.Temp != 41 || r.PercentUsed != 12 || r.PowerOnHours != 4242 || r.Counters[MediaErrors] != 3 {
+		t.Fatalf("Parse(nvme) = %+v, %v", r, err)
+	}
+
+	r, err = Parse([]byte(standby))
+	if err != nil || !r.Standby || r.Health != HealthStandby || r.Temp != -1 || len(r.Counters) != 0 {
+		t.Fatalf("Parse(standby) = %+v, %v", r, err)
+	}
+
+	if _, err := Parse([]byte(noDevice)); err == nil || err.Error() != "Smartctl open device: /dev/sdz failed: No such device" {
+		t.Fatalf("expected the smartctl message, got %v", err)
+	}
+	if _, err := Parse([]byte("smartctl 6.6 does not know --json")); err == nil {
+		t.Fatalf("expected an error for text output")
+	}
+}
+
+func TestIncreases(t *testing.T) {
+	prev := map[string]int64{Reallocated: 8, Pending: 2, CRCErrors: 3}
+	cur := map[string]int64{Reallocated: 10, Pending: 0, CRCErrors: 3, MediaErrors: 5}
+	if got := Increases(prev, cur); fmt.Sprint(got) != fmt.Sprint(map[string]int64{Reallocated: 8}) {
+		t.Fatalf("Increases = %v", got)
+	}
+	if got := Increases(nil, cur); got != nil {
+		t.Fatalf("first reading reported increases: %v", got)
+	}
+}
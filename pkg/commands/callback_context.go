@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"log/slog"
 	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -52,15 +53,25 @@ func (r *CallbackRegistry) Execute(ctx *AppContext, bot BotAPI, query *tgbotapi.
 	msgID := query.Message.MessageID
 	data := query.Data
 
+	var userID int64
+	if query.From != nil {
+		userID = query.From.ID
+	}
+	if !CanRunCallback(ctx, userID, data) {
+		slog.Warn("Callback denied", "data", data, "user", userID)
+		sendPermissionDenied(ctx, bot, chatID)
+		return true
+	}
+
 	// 1. Try exact matches
 	if handler, ok := r.exactMatches[data]; ok {
 		return handler.Handle(ctx, bot, chatID, msgID, query, data)
 	}
 
-	// 2. Try prefix matches
-	for prefix, handler := range r.prefixMatches {
+	// 2. Try prefix matches, most specific first so the catch-all runs last
+	for _, prefix := range sortedPrefixes(r.prefixMatches) {
 		if strings.HasPrefix(data, prefix) {
-			return handler.Handle(ctx, bot, chatID, msgID, query, data)
+			return r.prefixMatches[prefix].Handle(ctx, bot, chatID, msgID, query, data)
 		}
 	}
 
@@ -18,6 +18,20 @@ func (c *DockerStatsCmd) Execute(ctx *AppContext, bot BotAPI, msg *tgbotapi.Mess
 }
 func (c *DockerStatsCmd) Description() string { return "Show Docker resource usage" }
 
+type DockerUpdatesCmd struct{}
+
+func (c *DockerUpdatesCmd) Execute(ctx *AppContext, bot BotAPI, msg *tgbotapi.Message, args string) {
+	handleImageUpdatesCommand(ctx, bot, msg.Chat.ID)
+}
+func (c *DockerUpdatesCmd) Description() string { return "Show containers with a newer image" }
+
+type DockerDiskCmd struct{}
+
+func (c *DockerDiskCmd) Execute(ctx *AppContext, bot BotAPI, msg *tgbotapi.Message, args string) {
+	handleDockerDiskCommand(ctx, bot, msg.Chat.ID)
+}
+func (c *DockerDiskCmd) Description() string { return "Show Docker disk usage and clean up" }
+
 type ContainerCmd struct{}
 
 func (c *ContainerCmd) Execute(ctx *AppContext, bot BotAPI, msg *tgbotapi.Message, args string) {
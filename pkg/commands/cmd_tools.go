@@ -110,6 +110,27 @@ func (c *DiskPredCmd) Execute(ctx *AppContext, bot BotAPI, msg *tgbotapi.Message
 }
 func (c *DiskPredCmd) Description() string { return "Show disk usage prediction" }
 
+type GraphCmd struct{}
+
+func (c *GraphCmd) Execute(ctx *AppContext, bot BotAPI, msg *tgbotapi.Message, args string) {
+	handleGraphCommand(ctx, bot, msg.Chat.ID, args)
+}
+func (c *GraphCmd) Description() string { return "Show a chart of a metric" }
+
+type SmartCmd struct{}
+
+func (c *SmartCmd) Execute(ctx *AppContext, bot BotAPI, msg *tgbotapi.Message, args string) {
+	handleSmartCommand(ctx, bot, msg.Chat.ID, args)
+}
+func (c *SmartCmd) Description() string { return "Show disk SMART health and self-tests" }
+
+type RaidCmd struct{}
+
+func (c *RaidCmd) Execute(ctx *AppContext, bot BotAPI, msg *tgbotapi.Message, args string) {
+	handleRaidCommand(ctx, bot, msg.Chat.ID)
+}
+func (c *RaidCmd) Description() string { return "Show RAID arrays and rebuild progress" }
+
 type HealthCmd struct{}
 
 func (c *HealthCmd) Execute(ctx *AppContext, bot BotAPI, msg *tgbotapi.Message, args string) {
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"log/slog"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -41,6 +43,13 @@ func (r *CommandRegistry) Execute(ctx *AppContext, bot BotAPI, msg *tgbotapi.Mes
 		return false
 	}
 	if cmd, ok := r.commands[cmdName]; ok {
+		if !CanRunCommand(ctx, MessageSenderID(msg), CommandRoleKey(ctx, cmdName, msg.CommandArguments())) {
+			slog.Warn("Command denied", "command", cmdName, "user", MessageSenderID(msg))
+			if msg.Chat != nil {
+				sendPermissionDenied(ctx, bot, msg.Chat.ID)
+			}
+			return true
+		}
 		cmd.Execute(ctx, bot, msg, msg.CommandArguments())
 		return true
 	}
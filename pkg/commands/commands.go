@@ -50,6 +50,9 @@ func diskTempStatus(ctx *AppContext, temp int, health string) (icon, status stri
 	if strings.Contains(strings.ToUpper(health), "FAIL") {
 		icon = "🚨"
 		status = tr("temp_disk_fail")
+	} else if health == "STANDBY" {
+		icon = "💤"
+		status = tr("temp_disk_standby")
 	} else if temp > diskWarmC && temp > 0 {
 		icon = "🟡"
 		status = tr("temp_disk_warm")
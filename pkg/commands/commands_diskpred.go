@@ -6,14 +6,47 @@ import (
 	"time"
 
 	"nasbot/internal/format"
+	"nasbot/pkg/model"
 )
 
+// diskPredictionWindow is how far back /diskpred looks when the persistent
+// history is available.
+const diskPredictionWindow = 30 * 24 * time.Hour
+
+// diskHistoryFor returns the usage samples of one disk ("SSD" or a mount
+// point): up to diskPredictionWindow of persistent history when enabled,
+// the in-memory week otherwise.
+func diskHistoryFor(ctx *AppContext, diskName string) []DiskUsagePoint {
+	if ctx.History == nil {
+		ctx.State.Mu.Lock()
+		defer ctx.State.Mu.Unlock()
+		history := make([]DiskUsagePoint, len(ctx.State.DiskHistory))
+		copy(history, ctx.State.DiskHistory)
+		return history
+	}
+
+	mount := diskName
+	if diskName == "SSD" {
+		mount = ctx.Config.Paths.SSD
+	}
+	now := time.Now()
+	points := ctx.History.Range(model.HistoryVolumeFree(mount), now.Add(-diskPredictionWindow), now)
+	history := make([]DiskUsagePoint, 0, len(points))
+	for _, p := range points {
+		dp := DiskUsagePoint{Time: p.Time}
+		if diskName == "SSD" {
+			dp.SSDFree = uint64(p.Avg)
+		} else {
+			dp.SecondaryFree = map[string]uint64{mount: uint64(p.Avg)}
+		}
+		history = append(history, dp)
+	}
+	return history
+}
+
 // getDiskPredictionText estimates when disks will be full
 func getDiskPredictionText(ctx *AppContext) string {
-	ctx.State.Mu.Lock()
-	history := make([]DiskUsagePoint, len(ctx.State.DiskHistory))
-	copy(history, ctx.State.DiskHistory)
-	ctx.State.Mu.Unlock()
+	history := diskHistoryFor(ctx, "SSD")
 
 	var b strings.Builder
 	b.WriteString("📊 *Disk Space Prediction*\n\n")
@@ -49,7 +82,7 @@ func getDiskPredictionText(ctx *AppContext) string {
 
 	writeDiskPred("💿", "SSD", ssdPred, s.VolSSD.Used)
 	for mount, vol := range s.SecondaryVols {
-		pred := predictDiskFull(history, mount)
+		pred := predictDiskFull(diskHistoryFor(ctx, mount), mount)
 		writeDiskPred("🗄", mount, pred, vol.Used)
 	}
 
@@ -74,7 +74,7 @@ func getLogSearchText(ctx *AppContext, args string) string {
 	reqCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	out, err := runCommandOutput(reqCtx, "docker", "logs", "--tail", "500", container)
+	out, err := containerLogs(reqCtx, container, 500)
 	if err != nil {
 		return fmt.Sprintf("❌ Error: `%v`", err)
 	}
@@ -4,6 +4,9 @@ import (
 	"math"
 	"testing"
 	"time"
+
+	"nasbot/internal/history"
+	"nasbot/pkg/model"
 )
 
 func TestPredictDiskFull_DecreasingFree(t *testing.T) {
@@ -39,3 +42,30 @@ func TestPredictDiskFull_StableFree(t *testing.T) {
 		t.Fatalf("expected DaysUntilFull -1, got %.2f", pred.DaysUntilFull)
 	}
 }
+
+func TestDiskHistoryForUsesPersistentHistory(t *testing.T) {
+	giB := float64(1024 * 1024 * 1024)
+	ctx := newTestAppContext()
+	ctx.Config.Paths.SSD = "/Volume1"
+	ctx.History = history.New([]history.Tier{{Step: time.Hour, Retention: 60 * 24 * time.Hour}})
+
+	// Three weeks of hourly samples, losing 1 GB/day on the data disk.
+	now := time.Now()
+	start := now.Add(-21 * 24 * time.Hour)
+	for ts := start; ts.Before(now); ts = ts.Add(time.Hour) {
+		days := ts.Sub(start).Hours() / 24
+		ctx.History.Add(ts, map[string]float64{
+			model.HistoryVolumeFree("/Volume1"):  50 * giB,
+			model.HistoryVolumeFree("/mnt/data"): (100 - days) * giB,
+		})
+	}
+
+	ssd := diskHistoryFor(ctx, "SSD")
+	if len(ssd) < 24*20 || ssd[0].SSDFree != uint64(50*giB) {
+		t.Fatalf("expected weeks of SSD history, got %d points", len(ssd))
+	}
+	pred := predictDiskFull(diskHistoryFor(ctx, "/mnt/data"), "/mnt/data")
+	if math.Abs(pred.GBPerDay-1) > 0.05 {
+		t.Fatalf("expected ~1 GB/day from history, got %.2f", pred.GBPerDay)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"runtime/debug"
+	"sort"
 	"strings"
 	"time"
 
+	"nasbot/internal/compose"
 	"nasbot/internal/format"
 
 	"github.com/shirou/gopsutil/v3/cpu"
@@ -56,6 +58,7 @@ func getStatusText(ctx *AppContext) string {
 		shortName := mountShortName(m)
 		b.WriteString(fmt.Sprintf("🗄 *%s:* %.1f%% | %s free\n", shortName, vol.Used, format.FormatBytes(vol.Free)))
 	}
+	b.WriteString(getDiskSpinLine(ctx))
 
 	if s.DiskUtil > 10 {
 		b.WriteString(fmt.Sprintf(tr("disk_io_fmt"), s.DiskUtil))
@@ -85,6 +88,9 @@ func getStatusText(ctx *AppContext) string {
 		} else {
 			b.WriteString(fmt.Sprintf("\n🐳 %d %s", running, containerLabel))
 		}
+		if stacks, _ := compose.Group(containers); len(stacks) > 0 {
+			b.WriteString("\n📚 " + compose.Summary(stacks))
+		}
 	}
 
 	b.WriteString(fmt.Sprintf(tr("uptime_fmt"), format.FormatUptime(s.Uptime)))
@@ -94,6 +100,31 @@ func getStatusText(ctx *AppContext) string {
 
 func GetStatusText(ctx *AppContext) string { return getStatusText(ctx) }
 
+// getDiskSpinLine shows which disks were spinning at the last SMART read.
+func getDiskSpinLine(ctx *AppContext) string {
+	ctx.Monitor.Mu.Lock()
+	devs := make([]string, 0, len(ctx.Monitor.SmartCache))
+	standby := make(map[string]bool, len(ctx.Monitor.SmartCache))
+	for dev, res := range ctx.Monitor.SmartCache {
+		devs = append(devs, dev)
+		standby[dev] = res.Standby
+	}
+	ctx.Monitor.Mu.Unlock()
+	if len(devs) == 0 {
+		return ""
+	}
+	sort.Strings(devs)
+	parts := make([]string, len(devs))
+	for i, dev := range devs {
+		icon := "⚡"
+		if standby[dev] {
+			icon = "💤"
+		}
+		parts[i] = dev + " " + icon
+	}
+	return "💽 " + strings.Join(parts, " · ") + "\n"
+}
+
 func getTempText(ctx *AppContext) string {
 	tr := ctx.Tr
 	var b strings.Builder
@@ -111,7 +142,9 @@ func getTempText(ctx *AppContext) string {
 	for _, dev := range getSmartDevices(ctx) {
 		temp, health := readDiskSMART(dev)
 		icon, status := diskTempStatus(ctx, temp, health)
-		if temp < 0 {
+		if health == "STANDBY" {
+			b.WriteString(fmt.Sprintf("%s %s — %s\n", icon, dev, status))
+		} else if temp < 0 {
 			b.WriteString(fmt.Sprintf("%s %s: N/A — %s\n", icon, dev, status))
 		} else {
 			b.WriteString(fmt.Sprintf("%s %s: %d°C — %s\n", icon, dev, temp, status))
@@ -215,11 +248,16 @@ func getHelpText(ctx *AppContext) string {
 	b.WriteString("/temp — check temperatures\n")
 	b.WriteString("/top — top processes by CPU\n")
 	b.WriteString("/sysinfo — detailed system info\n")
-	b.WriteString("/diskpred — disk space prediction\n\n")
+	b.WriteString("/diskpred — disk space prediction\n")
+	b.WriteString("/smart `disk` — disk health and self-tests\n")
+	b.WriteString("/raid — RAID arrays and rebuild progress\n")
+	b.WriteString("/graph `metric` `24h` — chart (cpu, ram, disk, net, temp...)\n\n")
 
 	b.WriteString(tr("help_docker"))
 	b.WriteString("/docker — manage containers\n")
 	b.WriteString("/dstats — container resources\n")
+	b.WriteString("/dupdates — image updates with rollback\n")
+	b.WriteString("/ddisk — disk usage and cleanup\n")
 	b.WriteString("/kill `name` — force kill container\n")
 	b.WriteString("/logsearch `name` `keyword` — search logs\n")
 	b.WriteString("/restartdocker — restart Docker service\n\n")
@@ -72,3 +72,19 @@ func TestGetPingText(t *testing.T) {
 		t.Errorf("Expected ping_pong, got: %s", text)
 	}
 }
+
+func TestGetStatusTextDiskSpin(t *testing.T) {
+	ctx := setupTestContext()
+	ctx.Stats.Set(model.Stats{VolSSD: model.VolumeStats{Used: 50.0}})
+	ctx.Monitor.SmartCache = map[string]model.SmartResult{
+		"sdb": {Standby: true, Health: "STANDBY", Temp: -1},
+		"sda": {Health: "PASSED", Temp: 35},
+	}
+
+	if text := GetStatusText(ctx); !strings.Contains(text, "💽 sda ⚡ · sdb 💤") {
+		t.Errorf("Expected disk spin line, got text: \n%s", text)
+	}
+	if icon, status := diskTempStatus(ctx, -1, "STANDBY"); icon != "💤" || status != "[temp_disk_standby]" {
+		t.Errorf("diskTempStatus(standby) = %q, %q", icon, status)
+	}
+}
@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"sort"
+	"strings"
+
+	"nasbot/pkg/model"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// defaultCommandRoles is the minimum role needed for each command.
+// Commands not listed here require admin. Entries of the form
+// "<command> <subcommand>" override the command's role for that subcommand.
+var defaultCommandRoles = map[string]string{
+	// Read-only views
+	"status":       model.RoleViewer,
+	"start":        model.RoleViewer,
+	"quick":        model.RoleViewer,
+	"q":            model.RoleViewer,
+	"top":          model.RoleViewer,
+	"processes":    model.RoleViewer,
+	"sysinfo":      model.RoleViewer,
+	"temp":         model.RoleViewer,
+	"docker":       model.RoleViewer,
+	"dstats":       model.RoleViewer,
+	"dupdates":     model.RoleViewer,
+	"ddisk":        model.RoleViewer,
+	"net":          model.RoleViewer,
+	"ping":         model.RoleViewer,
+	"help":         model.RoleViewer,
+	"diskpred":     model.RoleViewer,
+	"prediction":   model.RoleViewer,
+	"graph":        model.RoleViewer,
+	"smart":        model.RoleViewer,
+	"raid":         model.RoleViewer,
+	"health":       model.RoleViewer,
+	"healthchecks": model.RoleViewer,
+	"report":       model.RoleViewer,
+	"changelog":    model.RoleViewer,
+	"version":      model.RoleViewer,
+	"v":            model.RoleViewer,
+
+	"container find": model.RoleViewer,
+
+	// Day-to-day operations
+	"container":     model.RoleOperator,
+	"kill":          model.RoleOperator,
+	"restartdocker": model.RoleOperator,
+	"logs":          model.RoleOperator,
+	"logsearch":     model.RoleOperator,
+	"ask":           model.RoleOperator,
+	"speedtest":     model.RoleOperator,
+	"adblock":       model.RoleOperator,
+}
+
+// defaultCallbackRoles maps callback data prefixes to the minimum role.
+// The longest matching prefix wins; anything unmatched requires admin.
+var defaultCallbackRoles = map[string]string{
+	"refresh_status":    model.RoleViewer,
+	"back_main":         model.RoleViewer,
+	"show_temp":         model.RoleViewer,
+	"show_docker":       model.RoleViewer,
+	"show_dstats":       model.RoleViewer,
+	"show_top":          model.RoleViewer,
+	"show_net":          model.RoleViewer,
+	"show_report":       model.RoleViewer,
+	"container_select_": model.RoleViewer,
+	"dmenu_":            model.RoleViewer,
+	"container_cancel_": model.RoleViewer,
+	"stack_select_":     model.RoleViewer,
+	"dupdate_list":      model.RoleViewer,
+	"ddisk_refresh":     model.RoleViewer,
+	"proc_refresh":      model.RoleViewer,
+	"health_refresh":    model.RoleViewer,
+	"graph_":            model.RoleViewer,
+
+	"container_":          model.RoleOperator,
+	"docker_restart_":     model.RoleOperator,
+	"stack_":              model.RoleOperator,
+	"dupdate_":            model.RoleOperator,
+	"dlog_":               model.RoleOperator,
+	"dexec_":              model.RoleOperator,
+	"dinspect_":           model.RoleOperator,
+	"confirm_restart_":    model.RoleOperator,
+	"cancel_restart_":     model.RoleOperator,
+	"proc_":               model.RoleOperator,
+	"health_":             model.RoleOperator,
+	"adblock_":            model.RoleOperator,
+	"ai_analyze_critical": model.RoleOperator,
+	"alert_":              model.RoleOperator,
+}
+
+// RequiredCommandRole returns the minimum role needed to run a command,
+// applying overrides from the permissions config.
+func RequiredCommandRole(ctx *AppContext, name string) string {
+	if ctx != nil && ctx.Config != nil {
+		if role, ok := ctx.Config.Permissions.Commands[name]; ok {
+			return role
+		}
+	}
+	if role, ok := defaultCommandRoles[name]; ok {
+		return role
+	}
+	return model.RoleAdmin
+}
+
+// CommandRoleKey returns the permissions key for a command and its
+// arguments: "<command> <subcommand>" when that pair has its own role in
+// the defaults or config, otherwise the bare command name.
+func CommandRoleKey(ctx *AppContext, name, args string) string {
+	sub, _, _ := strings.Cut(strings.TrimSpace(args), " ")
+	if sub == "" {
+		return name
+	}
+	key := name + " " + sub
+	if ctx != nil && ctx.Config != nil {
+		if _, ok := ctx.Config.Permissions.Commands[key]; ok {
+			return key
+		}
+	}
+	if _, ok := defaultCommandRoles[key]; ok {
+		return key
+	}
+	return name
+}
+
+// RequiredCallbackRole returns the minimum role needed for callback data.
+// Config overrides take precedence over defaults with the same prefix.
+func RequiredCallbackRole(ctx *AppContext, data string) string {
+	roles := make(map[string]string, len(defaultCallbackRoles))
+	for prefix, role := range defaultCallbackRoles {
+		roles[prefix] = role
+	}
+	if ctx != nil && ctx.Config != nil {
+		for prefix, role := range ctx.Config.Permissions.Callbacks {
+			roles[prefix] = role
+		}
+	}
+	for _, prefix := range sortedPrefixes(roles) {
+		if strings.HasPrefix(data, prefix) {
+			return roles[prefix]
+		}
+	}
+	return model.RoleAdmin
+}
+
+// CanRunCommand reports whether userID may run the named command.
+func CanRunCommand(ctx *AppContext, userID int64, name string) bool {
+	if ctx == nil || ctx.Config == nil {
+		return true
+	}
+	return model.RoleAtLeast(ctx.Config.UserRole(userID), RequiredCommandRole(ctx, name))
+}
+
+// CanRunCallback reports whether userID may trigger the callback data.
+func CanRunCallback(ctx *AppContext, userID int64, data string) bool {
+	if ctx == nil || ctx.Config == nil {
+		return true
+	}
+	return model.RoleAtLeast(ctx.Config.UserRole(userID), RequiredCallbackRole(ctx, data))
+}
+
+// MessageSenderID returns the user who sent msg, falling back to the chat ID
+// for messages without a From field.
+func MessageSenderID(msg *tgbotapi.Message) int64 {
+	if msg == nil {
+		return 0
+	}
+	if msg.From != nil {
+		return msg.From.ID
+	}
+	if msg.Chat != nil {
+		return msg.Chat.ID
+	}
+	return 0
+}
+
+// sortedPrefixes returns the keys of m, longest first, so more specific
+// prefixes are matched before generic ones.
+func sortedPrefixes[V any](m map[string]V) []string {
+	prefixes := make([]string, 0, len(m))
+	for p := range m {
+		prefixes = append(prefixes, p)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		if len(prefixes[i]) != len(prefixes[j]) {
+			return len(prefixes[i]) > len(prefixes[j])
+		}
+		return prefixes[i] < prefixes[j]
+	})
+	return prefixes
+}
+
+func sendPermissionDenied(ctx *AppContext, bot BotAPI, chatID int64) {
+	safeSend(bot, tgbotapi.NewMessage(chatID, ctx.Tr("permission_denied")))
+}
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func newPermissionsTestContext() *AppContext {
+	ctx := newTestAppContext()
+	ctx.Config.Users = []UserConfig{
+		{ID: 2, Role: "operator"},
+		{ID: 3, Role: "viewer"},
+	}
+	return ctx
+}
+
+func TestCanRunCommandByRole(t *testing.T) {
+	ctx := newPermissionsTestContext()
+
+	cases := []struct {
+		user int64
+		cmd  string
+		want bool
+	}{
+		{1, "reboot", true},
+		{2, "kill", true},
+		{2, "configset", false},
+		{3, "status", true},
+		{3, "docker", true},
+		{3, "reboot", false},
+		{3, "kill", false},
+		{3, "configset", false},
+		{99, "status", false},
+	}
+	for _, tc := range cases {
+		if got := CanRunCommand(ctx, tc.user, tc.cmd); got != tc.want {
+			t.Errorf("CanRunCommand(%d, %q) = %v, want %v", tc.user, tc.cmd, got, tc.want)
+		}
+	}
+
+	ctx.Config.Permissions.Commands = map[string]string{"status": "admin"}
+	if CanRunCommand(ctx, 3, "status") {
+		t.Errorf("config override should restrict /status to admins")
+	}
+}
+
+func TestCommandRoleKeySubcommands(t *testing.T) {
+	ctx := newPermissionsTestContext()
+
+	if got := CommandRoleKey(ctx, "container", "find web"); got != "container find" {
+		t.Errorf("CommandRoleKey(container, find web) = %q, want container find", got)
+	}
+	if got := CommandRoleKey(ctx, "container", "nginx"); got != "container" {
+		t.Errorf("CommandRoleKey(container, nginx) = %q, want container", got)
+	}
+	if !CanRunCommand(ctx, 3, CommandRoleKey(ctx, "container", "find web")) {
+		t.Errorf("viewers should be able to run /container find")
+	}
+	if CanRunCommand(ctx, 3, CommandRoleKey(ctx, "container", "nginx")) {
+		t.Errorf("viewers should not be able to run /container <name>")
+	}
+
+	ctx.Config.Permissions.Commands = map[string]string{"logs tail": "viewer"}
+	if got := CommandRoleKey(ctx, "logs", "tail"); got != "logs tail" {
+		t.Errorf("config subcommand key not used: %q", got)
+	}
+}
+
+func TestRequiredCallbackRoleLongestPrefix(t *testing.T) {
+	ctx := newPermissionsTestContext()
+
+	if got := RequiredCallbackRole(ctx, "container_select_nginx"); got != "viewer" {
+		t.Errorf("container_select_ role = %q, want viewer", got)
+	}
+	if got := RequiredCallbackRole(ctx, "container_stop_nginx"); got != "operator" {
+		t.Errorf("container_stop_ role = %q, want operator", got)
+	}
+	if got := RequiredCallbackRole(ctx, "confirm_reboot"); got != "admin" {
+		t.Errorf("confirm_reboot role = %q, want admin", got)
+	}
+
+	ctx.Config.Permissions.Callbacks = map[string]string{"container_stop_": "viewer"}
+	if got := RequiredCallbackRole(ctx, "container_stop_nginx"); got != "viewer" {
+		t.Errorf("override role = %q, want viewer", got)
+	}
+}
+
+func TestCommandRegistryDeniesViewer(t *testing.T) {
+	ctx := newPermissionsTestContext()
+	r := NewCommandRegistry()
+	cmd := &testCmd{}
+	r.Register("reboot", cmd)
+
+	msg := &tgbotapi.Message{
+		Text:     "/reboot",
+		From:     &tgbotapi.User{ID: 3},
+		Chat:     &tgbotapi.Chat{ID: 3},
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 7}},
+	}
+	if ok := r.Execute(ctx, nil, msg); !ok {
+		t.Fatalf("denied command should still be reported as handled")
+	}
+	if cmd.called {
+		t.Fatalf("viewer must not be able to run /reboot")
+	}
+}
+
+func TestCallbackRegistryPrefixOrder(t *testing.T) {
+	ctx := newTestAppContext()
+	r := NewCallbackRegistry()
+	var got string
+	handler := func(name string) CallbackFunc {
+		return func(_ *AppContext, _ BotAPI, _ int64, _ int, _ *tgbotapi.CallbackQuery, _ string) bool {
+			got = name
+			return true
+		}
+	}
+	r.RegisterPrefix("", handler("catch-all"))
+	r.RegisterPrefix("container_", handler("container"))
+	r.RegisterPrefix("container_stop_", handler("stop"))
+
+	query := &tgbotapi.CallbackQuery{
+		Data:    "container_stop_nginx",
+		From:    &tgbotapi.User{ID: 1},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 1}},
+	}
+	for i := 0; i < 20; i++ {
+		got = ""
+		r.Execute(ctx, nil, query)
+		if got != "stop" {
+			t.Fatalf("prefix handler = %q, want stop", got)
+		}
+	}
+}
+
+func TestCallbackRegistryDeniesViewer(t *testing.T) {
+	ctx := newPermissionsTestContext()
+	r := NewCallbackRegistry()
+	called := false
+	r.RegisterExact("confirm_reboot", CallbackFunc(func(_ *AppContext, _ BotAPI, _ int64, _ int, _ *tgbotapi.CallbackQuery, _ string) bool {
+		called = true
+		return true
+	}))
+
+	query := &tgbotapi.CallbackQuery{
+		Data:    "confirm_reboot",
+		From:    &tgbotapi.User{ID: 3},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 3}},
+	}
+	r.Execute(ctx, nil, query)
+	if called {
+		t.Fatalf("viewer must not be able to confirm a reboot")
+	}
+}
@@ -42,9 +42,15 @@ type RuntimeDeps struct {
 	RunCommandOutput             func(ctx context.Context, name string, args ...string) ([]byte, error)
 	RunCommandStdout             func(ctx context.Context, name string, args ...string) ([]byte, error)
 	RunCommand                   func(ctx context.Context, name string, args ...string) error
+	ContainerLogs                func(ctx context.Context, name string, tail int) ([]byte, error)
 	EditMessage                  func(bot BotAPI, chatID int64, msgID int, text string, keyboard *tgbotapi.InlineKeyboardMarkup)
 	SafeSend                     func(bot BotAPI, c tgbotapi.Chattable)
 	HandleHealthCommand          func(ctx *AppContext, bot BotAPI, chatID int64)
+	HandleGraphCommand           func(ctx *AppContext, bot BotAPI, chatID int64, args string)
+	HandleSmartCommand           func(ctx *AppContext, bot BotAPI, chatID int64, args string)
+	HandleRaidCommand            func(ctx *AppContext, bot BotAPI, chatID int64)
+	HandleImageUpdatesCommand    func(ctx *AppContext, bot BotAPI, chatID int64)
+	HandleDockerDiskCommand      func(ctx *AppContext, bot BotAPI, chatID int64)
 	ApplyLatestRelease           func(ctx *AppContext, bot BotAPI, chatID int64, msgID int)
 	CheckForUpdate               func(ctx *AppContext) (ReleaseInfo, bool, error)
 	FetchLatestRelease           func(ctx *AppContext) (ReleaseInfo, error)
@@ -188,6 +194,13 @@ func runCommandOutput(ctx context.Context, name string, args ...string) ([]byte,
 	return nil, nil
 }
 
+func containerLogs(ctx context.Context, name string, tail int) ([]byte, error) {
+	if runtimeDeps.ContainerLogs != nil {
+		return runtimeDeps.ContainerLogs(ctx, name, tail)
+	}
+	return nil, nil
+}
+
 func runCommandStdout(ctx context.Context, name string, args ...string) ([]byte, error) {
 	if runtimeDeps.RunCommandStdout != nil {
 		return runtimeDeps.RunCommandStdout(ctx, name, args...)
@@ -220,6 +233,36 @@ func handleHealthCommand(ctx *AppContext, bot BotAPI, chatID int64) {
 	}
 }
 
+func handleImageUpdatesCommand(ctx *AppContext, bot BotAPI, chatID int64) {
+	if runtimeDeps.HandleImageUpdatesCommand != nil {
+		runtimeDeps.HandleImageUpdatesCommand(ctx, bot, chatID)
+	}
+}
+
+func handleDockerDiskCommand(ctx *AppContext, bot BotAPI, chatID int64) {
+	if runtimeDeps.HandleDockerDiskCommand != nil {
+		runtimeDeps.HandleDockerDiskCommand(ctx, bot, chatID)
+	}
+}
+
+func handleGraphCommand(ctx *AppContext, bot BotAPI, chatID int64, args string) {
+	if runtimeDeps.HandleGraphCommand != nil {
+		runtimeDeps.HandleGraphCommand(ctx, bot, chatID, args)
+	}
+}
+
+func handleSmartCommand(ctx *AppContext, bot BotAPI, chatID int64, args string) {
+	if runtimeDeps.HandleSmartCommand != nil {
+		runtimeDeps.HandleSmartCommand(ctx, bot, chatID, args)
+	}
+}
+
+func handleRaidCommand(ctx *AppContext, bot BotAPI, chatID int64) {
+	if runtimeDeps.HandleRaidCommand != nil {
+		runtimeDeps.HandleRaidCommand(ctx, bot, chatID)
+	}
+}
+
 func applyLatestRelease(ctx *AppContext, bot BotAPI, chatID int64, msgID int) {
 	if runtimeDeps.ApplyLatestRelease != nil {
 		runtimeDeps.ApplyLatestRelease(ctx, bot, chatID, msgID)
@@ -21,3 +21,4 @@ type DiskUsagePoint = model.DiskUsagePoint
 type DiskPrediction = model.DiskPrediction
 type ContainerInfo = model.ContainerInfo
 type ResourceConfig = model.ResourceConfig
+type UserConfig = model.UserConfig
@@ -0,0 +1,408 @@
+package model
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Alert levels reported by monitors. AlertLevelOK means the condition is
+// currently within limits.
+const (
+	AlertLevelOK       = ""
+	AlertLevelWarning  = "warning"
+	AlertLevelCritical = "critical"
+)
+
+func alertLevelRank(level string) int {
+	switch level {
+	case AlertLevelWarning:
+		return 1
+	case AlertLevelCritical:
+		return 2
+	}
+	return 0
+}
+
+// AlertRule controls how a single alert ID is debounced.
+type AlertRule struct {
+	// Sustain is how long a level must persist before the alert fires.
+	Sustain time.Duration
+	// Hysteresis keeps a firing alert active until the value drops below
+	// its threshold minus this amount. Only used for numeric observations.
+	Hysteresis float64
+	// Cooldown is the minimum gap between notifications for the same alert;
+	// reminders are repeated at this interval while it keeps firing.
+	// Zero disables reminders.
+	Cooldown time.Duration
+	// NotifyResolved asks for a notification when an announced alert clears.
+	NotifyResolved bool
+	// EscalateAfter re-notifies a critical alert once if nobody acknowledged
+	// it within this time. Zero disables escalation.
+	EscalateAfter time.Duration
+}
+
+// AlertObservation is one reading of the condition behind an alert.
+type AlertObservation struct {
+	ID    string
+	Level string
+	// Value and Threshold enable hysteresis; Threshold is the limit that
+	// produced Level and is zero for conditions that are simply on or off.
+	Value     float64
+	Threshold float64
+	// Signature identifies the details of the problem (e.g. the list of
+	// degraded arrays). A change while firing is notified immediately.
+	Signature string
+	// Quiet tells that the caller will not deliver notifications now
+	// (quiet hours). The reading is recorded, but a fire is held back and
+	// notified by the first reading after the quiet period; reminders and
+	// escalations wait for it too.
+	Quiet bool
+}
+
+// AlertTransition describes what an observation did to an alert.
+type AlertTransition int
+
+const (
+	AlertUnchanged AlertTransition = iota
+	AlertFired
+	AlertReminder
+	AlertEscalated
+	AlertResolved
+)
+
+// AlertEvent is the outcome of AlertManager.Observe.
+type AlertEvent struct {
+	Kind  AlertTransition
+	ID    string
+	Level string // firing level, or the level that cleared for AlertResolved
+	Since time.Time
+	// Notify is false when the transition should only be recorded, e.g. a
+	// fire that lands inside the cooldown of a previous notification or
+	// while the alert is snoozed.
+	Notify bool
+	// Deferred marks an AlertFired that delivers a fire held back by a
+	// Quiet reading; the fire itself was already reported.
+	Deferred bool
+}
+
+// AlertSilence is the user-controlled state of an alert ID, persisted across
+// restarts. Acked lasts until the alert resolves; snoozes expire on their own.
+type AlertSilence struct {
+	Acked        bool      `json:"acked,omitempty"`
+	Muted        bool      `json:"muted,omitempty"`
+	SnoozedUntil time.Time `json:"snoozed_until,omitempty"`
+}
+
+func (s AlertSilence) silenced(now time.Time) bool {
+	return s.Muted || now.Before(s.SnoozedUntil)
+}
+
+func (s AlertSilence) empty(now time.Time) bool {
+	return !s.Acked && !s.silenced(now)
+}
+
+type alertState struct {
+	level        string
+	threshold    float64
+	signature    string
+	pending      string
+	pendingSince time.Time
+	firingSince  time.Time
+	lastNotified time.Time
+	announced    bool
+	announcedAt  time.Time
+	escalated    bool
+	held         bool // a fire is waiting for the end of quiet hours
+}
+
+// AlertManager tracks every alert by a stable ID (e.g. "cpu", "disk:/mnt/data",
+// "container:nginx") so monitors share the same dedup, sustain, hysteresis,
+// cooldown, acknowledgement and escalation logic.
+type AlertManager struct {
+	mu       Mutex
+	alerts   map[string]*alertState
+	silences map[string]AlertSilence
+	refs     map[string][]string
+}
+
+func NewAlertManager() *AlertManager {
+	return &AlertManager{
+		alerts:   make(map[string]*alertState),
+		silences: make(map[string]AlertSilence),
+		refs:     make(map[string][]string),
+	}
+}
+
+// Observe feeds a new reading into the alert identified by obs.ID.
+func (m *AlertManager) Observe(now time.Time, obs AlertObservation, rule AlertRule) AlertEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ev := AlertEvent{ID: obs.ID}
+	sil := m.silences[obs.ID]
+	st, ok := m.alerts[obs.ID]
+	if !ok {
+		if obs.Level == AlertLevelOK {
+			m.clearAck(obs.ID)
+			return ev
+		}
+		st = &alertState{}
+		m.alerts[obs.ID] = st
+	}
+	silenced := sil.silenced(now)
+
+	level := obs.Level
+	if alertLevelRank(level) < alertLevelRank(st.level) && st.threshold > 0 && obs.Value >= st.threshold-rule.Hysteresis {
+		level = st.level
+	}
+
+	if level != st.pending {
+		st.pending = level
+		st.pendingSince = now
+	}
+
+	switch {
+	case level == AlertLevelOK:
+		if st.level == AlertLevelOK {
+			return ev
+		}
+		ev.Kind = AlertResolved
+		ev.Level = st.level
+		ev.Since = st.firingSince
+		ev.Notify = rule.NotifyResolved && st.announced && !silenced && !obs.Quiet
+		*st = alertState{pending: AlertLevelOK, pendingSince: now, lastNotified: st.lastNotified}
+		m.clearAck(obs.ID)
+		return ev
+
+	case alertLevelRank(level) > alertLevelRank(st.level):
+		if now.Sub(st.pendingSince) < rule.Sustain {
+			return ev
+		}
+		if st.level == AlertLevelOK {
+			st.firingSince = st.pendingSince
+			// Escalations always notify; a fresh fire respects the cooldown
+			// so a flapping condition cannot spam.
+			ev.Notify = st.lastNotified.IsZero() || now.Sub(st.lastNotified) >= rule.Cooldown
+		} else {
+			ev.Notify = true
+			m.clearAck(obs.ID)
+		}
+		ev.Notify = ev.Notify && !silenced
+		ev.Kind = AlertFired
+
+	case alertLevelRank(level) < alertLevelRank(st.level):
+		// Downgrade past the hysteresis band: keep firing at the lower
+		// level without a new notification.
+		st.level = level
+		st.threshold = obs.Threshold
+		return ev
+
+	case obs.Signature != st.signature:
+		ev.Kind = AlertFired
+		ev.Notify = !silenced
+		m.clearAck(obs.ID)
+
+	case st.held && !obs.Quiet:
+		ev.Kind = AlertFired
+		ev.Notify = !silenced
+		ev.Deferred = true
+		st.held = false
+
+	case silenced || sil.Acked || obs.Quiet:
+		return ev
+
+	case level == AlertLevelCritical && rule.EscalateAfter > 0 && st.announced && !st.escalated && now.Sub(st.announcedAt) >= rule.EscalateAfter:
+		ev.Kind = AlertEscalated
+		ev.Notify = true
+		st.escalated = true
+
+	case rule.Cooldown > 0 && now.Sub(st.lastNotified) >= rule.Cooldown:
+		ev.Kind = AlertReminder
+		ev.Notify = true
+
+	default:
+		return ev
+	}
+
+	st.level = level
+	st.threshold = obs.Threshold
+	st.signature = obs.Signature
+	if ev.Notify && obs.Quiet {
+		ev.Notify = false
+		st.held = true
+	}
+	if ev.Notify {
+		st.lastNotified = now
+		if !st.announced {
+			st.announced = true
+			st.announcedAt = now
+		}
+	}
+	ev.Level = level
+	ev.Since = st.firingSince
+	return ev
+}
+
+func (m *AlertManager) clearAck(id string) {
+	sil, ok := m.silences[id]
+	if !ok || !sil.Acked {
+		return
+	}
+	sil.Acked = false
+	if sil.Muted || !sil.SnoozedUntil.IsZero() {
+		m.silences[id] = sil
+	} else {
+		delete(m.silences, id)
+	}
+}
+
+// Active returns the level of every firing alert by ID.
+func (m *AlertManager) Active() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]string)
+	for id, st := range m.alerts {
+		if st.level != AlertLevelOK {
+			out[id] = st.level
+		}
+	}
+	return out
+}
+
+// Ack acknowledges the current occurrence of id: reminders and escalation
+// stop until it resolves or gets worse.
+func (m *AlertManager) Ack(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sil := m.silences[id]
+	sil.Acked = true
+	m.silences[id] = sil
+}
+
+// Snooze suppresses notifications for id until the given time.
+func (m *AlertManager) Snooze(id string, until time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sil := m.silences[id]
+	sil.SnoozedUntil = until
+	m.silences[id] = sil
+}
+
+// SetMuted mutes or unmutes id indefinitely.
+func (m *AlertManager) SetMuted(id string, muted bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sil := m.silences[id]
+	sil.Muted = muted
+	if !muted {
+		sil.SnoozedUntil = time.Time{}
+	}
+	m.silences[id] = sil
+}
+
+// Silence returns the user-controlled state of id.
+func (m *AlertManager) Silence(id string) AlertSilence {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.silences[id]
+}
+
+// Silences returns a copy of every silence still in effect at now.
+func (m *AlertManager) Silences(now time.Time) map[string]AlertSilence {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]AlertSilence, len(m.silences))
+	for id, sil := range m.silences {
+		if sil.empty(now) {
+			continue
+		}
+		out[id] = sil
+	}
+	return out
+}
+
+// RestoreSilences replaces the silences with a persisted copy.
+func (m *AlertManager) RestoreSilences(silences map[string]AlertSilence) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.silences = make(map[string]AlertSilence, len(silences))
+	for id, sil := range silences {
+		m.silences[id] = sil
+	}
+}
+
+const maxAlertRefs = 256
+
+// Ref returns a short token identifying a set of alert IDs, suitable for
+// Telegram callback data, and remembers it for Lookup.
+func (m *AlertManager) Ref(ids ...string) string {
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+	sum := sha1.Sum([]byte(strings.Join(sorted, "\n")))
+	ref := hex.EncodeToString(sum[:5])
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.refs) >= maxAlertRefs {
+		// Old buttons fall back to the single-ID lookup in Lookup.
+		m.refs = make(map[string][]string)
+	}
+	m.refs[ref] = sorted
+	return ref
+}
+
+// Lookup returns the alert IDs behind ref. Refs of single alerts survive a
+// restart as long as the alert is tracked or silenced again.
+func (m *AlertManager) Lookup(ref string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ids, ok := m.refs[ref]; ok {
+		return append([]string(nil), ids...)
+	}
+	match := func(id string) bool {
+		sum := sha1.Sum([]byte(id))
+		return hex.EncodeToString(sum[:5]) == ref
+	}
+	for id := range m.alerts {
+		if match(id) {
+			return []string{id}
+		}
+	}
+	for id := range m.silences {
+		if match(id) {
+			return []string{id}
+		}
+	}
+	return nil
+}
+
+// Firing returns the current level of id, or AlertLevelOK.
+func (m *AlertManager) Firing(id string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if st, ok := m.alerts[id]; ok {
+		return st.level
+	}
+	return AlertLevelOK
+}
+
+// Rule returns the rule configured for id: an exact key wins, otherwise the
+// longest key that is a prefix of id (e.g. "container:").
+func (a AlertsConfig) Rule(id string) (AlertRuleConfig, bool) {
+	if r, ok := a.Rules[id]; ok {
+		return r, true
+	}
+	best := ""
+	for key := range a.Rules {
+		if key != "" && strings.HasPrefix(id, key) && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best == "" {
+		return AlertRuleConfig{}, false
+	}
+	return a.Rules[best], true
+}
@@ -0,0 +1,258 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlertManagerSustainAndResolve(t *testing.T) {
+	m := NewAlertManager()
+	rule := AlertRule{Sustain: 5 * time.Minute, NotifyResolved: true}
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	hot := AlertObservation{ID: "cpu", Level: AlertLevelCritical, Value: 97, Threshold: 95}
+
+	if ev := m.Observe(t0, hot, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("alert fired before sustain elapsed: %+v", ev)
+	}
+	if ev := m.Observe(t0.Add(4*time.Minute), hot, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("alert fired before sustain elapsed: %+v", ev)
+	}
+	ev := m.Observe(t0.Add(5*time.Minute), hot, rule)
+	if ev.Kind != AlertFired || !ev.Notify || !ev.Since.Equal(t0) {
+		t.Fatalf("expected notified fire since t0, got %+v", ev)
+	}
+	if m.Firing("cpu") != AlertLevelCritical {
+		t.Fatalf("cpu should be firing")
+	}
+
+	ev = m.Observe(t0.Add(6*time.Minute), AlertObservation{ID: "cpu", Value: 10, Threshold: 90}, rule)
+	if ev.Kind != AlertResolved || !ev.Notify || ev.Level != AlertLevelCritical {
+		t.Fatalf("expected resolved notice, got %+v", ev)
+	}
+}
+
+func TestAlertManagerSustainResetsOnDip(t *testing.T) {
+	m := NewAlertManager()
+	rule := AlertRule{Sustain: 5 * time.Minute}
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	hot := AlertObservation{ID: "ram", Level: AlertLevelCritical, Value: 96, Threshold: 95}
+
+	m.Observe(t0, hot, rule)
+	m.Observe(t0.Add(3*time.Minute), AlertObservation{ID: "ram", Value: 50, Threshold: 90}, rule)
+	if ev := m.Observe(t0.Add(6*time.Minute), hot, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("a dip below threshold should restart the sustain window, got %+v", ev)
+	}
+}
+
+func TestAlertManagerHysteresis(t *testing.T) {
+	m := NewAlertManager()
+	rule := AlertRule{Hysteresis: 5, NotifyResolved: true}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	m.Observe(now, AlertObservation{ID: "temp:cpu", Level: AlertLevelCritical, Value: 86, Threshold: 85}, rule)
+
+	// 82 is below the threshold but within the hysteresis band.
+	ev := m.Observe(now.Add(time.Minute), AlertObservation{ID: "temp:cpu", Level: AlertLevelWarning, Value: 82, Threshold: 70}, rule)
+	if ev.Kind != AlertUnchanged || m.Firing("temp:cpu") != AlertLevelCritical {
+		t.Fatalf("alert should stay critical inside the hysteresis band, got %+v / %q", ev, m.Firing("temp:cpu"))
+	}
+
+	ev = m.Observe(now.Add(2*time.Minute), AlertObservation{ID: "temp:cpu", Level: AlertLevelWarning, Value: 79, Threshold: 70}, rule)
+	if ev.Kind != AlertUnchanged || m.Firing("temp:cpu") != AlertLevelWarning {
+		t.Fatalf("alert should downgrade silently to warning, got %+v / %q", ev, m.Firing("temp:cpu"))
+	}
+
+	if ev = m.Observe(now.Add(3*time.Minute), AlertObservation{ID: "temp:cpu", Value: 67, Threshold: 70}, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("warning should hold until 65, got %+v", ev)
+	}
+	if ev = m.Observe(now.Add(4*time.Minute), AlertObservation{ID: "temp:cpu", Value: 64, Threshold: 70}, rule); ev.Kind != AlertResolved {
+		t.Fatalf("expected resolve below the hysteresis band, got %+v", ev)
+	}
+}
+
+func TestAlertManagerCooldownAndReminders(t *testing.T) {
+	m := NewAlertManager()
+	rule := AlertRule{Cooldown: 10 * time.Minute, NotifyResolved: true}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	down := AlertObservation{ID: "container:db", Level: AlertLevelCritical}
+	up := AlertObservation{ID: "container:db"}
+
+	if ev := m.Observe(now, down, rule); ev.Kind != AlertFired || !ev.Notify {
+		t.Fatalf("expected first fire, got %+v", ev)
+	}
+	if ev := m.Observe(now.Add(5*time.Minute), down, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("duplicate inside cooldown should be suppressed, got %+v", ev)
+	}
+	if ev := m.Observe(now.Add(10*time.Minute), down, rule); ev.Kind != AlertReminder || !ev.Notify {
+		t.Fatalf("expected reminder after cooldown, got %+v", ev)
+	}
+
+	// Flapping: the re-fire lands inside the cooldown and stays silent, and
+	// so does its resolution.
+	m.Observe(now.Add(11*time.Minute), up, rule)
+	if ev := m.Observe(now.Add(12*time.Minute), down, rule); ev.Kind != AlertFired || ev.Notify {
+		t.Fatalf("re-fire inside cooldown should not notify, got %+v", ev)
+	}
+	if ev := m.Observe(now.Add(13*time.Minute), up, rule); ev.Kind != AlertResolved || ev.Notify {
+		t.Fatalf("unannounced alert should resolve silently, got %+v", ev)
+	}
+}
+
+func TestAlertManagerLevelIncreaseAndSignature(t *testing.T) {
+	m := NewAlertManager()
+	rule := AlertRule{Cooldown: time.Hour}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	m.Observe(now, AlertObservation{ID: "ram", Level: AlertLevelWarning, Value: 91, Threshold: 90}, rule)
+	ev := m.Observe(now.Add(time.Minute), AlertObservation{ID: "ram", Level: AlertLevelCritical, Value: 96, Threshold: 95}, rule)
+	if ev.Kind != AlertFired || !ev.Notify || ev.Level != AlertLevelCritical {
+		t.Fatalf("level increase should notify despite cooldown, got %+v", ev)
+	}
+
+	m.Observe(now, AlertObservation{ID: "raid", Level: AlertLevelCritical, Signature: "md0"}, rule)
+	if ev := m.Observe(now.Add(time.Minute), AlertObservation{ID: "raid", Level: AlertLevelCritical, Signature: "md0"}, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("same signature should be deduplicated, got %+v", ev)
+	}
+	if ev := m.Observe(now.Add(2*time.Minute), AlertObservation{ID: "raid", Level: AlertLevelCritical, Signature: "md0 | md1"}, rule); ev.Kind != AlertFired || !ev.Notify {
+		t.Fatalf("changed signature should notify, got %+v", ev)
+	}
+}
+
+func TestAlertManagerQuietHoldsFire(t *testing.T) {
+	m := NewAlertManager()
+	rule := AlertRule{Cooldown: time.Hour}
+	now := time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)
+	down := AlertObservation{ID: "net", Level: AlertLevelCritical, Quiet: true}
+
+	if ev := m.Observe(now, down, rule); ev.Kind != AlertFired || ev.Notify {
+		t.Fatalf("a fire in quiet hours should be recorded but not notified, got %+v", ev)
+	}
+	if ev := m.Observe(now.Add(time.Minute), down, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("held fire should wait for the end of quiet hours, got %+v", ev)
+	}
+	down.Quiet = false
+	ev := m.Observe(now.Add(5*time.Hour), down, rule)
+	if ev.Kind != AlertFired || !ev.Notify || !ev.Deferred || !ev.Since.Equal(now) {
+		t.Fatalf("held fire should be delivered after quiet hours, got %+v", ev)
+	}
+	if ev := m.Observe(now.Add(5*time.Hour+time.Minute), down, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("delivered fire should start the cooldown, got %+v", ev)
+	}
+}
+
+func TestAlertsConfigRule(t *testing.T) {
+	a := AlertsConfig{Rules: map[string]AlertRuleConfig{
+		"container:":      {CooldownMinutes: 10},
+		"container:db":    {CooldownMinutes: 1},
+		"disk:/mnt":       {SustainMinutes: 2},
+		"disk:/mnt/media": {SustainMinutes: 3},
+	}}
+
+	cases := map[string]int{"container:db": 1, "container:web": 10}
+	for id, want := range cases {
+		if r, ok := a.Rule(id); !ok || r.CooldownMinutes != want {
+			t.Errorf("Rule(%q) = %+v, %v; want cooldown %d", id, r, ok, want)
+		}
+	}
+	if r, _ := a.Rule("disk:/mnt/media/tv"); r.SustainMinutes != 3 {
+		t.Errorf("longest prefix should win, got %+v", r)
+	}
+	if _, ok := a.Rule("cpu"); ok {
+		t.Errorf("cpu should fall back to defaults")
+	}
+}
+
+func TestAlertManagerAckSnoozeMute(t *testing.T) {
+	m := NewAlertManager()
+	rule := AlertRule{Cooldown: 10 * time.Minute, NotifyResolved: true}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	down := AlertObservation{ID: "container:db", Level: AlertLevelCritical}
+
+	m.Observe(now, down, rule)
+	m.Ack("container:db")
+	if ev := m.Observe(now.Add(30*time.Minute), down, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("acknowledged alert should not remind, got %+v", ev)
+	}
+	m.Observe(now.Add(31*time.Minute), AlertObservation{ID: "container:db"}, rule)
+	if m.Silence("container:db").Acked {
+		t.Fatalf("ack should clear once the alert resolves")
+	}
+
+	m.Snooze("container:db", now.Add(2*time.Hour))
+	if ev := m.Observe(now.Add(time.Hour), down, rule); ev.Kind != AlertFired || ev.Notify {
+		t.Fatalf("snoozed alert should fire silently, got %+v", ev)
+	}
+	if ev := m.Observe(now.Add(2*time.Hour), down, rule); ev.Kind != AlertReminder || !ev.Notify {
+		t.Fatalf("expected notification once the snooze expires, got %+v", ev)
+	}
+
+	m.SetMuted("container:db", true)
+	if ev := m.Observe(now.Add(5*time.Hour), down, rule); ev.Notify {
+		t.Fatalf("muted alert should not notify, got %+v", ev)
+	}
+	if got := m.Silences(now.Add(5 * time.Hour)); !got["container:db"].Muted {
+		t.Fatalf("mute should be exported for persistence, got %+v", got)
+	}
+
+	restored := NewAlertManager()
+	restored.RestoreSilences(m.Silences(now))
+	if !restored.Silence("container:db").Muted {
+		t.Fatalf("mute not restored")
+	}
+}
+
+func TestAlertManagerEscalation(t *testing.T) {
+	m := NewAlertManager()
+	rule := AlertRule{Cooldown: time.Hour, EscalateAfter: 15 * time.Minute}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	down := AlertObservation{ID: "raid", Level: AlertLevelCritical, Signature: "md0"}
+
+	m.Observe(now, down, rule)
+	if ev := m.Observe(now.Add(10*time.Minute), down, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("escalated too early: %+v", ev)
+	}
+	if ev := m.Observe(now.Add(15*time.Minute), down, rule); ev.Kind != AlertEscalated || !ev.Notify {
+		t.Fatalf("expected escalation, got %+v", ev)
+	}
+	if ev := m.Observe(now.Add(20*time.Minute), down, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("escalation should happen once, got %+v", ev)
+	}
+
+	acked := NewAlertManager()
+	acked.Observe(now, down, rule)
+	acked.Ack("raid")
+	if ev := acked.Observe(now.Add(time.Hour), down, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("acknowledged alert should not escalate, got %+v", ev)
+	}
+
+	quiet := NewAlertManager()
+	quiet.Observe(now, down, rule)
+	held := down
+	held.Quiet = true
+	if ev := quiet.Observe(now.Add(20*time.Minute), held, rule); ev.Kind != AlertUnchanged {
+		t.Fatalf("escalation should wait for the end of quiet hours, got %+v", ev)
+	}
+	if ev := quiet.Observe(now.Add(8*time.Hour), down, rule); ev.Kind != AlertEscalated || !ev.Notify {
+		t.Fatalf("expected the escalation after quiet hours, got %+v", ev)
+	}
+}
+
+func TestAlertManagerRefLookup(t *testing.T) {
+	m := NewAlertManager()
+	group := m.Ref("ram", "cpu")
+	if ids := m.Lookup(group); len(ids) != 2 || ids[0] != "cpu" || ids[1] != "ram" {
+		t.Fatalf("Lookup(group) = %v", ids)
+	}
+
+	// A fresh manager (e.g. after a restart) still resolves single-alert refs
+	// once the alert is tracked again.
+	single := m.Ref("container:db")
+	fresh := NewAlertManager()
+	if ids := fresh.Lookup(single); ids != nil {
+		t.Fatalf("unknown ref should not resolve, got %v", ids)
+	}
+	fresh.Observe(time.Now(), AlertObservation{ID: "container:db", Level: AlertLevelCritical}, AlertRule{})
+	if ids := fresh.Lookup(single); len(ids) != 1 || ids[0] != "container:db" {
+		t.Fatalf("single ref not resolved after restart, got %v", ids)
+	}
+}
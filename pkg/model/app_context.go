@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"os"
 	"time"
+
+	"nasbot/internal/history"
 )
 
 // AppContext holds the application dependencies and state.
@@ -17,6 +19,8 @@ type AppContext struct {
 	Monitor  *MonitorState
 	Settings *UserSettings
 	HTTP     *http.Client
+	// History is the persistent metric history; nil when disabled.
+	History *history.Store
 }
 
 // ThreadSafeStats wraps stats with a mutex
@@ -56,42 +60,156 @@ type DockerManager struct {
 	LastStates        map[string]bool      // true = running
 	ContainerDowntime map[string]time.Time // When it went down
 	PruneDoneToday    bool
+	// Event stream state: while EventsActive, state changes are applied as
+	// they happen and the poller only acts as a safety net.
+	EventsActive  bool
+	Health        map[string]string    // last health_status per container
+	OOMKilled     map[string]bool      // an oom event is pending for the next die
+	StopRequested map[string]time.Time // stop/kill seen, the next die is expected
+	// Restart-loop tracking
+	Crashes          map[string][]time.Time     // unexpected exits inside the loop window
+	RestartCounts    map[string][]RestartSample // RestartCount readings of the last hour, oldest first
+	RestartLoop      map[string]bool            // a restart-loop alert is outstanding
+	RestartCheckTime time.Time
+	// Image update checks
+	Updates        map[string]ImageUpdate // by container name
+	UpdatesChecked time.Time
+	Updating       map[string]bool // an update of the container is in progress
+}
+
+// RestartSample is a RestartCount reading.
+type RestartSample struct {
+	At    time.Time
+	Count int
+}
+
+// ImageUpdate is the result of comparing a container's image with its
+// registry.
+type ImageUpdate struct {
+	Image    string // reference the container was created from
+	Local    string // digest of the local image
+	Remote   string // digest the registry serves for the tag
+	Outdated bool
+	Err      string
+	Checked  time.Time
 }
 
 // SmartResult holds the last known SMART status for a disk
 type SmartResult struct {
-	Temp   int
-	Health string
+	Temp         int
+	Health       string
+	Model        string
+	PowerOnHours int64            // -1 when unknown
+	PercentUsed  int              // SSD wear, -1 when unknown
+	Counters     map[string]int64 // reallocated sectors, CRC errors...
+	// Increases holds the previous value of each counter that grew since
+	// the reading before this one.
+	Increases map[string]int64
+	// LastSelfTest is the newest self-test log entry, e.g.
+	// "Extended offline: Completed: read failure".
+	LastSelfTest   string
+	SelfTestFailed bool
+	// Standby is set when the disk was spun down at the last check; the
+	// other fields then hold the reading from before.
+	Standby bool
+}
+
+// DiskPowerStats tracks the spin state of a disk over a report period.
+type DiskPowerStats struct {
+	Standby     bool
+	LastCheck   time.Time
+	SpinUps     int
+	StandbyTime time.Duration
+	Tracked     time.Duration // time covered by the checks
+	StartStops  int64         // last Start_Stop_Count seen, -1 when unknown
+}
+
+// Observe records a power-state check. startStops is the disk's
+// Start_Stop_Count, or -1 when it has none or was not read. Spin-ups come
+// from that count when the disk has it, so the ones between two checks
+// are not missed, and from standby-to-active changes otherwise. An
+// interval between two standby checks counts as standby, half of it when
+// the state changed.
+func (p *DiskPowerStats) Observe(now time.Time, standby bool, startStops int64) {
+	if p.LastCheck.IsZero() {
+		p.StartStops = -1
+	} else {
+		elapsed := now.Sub(p.LastCheck)
+		p.Tracked += elapsed
+		switch {
+		case p.Standby && standby:
+			p.StandbyTime += elapsed
+		case p.Standby != standby:
+			p.StandbyTime += elapsed / 2
+		}
+	}
+	if !standby {
+		switch {
+		case startStops >= 0 && p.StartStops >= 0:
+			p.SpinUps += int(max(0, startStops-p.StartStops))
+		case p.Standby:
+			p.SpinUps++
+		}
+		if startStops >= 0 {
+			p.StartStops = startStops
+		}
+	}
+	p.Standby = standby
+	p.LastCheck = now
+}
+
+// ResetPeriod starts a new report period.
+func (p *DiskPowerStats) ResetPeriod() {
+	p.SpinUps = 0
+	p.StandbyTime = 0
+	p.Tracked = 0
+}
+
+// SmartTestRun is a self-test the bot started and is waiting for.
+type SmartTestRun struct {
+	Kind     string // "short" or "long"
+	Started  time.Time
+	LastPoll time.Time
+	// LogLen and LogHead describe the self-test log before the test, so
+	// its own entry can be told apart.
+	LogLen  int
+	LogHead string
+}
+
+// RaidSyncRun is an md resync, recovery, reshape or check being followed.
+type RaidSyncRun struct {
+	Action       string
+	Started      time.Time // when the bot first saw it
+	FirstPercent float64   // progress when first seen
+	Reported     float64   // progress at the last update
 }
 
 // MonitorState holds historical trends and alert states
 type MonitorState struct {
-	Mu                         Mutex
-	CPUTrend                   []TrendPoint
-	RAMTrend                   []TrendPoint
-	LastTempAlert              time.Time
-	Healthchecks               HealthchecksState
-	HealthInDowntime           bool
-	RaidLastSignature          string
-	RaidDownSince              time.Time
-	RaidAlertTime              time.Time
-	LastCriticalAlert          time.Time
-	LastCriticalContainerAlert map[string]time.Time
-	SmartLastCheckTime         time.Time
-	SmartCache                 map[string]SmartResult
-	NetFailCount               int
-	NetLastCheckTime           time.Time
-	NetConsecutiveDegraded     int
-	NetDownSince               time.Time
-	NetDownAlertTime           time.Time
-	NetDNSAlertTime            time.Time
-	NetForceRebootTriggered    bool
-	KwLastSignatures           map[string]string
-	KwInitialized              bool
-	KwLastCheckTime            time.Time
-	KwConsecutiveCheckErrors   int
-	KwLastCheckError           string
-	RecentOOMs                 []time.Time
+	Mu                       Mutex
+	CPUTrend                 []TrendPoint
+	RAMTrend                 []TrendPoint
+	Alerts                   *AlertManager
+	Healthchecks             HealthchecksState
+	HealthInDowntime         bool
+	SmartLastCheckTime       time.Time
+	SmartCache               map[string]SmartResult
+	SmartCounters            map[string]map[string]int64 // last counters per disk, persisted
+	SmartTestsStarted        map[string]time.Time        // "sda:short" -> last scheduled start
+	SmartTestsRunning        map[string]SmartTestRun     // by disk
+	DiskPower                map[string]*DiskPowerStats  // by disk
+	RaidSyncs                map[string]RaidSyncRun      // by array
+	NetFailCount             int
+	NetLastCheckTime         time.Time
+	NetConsecutiveDegraded   int
+	NetDownSince             time.Time
+	NetForceRebootTriggered  bool
+	KwLastSignatures         map[string]string
+	KwInitialized            bool
+	KwLastCheckTime          time.Time
+	KwConsecutiveCheckErrors int
+	KwLastCheckError         string
+	RecentOOMs               []time.Time
 }
 
 // UserSettings holds persistent user preferences (loaded from JSON)
@@ -192,11 +310,16 @@ func InitApp(cfg *Config) *AppContext {
 			ContainerDowntime: make(map[string]time.Time),
 		},
 		Monitor: &MonitorState{
-			CPUTrend:                   make([]TrendPoint, 0, 72),
-			RAMTrend:                   make([]TrendPoint, 0, 72),
-			LastCriticalContainerAlert: make(map[string]time.Time),
-			SmartCache:                 make(map[string]SmartResult),
-			KwLastSignatures:           make(map[string]string),
+			CPUTrend:          make([]TrendPoint, 0, 72),
+			RAMTrend:          make([]TrendPoint, 0, 72),
+			Alerts:            NewAlertManager(),
+			SmartCache:        make(map[string]SmartResult),
+			SmartCounters:     make(map[string]map[string]int64),
+			SmartTestsStarted: make(map[string]time.Time),
+			SmartTestsRunning: make(map[string]SmartTestRun),
+			DiskPower:         make(map[string]*DiskPowerStats),
+			RaidSyncs:         make(map[string]RaidSyncRun),
+			KwLastSignatures:  make(map[string]string),
 		},
 		Settings: &UserSettings{
 			Language:       "en",
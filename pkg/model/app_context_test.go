@@ -78,3 +78,32 @@ func TestContextLogger(t *testing.T) {
 	ctx.LogError("test error", slog.String("key", "val"))
 	ctx.LogInfo("test info", slog.String("key", "val"))
 }
+
+func TestDiskPowerStatsObserve(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(min int) time.Time { return t0.Add(time.Duration(min) * time.Minute) }
+
+	// Without Start_Stop_Count, spin-ups are the observed wake-ups.
+	var p DiskPowerStats
+	p.Observe(at(0), false, -1)
+	p.Observe(at(10), true, -1)
+	p.Observe(at(20), true, -1)
+	p.Observe(at(30), false, -1)
+	if p.SpinUps != 1 || p.StandbyTime != 20*time.Minute || p.Tracked != 30*time.Minute {
+		t.Fatalf("unexpected stats: %+v", p)
+	}
+
+	// With it, wake-ups between two checks are counted too.
+	var q DiskPowerStats
+	q.Observe(at(0), false, 100)
+	q.Observe(at(10), true, -1)
+	q.Observe(at(20), false, 103)
+	if q.SpinUps != 3 || q.StandbyTime != 10*time.Minute {
+		t.Fatalf("unexpected stats: %+v", q)
+	}
+
+	q.ResetPeriod()
+	if q.SpinUps != 0 || q.StandbyTime != 0 || q.Tracked != 0 || q.StartStops != 103 {
+		t.Fatalf("reset lost the spin-up baseline: %+v", q)
+	}
+}
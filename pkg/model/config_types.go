@@ -3,6 +3,12 @@ package model
 type Config struct {
 	BotToken           string                `json:"bot_token"`
 	AllowedUserID      int64                 `json:"allowed_user_id"`
+	Users              []UserConfig          `json:"users"`
+	Permissions        PermissionsConfig     `json:"permissions"`
+	Notifiers          NotifiersConfig       `json:"notifiers"`
+	Alerts             AlertsConfig          `json:"alerts"`
+	API                APIConfig             `json:"api"`
+	History            HistoryConfig         `json:"history"`
 	GeminiAPIKey       string                `json:"gemini_api_key"`
 	Paths              PathsConfig           `json:"paths"`
 	Timezone           string                `json:"timezone"`
@@ -25,6 +31,127 @@ type Config struct {
 	AdBlock            AdBlockConfig         `json:"adblock"`
 }
 
+// UserConfig describes an additional Telegram user allowed to talk to the bot.
+// AllowedUserID is always treated as an admin, even when not listed here.
+type UserConfig struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"` // "admin", "operator" or "viewer"
+	// Alert topics the user receives ("critical", "warning", "info", "report").
+	// Empty means every topic.
+	Subscriptions []string `json:"subscriptions"`
+}
+
+// PermissionsConfig overrides the minimum role required by a command name or
+// a callback data prefix. Unlisted entries fall back to the built-in defaults.
+type PermissionsConfig struct {
+	Commands  map[string]string `json:"commands"`
+	Callbacks map[string]string `json:"callbacks"`
+}
+
+// NotifiersConfig selects the channels alerts are delivered to. Each channel
+// lists the topics it receives ("critical", "warning", "info", "report");
+// an empty list means every topic.
+type NotifiersConfig struct {
+	Telegram TelegramNotifierConfig `json:"telegram"`
+	Webhook  WebhookNotifierConfig  `json:"webhook"`
+	Ntfy     NtfyNotifierConfig     `json:"ntfy"`
+	Gotify   GotifyNotifierConfig   `json:"gotify"`
+	SMTP     SMTPNotifierConfig     `json:"smtp"`
+}
+
+// TelegramNotifierConfig is opt-out: Telegram stays the primary channel unless
+// explicitly disabled.
+type TelegramNotifierConfig struct {
+	Disabled bool     `json:"disabled"`
+	Topics   []string `json:"topics"`
+}
+
+type WebhookNotifierConfig struct {
+	Enabled bool              `json:"enabled"`
+	Topics  []string          `json:"topics"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+type NtfyNotifierConfig struct {
+	Enabled bool     `json:"enabled"`
+	Topics  []string `json:"topics"`
+	Server  string   `json:"server"`
+	Topic   string   `json:"topic"`
+	Token   string   `json:"token"`
+}
+
+type GotifyNotifierConfig struct {
+	Enabled bool     `json:"enabled"`
+	Topics  []string `json:"topics"`
+	URL     string   `json:"url"`
+	Token   string   `json:"token"`
+}
+
+type SMTPNotifierConfig struct {
+	Enabled  bool     `json:"enabled"`
+	Topics   []string `json:"topics"`
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// AlertsConfig tunes the alert pipeline. Rules are keyed by alert ID
+// ("cpu", "ram", "swap", "disk:ssd", "disk:/mnt/data", "temp:cpu",
+// "container:nginx", "raid", "net", ...) or by an ID prefix such as
+// "container:"; unmatched IDs use Defaults.
+type AlertsConfig struct {
+	Defaults   AlertRuleConfig            `json:"defaults"`
+	Rules      map[string]AlertRuleConfig `json:"rules"`
+	Escalation AlertEscalationConfig      `json:"escalation"`
+}
+
+// AlertEscalationConfig re-sends critical alerts nobody acknowledged within
+// AfterMinutes (0 disables it). Users and Channels (notifier names such as
+// "ntfy" or "smtp") receive the escalation on top of the usual recipients.
+type AlertEscalationConfig struct {
+	AfterMinutes int      `json:"after_minutes"`
+	Users        []int64  `json:"users"`
+	Channels     []string `json:"channels"`
+}
+
+type AlertRuleConfig struct {
+	// Fire only if the condition holds for this many minutes.
+	SustainMinutes int `json:"sustain_minutes"`
+	// Clear only once the value drops this far below the threshold.
+	Hysteresis float64 `json:"hysteresis"`
+	// Minimum minutes between notifications for the same alert. Zero falls
+	// back to intervals.critical_alert_cooldown_minutes.
+	CooldownMinutes int  `json:"cooldown_minutes"`
+	NotifyResolved  bool `json:"notify_resolved"`
+}
+
+// APIConfig controls the embedded HTTP server. /metrics is public unless
+// MetricsRequireToken is set; the JSON API under /api/ always needs Token.
+type APIConfig struct {
+	Enabled             bool   `json:"enabled"`
+	Listen              string `json:"listen"`
+	Token               string `json:"token"`
+	MetricsRequireToken bool   `json:"metrics_require_token"`
+}
+
+// HistoryConfig controls the persistent metric history. Samples are kept
+// raw and rolled up into 5-minute, hourly and daily averages, each with its
+// own retention.
+type HistoryConfig struct {
+	Enabled              bool `json:"enabled"`
+	SampleSeconds        int  `json:"sample_seconds"`
+	RawRetentionHours    int  `json:"raw_retention_hours"`
+	FiveMinRetentionDays int  `json:"five_min_retention_days"`
+	HourlyRetentionDays  int  `json:"hourly_retention_days"`
+	DailyRetentionDays   int  `json:"daily_retention_days"`
+	FlushMinutes         int  `json:"flush_minutes"`
+}
+
 type BackupConfig struct {
 	TargetUserID int64 `json:"target_user_id"`
 }
@@ -91,6 +218,32 @@ type DiskIOConfig struct {
 type SmartConfig struct {
 	Enabled bool     `json:"enabled"`
 	Devices []string `json:"devices"`
+	// WearWarningPercent warns when an SSD has used this much of its
+	// rated life.
+	WearWarningPercent int                 `json:"wear_warning_percent"`
+	SelfTests          SmartSelfTestConfig `json:"self_tests"`
+}
+
+// SmartSelfTestConfig schedules smartctl self-tests. Short and Long apply
+// to every SMART device not listed in Devices.
+type SmartSelfTestConfig struct {
+	Enabled bool                           `json:"enabled"`
+	Short   SmartTestSchedule              `json:"short"`
+	Long    SmartTestSchedule              `json:"long"`
+	Devices map[string]SmartDeviceSchedule `json:"devices"`
+}
+
+// SmartDeviceSchedule overrides the self-test schedules of one disk.
+type SmartDeviceSchedule struct {
+	Short SmartTestSchedule `json:"short"`
+	Long  SmartTestSchedule `json:"long"`
+}
+
+// SmartTestSchedule runs a self-test at Hour on Day: "daily", a weekday
+// ("sunday"), a day of the month ("1" to "28") or "never".
+type SmartTestSchedule struct {
+	Day  string `json:"day"`
+	Hour int    `json:"hour"`
 }
 
 type StressTrackingConfig struct {
@@ -99,9 +252,27 @@ type StressTrackingConfig struct {
 }
 
 type DockerConfig struct {
+	// Runtime is the container engine, "docker" or "podman".
+	Runtime string `json:"runtime"`
+	// Socket is the Docker Engine API socket; the runtime's CLI is used when
+	// it cannot be reached. Empty means the runtime's default.
+	Socket                   string                  `json:"socket"`
 	Watchdog                 DockerWatchdogConfig    `json:"watchdog"`
 	WeeklyPrune              DockerPruneConfig       `json:"weekly_prune"`
 	AutoRestartOnRAMCritical DockerAutoRestartConfig `json:"auto_restart_on_ram_critical"`
+	RestartLoop              DockerRestartLoopConfig `json:"restart_loop"`
+	// AutoRestartUnhealthy restarts critical containers whose healthcheck
+	// fails, sharing the max_restarts_per_hour budget.
+	AutoRestartUnhealthy bool                `json:"auto_restart_unhealthy"`
+	Updates              DockerUpdatesConfig `json:"updates"`
+	// ContainerLimits holds thresholds by container name; "*" applies to
+	// containers without an entry of their own.
+	ContainerLimits map[string]ContainerLimitConfig `json:"container_limits"`
+	Logs            DockerLogsConfig                `json:"logs"`
+	Dependencies    DockerDependencyConfig          `json:"dependencies"`
+	// ExecActions are commands offered as buttons on a container, by
+	// container name.
+	ExecActions map[string][]ExecActionConfig `json:"exec_actions"`
 }
 
 type DockerWatchdogConfig struct {
@@ -122,6 +293,66 @@ type DockerAutoRestartConfig struct {
 	RAMThreshold       float64 `json:"ram_threshold"`
 }
 
+// DockerRestartLoopConfig flags containers that exit more than MaxRestarts
+// times within WindowMinutes.
+type DockerRestartLoopConfig struct {
+	Enabled       bool `json:"enabled"`
+	MaxRestarts   int  `json:"max_restarts"`
+	WindowMinutes int  `json:"window_minutes"`
+}
+
+// DockerUpdatesConfig compares container images with their registry every
+// CheckIntervalHours. An update whose container is not running and healthy
+// within RollbackGraceSeconds is rolled back to the previous image.
+type DockerUpdatesConfig struct {
+	Enabled              bool `json:"enabled"`
+	CheckIntervalHours   int  `json:"check_interval_hours"`
+	RollbackGraceSeconds int  `json:"rollback_grace_seconds"`
+	// InsecureRegistries are "host:port" registries reached over plain HTTP.
+	InsecureRegistries []string `json:"insecure_registries"`
+}
+
+// ContainerLimitConfig is checked every monitor cycle; a zero field
+// disables that check.
+type ContainerLimitConfig struct {
+	// CPUPercent is measured like docker stats: 100 is one full core.
+	CPUPercent float64 `json:"cpu_percent"`
+	// MemPercent is relative to the container's memory limit, or to the
+	// host RAM when it has none.
+	MemPercent         float64 `json:"mem_percent"`
+	MemMB              float64 `json:"mem_mb"`
+	MaxRestartsPerHour int     `json:"max_restarts_per_hour"`
+}
+
+// DockerLogsConfig watches the size of json-file container logs every
+// monitor cycle; a zero threshold disables that check.
+type DockerLogsConfig struct {
+	Enabled            bool    `json:"enabled"`
+	MaxSizeMB          float64 `json:"max_size_mb"`
+	MaxGrowthMBPerHour float64 `json:"max_growth_mb_per_hour"`
+}
+
+// DockerDependencyConfig orders container starts: restart-all, the
+// watchdog's recovery and the boot check start a container only once the
+// containers it depends on are running and healthy.
+type DockerDependencyConfig struct {
+	// DependsOn maps a container to the containers it needs.
+	DependsOn            map[string][]string `json:"depends_on"`
+	HealthTimeoutSeconds int                 `json:"health_timeout_seconds"`
+	// StartOnBoot starts stopped critical containers and their
+	// dependencies when NASBot starts.
+	StartOnBoot bool `json:"start_on_boot"`
+}
+
+// ExecActionConfig is a command run inside a container after confirmation.
+// Command is passed as arguments, never through a shell.
+type ExecActionConfig struct {
+	Name           string   `json:"name"`
+	Command        []string `json:"command"`
+	User           string   `json:"user"`
+	TimeoutSeconds int      `json:"timeout_seconds"`
+}
+
 type IntervalsConfig struct {
 	StatsSeconds              int `json:"stats_seconds"`
 	MonitorSeconds            int `json:"monitor_seconds"`
@@ -172,6 +403,9 @@ type RaidWatchdogConfig struct {
 	CheckIntervalSecs int  `json:"check_interval_seconds"`
 	CooldownMins      int  `json:"cooldown_minutes"`
 	RecoveryNotify    bool `json:"recovery_notify"`
+	// ProgressStepPercent is how far a rebuild advances between two
+	// progress updates; 0 only reports its start and end.
+	ProgressStepPercent int `json:"progress_step_percent"`
 }
 
 type AdBlockConfig struct {
@@ -0,0 +1,36 @@
+package model
+
+// Metric names recorded in AppContext.History.
+const (
+	HistoryCPU          = "cpu"
+	HistoryRAM          = "ram"
+	HistorySwap         = "swap"
+	HistoryRAMFreeMB    = "ram_free_mb"
+	HistoryLoad1m       = "load_1m"
+	HistoryLoad5m       = "load_5m"
+	HistoryLoad15m      = "load_15m"
+	HistoryDiskReadMBs  = "disk_read_mbs"
+	HistoryDiskWriteMBs = "disk_write_mbs"
+	HistoryDiskUtil     = "disk_util"
+	HistoryNetRxMbps    = "net_rx_mbps"
+	HistoryNetTxMbps    = "net_tx_mbps"
+	HistoryNetRxTotalMB = "net_rx_total_mb"
+	HistoryNetTxTotalMB = "net_tx_total_mb"
+	HistoryUptimeHours  = "uptime_hours"
+)
+
+// HistoryVolumeUsed is the used-percent metric of a mount point.
+func HistoryVolumeUsed(mount string) string { return "vol_used:" + mount }
+
+// HistoryVolumeFree is the free-bytes metric of a mount point.
+func HistoryVolumeFree(mount string) string { return "vol_free:" + mount }
+
+// HistoryTemp is the temperature metric of a sensor ("cpu") or disk ("sda").
+func HistoryTemp(sensor string) string { return "temp:" + sensor }
+
+// HistorySmart is a SMART metric of a disk: a counter such as
+// "reallocated_sectors", "power_on_hours" or "percent_used".
+func HistorySmart(dev, metric string) string { return "smart_" + metric + ":" + dev }
+
+// HistoryContainerMem is the memory usage (MiB) metric of a container.
+func HistoryContainerMem(name string) string { return "container_mem:" + name }
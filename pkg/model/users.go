@@ -0,0 +1,112 @@
+package model
+
+import "strings"
+
+// User roles, from least to most privileged.
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+// Alert topics users can subscribe to.
+const (
+	AlertTopicCritical = "critical"
+	AlertTopicWarning  = "warning"
+	AlertTopicInfo     = "info"
+	AlertTopicReport   = "report"
+)
+
+// RoleRank orders roles so they can be compared. Unknown roles rank 0.
+func RoleRank(role string) int {
+	switch strings.ToLower(strings.TrimSpace(role)) {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	}
+	return 0
+}
+
+// RoleAtLeast reports whether role grants at least the privileges of required.
+func RoleAtLeast(role, required string) bool {
+	r := RoleRank(role)
+	return r > 0 && r >= RoleRank(required)
+}
+
+// UserRole returns the role of a Telegram user, or "" if the user is unknown.
+// AllowedUserID is always an admin so single-user configs keep working.
+func (c *Config) UserRole(userID int64) string {
+	if c == nil || userID == 0 {
+		return ""
+	}
+	if c.AllowedUserID != 0 && userID == c.AllowedUserID {
+		return RoleAdmin
+	}
+	for _, u := range c.Users {
+		if u.ID == userID {
+			return strings.ToLower(strings.TrimSpace(u.Role))
+		}
+	}
+	return ""
+}
+
+// IsAuthorized reports whether the user may interact with the bot at all.
+func (c *Config) IsAuthorized(userID int64) bool {
+	return RoleRank(c.UserRole(userID)) > 0
+}
+
+// AlertRecipients returns the chat IDs subscribed to an alert topic.
+// AllowedUserID receives everything unless it narrows its own subscriptions
+// through a matching entry in Users.
+func (c *Config) AlertRecipients(topic string) []int64 {
+	if c == nil {
+		return nil
+	}
+	var ids []int64
+	seen := make(map[int64]struct{})
+	add := func(id int64) {
+		if id == 0 {
+			return
+		}
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	ownerListed := false
+	for _, u := range c.Users {
+		if u.ID == c.AllowedUserID {
+			ownerListed = true
+		}
+	}
+	if !ownerListed {
+		add(c.AllowedUserID)
+	}
+	for _, u := range c.Users {
+		if u.ID != c.AllowedUserID && RoleRank(u.Role) == 0 {
+			continue
+		}
+		if subscribedTo(u.Subscriptions, topic) {
+			add(u.ID)
+		}
+	}
+	return ids
+}
+
+func subscribedTo(subs []string, topic string) bool {
+	if len(subs) == 0 {
+		return true
+	}
+	for _, s := range subs {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == topic || s == "all" {
+			return true
+		}
+	}
+	return false
+}
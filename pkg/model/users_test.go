@@ -0,0 +1,63 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfigUserRole(t *testing.T) {
+	cfg := &Config{
+		AllowedUserID: 1,
+		Users: []UserConfig{
+			{ID: 2, Role: "Operator"},
+			{ID: 3, Role: "viewer"},
+			{ID: 4, Role: "guest"},
+		},
+	}
+
+	cases := map[int64]string{1: RoleAdmin, 2: RoleOperator, 3: RoleViewer, 4: "guest", 5: ""}
+	for id, want := range cases {
+		if got := cfg.UserRole(id); got != want {
+			t.Errorf("UserRole(%d) = %q, want %q", id, got, want)
+		}
+	}
+	if cfg.IsAuthorized(4) || cfg.IsAuthorized(5) {
+		t.Errorf("unknown roles and users must not be authorized")
+	}
+	if !cfg.IsAuthorized(3) {
+		t.Errorf("viewer should be authorized")
+	}
+}
+
+func TestRoleAtLeast(t *testing.T) {
+	if !RoleAtLeast(RoleAdmin, RoleOperator) || !RoleAtLeast(RoleViewer, RoleViewer) {
+		t.Fatalf("expected higher or equal roles to pass")
+	}
+	if RoleAtLeast(RoleViewer, RoleOperator) || RoleAtLeast("", RoleViewer) {
+		t.Fatalf("expected lower or empty roles to fail")
+	}
+}
+
+func TestConfigAlertRecipients(t *testing.T) {
+	cfg := &Config{
+		AllowedUserID: 1,
+		Users: []UserConfig{
+			{ID: 2, Role: RoleOperator},
+			{ID: 3, Role: RoleViewer, Subscriptions: []string{"critical"}},
+			{ID: 4, Role: "unknown"},
+		},
+	}
+
+	if got, want := cfg.AlertRecipients(AlertTopicCritical), []int64{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("critical recipients = %v, want %v", got, want)
+	}
+	if got, want := cfg.AlertRecipients(AlertTopicReport), []int64{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("report recipients = %v, want %v", got, want)
+	}
+
+	// The owner can narrow its own subscriptions by listing itself.
+	cfg.Users = append(cfg.Users, UserConfig{ID: 1, Role: RoleAdmin, Subscriptions: []string{"critical"}})
+	if got, want := cfg.AlertRecipients(AlertTopicInfo), []int64{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("info recipients = %v, want %v", got, want)
+	}
+}